@@ -43,6 +43,11 @@ func HandleApiError(r *http.Response, errBody []byte) *shared.ApiError {
 
 func refreshTokenIfNeeded(apiErr *shared.ApiError) (bool, *shared.ApiError) {
 	if apiErr.Type == shared.ApiErrorTypeInvalidToken {
+		// api tokens can't be refreshed -- a new one needs to be created
+		if auth.UsingApiToken() {
+			return false, &shared.ApiError{Type: shared.ApiErrorTypeInvalidToken, Status: apiErr.Status, Msg: "Invalid, expired, or revoked api token"}
+		}
+
 		err := auth.RefreshInvalidToken()
 		if err != nil {
 			return false, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: "error refreshing invalid token"}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"plandex-cli/types"
+	"strconv"
 	"time"
 
 	shared "plandex-shared"
@@ -16,27 +18,47 @@ import (
 // 3 heartbeat misses = timeout
 const HeartbeatTimeout = 16 * time.Second
 
-func connectPlanRespStream(body io.ReadCloser, onStream types.OnStreamPlan) {
+const MaxStreamResumeAttempts = 5
+const streamResumeBackoff = 1 * time.Second
+
+func connectPlanRespStream(planId, branch string, body io.ReadCloser, onStream types.OnStreamPlan) {
 	reader := bufio.NewReader(body)
 	timer := time.NewTimer(HeartbeatTimeout)
 	defer timer.Stop()
 
+	// seq of the last message received, used to resume the stream after a disconnect
+	var lastSeq int64
+
 	go func() {
 		for {
+			var streamErr error
 			select {
 			case <-timer.C:
 				log.Println("Connection to plan stream timed out due to missing heartbeats")
-				onStream(types.OnStreamPlanParams{Msg: nil, Err: fmt.Errorf("connection to plan stream timed out due to missing heartbeats")})
-				body.Close()
-				return
+				streamErr = fmt.Errorf("connection to plan stream timed out due to missing heartbeats")
 			default:
 			}
 
-			s, err := readUntilSeparator(reader, shared.STREAM_MESSAGE_SEPARATOR)
-			if err != nil {
-				log.Println("Error reading line:", err)
-				onStream(types.OnStreamPlanParams{Msg: nil, Err: err})
+			var s string
+			if streamErr == nil {
+				s, streamErr = readUntilSeparator(reader, shared.STREAM_MESSAGE_SEPARATOR)
+				if streamErr != nil {
+					log.Println("Error reading line:", streamErr)
+				}
+			}
+
+			if streamErr != nil {
 				body.Close()
+
+				resumedBody := resumePlanStream(planId, branch, lastSeq)
+				if resumedBody != nil {
+					body = resumedBody
+					reader = bufio.NewReader(body)
+					timer.Reset(HeartbeatTimeout)
+					continue
+				}
+
+				onStream(types.OnStreamPlanParams{Msg: nil, Err: streamErr})
 				return
 			}
 
@@ -48,7 +70,7 @@ func connectPlanRespStream(body io.ReadCloser, onStream types.OnStreamPlan) {
 			}
 
 			var msg shared.StreamMessage
-			err = json.Unmarshal([]byte(s), &msg)
+			err := json.Unmarshal([]byte(s), &msg)
 			if err != nil {
 				log.Println("Error unmarshalling message:", err)
 				onStream(types.OnStreamPlanParams{Msg: nil, Err: err})
@@ -56,6 +78,10 @@ func connectPlanRespStream(body io.ReadCloser, onStream types.OnStreamPlan) {
 				return
 			}
 
+			if msg.Seq > lastSeq {
+				lastSeq = msg.Seq
+			}
+
 			// log.Println("connectPlanRespStream: received message:", msg)
 
 			onStream(types.OnStreamPlanParams{Msg: &msg, Err: nil})
@@ -69,6 +95,47 @@ func connectPlanRespStream(body io.ReadCloser, onStream types.OnStreamPlan) {
 	}()
 }
 
+// resumePlanStream reconnects to a plan's stream after a disconnect, asking the server to replay any messages after lastSeq. Returns nil if the stream can't be resumed, for example because the plan is no longer active.
+func resumePlanStream(planId, branch string, lastSeq int64) io.ReadCloser {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/connect", GetApiHost(), planId, branch)
+
+	for attempt := 1; attempt <= MaxStreamResumeAttempts; attempt++ {
+		time.Sleep(time.Duration(attempt) * streamResumeBackoff)
+
+		log.Printf("Resuming plan stream from seq %d (attempt %d)\n", lastSeq, attempt)
+
+		req, err := http.NewRequest(http.MethodPatch, serverUrl, nil)
+		if err != nil {
+			log.Println("Error creating resume request:", err)
+			return nil
+		}
+		req.Header.Set(shared.STREAM_RESUME_HEADER, strconv.FormatInt(lastSeq, 10))
+
+		resp, err := authenticatedStreamingClient.Do(req)
+		if err != nil {
+			log.Println("Error sending resume request:", err)
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			errorBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			log.Printf("Error resuming plan stream: %d %s\n", resp.StatusCode, string(errorBody))
+
+			// plan is no longer active or we're not allowed to connect -- retrying won't help
+			if resp.StatusCode < 500 {
+				return nil
+			}
+			continue
+		}
+
+		log.Println("Resumed plan stream")
+		return resp.Body
+	}
+
+	return nil
+}
+
 func readUntilSeparator(reader *bufio.Reader, separator string) (string, error) {
 	var result []byte
 	sepBytes := []byte(separator)
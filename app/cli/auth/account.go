@@ -185,6 +185,33 @@ func promptSignInNewAccount() error {
 			return fmt.Errorf("error prompting host: %v", err)
 		}
 
+		if selected == SignInOtherOption {
+			// servers without sso support return an error here -- fall back to email sign in
+			term.StartSpinner("")
+			ssoRes, apiErr := apiClient.ListSsoProviders(host)
+			term.StopSpinner()
+
+			if apiErr == nil && len(ssoRes.Providers) > 0 {
+				provider, err := selectSsoProvider(ssoRes.Providers, !ssoRes.RequireSso)
+				if err != nil {
+					return err
+				}
+
+				if provider != nil {
+					err = SignInWithSso(host, provider.Id)
+					if err != nil {
+						return fmt.Errorf("error signing in with sso: %v", err)
+					}
+
+					if !term.IsRepl {
+						term.PrintCmds("", "")
+					}
+
+					return nil
+				}
+			}
+		}
+
 		if selected == SignInLocalOption {
 			email = "local-admin@plandex.ai"
 		} else {
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"os"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+var usingApiToken bool
+
+func UsingApiToken() bool {
+	return usingApiToken
+}
+
+// resolveApiTokenAuth uses an api token from the environment when one is set, for CI and other non-interactive use. Nothing is written to the auth or accounts files. The org comes from the token.
+func resolveApiTokenAuth(requireOrg bool) bool {
+	token := strings.TrimSpace(os.Getenv(shared.ApiTokenEnvVar))
+	if token == "" {
+		return false
+	}
+
+	if !strings.HasPrefix(token, shared.ApiTokenPrefix) {
+		term.OutputErrorAndExit("%s is not a valid api token", shared.ApiTokenEnvVar)
+	}
+
+	// PLANDEX_API_HOST points the token at a self-hosted server
+	host := os.Getenv("PLANDEX_API_HOST")
+
+	usingApiToken = true
+	Current = &shared.ClientAuth{
+		ClientAccount: shared.ClientAccount{
+			IsCloud: host == "",
+			Host:    host,
+			Token:   token,
+			// local settings are keyed by user id -- the token prefix keeps them separate from signed in accounts
+			UserId: token[:len(shared.ApiTokenPrefix)+8],
+		},
+	}
+
+	if requireOrg {
+		term.StartSpinner("")
+		org, apiErr := apiClient.GetOrgSession()
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error authenticating with api token: %v", apiErr.Msg)
+		}
+
+		Current.OrgId = org.Id
+		Current.OrgName = org.Name
+		Current.OrgIsTrial = org.IsTrial
+		Current.IntegratedModelsMode = org.IntegratedModelsMode
+	}
+
+	return true
+}
@@ -12,6 +12,7 @@ import (
 
 var openUnauthenticatedCloudURL func(msg, path string)
 var openAuthenticatedURL func(msg, path string)
+var openURL func(msg, url string)
 
 func SetOpenUnauthenticatedCloudURLFn(fn func(msg, path string)) {
 	openUnauthenticatedCloudURL = fn
@@ -21,6 +22,10 @@ func SetOpenAuthenticatedURLFn(fn func(msg, path string)) {
 	openAuthenticatedURL = fn
 }
 
+func SetOpenURLFn(fn func(msg, url string)) {
+	openURL = fn
+}
+
 func MustResolveAuthWithOrg() {
 	MustResolveAuth(true)
 }
@@ -30,6 +35,10 @@ func MustResolveAuth(requireOrg bool) {
 		term.OutputErrorAndExit("error resolving auth: api client not set")
 	}
 
+	if resolveApiTokenAuth(requireOrg) {
+		return
+	}
+
 	// load HomeAuthPath file into ClientAuth struct
 	bytes, err := os.ReadFile(fs.HomeAuthPath)
 
@@ -91,6 +100,10 @@ func RefreshInvalidToken() error {
 	if Current == nil {
 		return fmt.Errorf("error refreshing token: auth not loaded")
 	}
+
+	if usingApiToken {
+		return fmt.Errorf("api token is invalid, expired, or revoked")
+	}
 	res, err := verifyEmail(Current.Email, Current.Host)
 
 	if err != nil {
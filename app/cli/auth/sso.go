@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"fmt"
+	"plandex-cli/term"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+const SignInEmailOption = "Sign in with email"
+
+// SignInWithSso signs in through one of the host's sso providers with the device flow. If providerId is empty, the user picks a provider.
+func SignInWithSso(host, providerId string) error {
+	if providerId == "" {
+		term.StartSpinner("")
+		res, apiErr := apiClient.ListSsoProviders(host)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			return fmt.Errorf("error listing sso providers: %v", apiErr.Msg)
+		}
+
+		if len(res.Providers) == 0 {
+			return fmt.Errorf("no sso providers are configured on %s", host)
+		}
+
+		provider, err := selectSsoProvider(res.Providers, false)
+		if err != nil {
+			return err
+		}
+		providerId = provider.Id
+	}
+
+	term.StartSpinner("")
+	res, apiErr := apiClient.StartSsoDevice(providerId, host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return fmt.Errorf("error starting sso sign in: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("🔑 Your sign in code: %s\n\n", color.New(color.Bold, term.ColorHiCyan).Sprint(res.UserCode))
+
+	verificationUri := res.VerificationUriComplete
+	if verificationUri == "" {
+		verificationUri = res.VerificationUri
+	}
+
+	openURL("Opening your identity provider in your browser. Check that the code matches, then approve the sign in.", verificationUri)
+	fmt.Println()
+
+	interval := time.Duration(res.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	term.StartSpinner("Waiting for sign in...")
+
+	for {
+		if time.Now().After(res.ExpiresAt) {
+			term.StopSpinner()
+			return fmt.Errorf("sign in expired before it was approved")
+		}
+
+		time.Sleep(interval)
+
+		pollRes, apiErr := apiClient.PollSsoDevice(res.PollToken, host)
+
+		if apiErr != nil {
+			term.StopSpinner()
+			return fmt.Errorf("error signing in: %v", apiErr.Msg)
+		}
+
+		switch pollRes.Status {
+		case shared.SsoDeviceStatusComplete:
+			term.StopSpinner()
+			return handleSignInResponse(pollRes.Session, host)
+		case shared.SsoDeviceStatusSlowDown:
+			interval += 5 * time.Second
+		}
+	}
+}
+
+// selectSsoProvider prompts for a provider, with an extra email option if withEmail is set. Returns nil if email is selected.
+func selectSsoProvider(providers []*shared.SsoProvider, withEmail bool) (*shared.SsoProvider, error) {
+	if len(providers) == 1 && !withEmail {
+		return providers[0], nil
+	}
+
+	var opts []string
+	for _, p := range providers {
+		opts = append(opts, fmt.Sprintf("Sign in with %s", p.Name))
+	}
+	if withEmail {
+		opts = append(opts, SignInEmailOption)
+	}
+
+	selected, err := term.SelectFromList("How do you want to sign in?", opts)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting sign in option: %v", err)
+	}
+
+	for i, opt := range opts {
+		if opt == selected && i < len(providers) {
+			return providers[i], nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var auditActions []string
+var auditUser string
+var auditPlanId string
+var auditCurrentPlan bool
+var auditSince string
+var auditUntil string
+var auditAfterSeq int64
+var auditLimit int
+var auditAll bool
+var auditJson bool
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the org's audit log",
+	Args:  cobra.NoArgs,
+	Run:   listAuditLog,
+}
+
+func init() {
+	RootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringSliceVarP(&auditActions, "action", "a", nil, "Only show these actions (comma-separated) -- pass a category like 'plan' to match all plan actions")
+	auditCmd.Flags().StringVarP(&auditUser, "user", "u", "", "Only show actions by this user (email or id)")
+	auditCmd.Flags().StringVar(&auditPlanId, "plan", "", "Only show actions on this plan (id)")
+	auditCmd.Flags().BoolVar(&auditCurrentPlan, "current", false, "Only show actions on the current plan")
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Only show actions since a duration ago (e.g. 24h, 7d) or a date (2006-01-02 or RFC3339)")
+	auditCmd.Flags().StringVar(&auditUntil, "until", "", "Only show actions before a duration ago or a date")
+	auditCmd.Flags().Int64Var(&auditAfterSeq, "after-seq", 0, "Only show entries after this sequence number -- for incremental exports")
+	auditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 50, "Max entries to show")
+	auditCmd.Flags().BoolVar(&auditAll, "all", false, "Show every matching entry, ignoring --limit")
+	auditCmd.Flags().BoolVar(&auditJson, "json", false, "Output entries as a json array")
+}
+
+func listAuditLog(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	req := shared.ListAuditLogRequest{
+		Actions:  auditActions,
+		Actor:    auditUser,
+		PlanId:   auditPlanId,
+		AfterSeq: auditAfterSeq,
+		Limit:    auditLimit,
+	}
+
+	if auditCurrentPlan {
+		lib.MustResolveProject()
+		if lib.CurrentPlanId == "" {
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+		req.PlanId = lib.CurrentPlanId
+	}
+
+	var err error
+	req.Since, err = parseAuditTime(auditSince)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --since: %v", err)
+	}
+	req.Until, err = parseAuditTime(auditUntil)
+	if err != nil {
+		term.OutputErrorAndExit("Invalid --until: %v", err)
+	}
+
+	if auditAll {
+		req.Limit = 0
+	}
+
+	var entries []*shared.AuditLogEntry
+
+	if !auditJson {
+		term.StartSpinner("")
+	}
+
+	for {
+		res, apiErr := api.Client.ListAuditLog(req)
+
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error fetching audit log: %v", apiErr.Msg)
+			return
+		}
+
+		entries = append(entries, res.Entries...)
+
+		if !auditAll || !res.HasMore || len(res.Entries) == 0 {
+			break
+		}
+
+		req.BeforeSeq = res.Entries[len(res.Entries)-1].Seq
+	}
+
+	term.StopSpinner()
+
+	if auditJson {
+		if entries == nil {
+			entries = []*shared.AuditLogEntry{}
+		}
+
+		bytes, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			term.OutputErrorAndExit("Error marshalling audit log: %v", err)
+		}
+
+		fmt.Println(string(bytes))
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("🤷‍♂️ No matching audit log entries")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "When", "Actor", "Action", "Target", "Change", "IP"})
+
+	for _, entry := range entries {
+		actor := entry.ActorEmail
+		if entry.ApiTokenId != nil {
+			actor += " (api token)"
+		}
+
+		table.Append([]string{
+			strconv.FormatInt(entry.Seq, 10),
+			format.Time(entry.CreatedAt),
+			actor,
+			string(entry.Action),
+			fmt.Sprintf("%s %s", entry.TargetType, entry.TargetId),
+			auditChangeSummary(entry),
+			entry.ClientIp,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+}
+
+// parseAuditTime accepts a duration ago (with a 'd' suffix for days) or a date
+func parseAuditTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err == nil {
+			t := time.Now().AddDate(0, 0, -days)
+			return &t, nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return &t, nil
+	}
+
+	return nil, fmt.Errorf("expected a duration like 24h or 7d, or a date like 2006-01-02")
+}
+
+func auditChangeSummary(entry *shared.AuditLogEntry) string {
+	const maxLen = 60
+
+	truncate := func(s string) string {
+		runes := []rune(s)
+		if len(runes) > maxLen {
+			return string(runes[:maxLen]) + "…"
+		}
+		return s
+	}
+
+	switch {
+	case entry.Before != "" && entry.After != "":
+		return truncate(entry.Before) + " → " + truncate(entry.After)
+	case entry.After != "":
+		return truncate(entry.After)
+	default:
+		return truncate(entry.Before)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"plandex-cli/term"
 	"strconv"
 
+	shared "plandex-shared"
+
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -45,6 +47,15 @@ func branches(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		res := []*shared.CliJsonBranch{}
+		for _, b := range branches {
+			res = append(res, &shared.CliJsonBranch{Branch: b, IsCurrent: b.Name == lib.CurrentBranch})
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
 	table.SetHeader([]string{"#", "Name", "Updated" /* "Created",*/, "Context", "Convo"})
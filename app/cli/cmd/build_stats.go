@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"sort"
+	"strconv"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var buildStatsLang string
+var buildStatsStrategy string
+
+var buildStatsCmd = &cobra.Command{
+	Use:   "build-stats",
+	Short: "Show builder strategy success rates and latency",
+	Long:  "Show how often each build strategy succeeds, and how long it takes, for files of each language and size. Plandex uses these stats to choose which strategies to start first.",
+	Args:  cobra.NoArgs,
+	Run:   showBuildStats,
+}
+
+func init() {
+	RootCmd.AddCommand(buildStatsCmd)
+
+	buildStatsCmd.Flags().StringVarP(&buildStatsLang, "lang", "l", "", "Only show stats for this language (e.g. go, typescript)")
+	buildStatsCmd.Flags().StringVarP(&buildStatsStrategy, "strategy", "s", "", "Only show stats for this strategy (auto_apply, validate, fast_apply, whole_file)")
+}
+
+func showBuildStats(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if buildStatsStrategy != "" && !isBuilderStrategy(shared.BuilderStrategy(buildStatsStrategy)) {
+		term.OutputErrorAndExit("Invalid --strategy: %s", buildStatsStrategy)
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.GetBuilderStats()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching builder stats: %v", apiErr.Msg)
+		return
+	}
+
+	stats := []*shared.BuilderStrategyStats{}
+	for _, s := range res.Stats {
+		if buildStatsLang != "" && s.Lang != buildStatsLang {
+			continue
+		}
+		if buildStatsStrategy != "" && string(s.Strategy) != buildStatsStrategy {
+			continue
+		}
+		stats = append(stats, s)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Lang != b.Lang {
+			return a.Lang < b.Lang
+		}
+		if a.SizeBucket != b.SizeBucket {
+			return sizeBucketIndex(a.SizeBucket) < sizeBucketIndex(b.SizeBucket)
+		}
+		if a.Strategy != b.Strategy {
+			return strategyIndex(a.Strategy) < strategyIndex(b.Strategy)
+		}
+		return a.Model < b.Model
+	})
+
+	if term.IsJsonOutput {
+		term.OutputJson(stats)
+		return
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("🤷‍♂️ No builder stats yet")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Language", "Size", "Strategy", "Model", "Attempts", "Success", "Avg Latency"})
+
+	for _, s := range stats {
+		model := s.Model
+		if model == "" {
+			model = "-"
+		}
+
+		table.Append([]string{
+			s.Lang,
+			sizeBucketLabel(s.SizeBucket),
+			string(s.Strategy),
+			model,
+			strconv.Itoa(s.Attempts),
+			successRateLabel(s),
+			s.AvgLatency().Round(100 * time.Millisecond).String(),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	fmt.Println("Sizes are the file's token count before the build. When a strategy often fails for a language and size, Plandex starts the next strategies alongside it.")
+}
+
+func isBuilderStrategy(strategy shared.BuilderStrategy) bool {
+	return strategyIndex(strategy) < len(shared.BuilderStrategies)
+}
+
+func strategyIndex(strategy shared.BuilderStrategy) int {
+	for i, s := range shared.BuilderStrategies {
+		if s == strategy {
+			return i
+		}
+	}
+	return len(shared.BuilderStrategies)
+}
+
+func sizeBucketIndex(name string) int {
+	for i, bucket := range shared.BuilderSizeBuckets {
+		if bucket.Name == name {
+			return i
+		}
+	}
+	return len(shared.BuilderSizeBuckets)
+}
+
+func sizeBucketLabel(name string) string {
+	prevMax := 0
+	for _, bucket := range shared.BuilderSizeBuckets {
+		if bucket.Name == name {
+			if bucket.MaxTokens == 0 {
+				return fmt.Sprintf("%s (>%d)", name, prevMax)
+			}
+			return fmt.Sprintf("%s (≤%d)", name, bucket.MaxTokens)
+		}
+		prevMax = bucket.MaxTokens
+	}
+	return name
+}
+
+func successRateLabel(s *shared.BuilderStrategyStats) string {
+	rate := s.SuccessRate()
+	label := fmt.Sprintf("%.0f%%", rate*100)
+
+	switch {
+	case rate >= 0.8:
+		return color.New(color.FgHiGreen).Sprint(label)
+	case rate >= 0.5:
+		return color.New(color.FgHiYellow).Sprint(label)
+	default:
+		return color.New(color.FgHiRed).Sprint(label)
+	}
+}
@@ -16,8 +16,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var cdShared bool
+
 func init() {
 	RootCmd.AddCommand(cdCmd)
+	cdCmd.Flags().BoolVar(&cdShared, "shared", false, "Select from plans other members have shared with you")
 }
 
 var cdCmd = &cobra.Command{
@@ -39,8 +42,19 @@ func cd(cmd *cobra.Command, args []string) {
 
 	var plan *shared.Plan
 
+	var plans []*shared.Plan
+	var apiErr *shared.ApiError
+
 	term.StartSpinner("")
-	plans, apiErr := api.Client.ListPlans([]string{lib.CurrentProjectId})
+	if cdShared {
+		var res *shared.ListSharedPlansResponse
+		res, apiErr = api.Client.ListSharedPlans([]string{lib.CurrentProjectId})
+		if res != nil {
+			plans = res.Plans
+		}
+	} else {
+		plans, apiErr = api.Client.ListPlans([]string{lib.CurrentProjectId})
+	}
 	term.StopSpinner()
 
 	if apiErr != nil {
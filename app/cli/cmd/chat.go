@@ -33,17 +33,14 @@ func init() {
 		omitSmartContext: true,
 	})
 
+	chatCmd.Flags().BoolVar(&tellPreview, "preview", false, "Show the token breakdown and model for the prompt without sending it")
 }
 
 func doChat(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveProject()
 	mustSetPlanExecFlags(cmd)
-
-	var apiKeys map[string]string
-	if !auth.Current.IntegratedModelsMode {
-		apiKeys = lib.MustVerifyApiKeys()
-	}
+	mustValidatePreviewFlags()
 
 	prompt := getTellPrompt(args)
 
@@ -52,6 +49,21 @@ func doChat(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	tellFlags := types.TellFlags{
+		IsChatOnly:  true,
+		AutoContext: tellAutoContext,
+	}
+
+	if tellPreview {
+		previewTell(prompt, tellFlags)
+		return
+	}
+
+	var apiKeys map[string]string
+	if !auth.Current.IntegratedModelsMode {
+		apiKeys = lib.MustVerifyApiKeys()
+	}
+
 	plan_exec.TellPlan(plan_exec.ExecParams{
 		CurrentPlanId: lib.CurrentPlanId,
 		CurrentBranch: lib.CurrentBranch,
@@ -60,8 +72,5 @@ func doChat(cmd *cobra.Command, args []string) {
 			auto := autoConfirm || tellAutoApply || tellAutoContext
 			return lib.CheckOutdatedContextWithOutput(auto, auto, maybeContexts, projectPaths)
 		},
-	}, prompt, types.TellFlags{
-		IsChatOnly:  true,
-		AutoContext: tellAutoContext,
-	})
+	}, prompt, tellFlags)
 }
@@ -47,6 +47,11 @@ func config(cmd *cobra.Command, args []string) {
 
 	term.StopSpinner()
 
+	if term.IsJsonOutput {
+		term.OutputJson(config)
+		return
+	}
+
 	color.New(color.Bold, term.ColorHiCyan).Println("⚙️  Plan Config")
 	lib.ShowPlanConfig(config, "")
 	fmt.Println()
@@ -66,6 +71,11 @@ func defaultConfig(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		term.OutputJson(config)
+		return
+	}
+
 	color.New(color.Bold, term.ColorHiCyan).Println("⚙️  Default Config")
 	lib.ShowPlanConfig(config, "")
 	fmt.Println()
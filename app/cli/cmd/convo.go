@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	shared "plandex-shared"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -49,7 +51,7 @@ func convo(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error loading conversation: %v", apiErr.Msg)
 	}
 
-	if len(conversation) == 0 {
+	if len(conversation) == 0 && !term.IsJsonOutput {
 		fmt.Println("🤷‍♂️ No conversation history")
 		return
 	}
@@ -81,6 +83,21 @@ func convo(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if term.IsJsonOutput {
+		res := []*shared.ConvoMessage{}
+		for _, msg := range conversation {
+			if msgRangeStart > 0 && msg.Num < msgRangeStart {
+				continue
+			}
+			if msgRangeEnd > 0 && msg.Num > msgRangeEnd {
+				break
+			}
+			res = append(res, msg)
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	var convo string
 	var totalTokens int
 	var didCut bool
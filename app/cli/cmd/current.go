@@ -50,6 +50,14 @@ func current(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error getting current branches: %v", err)
 	}
 
+	if term.IsJsonOutput {
+		term.OutputJson(shared.CliJsonCurrentPlan{
+			Plan:   plan,
+			Branch: currentBranchesByPlanId[lib.CurrentPlanId],
+		})
+		return
+	}
+
 	table := lib.GetCurrentPlanTable(plan, currentBranchesByPlanId, nil)
 	fmt.Println(table)
 
@@ -12,6 +12,9 @@ import (
 	"plandex-cli/lib"
 	"plandex-cli/term"
 	"plandex-cli/ui"
+	"strings"
+
+	shared "plandex-shared"
 
 	"github.com/eiannone/keyboard"
 	"github.com/fatih/color"
@@ -22,6 +25,7 @@ var showDiffUi bool = true
 var diffUiSideBySide = true
 var diffUiLineByLine bool
 var diffGit bool
+var diffFormatting bool
 
 var fromTellMenu bool
 
@@ -40,6 +44,7 @@ func init() {
 	diffsCmd.Flags().BoolVar(&diffGit, "git", true, "Show diffs in git diff format")
 	diffsCmd.Flags().BoolVarP(&diffUiSideBySide, "side", "s", true, "Show diffs UI in side-by-side view")
 	diffsCmd.Flags().BoolVarP(&diffUiLineByLine, "line", "l", false, "Show diffs UI in line-by-line view")
+	diffsCmd.Flags().BoolVar(&diffFormatting, "formatting", false, "Also show the changes formatters will make on apply, separately")
 
 	diffsCmd.Flags().BoolVar(&fromTellMenu, "from-tell-menu", false, "Show diffs from the tell menu")
 	diffsCmd.Flags().MarkHidden("from-tell-menu")
@@ -64,18 +69,45 @@ func diffs(cmd *cobra.Command, args []string) {
 		diffGit = true
 	}
 
-	diffs, err := api.Client.GetPlanDiffs(lib.CurrentPlanId, lib.CurrentBranch, plainTextOutput || showDiffUi)
+	diffs, err := api.Client.GetPlanDiffs(lib.CurrentPlanId, lib.CurrentBranch, plainTextOutput || showDiffUi || term.IsJsonOutput)
 	term.StopSpinner()
 	if err != nil {
 		term.OutputErrorAndExit("Error getting plan diffs: %v", err)
 		return
 	}
 
+	var formattingDiffs string
+	if diffFormatting && len(diffs) > 0 {
+		formattingDiffs = getFormattingDiffs(!(plainTextOutput || showDiffUi || term.IsJsonOutput))
+	}
+
+	if term.IsJsonOutput {
+		res := splitDiffByFile(diffs)
+		for _, fileDiff := range splitDiffByFile(formattingDiffs) {
+			fileDiff.Formatting = true
+			res = append(res, fileDiff)
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	if len(diffs) == 0 {
 		fmt.Println("🤷‍♂️ No pending changes")
 		return
 	}
 
+	if formattingDiffs != "" {
+		if showDiffUi {
+			diffs += "\n" + formattingDiffs
+		} else {
+			header := "🧹 Formatting changes, made when the plan is applied"
+			if !plainTextOutput {
+				header = color.New(color.Bold, term.ColorHiCyan).Sprint(header)
+			}
+			diffs += "\n\n" + header + "\n\n" + formattingDiffs
+		}
+	}
+
 	if showDiffUi {
 		getNewListener := func() net.Listener {
 			outputFormat := "line-by-line"
@@ -246,6 +278,79 @@ func diffs(cmd *cobra.Command, args []string) {
 	}
 }
 
+// splitDiffByFile splits 'git diff' output into one diff per file
+func splitDiffByFile(diffs string) []*shared.CliJsonFileDiff {
+	res := []*shared.CliJsonFileDiff{}
+
+	var current *shared.CliJsonFileDiff
+	var lines []string
+
+	flush := func() {
+		if current != nil {
+			current.Diff = strings.Join(lines, "\n")
+			res = append(res, current)
+		}
+	}
+
+	for _, line := range strings.Split(diffs, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			current = &shared.CliJsonFileDiff{}
+			lines = nil
+
+			// 'diff --git a/path b/path' -- the '+++'/'---' lines below override this for paths with spaces
+			parts := strings.SplitN(strings.TrimPrefix(line, "diff --git "), " b/", 2)
+			if len(parts) == 2 {
+				current.Path = parts[1]
+			}
+		} else if current != nil && strings.HasPrefix(line, "+++ b/") {
+			current.Path = strings.TrimPrefix(line, "+++ b/")
+		} else if current != nil && strings.HasPrefix(line, "--- a/") && current.Path == "" {
+			current.Path = strings.TrimPrefix(line, "--- a/")
+		}
+
+		if current != nil {
+			lines = append(lines, line)
+		}
+	}
+	flush()
+
+	return res
+}
+
+// getFormattingDiffs returns the diffs formatters will make to the plan's files on apply, or an empty string if there are none or they can't be shown
+func getFormattingDiffs(withColor bool) string {
+	if !lib.HasFormatters() {
+		if !term.IsJsonOutput {
+			fmt.Printf("ℹ️  No formatters are configured in %s\n\n", lib.FormatConfigFile)
+		}
+		return ""
+	}
+
+	term.StartSpinner("")
+	planState, apiErr := api.Client.GetCurrentPlanState(lib.CurrentPlanId, lib.CurrentBranch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr.Msg)
+	}
+
+	res, formatErrs, err := lib.FormattingDiffs(planState.CurrentPlanFiles.Files, withColor)
+	term.StopSpinner()
+	if err != nil {
+		term.OutputErrorAndExit("Error getting formatting diffs: %v", err)
+	}
+
+	if !term.IsJsonOutput {
+		for _, formatErr := range formatErrs {
+			fmt.Printf("⚠️  Couldn't format %s: %v\n", formatErr.Path, formatErr.Err)
+		}
+		if len(formatErrs) > 0 {
+			fmt.Println()
+		}
+	}
+
+	return res
+}
+
 func showGitDiff() {
 	_, err := lib.ExecPlandexCommandWithParams([]string{"diff", "--git"}, lib.ExecPlandexCommandParams{
 		DisableSuggestions: true,
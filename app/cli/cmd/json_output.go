@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"plandex-cli/term"
+
+	"github.com/spf13/cobra"
+)
+
+const jsonOutputAnnotation = "json-output"
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&term.IsJsonOutput, "json", false, "Output JSON instead of tables and text (read commands only)")
+
+	// read commands that support --json
+	for _, cmd := range []*cobra.Command{
+		plansCmd,
+		currentCmd,
+		contextCmd,
+		branchesCmd,
+		logCmd,
+		convoCmd,
+		diffsCmd,
+		psCmd,
+		modelsCmd,
+		defaultModelsCmd,
+		listAvailableModelsCmd,
+		modelPacksCmd,
+		usageCmd,
+		configCmd,
+		defaultConfigCmd,
+		templatesCmd,
+		buildStatsCmd,
+		showPlanTemplateCmd,
+		tellCmd, // with --preview
+		chatCmd, // with --preview
+	} {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[jsonOutputAnnotation] = "true"
+	}
+
+	RootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if term.IsJsonOutput && cmd.Annotations[jsonOutputAnnotation] != "true" {
+			term.OutputErrorAndExit("--json isn't supported by '%s'", cmd.CommandPath())
+		}
+	}
+}
@@ -19,6 +19,7 @@ var (
 	forceSkipIgnore bool
 	imageDetail     string
 	defsOnly        bool
+	allowGenerated  bool
 )
 
 var contextLoadCmd = &cobra.Command{
@@ -36,6 +37,7 @@ func init() {
 	contextLoadCmd.Flags().BoolVarP(&forceSkipIgnore, "force", "f", false, "Load files even when ignored by .gitignore or .plandexignore")
 	contextLoadCmd.Flags().StringVarP(&imageDetail, "detail", "d", "high", "Image detail level (high or low)")
 	contextLoadCmd.Flags().BoolVar(&defsOnly, "map", false, "Load file maps (function/method/class signatures, variable names, types, etc.)")
+	contextLoadCmd.Flags().BoolVar(&allowGenerated, "allow-generated", false, "Load generated files (lockfiles, minified code, *.pb.go, etc.) in full instead of as maps")
 	RootCmd.AddCommand(contextLoadCmd)
 }
 
@@ -55,6 +57,7 @@ func contextLoad(cmd *cobra.Command, args []string) {
 		ForceSkipIgnore: forceSkipIgnore,
 		ImageDetail:     openai.ImageURLDetail(imageDetail),
 		DefsOnly:        defsOnly,
+		AllowGenerated:  allowGenerated,
 		SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
 	})
 
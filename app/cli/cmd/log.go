@@ -42,6 +42,14 @@ func runLog(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error getting logs: %v", apiErr)
 	}
 
+	if term.IsJsonOutput {
+		if res.Shas == nil {
+			res.Shas = []string{}
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	withLocalTimestamps, err := convertTimestampsToLocal(res.Body)
 
 	if err != nil {
@@ -41,6 +41,11 @@ func listContext(cmd *cobra.Command, args []string) {
 	}
 	term.StopSpinner()
 
+	if term.IsJsonOutput {
+		outputContextJson(contexts)
+		return
+	}
+
 	totalTokens := 0
 	totalPlannerTokens := 0
 	totalMapTokens := 0
@@ -109,6 +114,26 @@ func listContext(cmd *cobra.Command, args []string) {
 
 }
 
+func outputContextJson(contexts []*shared.Context) {
+	res := shared.CliJsonContext{Contexts: []*shared.Context{}}
+
+	for _, context := range contexts {
+		res.TotalTokens += context.NumTokens
+		if context.ContextType == shared.ContextMapType {
+			res.MapTokens += context.NumTokens
+		} else {
+			res.ContextTokens += context.NumTokens
+		}
+
+		c := *context
+		c.Body = ""
+		c.MapParts = nil
+		res.Contexts = append(res.Contexts, &c)
+	}
+
+	term.OutputJson(res)
+}
+
 func init() {
 	RootCmd.AddCommand(contextCmd)
 
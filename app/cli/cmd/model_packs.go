@@ -134,6 +134,19 @@ func listModelPacks(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		res := shared.CliJsonModelPacks{
+			BuiltIn: []*shared.ModelPack{},
+			Custom:  []*shared.ModelPack{},
+		}
+		if !customModelPacksOnly {
+			res.BuiltIn = append(res.BuiltIn, builtInModelPacks...)
+		}
+		res.Custom = append(res.Custom, customModelPacks...)
+		term.OutputJson(res)
+		return
+	}
+
 	if !customModelPacksOnly {
 		color.New(color.Bold, term.ColorHiCyan).Println("🏠 Built-in Model Packs")
 		table := tablewriter.NewWriter(os.Stdout)
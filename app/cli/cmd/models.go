@@ -308,6 +308,11 @@ func models(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		term.OutputJson(settings)
+		return
+	}
+
 	title := fmt.Sprintf("%s Model Settings", color.New(color.Bold, term.ColorHiGreen).Sprint(plan.Name))
 
 	table := tablewriter.NewWriter(os.Stdout)
@@ -333,6 +338,11 @@ func defaultModels(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		term.OutputJson(settings)
+		return
+	}
+
 	title := fmt.Sprintf("%s Model Settings", color.New(color.Bold, term.ColorHiGreen).Sprint("Org-Wide Default"))
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
@@ -359,6 +369,19 @@ func listAvailableModels(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		res := shared.CliJsonAvailableModels{
+			BuiltIn: []*shared.AvailableModel{},
+			Custom:  []*shared.AvailableModel{},
+		}
+		if !customModelsOnly {
+			res.BuiltIn = append(res.BuiltIn, shared.AvailableModels...)
+		}
+		res.Custom = append(res.Custom, customModels...)
+		term.OutputJson(res)
+		return
+	}
+
 	if !customModelsOnly {
 		color.New(color.Bold, term.ColorHiCyan).Println("🏠 Built-in Models")
 		builtIn := shared.AvailableModels
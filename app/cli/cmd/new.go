@@ -17,6 +17,8 @@ import (
 
 var name string
 var contextBaseDir string
+var newTemplateName string
+var newTemplateVars []string
 
 // newCmd represents the new command
 var newCmd = &cobra.Command{
@@ -32,6 +34,8 @@ func init() {
 	RootCmd.AddCommand(newCmd)
 	newCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the new plan")
 	newCmd.Flags().StringVar(&contextBaseDir, "context-dir", ".", "Base directory to auto-load context from")
+	newCmd.Flags().StringVarP(&newTemplateName, "template", "t", "", "Start the plan from a plan template")
+	newCmd.Flags().StringArrayVar(&newTemplateVars, "var", nil, "Set a template variable (key=val) -- can be repeated")
 
 	AddNewPlanFlags(newCmd)
 }
@@ -40,6 +44,13 @@ func new(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveOrCreateProject()
 
+	var template *shared.PlanTemplate
+	if newTemplateName != "" {
+		template = mustRenderPlanTemplate(newTemplateName, newTemplateVars)
+	} else if len(newTemplateVars) > 0 {
+		term.OutputErrorAndExit("--var can only be used with --template")
+	}
+
 	term.StartSpinner("")
 
 	errCh := make(chan error, 2)
@@ -48,7 +59,11 @@ func new(cmd *cobra.Command, args []string) {
 	var config *shared.PlanConfig
 
 	go func() {
-		res, apiErr := api.Client.CreatePlan(lib.CurrentProjectId, shared.CreatePlanRequest{Name: name})
+		req := shared.CreatePlanRequest{Name: name}
+		if template != nil {
+			req.TemplateId = template.Id
+		}
+		res, apiErr := api.Client.CreatePlan(lib.CurrentProjectId, req)
 		if apiErr != nil {
 			errCh <- fmt.Errorf("error creating plan: %v", apiErr.Msg)
 			return
@@ -92,16 +107,27 @@ func new(cmd *cobra.Command, args []string) {
 	term.StopSpinner()
 
 	fmt.Printf("✅ Started new plan %s and set it to current plan\n", color.New(color.Bold, term.ColorHiGreen).Sprint(name))
-	fmt.Printf("⚙️  Using default config\n")
+	if template != nil {
+		fmt.Printf("📋 Using template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+	} else {
+		fmt.Printf("⚙️  Using default config\n")
+	}
 
-	resolveAutoMode(config)
+	_, config = resolveAutoMode(config)
 
 	resolveModelPack()
 
 	// autoModeLabel := shared.ConfigSettingsByKey["automode"].KeyToLabel(string(config.AutoMode))
 	// fmt.Println("⚡️ Auto-mode:", autoModeLabel)
 
-	if config.AutoLoadContext {
+	loadedTemplateContext := template != nil && !template.Context.IsEmpty()
+
+	if loadedTemplateContext {
+		fmt.Println("📥 Loading context from template")
+		fmt.Println()
+
+		lib.MustLoadTemplateContext(template.Context)
+	} else if config.AutoLoadContext {
 		fmt.Println("📥 Automatic context loading is enabled")
 
 		baseDir := contextBaseDir
@@ -125,10 +151,16 @@ func new(cmd *cobra.Command, args []string) {
 		cmds = []string{"tell", "chat", "config"}
 	}
 
-	if !config.AutoLoadContext {
+	if !config.AutoLoadContext && !loadedTemplateContext {
 		cmds = append([]string{"load"}, cmds...)
 	}
 
+	if template != nil && template.Prompt != "" {
+		if maybeSendTemplatePrompt(cmd, template.Prompt) {
+			return
+		}
+	}
+
 	fmt.Println()
 	term.PrintCmds("", cmds...)
 }
@@ -207,6 +207,8 @@ func mustSetPlanExecFlagsWithConfig(cmd *cobra.Command, config *shared.PlanConfi
 		editor = config.Editor
 	}
 
+	lib.SemanticCheck = config.SemanticCheck
+
 	validatePlanExecFlags()
 }
 
@@ -25,6 +25,10 @@ var (
 	strongModels bool
 	ossModels    bool
 	cheapModels  bool
+
+	// set by 'plandex new --template' -- the tier and type flags take precedence
+	templateAutoMode  shared.AutoModeType
+	templateModelPack string
 )
 
 func AddNewPlanFlags(cmd *cobra.Command) {
@@ -64,6 +68,8 @@ func resolveAutoModeWithArgs(config *shared.PlanConfig, silent bool) (bool, *sha
 		toSetAutoMode = shared.AutoModeSemi
 	} else if fullAuto {
 		toSetAutoMode = shared.AutoModeFull
+	} else if templateAutoMode != "" {
+		toSetAutoMode = templateAutoMode
 	}
 
 	if toSetAutoMode != "" && toSetAutoMode != currentAutoMode {
@@ -131,6 +137,10 @@ func resolveModelPackWithArgs(settings *shared.PlanSettings, silent bool) (*shar
 		packName = shared.StrongModelPack.Name
 	} else if cheapModels {
 		packName = shared.CheapModelPack.Name
+	} else if dailyModels {
+		packName = shared.DailyDriverModelPack.Name
+	} else if templateModelPack != "" {
+		packName = templateModelPack
 	} else {
 		packName = shared.DailyDriverModelPack.Name
 	}
@@ -27,10 +27,12 @@ import (
 )
 
 var archivedOnly bool
+var sharedOnly bool
 
 func init() {
 	RootCmd.AddCommand(plansCmd)
 	plansCmd.Flags().BoolVarP(&archivedOnly, "archived", "a", false, "List archived plans")
+	plansCmd.Flags().BoolVar(&sharedOnly, "shared", false, "List plans other members have shared with you")
 }
 
 // plansCmd represents the list command
@@ -47,6 +49,8 @@ func plans(cmd *cobra.Command, args []string) {
 
 	if archivedOnly {
 		listArchived()
+	} else if sharedOnly {
+		listShared()
 	} else {
 		listActive()
 	}
@@ -113,6 +117,10 @@ func listActive() {
 	}
 
 	if len(projectIds) == 0 {
+		if term.IsJsonOutput {
+			term.OutputJson([]*shared.CliJsonPlan{})
+			return
+		}
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
 		term.PrintCmds("", "new")
@@ -127,6 +135,11 @@ func listActive() {
 		term.OutputErrorAndExit("Error getting plans: %v", apiErr)
 	}
 
+	if term.IsJsonOutput {
+		outputActivePlansJson(plans, append(parentProjectIdsWithPaths, childProjectIdsWithPaths...))
+		return
+	}
+
 	if len(plans) == 0 {
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
@@ -352,6 +365,52 @@ func listActive() {
 	}
 }
 
+func outputActivePlansJson(plans []*shared.Plan, projectIdsWithPaths [][2]string) {
+	pathsByProjectId := map[string]string{}
+	for _, p := range projectIdsWithPaths {
+		pathsByProjectId[p[1]] = p[0]
+	}
+
+	var currentProjectPlanIds []string
+	for _, p := range plans {
+		if p.ProjectId == lib.CurrentProjectId {
+			currentProjectPlanIds = append(currentProjectPlanIds, p.Id)
+		}
+	}
+
+	var currentBranchesByPlanId map[string]*shared.Branch
+	if len(currentProjectPlanIds) > 0 {
+		currentBranchNamesByPlanId, err := lib.GetCurrentBranchNamesByPlanId(currentProjectPlanIds)
+		if err != nil {
+			term.OutputErrorAndExit("Error getting current branches: %v", err)
+		}
+
+		var apiErr *shared.ApiError
+		currentBranchesByPlanId, apiErr = api.Client.GetCurrentBranchByPlanId(lib.CurrentProjectId, shared.GetCurrentBranchByPlanIdRequest{
+			CurrentBranchByPlanId: currentBranchNamesByPlanId,
+		})
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting current branches: %v", apiErr)
+		}
+	}
+
+	res := []*shared.CliJsonPlan{}
+	for _, p := range plans {
+		item := &shared.CliJsonPlan{
+			Plan:      p,
+			IsCurrent: p.Id == lib.CurrentPlanId,
+		}
+		if p.ProjectId == lib.CurrentProjectId {
+			item.CurrentBranch = currentBranchesByPlanId[p.Id]
+		} else {
+			item.ProjectPath = pathsByProjectId[p.ProjectId]
+		}
+		res = append(res, item)
+	}
+
+	term.OutputJson(res)
+}
+
 func listArchived() {
 	var projectIds []string
 
@@ -367,6 +426,15 @@ func listArchived() {
 		term.OutputErrorAndExit("Error getting plans: %v", apiErr)
 	}
 
+	if term.IsJsonOutput {
+		res := []*shared.CliJsonPlan{}
+		for _, p := range plans {
+			res = append(res, &shared.CliJsonPlan{Plan: p, IsCurrent: p.Id == lib.CurrentPlanId})
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	if len(plans) == 0 {
 		fmt.Println("🤷‍♂️ No archived plans")
 		fmt.Println()
@@ -412,3 +480,76 @@ func listArchived() {
 	fmt.Println()
 	term.PrintCmds("", "unarchive")
 }
+
+func listShared() {
+	var projectIds []string
+
+	if lib.CurrentProjectId != "" {
+		projectIds = append(projectIds, lib.CurrentProjectId)
+	}
+
+	if len(projectIds) == 0 {
+		if term.IsJsonOutput {
+			term.OutputJsonErrorAndExit("no project in current directory")
+		}
+		fmt.Println("🤷‍♂️ No project in current directory")
+		return
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.ListSharedPlans(projectIds)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting shared plans: %v", apiErr)
+	}
+
+	if term.IsJsonOutput {
+		term.OutputJson(res)
+		return
+	}
+
+	if len(res.Plans) == 0 {
+		fmt.Println("🤷‍♂️ No plans shared with you")
+		return
+	}
+
+	var b strings.Builder
+	table := tablewriter.NewWriter(&b)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Owner", "Access", "Updated"})
+
+	for i, p := range res.Plans {
+		num := strconv.Itoa(i + 1)
+		if p.Id == lib.CurrentPlanId {
+			num = color.New(color.Bold, term.ColorHiGreen).Sprint(num)
+		}
+
+		row := []string{
+			num,
+			p.Name,
+			res.OwnerNameByPlanId[p.Id],
+			strings.ReplaceAll(string(res.LevelByPlanId[p.Id]), "_", "-"),
+			format.Time(p.UpdatedAt),
+		}
+
+		var style []tablewriter.Colors
+		if p.Id == lib.CurrentPlanId {
+			style = []tablewriter.Colors{
+				{tablewriter.FgHiGreenColor, tablewriter.Bold},
+			}
+		} else {
+			style = []tablewriter.Colors{
+				{tablewriter.Bold},
+			}
+		}
+
+		table.Rich(row, style)
+	}
+	table.Render()
+
+	term.PageOutput(b.String())
+
+	fmt.Println()
+	term.PrintCmds("", "cd", "connect")
+}
@@ -42,6 +42,14 @@ func ps(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		if res.Branches == nil {
+			res.Branches = []*shared.Branch{}
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	if len(res.Branches) == 0 {
 		fmt.Println("🤷‍♂️ No active or recently finished streams")
 		return
@@ -219,6 +219,7 @@ func getSuggestions() []prompt.Suggest {
 	if lib.CurrentReplState.IsMulti {
 		suggestions = append(suggestions, []prompt.Suggest{
 			{Text: "\\send", Description: "(\\s) Send the current prompt"},
+			{Text: "\\preview", Description: "(\\pv) Show the token budget for the current prompt without sending it"},
 			{Text: "\\multi", Description: "(\\m) Turn multi-line mode off"},
 			{Text: "\\run", Description: "(\\r) Run a file through tell/chat based on current mode"},
 			{Text: "\\quit", Description: "(\\q) Exit the REPL"},
@@ -239,6 +240,7 @@ func getSuggestions() []prompt.Suggest {
 	if !lib.CurrentReplState.IsMulti {
 		suggestions = append(suggestions, []prompt.Suggest{
 			{Text: "\\multi", Description: "(\\m) Turn multi-line mode on"},
+			{Text: "\\preview", Description: "(\\pv) Show the token budget for a prompt without sending it"},
 			{Text: "\\run", Description: "(\\r) Run a file through tell/chat based on current mode"},
 			{Text: "\\quit", Description: "(\\q) Exit the REPL"},
 		}...)
@@ -463,6 +465,32 @@ func executor(in string, p *prompt.Prompt) {
 			}
 			return
 
+		case cmd == "preview" || cmd == lib.ReplCmdAliases["preview"]:
+			if lastBackslashIndex > 0 {
+				preservedBuffer += lastLine[:lastBackslashIndex]
+			}
+			fmt.Println()
+			previewPrompt := strings.TrimSpace(preservedBuffer)
+			if previewPrompt == "" {
+				fmt.Println("🤷‍♂️ No prompt to preview")
+				fmt.Println()
+				return
+			}
+			previewCmd := "tell"
+			if lib.CurrentReplState.Mode == lib.ReplModeChat {
+				previewCmd = "chat"
+			}
+			_, err := lib.ExecPlandexCommandWithParams([]string{previewCmd, "--preview", previewPrompt}, lib.ExecPlandexCommandParams{
+				SessionId: sessionId,
+			})
+			if err != nil {
+				color.New(term.ColorHiRed).Printf("Error executing preview: %v\n", err)
+			}
+			fmt.Println()
+			// put the prompt back so it can be edited or sent
+			p.InsertTextMoveCursor(preservedBuffer, true)
+			return
+
 		case cmd == "run" || cmd == lib.ReplCmdAliases["run"]:
 			if lastBackslashIndex > 0 {
 				preservedBuffer += lastLine[:lastBackslashIndex]
@@ -701,6 +729,7 @@ func completer(in prompt.Document) ([]prompt.Suggest, pstrings.RuneNumber, pstri
 			strings.HasPrefix("tell", wCmd) ||
 			strings.HasPrefix("chat", wCmd) ||
 			strings.HasPrefix("send", wCmd) ||
+			strings.HasPrefix("preview", wCmd) ||
 			strings.HasPrefix("run", wCmd) {
 			isValidCommand = true
 		}
@@ -762,10 +791,10 @@ func completer(in prompt.Document) ([]prompt.Suggest, pstrings.RuneNumber, pstri
 		sort.Slice(prefixMatches, func(i, j int) bool {
 			iTxt := prefixMatches[i].Text
 			jTxt := prefixMatches[j].Text
-			if iTxt == "\\chat" || iTxt == "\\tell" || iTxt == "\\multi" || iTxt == "\\quit" || iTxt == "\\send" || iTxt == "\\run" {
+			if iTxt == "\\chat" || iTxt == "\\tell" || iTxt == "\\multi" || iTxt == "\\quit" || iTxt == "\\send" || iTxt == "\\preview" || iTxt == "\\run" {
 				return true
 			}
-			if jTxt == "\\chat" || jTxt == "\\tell" || jTxt == "\\multi" || jTxt == "\\quit" || jTxt == "\\send" || jTxt == "\\run" {
+			if jTxt == "\\chat" || jTxt == "\\tell" || jTxt == "\\multi" || jTxt == "\\quit" || jTxt == "\\send" || jTxt == "\\preview" || jTxt == "\\run" {
 				return false
 			}
 			return prefixMatches[i].Text < prefixMatches[j].Text
@@ -1064,6 +1093,9 @@ func parseCommand(in string) (string, string) {
 		case "chat", lib.ReplCmdAliases["chat"]:
 			return "\\chat", "\\" + cmdString
 
+		case "preview", lib.ReplCmdAliases["preview"]:
+			return "\\preview", "\\" + cmdString
+
 		case "run", lib.ReplCmdAliases["run"]:
 			return "\\run", "\\" + cmdString
 
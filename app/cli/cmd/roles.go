@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var roleDescription string
+var rolePermissions []string
+var roleAddPermissions []string
+var roleRemovePermissions []string
+var roleLabel string
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List org roles and their permissions",
+	Run:   listRoles,
+}
+
+var rolesPermissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "List permissions that can be given to custom roles",
+	Run:   listRolePermissions,
+}
+
+var createRoleCmd = &cobra.Command{
+	Use:   "create [label]",
+	Short: "Create a custom org role",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createRole,
+}
+
+var updateRoleCmd = &cobra.Command{
+	Use:   "update [label-or-index]",
+	Short: "Update a custom org role",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   updateRole,
+}
+
+var deleteRoleCmd = &cobra.Command{
+	Use:     "delete [label-or-index]",
+	Aliases: []string{"rm"},
+	Short:   "Delete a custom org role",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deleteRole,
+}
+
+func init() {
+	RootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(rolesPermissionsCmd)
+	rolesCmd.AddCommand(createRoleCmd)
+	rolesCmd.AddCommand(updateRoleCmd)
+	rolesCmd.AddCommand(deleteRoleCmd)
+
+	createRoleCmd.Flags().StringVarP(&roleDescription, "description", "d", "", "Role description")
+	createRoleCmd.Flags().StringSliceVarP(&rolePermissions, "permissions", "p", nil, "Permissions for the role (comma-separated) -- see 'plandex roles permissions'")
+
+	updateRoleCmd.Flags().StringVar(&roleLabel, "label", "", "New label for the role")
+	updateRoleCmd.Flags().StringVarP(&roleDescription, "description", "d", "", "New description for the role")
+	updateRoleCmd.Flags().StringSliceVarP(&rolePermissions, "permissions", "p", nil, "Replace the role's permissions (comma-separated)")
+	updateRoleCmd.Flags().StringSliceVar(&roleAddPermissions, "add", nil, "Add permissions to the role (comma-separated)")
+	updateRoleCmd.Flags().StringSliceVar(&roleRemovePermissions, "remove", nil, "Remove permissions from the role (comma-separated)")
+}
+
+func listRoles(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(true)
+	table.SetHeader([]string{"#", "Role", "Type", "Description", "Permissions"})
+
+	for i, role := range orgRoles {
+		roleType := "Custom"
+		if role.IsDefault {
+			roleType = "Default"
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			role.Label,
+			roleType,
+			role.Description,
+			strings.Join(rolePermissionNames(role), ", "),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "roles create", "roles update", "roles delete", "roles permissions")
+}
+
+func listRolePermissions(cmd *cobra.Command, args []string) {
+	for _, p := range shared.CustomRolePermissions {
+		fmt.Println(string(p))
+	}
+}
+
+func createRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var label string
+	if len(args) > 0 {
+		label = args[0]
+	} else {
+		var err error
+		label, err = term.GetRequiredUserStringInput("Role label:")
+		if err != nil {
+			term.OutputErrorAndExit("Error reading role label: %v", err)
+			return
+		}
+	}
+
+	req := shared.CreateOrgRoleRequest{
+		Label:       label,
+		Description: roleDescription,
+		Permissions: parseRolePermissions(rolePermissions),
+	}
+
+	term.StartSpinner("")
+	role, apiErr := api.Client.CreateOrgRole(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating org role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Created role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label))
+	fmt.Println()
+	term.PrintCmds("", "roles", "invite")
+}
+
+func updateRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	role := selectCustomRole(args, "Select a role to update:")
+	if role == nil {
+		return
+	}
+
+	req := shared.UpdateOrgRoleRequest{
+		Label:       role.Label,
+		Description: role.Description,
+		Permissions: parseRolePermissions(rolePermissionNames(role)),
+	}
+
+	if cmd.Flags().Changed("label") {
+		req.Label = roleLabel
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = roleDescription
+	}
+	if cmd.Flags().Changed("permissions") {
+		req.Permissions = parseRolePermissions(rolePermissions)
+	}
+
+	for _, p := range parseRolePermissions(roleAddPermissions) {
+		found := false
+		for _, existing := range req.Permissions {
+			if existing == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			req.Permissions = append(req.Permissions, p)
+		}
+	}
+
+	if len(roleRemovePermissions) > 0 {
+		toRemove := map[shared.Permission]bool{}
+		for _, p := range parseRolePermissions(roleRemovePermissions) {
+			toRemove[p] = true
+		}
+
+		var kept []shared.Permission
+		for _, p := range req.Permissions {
+			if !toRemove[p] {
+				kept = append(kept, p)
+			}
+		}
+		req.Permissions = kept
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdateOrgRole(role.Id, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating org role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Updated role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(req.Label))
+}
+
+func deleteRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	role := selectCustomRole(args, "Select a role to delete:")
+	if role == nil {
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgRole(role.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting org role: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Deleted role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label))
+}
+
+func selectCustomRole(args []string, msg string) *shared.OrgRole {
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+		return nil
+	}
+
+	if len(args) == 1 {
+		input := args[0]
+		var role *shared.OrgRole
+
+		index, err := strconv.Atoi(input)
+		if err == nil && index > 0 && index <= len(orgRoles) {
+			role = orgRoles[index-1]
+		} else {
+			for _, r := range orgRoles {
+				if strings.EqualFold(r.Label, input) {
+					role = r
+					break
+				}
+			}
+		}
+
+		if role == nil {
+			term.OutputErrorAndExit("No org role found for '%s'", input)
+			return nil
+		}
+
+		if role.IsDefault {
+			term.OutputErrorAndExit("Default roles can't be changed")
+			return nil
+		}
+
+		return role
+	}
+
+	var customRoles []*shared.OrgRole
+	var opts []string
+	for _, r := range orgRoles {
+		if !r.IsDefault {
+			customRoles = append(customRoles, r)
+			opts = append(opts, r.Label)
+		}
+	}
+
+	if len(customRoles) == 0 {
+		fmt.Println("🤷‍♂️ No custom roles")
+		fmt.Println()
+		term.PrintCmds("", "roles create")
+		return nil
+	}
+
+	selected, err := term.SelectFromList(msg, opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting role: %v", err)
+		return nil
+	}
+
+	for i, opt := range opts {
+		if opt == selected {
+			return customRoles[i]
+		}
+	}
+
+	return nil
+}
+
+// rolePermissionNames returns a role's permissions that aren't scoped to another role
+func rolePermissionNames(role *shared.OrgRole) []string {
+	var names []string
+	for _, p := range role.Permissions {
+		if !strings.Contains(string(p), "|") {
+			names = append(names, string(p))
+		}
+	}
+	return names
+}
+
+func parseRolePermissions(input []string) []shared.Permission {
+	var permissions []shared.Permission
+	for _, p := range input {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		permission := shared.Permission(strings.ReplaceAll(strings.ToLower(p), "-", "_"))
+		if !shared.IsCustomRolePermission(permission) {
+			term.OutputErrorAndExit("Unknown permission '%s' -- see 'plandex roles permissions'", p)
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions
+}
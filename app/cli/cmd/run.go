@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var runReportPath string
+var runReportFormat string
+var runVars []string
+
+var runCmd = &cobra.Command{
+	Use:   "run <spec.yaml>",
+	Short: "Run a plan from a yaml spec without prompting",
+	Long: `Start a new plan from a yaml spec, send its prompts, apply the changes, and run its checks. Every prompt is answered by the spec's policies, so it's suitable for CI.
+
+Exits with 0 if all checks pass, 2 if commands or checks fail, and 1 on any other error.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSpec,
+}
+
+func init() {
+	RootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringVar(&runReportPath, "report", "", "Write a report to this path (overrides the spec)")
+	runCmd.Flags().StringVar(&runReportFormat, "format", "", "Report format: json or junit (overrides the spec)")
+	runCmd.Flags().StringArrayVar(&runVars, "var", nil, "Set a template variable (key=val) -- overrides the spec's vars")
+}
+
+func runSpec(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveOrCreateProject()
+
+	spec, err := lib.LoadRunSpec(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error loading run spec: %v", err)
+	}
+
+	if runReportPath != "" {
+		spec.Report.Path = runReportPath
+	}
+	if runReportFormat != "" {
+		spec.Report.Format = types.RunReportFormat(runReportFormat)
+		if spec.Report.Format != types.RunReportFormatJson && spec.Report.Format != types.RunReportFormatJunit {
+			term.OutputErrorAndExit("Invalid --format '%s' -- use json or junit", runReportFormat)
+		}
+	}
+	if len(runVars) > 0 && spec.Template == "" {
+		term.OutputErrorAndExit("--var can only be used when the spec has a template")
+	}
+
+	report := &types.RunReport{
+		Name:      spec.Name,
+		Status:    types.RunStatusPassed,
+		StartedAt: time.Now(),
+		Prompts:   []*types.RunPromptResult{},
+		Checks:    []*types.RunCheckResult{},
+	}
+
+	writeReport := func() {
+		report.FinishedAt = time.Now()
+		if spec.Report.Path == "" {
+			return
+		}
+		err := lib.WriteRunReport(report, spec.Report.Format, spec.Report.Path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	// anything that exits early still leaves a report behind
+	term.SetBeforeErrorExitFn(func(msg string) {
+		term.SetBeforeErrorExitFn(nil)
+		report.Status = types.RunStatusError
+		report.Error = msg
+		writeReport()
+	})
+
+	var template *shared.PlanTemplate
+	if spec.Template != "" {
+		var varArgs []string
+		var keys []string
+		for k := range spec.Vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			varArgs = append(varArgs, k+"="+spec.Vars[k])
+		}
+		varArgs = append(varArgs, runVars...)
+
+		template = mustRenderPlanTemplate(spec.Template, varArgs)
+	}
+
+	// the spec's config takes precedence over the template's
+	if spec.Config.AutoMode != "" {
+		templateAutoMode = spec.Config.AutoMode
+	}
+	if spec.Config.ModelPack != "" {
+		templateModelPack = mustParseTemplateModelPack(spec.Config.ModelPack)
+	}
+
+	req := shared.CreatePlanRequest{Name: spec.Name}
+	if template != nil {
+		req.TemplateId = template.Id
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreatePlan(lib.CurrentProjectId, req)
+	term.StopSpinner()
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating plan: %v", apiErr.Msg)
+	}
+
+	err = lib.WriteCurrentPlan(res.Id)
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current plan: %v", err)
+	}
+
+	err = lib.WriteCurrentBranch("main")
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current branch: %v", err)
+	}
+	lib.CurrentBranch = "main"
+
+	report.PlanId = res.Id
+	report.Branch = lib.CurrentBranch
+
+	fmt.Printf("✅ Started new plan %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(res.Name))
+	if template != nil {
+		fmt.Printf("📋 Using template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+	}
+
+	config, apiErr := api.Client.GetPlanConfig(lib.CurrentPlanId)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan config: %v", apiErr.Msg)
+	}
+
+	_, config = resolveAutoMode(config)
+	resolveModelPack()
+
+	planContext := spec.Context
+	if template != nil {
+		planContext.Files = append(template.Context.Files, planContext.Files...)
+		planContext.Maps = append(template.Context.Maps, planContext.Maps...)
+		planContext.Trees = append(template.Context.Trees, planContext.Trees...)
+		planContext.Notes = append(template.Context.Notes, planContext.Notes...)
+	}
+
+	if !planContext.IsEmpty() {
+		fmt.Println("📥 Loading context")
+		fmt.Println()
+		lib.MustLoadTemplateContext(planContext)
+	}
+
+	var apiKeys map[string]string
+	if !auth.Current.IntegratedModelsMode {
+		apiKeys = lib.MustVerifyApiKeysSilent()
+	}
+
+	shouldApply := spec.Config.Apply == nil || *spec.Config.Apply
+	lib.SemanticCheck = config.SemanticCheck
+	canExec := config.CanExec
+	if spec.Config.Exec != nil {
+		canExec = *spec.Config.Exec
+	}
+	debugTries := spec.Config.Debug
+	if debugTries == 0 && config.AutoDebug {
+		debugTries = config.AutoDebugTries
+	}
+	if !canExec {
+		debugTries = 0
+	}
+
+	execParams := plan_exec.ExecParams{
+		CurrentPlanId: lib.CurrentPlanId,
+		CurrentBranch: lib.CurrentBranch,
+		ApiKeys:       apiKeys,
+		CheckOutdatedContext: func(maybeContexts []*shared.Context, projectPaths *types.ProjectPaths) (bool, bool, error) {
+			if spec.Policy.OutdatedContext == types.RunOutdatedContextUpdate {
+				return lib.CheckOutdatedContextWithOutput(true, true, maybeContexts, projectPaths)
+			}
+			outdatedRes, err := lib.CheckOutdatedContext(maybeContexts, projectPaths)
+			if err != nil {
+				return false, false, err
+			}
+			return len(outdatedRes.UpdatedContexts) > 0 || len(outdatedRes.RemovedContexts) > 0, false, nil
+		},
+	}
+
+	tellFlags := types.TellFlags{
+		TellStop:     !config.AutoContinue,
+		AutoContext:  config.AutoLoadContext,
+		SmartContext: config.SmartContext,
+		ExecEnabled:  canExec,
+		AutoApply:    shouldApply,
+	}
+
+	var prompts []string
+	if template != nil && template.Prompt != "" {
+		prompts = append(prompts, template.Prompt)
+	}
+	prompts = append(prompts, spec.Prompts...)
+
+	for _, prompt := range prompts {
+		promptRes := &types.RunPromptResult{
+			Prompt: prompt,
+			Status: types.RunStatusPassed,
+		}
+		report.Prompts = append(report.Prompts, promptRes)
+
+		start := time.Now()
+		tellRes, err := plan_exec.TellPlanHeadless(execParams, prompt, tellFlags, spec.Policy.MissingFile)
+		promptRes.Duration = time.Since(start)
+
+		if err != nil {
+			promptRes.Status = types.RunStatusError
+			promptRes.Error = err.Error()
+			report.Status = types.RunStatusError
+			report.Error = err.Error()
+			term.OutputSimpleError("Prompt failed: %v", err)
+			break
+		}
+
+		promptRes.BuiltFiles = tellRes.BuiltFiles
+		promptRes.MissingFiles = tellRes.MissingFiles
+	}
+
+	if report.Status == types.RunStatusPassed && shouldApply {
+		report.Apply = &types.RunApplyResult{Status: types.RunStatusPassed}
+
+		applyFlags := types.ApplyFlags{
+			AutoConfirm: true,
+			AutoCommit:  spec.Config.Commit,
+			NoCommit:    !spec.Config.Commit,
+			AutoExec:    true,
+			NoExec:      !canExec,
+			AutoDebug:   debugTries,
+		}
+
+		lib.MustApplyPlan(lib.ApplyPlanParams{
+			PlanId:     lib.CurrentPlanId,
+			Branch:     lib.CurrentBranch,
+			ApplyFlags: applyFlags,
+			TellFlags:  tellFlags,
+			OnExecFail: plan_exec.GetHeadlessOnApplyExecFail(execParams, applyFlags, tellFlags, spec.Policy.MissingFile, spec.Policy.ExecFail, func(status int, output string, attempt int, err error) {
+				report.Apply.ExecStatus = status
+				report.Apply.ExecOutput = output
+				report.Apply.DebugAttempts = attempt
+				if err != nil {
+					report.Apply.Status = types.RunStatusError
+					report.Apply.Error = err.Error()
+				} else {
+					report.Apply.Status = types.RunStatusFailed
+					report.Apply.Error = fmt.Sprintf("commands failed with exit status %d", status)
+				}
+			}),
+		})
+
+		switch report.Apply.Status {
+		case types.RunStatusError:
+			report.Status = types.RunStatusError
+			report.Error = report.Apply.Error
+		case types.RunStatusFailed:
+			report.Status = types.RunStatusFailed
+		}
+	}
+
+	if report.Status != types.RunStatusError && len(spec.Checks) > 0 {
+		fmt.Println()
+		report.Checks = lib.RunChecks(spec.Checks)
+
+		for _, check := range report.Checks {
+			if check.Status != types.RunStatusPassed {
+				report.Status = types.RunStatusFailed
+			}
+		}
+	}
+
+	term.SetBeforeErrorExitFn(nil)
+	writeReport()
+
+	fmt.Println()
+	switch report.Status {
+	case types.RunStatusPassed:
+		color.New(color.Bold, term.ColorHiGreen).Printf("✅ Run '%s' passed\n", spec.Name)
+	case types.RunStatusFailed:
+		color.New(color.Bold, term.ColorHiRed).Printf("❌ Run '%s' failed\n", spec.Name)
+	default:
+		color.New(color.Bold, term.ColorHiRed).Printf("🚨 Run '%s' errored: %s\n", spec.Name, report.Error)
+	}
+
+	if spec.Report.Path != "" {
+		fmt.Printf("📝 Report written to %s\n", spec.Report.Path)
+	}
+
+	os.Exit(report.ExitCode())
+}
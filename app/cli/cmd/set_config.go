@@ -303,6 +303,12 @@ func updateConfig(args []string, originalConfig *shared.PlanConfig) (string, *sh
 		}
 	}
 
+	err := config.Validate()
+	if err != nil {
+		term.OutputErrorAndExit("Invalid value for %s: %v", cfgSetting.Name, err)
+		return "", nil
+	}
+
 	return setting, &config
 }
 
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var shareLevel string
+var shareWholeProject bool
+
+var shareCmd = &cobra.Command{
+	Use:   "share [email]",
+	Short: "Share the current plan with an org member or the whole project -- lists current shares if no email is given",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   share,
+}
+
+var unshareCmd = &cobra.Command{
+	Use:   "unshare [email-or-index]",
+	Short: "Stop sharing the current plan with an org member or the whole project",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   unshare,
+}
+
+func init() {
+	RootCmd.AddCommand(shareCmd)
+	RootCmd.AddCommand(unshareCmd)
+
+	shareCmd.Flags().StringVarP(&shareLevel, "level", "l", "read-only", "Access level: read-only, comment, or contributor")
+	shareCmd.Flags().BoolVar(&shareWholeProject, "project", false, "Share with everyone working in the plan's project")
+
+	unshareCmd.Flags().BoolVar(&shareWholeProject, "project", false, "Remove the whole-project share")
+}
+
+func share(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	if len(args) == 0 && !shareWholeProject {
+		listPlanShares()
+		return
+	}
+
+	level := shared.PlanShareLevel(strings.ReplaceAll(strings.ToLower(shareLevel), "-", "_"))
+	if !level.IsValid() {
+		term.OutputErrorAndExit("Invalid level '%s' -- use read-only, comment, or contributor", shareLevel)
+	}
+
+	req := shared.SharePlanRequest{
+		WholeProject: shareWholeProject,
+		Level:        level,
+	}
+
+	if len(args) > 0 {
+		if shareWholeProject {
+			term.OutputErrorAndExit("Use either an email or --project, not both")
+		}
+		req.Email = args[0]
+	}
+
+	term.StartSpinner("")
+	planShare, apiErr := api.Client.SharePlan(lib.CurrentPlanId, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error sharing plan: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Shared plan with %s (%s)\n", color.New(color.Bold, term.ColorHiCyan).Sprint(planShareTarget(planShare)), planShareLevelLabel(planShare.Level))
+	fmt.Println()
+	term.PrintCmds("", "share", "unshare")
+}
+
+func unshare(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching plan shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ Plan isn't shared")
+		return
+	}
+
+	var toRemove *shared.PlanShare
+
+	if shareWholeProject {
+		for _, s := range shares {
+			if s.ProjectId != nil {
+				toRemove = s
+				break
+			}
+		}
+
+		if toRemove == nil {
+			term.OutputErrorAndExit("Plan isn't shared with the whole project")
+		}
+	} else if len(args) == 1 {
+		input := args[0]
+		index, err := strconv.Atoi(input)
+		if err == nil && index > 0 && index <= len(shares) {
+			toRemove = shares[index-1]
+		} else {
+			for _, s := range shares {
+				if strings.EqualFold(s.UserEmail, input) {
+					toRemove = s
+					break
+				}
+			}
+		}
+
+		if toRemove == nil {
+			term.OutputErrorAndExit("No share found for '%s'", input)
+		}
+	} else {
+		opts := make([]string, len(shares))
+		for i, s := range shares {
+			opts[i] = fmt.Sprintf("%d. %s (%s)", i+1, planShareTarget(s), planShareLevelLabel(s.Level))
+		}
+
+		selected, err := term.SelectFromList("Select a share to remove:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting share: %v", err)
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				toRemove = shares[i]
+				break
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.UnsharePlan(lib.CurrentPlanId, toRemove.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing share: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Stopped sharing plan with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(planShareTarget(toRemove)))
+}
+
+func listPlanShares() {
+	term.StartSpinner("")
+	shares, apiErr := api.Client.ListPlanShares(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching plan shares: %v", apiErr.Msg)
+	}
+
+	if len(shares) == 0 {
+		fmt.Println("🤷‍♂️ Plan isn't shared")
+		fmt.Println()
+		term.PrintCmds("", "share")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Shared With", "Access", "Shared"})
+
+	for i, s := range shares {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			planShareTarget(s),
+			planShareLevelLabel(s.Level),
+			format.Time(s.CreatedAt),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "share", "unshare")
+}
+
+func planShareTarget(s *shared.PlanShare) string {
+	if s.ProjectId != nil {
+		return "whole project"
+	}
+	if s.UserName != "" {
+		return fmt.Sprintf("%s <%s>", s.UserName, s.UserEmail)
+	}
+	return s.UserEmail
+}
+
+func planShareLevelLabel(level shared.PlanShareLevel) string {
+	return strings.ReplaceAll(string(level), "_", "-")
+}
@@ -3,11 +3,14 @@ package cmd
 import (
 	"plandex-cli/auth"
 	"plandex-cli/term"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var pin string
+var signInSso string
+var signInHost string
 
 var signInCmd = &cobra.Command{
 	Use:   "sign-in",
@@ -20,11 +23,33 @@ func init() {
 	RootCmd.AddCommand(signInCmd)
 
 	signInCmd.Flags().StringVar(&pin, "pin", "", "Sign in with a pin from the Plandex Cloud web UI")
+	signInCmd.Flags().StringVar(&signInSso, "sso", "", "Sign in with single sign-on -- optionally pass a provider id")
+	signInCmd.Flags().Lookup("sso").NoOptDefVal = " "
+	signInCmd.Flags().StringVar(&signInHost, "host", "", "Host to sign in to (for self-hosted servers)")
 }
 
 func signIn(cmd *cobra.Command, args []string) {
+	if cmd.Flags().Changed("sso") {
+		host := signInHost
+		if host == "" {
+			var err error
+			host, err = term.GetRequiredUserStringInput("Host:")
+			if err != nil {
+				term.OutputErrorAndExit("Error prompting host: %v", err)
+			}
+		}
+
+		err := auth.SignInWithSso(host, strings.TrimSpace(signInSso))
+
+		if err != nil {
+			term.OutputErrorAndExit("Error signing in: %v", err)
+		}
+
+		return
+	}
+
 	if pin != "" {
-		err := auth.SignInWithCode(pin, "")
+		err := auth.SignInWithCode(pin, signInHost)
 
 		if err != nil {
 			term.OutputErrorAndExit("Error signing in: %v", err)
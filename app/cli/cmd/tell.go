@@ -36,17 +36,14 @@ func init() {
 	initExecFlags(tellCmd, initExecFlagsParams{})
 
 	tellCmd.Flags().BoolVar(&isImplementationOfChat, "from-chat", false, "Begin implementation based on conversation so far")
+	tellCmd.Flags().BoolVar(&tellPreview, "preview", false, "Show the token breakdown and model for the prompt without sending it")
 }
 
 func doTell(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveProject()
 	mustSetPlanExecFlags(cmd)
-
-	var apiKeys map[string]string
-	if !auth.Current.IntegratedModelsMode {
-		apiKeys = lib.MustVerifyApiKeys()
-	}
+	mustValidatePreviewFlags()
 
 	if isImplementationOfChat && len(args) > 0 {
 		term.OutputErrorAndExit("Error: --from-chat cannot be used with a prompt")
@@ -73,6 +70,16 @@ func doTell(cmd *cobra.Command, args []string) {
 		IsImplementationOfChat: isImplementationOfChat,
 	}
 
+	if tellPreview {
+		previewTell(prompt, tellFlags)
+		return
+	}
+
+	var apiKeys map[string]string
+	if !auth.Current.IntegratedModelsMode {
+		apiKeys = lib.MustVerifyApiKeys()
+	}
+
 	plan_exec.TellPlan(plan_exec.ExecParams{
 		CurrentPlanId: lib.CurrentPlanId,
 		CurrentBranch: lib.CurrentBranch,
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+var tellPreview bool
+
+// --json is only accepted by tell and chat along with --preview
+func mustValidatePreviewFlags() {
+	if term.IsJsonOutput && !tellPreview {
+		term.OutputErrorAndExit("--json can only be used with --preview")
+	}
+}
+
+func previewTell(prompt string, flags types.TellFlags) {
+	res := plan_exec.PreviewTellPlan(plan_exec.ExecParams{
+		CurrentPlanId: lib.CurrentPlanId,
+		CurrentBranch: lib.CurrentBranch,
+	}, prompt, flags)
+
+	if term.IsJsonOutput {
+		term.OutputJson(res)
+		return
+	}
+
+	stage := string(res.Stage.TellStage)
+	if res.Stage.PlanningPhase != "" {
+		stage += " → " + string(res.Stage.PlanningPhase)
+	}
+	color.New(color.Bold, term.ColorHiCyan).Printf("🔎 Preview for %s stage — nothing was sent\n\n", stage)
+
+	if len(res.Contexts) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"#", "Context", "🪙", "Status"})
+		table.SetAutoWrapText(false)
+
+		for i, previewContext := range res.Contexts {
+			_, icon := (&shared.Context{ContextType: previewContext.ContextType}).TypeAndIcon()
+
+			name := previewContext.Name
+			if name == "" {
+				name = previewContext.FilePath
+			}
+			if len(name) > 40 {
+				name = name[:20] + "⋯" + name[len(name)-20:]
+			}
+			if previewContext.IsPending {
+				name += " (pending)"
+			}
+
+			var statusColor tablewriter.Colors
+			switch previewContext.Status {
+			case shared.TellPlanPreviewContextIncluded:
+				statusColor = tablewriter.Colors{tablewriter.FgHiGreenColor}
+			case shared.TellPlanPreviewContextDropped:
+				statusColor = tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold}
+			default:
+				statusColor = tablewriter.Colors{tablewriter.FgHiBlackColor}
+			}
+
+			table.Rich([]string{
+				strconv.Itoa(i + 1),
+				" " + icon + " " + name,
+				strconv.Itoa(previewContext.NumTokens),
+				string(previewContext.Status),
+			}, []tablewriter.Colors{
+				{tablewriter.Bold},
+				{tablewriter.FgHiGreenColor, tablewriter.Bold},
+				{},
+				statusColor,
+			})
+		}
+
+		table.Render()
+		fmt.Println()
+	}
+
+	convoLabel := fmt.Sprintf("%d messages", res.ConvoMessages)
+	convoTokens := res.ConvoTokens
+	if res.WillSummarize {
+		convoLabel += fmt.Sprintf(", summarized from %d 🪙", res.ConvoTokens)
+		convoTokens = res.ConvoTokensAfterSummary
+	}
+
+	tokensTbl := tablewriter.NewWriter(os.Stdout)
+	tokensTbl.SetAutoWrapText(false)
+	tokensTbl.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_LEFT})
+	for _, row := range [][]string{
+		{"System prompt", strconv.Itoa(res.SystemPromptTokens), ""},
+		{"Context", strconv.Itoa(res.ContextTokens), ""},
+		{"Conversation", strconv.Itoa(convoTokens), convoLabel},
+		{"Summary", strconv.Itoa(res.SummaryTokens), ""},
+		{"Prompt", strconv.Itoa(res.PromptTokens), ""},
+		{"Total input", strconv.Itoa(res.TotalTokens), fmt.Sprintf("limit %d 🪙", res.MaxTokens)},
+		{"Reserved output", strconv.Itoa(res.ReservedOutputTokens), ""},
+	} {
+		tokensTbl.Append([]string{
+			color.New(term.ColorHiCyan, color.Bold).Sprint(row[0]),
+			row[1] + " 🪙",
+			row[2],
+		})
+	}
+	tokensTbl.Render()
+	fmt.Println()
+
+	model := color.New(color.Bold, term.ColorHiGreen).Sprint(string(res.ModelName))
+	if res.IsFallback {
+		fmt.Printf("🧠 Model → %s (large context fallback for %s)\n", model, res.BaseModelName)
+	} else {
+		fmt.Printf("🧠 Model → %s\n", model)
+	}
+
+	if res.WillSummarize {
+		fmt.Printf("📝 Conversation is over the limit (%d 🪙 max) — earlier messages will be replaced by a summary\n", res.MaxConvoTokens)
+	}
+
+	numDropped := 0
+	for _, previewContext := range res.Contexts {
+		if previewContext.Status == shared.TellPlanPreviewContextDropped {
+			numDropped++
+		}
+	}
+	if numDropped == 1 {
+		fmt.Println("✂️  1 context item won't fit in the token budget and will be dropped")
+	} else if numDropped > 1 {
+		fmt.Printf("✂️  %d context items won't fit in the token budget and will be dropped\n", numDropped)
+	}
+
+	if res.Error != "" {
+		fmt.Println()
+		term.OutputSimpleError("This prompt would fail before reaching the model: %s", res.Error)
+	}
+}
@@ -0,0 +1,484 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var templateNewName string
+var templateDescription string
+var templatePrompt string
+var templatePromptFile string
+var templateFiles []string
+var templateMaps []string
+var templateTrees []string
+var templateNotes []string
+var templateAutoModeFlag string
+var templateModelPackFlag string
+
+var templatesCmd = &cobra.Command{
+	Use:     "templates",
+	Aliases: []string{"template"},
+	Short:   "List the org's plan templates",
+	Run:     listPlanTemplates,
+}
+
+var showPlanTemplateCmd = &cobra.Command{
+	Use:   "show [name-or-index]",
+	Short: "Show a plan template",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   showPlanTemplate,
+}
+
+var createPlanTemplateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a plan template",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createPlanTemplate,
+}
+
+var updatePlanTemplateCmd = &cobra.Command{
+	Use:   "update [name-or-index]",
+	Short: "Update a plan template",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   updatePlanTemplate,
+}
+
+var deletePlanTemplateCmd = &cobra.Command{
+	Use:     "delete [name-or-index]",
+	Aliases: []string{"rm"},
+	Short:   "Delete a plan template",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deletePlanTemplate,
+}
+
+func init() {
+	RootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(showPlanTemplateCmd)
+	templatesCmd.AddCommand(createPlanTemplateCmd)
+	templatesCmd.AddCommand(updatePlanTemplateCmd)
+	templatesCmd.AddCommand(deletePlanTemplateCmd)
+
+	for _, cmd := range []*cobra.Command{createPlanTemplateCmd, updatePlanTemplateCmd} {
+		cmd.Flags().StringVarP(&templateDescription, "description", "d", "", "Template description")
+		cmd.Flags().StringVarP(&templatePrompt, "prompt", "p", "", "Prompt skeleton -- use {{name}} for variables")
+		cmd.Flags().StringVarP(&templatePromptFile, "file", "f", "", "File containing the prompt skeleton")
+		cmd.Flags().StringArrayVar(&templateFiles, "load", nil, "File, directory, or glob to load as context -- can be repeated")
+		cmd.Flags().StringArrayVar(&templateMaps, "map", nil, "Directory to load a project map for -- can be repeated")
+		cmd.Flags().StringArrayVar(&templateTrees, "tree", nil, "Directory to load a file tree for -- can be repeated")
+		cmd.Flags().StringArrayVarP(&templateNotes, "note", "n", nil, "Note to load as context -- can be repeated")
+		cmd.Flags().StringVar(&templateAutoModeFlag, "auto-mode", "", "Auto mode to start plans with (full, semi, plus, basic, or none)")
+		cmd.Flags().StringVar(&templateModelPackFlag, "model-pack", "", "Built-in model pack to start plans with")
+	}
+
+	updatePlanTemplateCmd.Flags().StringVar(&templateNewName, "name", "", "New name for the template")
+}
+
+func listPlanTemplates(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	templates, apiErr := api.Client.ListPlanTemplates()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching plan templates: %v", apiErr.Msg)
+		return
+	}
+
+	if term.IsJsonOutput {
+		if templates == nil {
+			templates = []*shared.PlanTemplate{}
+		}
+		term.OutputJson(templates)
+		return
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("🤷‍♂️ No plan templates")
+		fmt.Println()
+		term.PrintCmds("", "templates create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Description", "Variables", "Auto Mode", "Model Pack"})
+
+	for i, template := range templates {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			template.Name,
+			template.Description,
+			strings.Join(template.Variables(), ", "),
+			string(template.AutoMode),
+			template.ModelPack,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "new --template", "templates show", "templates create")
+}
+
+func showPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	template := mustSelectPlanTemplate(args, "show")
+
+	if term.IsJsonOutput {
+		term.OutputJson(template)
+		return
+	}
+
+	color.New(color.Bold, term.ColorHiCyan).Println(template.Name)
+	if template.Description != "" {
+		fmt.Println(template.Description)
+	}
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+
+	vars := template.Variables()
+	if len(vars) > 0 {
+		table.Append([]string{"Variables", strings.Join(vars, ", ")})
+	}
+	if template.AutoMode != "" {
+		table.Append([]string{"Auto Mode", string(template.AutoMode)})
+	}
+	if template.ModelPack != "" {
+		table.Append([]string{"Model Pack", template.ModelPack})
+	}
+	for _, path := range template.Context.Files {
+		table.Append([]string{"Load", path})
+	}
+	for _, dir := range template.Context.Maps {
+		table.Append([]string{"Map", dir})
+	}
+	for _, dir := range template.Context.Trees {
+		table.Append([]string{"Tree", dir})
+	}
+	for _, note := range template.Context.Notes {
+		table.Append([]string{"Note", note})
+	}
+
+	if table.NumLines() > 0 {
+		table.Render()
+		fmt.Println()
+	}
+
+	if template.Prompt != "" {
+		color.New(color.Bold).Println("Prompt")
+		fmt.Println(template.Prompt)
+		fmt.Println()
+	}
+
+	term.PrintCmds("", "new --template", "templates update")
+}
+
+func createPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Template name:")
+		if err != nil {
+			term.OutputErrorAndExit("Error reading template name: %v", err)
+			return
+		}
+	}
+
+	req := shared.CreatePlanTemplateRequest{
+		Name:        strings.TrimSpace(name),
+		Description: templateDescription,
+		Prompt:      mustGetTemplatePromptFlag(),
+		Context: shared.PlanTemplateContext{
+			Files: templateFiles,
+			Maps:  templateMaps,
+			Trees: templateTrees,
+			Notes: templateNotes,
+		},
+		AutoMode:  mustParseTemplateAutoMode(templateAutoModeFlag),
+		ModelPack: mustParseTemplateModelPack(templateModelPackFlag),
+	}
+
+	term.StartSpinner("")
+	template, apiErr := api.Client.CreatePlanTemplate(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating plan template: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Created plan template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+
+	vars := template.Variables()
+	if len(vars) > 0 {
+		fmt.Printf("Variables: %s\n", strings.Join(vars, ", "))
+	}
+	fmt.Println()
+
+	term.PrintCmds("", "new --template", "templates")
+}
+
+func updatePlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	template := mustSelectPlanTemplate(args, "update")
+
+	var req shared.UpdatePlanTemplateRequest
+
+	if cmd.Flags().Changed("name") {
+		req.Name = &templateNewName
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = &templateDescription
+	}
+	if cmd.Flags().Changed("prompt") || cmd.Flags().Changed("file") {
+		prompt := mustGetTemplatePromptFlag()
+		req.Prompt = &prompt
+	}
+
+	// each context flag replaces that part of the template's context
+	if cmd.Flags().Changed("load") || cmd.Flags().Changed("map") || cmd.Flags().Changed("tree") || cmd.Flags().Changed("note") {
+		templateContext := template.Context
+		if cmd.Flags().Changed("load") {
+			templateContext.Files = templateFiles
+		}
+		if cmd.Flags().Changed("map") {
+			templateContext.Maps = templateMaps
+		}
+		if cmd.Flags().Changed("tree") {
+			templateContext.Trees = templateTrees
+		}
+		if cmd.Flags().Changed("note") {
+			templateContext.Notes = templateNotes
+		}
+		req.Context = &templateContext
+	}
+
+	if cmd.Flags().Changed("auto-mode") {
+		autoMode := mustParseTemplateAutoMode(templateAutoModeFlag)
+		req.AutoMode = &autoMode
+	}
+	if cmd.Flags().Changed("model-pack") {
+		modelPack := mustParseTemplateModelPack(templateModelPackFlag)
+		req.ModelPack = &modelPack
+	}
+
+	if req.Name == nil && req.Description == nil && req.Prompt == nil && req.Context == nil && req.AutoMode == nil && req.ModelPack == nil {
+		term.OutputErrorAndExit("Nothing to update -- use --name, --description, --prompt, --file, --load, --map, --tree, --note, --auto-mode, or --model-pack")
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdatePlanTemplate(template.Id, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating plan template: %v", apiErr.Msg)
+		return
+	}
+
+	name := template.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+
+	fmt.Printf("✅ Updated plan template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name))
+}
+
+func deletePlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	template := mustSelectPlanTemplate(args, "delete")
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeletePlanTemplate(template.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting plan template: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Deleted plan template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+}
+
+func mustSelectPlanTemplate(args []string, verb string) *shared.PlanTemplate {
+	term.StartSpinner("")
+	templates, apiErr := api.Client.ListPlanTemplates()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching plan templates: %v", apiErr.Msg)
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("🤷‍♂️ No plan templates")
+		os.Exit(0)
+	}
+
+	if len(args) == 1 {
+		input := args[0]
+		index, err := strconv.Atoi(input)
+		if err == nil && index > 0 && index <= len(templates) {
+			return templates[index-1]
+		}
+
+		for _, template := range templates {
+			if template.Name == input {
+				return template
+			}
+		}
+
+		term.OutputErrorAndExit("No plan template found for '%s'", input)
+	}
+
+	if len(templates) == 1 {
+		return templates[0]
+	}
+
+	opts := make([]string, len(templates))
+	for i, template := range templates {
+		opts[i] = fmt.Sprintf("%d. %s", i+1, template.Name)
+	}
+
+	selected, err := term.SelectFromList(fmt.Sprintf("Select a plan template to %s:", verb), opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting plan template: %v", err)
+	}
+
+	for i, opt := range opts {
+		if opt == selected {
+			return templates[i]
+		}
+	}
+
+	return nil
+}
+
+// mustRenderPlanTemplate gets a template by name and fills in its variables from key=val args. It also sets the template's auto mode and model pack for resolveAutoMode and resolveModelPack.
+func mustRenderPlanTemplate(name string, varArgs []string) *shared.PlanTemplate {
+	template := mustSelectPlanTemplate([]string{name}, "use")
+
+	vars := map[string]string{}
+	for _, arg := range varArgs {
+		key, val, ok := strings.Cut(arg, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			term.OutputErrorAndExit("Invalid --var '%s' -- use key=val", arg)
+		}
+		vars[key] = val
+	}
+
+	rendered, err := template.Render(vars)
+	if err != nil {
+		var flags []string
+		for _, v := range template.Variables() {
+			if _, ok := vars[v]; !ok {
+				flags = append(flags, fmt.Sprintf("--var %s=...", v))
+			}
+		}
+		term.OutputErrorAndExit("%v\nPass them with %s", err, strings.Join(flags, " "))
+	}
+
+	templateAutoMode = rendered.AutoMode
+	templateModelPack = rendered.ModelPack
+
+	return rendered
+}
+
+// maybeSendTemplatePrompt shows a template's rendered prompt and offers to send it. It returns true if the prompt was sent.
+func maybeSendTemplatePrompt(cmd *cobra.Command, prompt string) bool {
+	fmt.Println()
+	color.New(color.Bold, term.ColorHiCyan).Println("📝 Template prompt")
+	fmt.Println(prompt)
+	fmt.Println()
+
+	if term.IsRepl {
+		return false
+	}
+
+	send, err := term.ConfirmYesNo("Send the prompt now?")
+	if err != nil {
+		term.OutputErrorAndExit("Error getting confirmation: %v", err)
+	}
+
+	if !send {
+		return false
+	}
+
+	doTell(cmd, []string{prompt})
+	return true
+}
+
+func mustGetTemplatePromptFlag() string {
+	if templatePromptFile == "" {
+		return templatePrompt
+	}
+
+	if templatePrompt != "" {
+		term.OutputErrorAndExit("--prompt and --file can't be used together")
+	}
+
+	bytes, err := os.ReadFile(templatePromptFile)
+	if err != nil {
+		term.OutputErrorAndExit("Error reading prompt file: %v", err)
+	}
+
+	return strings.TrimSpace(string(bytes))
+}
+
+func mustParseTemplateAutoMode(s string) shared.AutoModeType {
+	mode := shared.AutoModeType(strings.ToLower(strings.TrimSpace(s)))
+	if !shared.IsValidPlanTemplateAutoMode(mode) {
+		var valid []string
+		for _, m := range shared.PlanTemplateAutoModes {
+			valid = append(valid, string(m))
+		}
+		term.OutputErrorAndExit("Invalid auto mode '%s' -- valid modes: %s", s, strings.Join(valid, ", "))
+	}
+	return mode
+}
+
+// mustParseTemplateModelPack resolves a model pack name to the built-in pack's name
+func mustParseTemplateModelPack(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+
+	compare := s
+	if compare == "daily" {
+		compare = shared.DailyDriverModelPack.Name
+	}
+
+	var valid []string
+	for _, mp := range shared.BuiltInModelPacks {
+		if strings.EqualFold(mp.Name, compare) {
+			return mp.Name
+		}
+		valid = append(valid, mp.Name)
+	}
+
+	term.OutputErrorAndExit("Invalid model pack '%s' -- valid packs: %s", s, strings.Join(valid, ", "))
+	return ""
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var tokenPermissions []string
+var tokenExpiresInDays int
+var tokenServiceAccount string
+var tokenRole string
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "List api tokens",
+	Run:   listApiTokens,
+}
+
+var createTokenCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an api token for automation",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createApiToken,
+}
+
+var revokeTokenCmd = &cobra.Command{
+	Use:     "revoke [name-or-index]",
+	Aliases: []string{"rm"},
+	Short:   "Revoke an api token by name or index",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     revokeApiToken,
+}
+
+func init() {
+	RootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(createTokenCmd)
+	tokensCmd.AddCommand(revokeTokenCmd)
+
+	createTokenCmd.Flags().StringSliceVarP(&tokenPermissions, "permissions", "p", nil, "Limit the token to these permissions (comma-separated) -- defaults to all of the account's permissions")
+	createTokenCmd.Flags().IntVarP(&tokenExpiresInDays, "expires", "e", 90, "Days until the token expires -- 0 for no expiration")
+	createTokenCmd.Flags().StringVarP(&tokenServiceAccount, "service-account", "s", "", "Create the token for an org service account instead of yourself -- the account is created if it doesn't exist")
+	createTokenCmd.Flags().StringVar(&tokenRole, "role", "", "Org role for a new service account (defaults to member)")
+}
+
+func listApiTokens(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiTokens, apiErr := api.Client.ListApiTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching api tokens: %v", apiErr.Msg)
+		return
+	}
+
+	if len(apiTokens) == 0 {
+		fmt.Println("🤷‍♂️ No api tokens")
+		fmt.Println()
+		term.PrintCmds("", "tokens create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Token", "Account", "Permissions", "Expires", "Last Used"})
+
+	for i, apiToken := range apiTokens {
+		account := "You"
+		if apiToken.ServiceAccountName != "" {
+			account = "🤖 " + apiToken.ServiceAccountName
+		}
+
+		permissions := "All"
+		if len(apiToken.Permissions) > 0 {
+			names := make([]string, len(apiToken.Permissions))
+			for j, p := range apiToken.Permissions {
+				names[j] = string(p)
+			}
+			permissions = strings.Join(names, ", ")
+		}
+
+		expires := "Never"
+		if apiToken.ExpiresAt != nil {
+			expires = apiToken.ExpiresAt.Local().Format("2006-01-02")
+		}
+
+		lastUsed := "Never"
+		if apiToken.LastUsedAt != nil {
+			lastUsed = format.Time(*apiToken.LastUsedAt)
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			apiToken.Name,
+			apiToken.TokenPrefix + "…",
+			account,
+			permissions,
+			expires,
+			lastUsed,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "tokens create", "tokens revoke")
+}
+
+func createApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		var err error
+		name, err = term.GetRequiredUserStringInput("Token name:")
+		if err != nil {
+			term.OutputErrorAndExit("Error reading token name: %v", err)
+			return
+		}
+	}
+
+	req := shared.CreateApiTokenRequest{
+		Name:               name,
+		ExpiresInDays:      tokenExpiresInDays,
+		ServiceAccountName: tokenServiceAccount,
+	}
+
+	for _, p := range tokenPermissions {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			req.Permissions = append(req.Permissions, shared.Permission(p))
+		}
+	}
+
+	if tokenRole != "" {
+		if tokenServiceAccount == "" {
+			term.OutputErrorAndExit("--role can only be used with --service-account")
+			return
+		}
+
+		term.StartSpinner("")
+		orgRoles, apiErr := api.Client.ListOrgRoles()
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+			return
+		}
+
+		for _, role := range orgRoles {
+			if strings.EqualFold(role.Label, tokenRole) {
+				req.OrgRoleId = role.Id
+				break
+			}
+		}
+
+		if req.OrgRoleId == "" {
+			term.OutputErrorAndExit("No org role named '%s'", tokenRole)
+			return
+		}
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateApiToken(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating api token: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Created api token %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(res.ApiToken.Name))
+	fmt.Println()
+	fmt.Println(res.Token)
+	fmt.Println()
+	fmt.Println("This token won't be shown again. Store it somewhere safe.")
+	fmt.Printf("To use it, set %s in the environment of any plandex command.\n", color.New(color.Bold).Sprint(shared.ApiTokenEnvVar))
+	fmt.Println()
+
+	term.PrintCmds("", "tokens", "tokens revoke")
+}
+
+func revokeApiToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiTokens, apiErr := api.Client.ListApiTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching api tokens: %v", apiErr.Msg)
+		return
+	}
+
+	if len(apiTokens) == 0 {
+		fmt.Println("🤷‍♂️ No api tokens")
+		return
+	}
+
+	var toRevoke *shared.ApiToken
+
+	if len(args) == 1 {
+		input := args[0]
+		index, err := strconv.Atoi(input)
+		if err == nil && index > 0 && index <= len(apiTokens) {
+			toRevoke = apiTokens[index-1]
+		} else {
+			for _, apiToken := range apiTokens {
+				if apiToken.Name == input || apiToken.TokenPrefix == input {
+					toRevoke = apiToken
+					break
+				}
+			}
+		}
+
+		if toRevoke == nil {
+			term.OutputErrorAndExit("No api token found for '%s'", input)
+			return
+		}
+	} else {
+		opts := make([]string, len(apiTokens))
+		for i, apiToken := range apiTokens {
+			label := fmt.Sprintf("%d. %s (%s…)", i+1, apiToken.Name, apiToken.TokenPrefix)
+			if apiToken.ServiceAccountName != "" {
+				label += " 🤖 " + apiToken.ServiceAccountName
+			}
+			opts[i] = label
+		}
+
+		selected, err := term.SelectFromList("Select a token to revoke:", opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting token: %v", err)
+			return
+		}
+
+		for i, opt := range opts {
+			if opt == selected {
+				toRevoke = apiTokens[i]
+				break
+			}
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.RevokeApiToken(toRevoke.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error revoking api token: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Revoked api token %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(toRevoke.Name))
+}
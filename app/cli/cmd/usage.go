@@ -118,6 +118,11 @@ func showUsage() {
 		term.OutputErrorAndExit("Error getting credits summary: %v", apiErr)
 	}
 
+	if term.IsJsonOutput {
+		term.OutputJson(res)
+		return
+	}
+
 	builder := strings.Builder{}
 
 	balance := res.Balance
@@ -299,6 +304,14 @@ func showLog(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.IsJsonOutput {
+		if res.Transactions == nil {
+			res.Transactions = []*shared.CreditsTransaction{}
+		}
+		term.OutputJson(res)
+		return
+	}
+
 	transactions := res.Transactions
 
 	if len(transactions) == 0 {
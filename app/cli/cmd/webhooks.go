@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/format"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var webhookEvents []string
+var webhookProject bool
+var webhookDescription string
+var webhookUrl string
+var webhookDisable bool
+var webhookEnable bool
+var webhookDeliveriesLimit int
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "List webhooks for plan events",
+	Run:   listWebhooks,
+}
+
+var createWebhookCmd = &cobra.Command{
+	Use:   "create [url]",
+	Short: "Create a webhook for plan events",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   createWebhook,
+}
+
+var updateWebhookCmd = &cobra.Command{
+	Use:   "update [url-or-index]",
+	Short: "Update a webhook",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   updateWebhook,
+}
+
+var deleteWebhookCmd = &cobra.Command{
+	Use:     "delete [url-or-index]",
+	Aliases: []string{"rm"},
+	Short:   "Delete a webhook",
+	Args:    cobra.MaximumNArgs(1),
+	Run:     deleteWebhook,
+}
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries [url-or-index]",
+	Short: "Show a webhook's recent deliveries",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   listWebhookDeliveries,
+}
+
+var testWebhookCmd = &cobra.Command{
+	Use:   "test [url-or-index]",
+	Short: "Send a test delivery to a webhook",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   testWebhook,
+}
+
+func init() {
+	RootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(createWebhookCmd)
+	webhooksCmd.AddCommand(updateWebhookCmd)
+	webhooksCmd.AddCommand(deleteWebhookCmd)
+	webhooksCmd.AddCommand(webhookDeliveriesCmd)
+	webhooksCmd.AddCommand(testWebhookCmd)
+
+	createWebhookCmd.Flags().StringSliceVarP(&webhookEvents, "events", "e", nil, "Events to send (comma-separated) -- defaults to all plan events")
+	createWebhookCmd.Flags().BoolVar(&webhookProject, "project", false, "Only send events for plans in the current project")
+	createWebhookCmd.Flags().StringVarP(&webhookDescription, "description", "d", "", "Webhook description")
+
+	updateWebhookCmd.Flags().StringVar(&webhookUrl, "url", "", "New url for the webhook")
+	updateWebhookCmd.Flags().StringSliceVarP(&webhookEvents, "events", "e", nil, "Replace the webhook's events (comma-separated)")
+	updateWebhookCmd.Flags().StringVarP(&webhookDescription, "description", "d", "", "New description for the webhook")
+	updateWebhookCmd.Flags().BoolVar(&webhookDisable, "disable", false, "Stop sending deliveries to the webhook")
+	updateWebhookCmd.Flags().BoolVar(&webhookEnable, "enable", false, "Resume sending deliveries to the webhook")
+
+	webhookDeliveriesCmd.Flags().IntVarP(&webhookDeliveriesLimit, "limit", "n", 20, "Max deliveries to show")
+}
+
+func listWebhooks(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MaybeResolveProject()
+
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhooks: %v", apiErr.Msg)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("🤷‍♂️ No webhooks")
+		fmt.Println()
+		term.PrintCmds("", "webhooks create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Url", "Events", "Scope", "Description", "Status"})
+
+	for i, webhook := range webhooks {
+		scope := "Org"
+		if webhook.ProjectId != nil {
+			if *webhook.ProjectId == lib.CurrentProjectId {
+				scope = "This project"
+			} else {
+				scope = "Other project"
+			}
+		}
+
+		events := make([]string, len(webhook.Events))
+		for j, e := range webhook.Events {
+			events[j] = string(e)
+		}
+
+		status := "Enabled"
+		if webhook.Disabled {
+			status = "Disabled"
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			webhook.Url,
+			strings.Join(events, ", "),
+			scope,
+			webhook.Description,
+			status,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "webhooks create", "webhooks test", "webhooks deliveries")
+}
+
+func createWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	var url string
+	if len(args) > 0 {
+		url = args[0]
+	} else {
+		var err error
+		url, err = term.GetRequiredUserStringInput("Webhook url:")
+		if err != nil {
+			term.OutputErrorAndExit("Error reading webhook url: %v", err)
+			return
+		}
+	}
+
+	req := shared.CreateWebhookRequest{
+		Url:         url,
+		Description: webhookDescription,
+		Events:      parseWebhookEvents(webhookEvents),
+	}
+
+	if len(req.Events) == 0 {
+		req.Events = shared.WebhookEvents
+	}
+
+	if webhookProject {
+		lib.MustResolveProject()
+		req.ProjectId = lib.CurrentProjectId
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateWebhook(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Created webhook for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(res.Webhook.Url))
+	fmt.Println()
+	fmt.Println("Signing secret:")
+	fmt.Println(res.Secret)
+	fmt.Println()
+	fmt.Println("This secret won't be shown again. Use it to verify the " + shared.WebhookSignatureHeader + " header on each delivery.")
+	fmt.Println()
+
+	term.PrintCmds("", "webhooks test", "webhooks")
+}
+
+func updateWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if webhookDisable && webhookEnable {
+		term.OutputErrorAndExit("--disable and --enable can't be used together")
+		return
+	}
+
+	webhook := mustSelectWebhook(args, "update")
+
+	var req shared.UpdateWebhookRequest
+
+	if cmd.Flags().Changed("url") {
+		req.Url = &webhookUrl
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = &webhookDescription
+	}
+	if cmd.Flags().Changed("events") {
+		req.Events = parseWebhookEvents(webhookEvents)
+	}
+	if webhookDisable || webhookEnable {
+		req.Disabled = &webhookDisable
+	}
+
+	if req.Url == nil && req.Description == nil && req.Events == nil && req.Disabled == nil {
+		term.OutputErrorAndExit("Nothing to update -- use --url, --events, --description, --disable, or --enable")
+		return
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdateWebhook(webhook.Id, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Updated webhook for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+}
+
+func deleteWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustSelectWebhook(args, "delete")
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Deleted webhook for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+}
+
+func listWebhookDeliveries(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustSelectWebhook(args, "show deliveries for")
+
+	term.StartSpinner("")
+	deliveries, apiErr := api.Client.ListWebhookDeliveries(webhook.Id, webhookDeliveriesLimit)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhook deliveries: %v", apiErr.Msg)
+		return
+	}
+
+	if len(deliveries) == 0 {
+		fmt.Println("🤷‍♂️ No deliveries yet")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"When", "Event", "Status", "Attempts", "Response", "Next Attempt"})
+
+	for _, delivery := range deliveries {
+		response := ""
+		if delivery.LastStatusCode != nil {
+			response = strconv.Itoa(*delivery.LastStatusCode)
+		}
+		if delivery.LastError != "" {
+			lastError := delivery.LastError
+			if len(lastError) > 60 {
+				lastError = lastError[:60] + "…"
+			}
+			response = strings.TrimSpace(response + " " + lastError)
+		}
+
+		nextAttempt := ""
+		if delivery.Status == shared.WebhookDeliveryStatusPending && delivery.NextAttemptAt != nil {
+			nextAttempt = format.Time(*delivery.NextAttemptAt)
+		}
+
+		table.Append([]string{
+			format.Time(delivery.CreatedAt),
+			string(delivery.Event),
+			webhookStatusLabel(delivery),
+			strconv.Itoa(delivery.Attempts),
+			response,
+			nextAttempt,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+}
+
+func testWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustSelectWebhook(args, "test")
+
+	term.StartSpinner("Sending test delivery...")
+	delivery, apiErr := api.Client.TestWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error testing webhook: %v", apiErr.Msg)
+		return
+	}
+
+	if delivery.Status == shared.WebhookDeliveryStatusSucceeded {
+		fmt.Printf("✅ Test delivery to %s succeeded\n", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+		return
+	}
+
+	term.OutputErrorAndExit("Test delivery to %s failed: %s", webhook.Url, delivery.LastError)
+}
+
+func mustSelectWebhook(args []string, verb string) *shared.Webhook {
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhooks: %v", apiErr.Msg)
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("🤷‍♂️ No webhooks")
+		os.Exit(0)
+	}
+
+	if len(args) == 1 {
+		input := args[0]
+		index, err := strconv.Atoi(input)
+		if err == nil && index > 0 && index <= len(webhooks) {
+			return webhooks[index-1]
+		}
+
+		for _, webhook := range webhooks {
+			if webhook.Url == input {
+				return webhook
+			}
+		}
+
+		term.OutputErrorAndExit("No webhook found for '%s'", input)
+	}
+
+	if len(webhooks) == 1 {
+		return webhooks[0]
+	}
+
+	opts := make([]string, len(webhooks))
+	for i, webhook := range webhooks {
+		opts[i] = fmt.Sprintf("%d. %s", i+1, webhook.Url)
+	}
+
+	selected, err := term.SelectFromList(fmt.Sprintf("Select a webhook to %s:", verb), opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting webhook: %v", err)
+	}
+
+	for i, opt := range opts {
+		if opt == selected {
+			return webhooks[i]
+		}
+	}
+
+	return nil
+}
+
+func parseWebhookEvents(events []string) []shared.WebhookEvent {
+	var res []shared.WebhookEvent
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		// allow leaving off the 'plan.' prefix
+		if !strings.Contains(e, ".") {
+			e = "plan." + e
+		}
+
+		event := shared.WebhookEvent(e)
+		if !shared.IsValidWebhookEvent(event) {
+			var valid []string
+			for _, v := range shared.WebhookEvents {
+				valid = append(valid, string(v))
+			}
+			term.OutputErrorAndExit("Invalid event '%s' -- valid events: %s", e, strings.Join(valid, ", "))
+		}
+
+		res = append(res, event)
+	}
+	return res
+}
+
+func webhookStatusLabel(delivery *shared.WebhookDelivery) string {
+	switch delivery.Status {
+	case shared.WebhookDeliveryStatusSucceeded:
+		return "✅ Succeeded"
+	case shared.WebhookDeliveryStatusFailed:
+		return "❌ Failed"
+	}
+	if delivery.Attempts == 0 {
+		return "⏳ Pending"
+	}
+	return "⏳ Retrying"
+}
@@ -0,0 +1,113 @@
+package fs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// GitAttributes holds the linguist attributes from a project's .gitattributes that say whether files are generated or vendored
+type GitAttributes struct {
+	dir   string
+	rules []gitAttributesRule
+}
+
+type gitAttributesRule struct {
+	matcher *ignore.GitIgnore
+	// nil when the rule doesn't set the attribute
+	generated *bool
+	vendored  *bool
+}
+
+// GetGitAttributes reads .gitattributes in dir. It returns nil if there isn't one.
+func GetGitAttributes(dir string) (*GitAttributes, error) {
+	f, err := os.Open(filepath.Join(dir, ".gitattributes"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading .gitattributes file: %s", err)
+	}
+	defer f.Close()
+
+	attrs := &GitAttributes{dir: dir}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var rule gitAttributesRule
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "linguist-generated", "linguist-generated=true":
+				rule.generated = boolPtr(true)
+			case "-linguist-generated", "linguist-generated=false":
+				rule.generated = boolPtr(false)
+			case "linguist-vendored", "linguist-vendored=true":
+				rule.vendored = boolPtr(true)
+			case "-linguist-vendored", "linguist-vendored=false":
+				rule.vendored = boolPtr(false)
+			}
+		}
+
+		if rule.generated == nil && rule.vendored == nil {
+			continue
+		}
+
+		rule.matcher = ignore.CompileIgnoreLines(fields[0])
+		attrs.rules = append(attrs.rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading .gitattributes file: %s", err)
+	}
+
+	return attrs, nil
+}
+
+// IsGenerated returns whether .gitattributes marks a path as generated or vendored. Like git, later lines override earlier ones. set is false when no line sets either attribute for the path, so other checks should decide.
+func (attrs *GitAttributes) IsGenerated(path string) (generated, set bool) {
+	if attrs == nil {
+		return false, false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, false
+	}
+	relPath, err := filepath.Rel(attrs.dir, absPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return false, false
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	var isGenerated, isVendored *bool
+	for _, rule := range attrs.rules {
+		if !rule.matcher.MatchesPath(relPath) {
+			continue
+		}
+		if rule.generated != nil {
+			isGenerated = rule.generated
+		}
+		if rule.vendored != nil {
+			isVendored = rule.vendored
+		}
+	}
+
+	if isGenerated == nil && isVendored == nil {
+		return false, false
+	}
+
+	return (isGenerated != nil && *isGenerated) || (isVendored != nil && *isVendored), true
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
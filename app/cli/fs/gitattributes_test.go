@@ -0,0 +1,83 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitAttributesIsGenerated(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(`# generated code
+*.pb.go linguist-generated
+api/*.json linguist-generated=true
+api/schema.json -linguist-generated
+
+vendor/** linguist-vendored
+vendor/internal/** -linguist-vendored
+third_party/** linguist-vendored=false
+
+docs/** linguist-documentation
+*.go text eol=lf
+assets/** linguist-vendored
+assets/** linguist-generated=false
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attrs, err := GetGitAttributes(dir)
+	if err != nil {
+		t.Fatalf("GetGitAttributes() error = %v", err)
+	}
+
+	tests := []struct {
+		path          string
+		wantGenerated bool
+		wantSet       bool
+	}{
+		{"user.pb.go", true, true},
+		{"api/v1/user.pb.go", true, true},
+		{"api/openapi.json", true, true},
+		// later lines win
+		{"api/schema.json", false, true},
+		{"vendor/github.com/pkg/errors/errors.go", true, true},
+		// -linguist-vendored overrides the vendored directory rule, so the path and content checks are skipped
+		{"vendor/internal/app.go", false, true},
+		{"third_party/zlib/zlib.h", false, true},
+		// vendored still counts when generated is explicitly off
+		{"assets/lib.js", true, true},
+
+		// attributes that aren't linguist-generated or linguist-vendored don't decide anything
+		{"docs/index.md", false, false},
+		{"main.go", false, false},
+		{"api/v1/user.go", false, false},
+	}
+
+	for _, tt := range tests {
+		generated, set := attrs.IsGenerated(filepath.Join(dir, tt.path))
+		if generated != tt.wantGenerated || set != tt.wantSet {
+			t.Errorf("IsGenerated(%s) = (%v, %v), want (%v, %v)", tt.path, generated, set, tt.wantGenerated, tt.wantSet)
+		}
+	}
+
+	if _, set := attrs.IsGenerated(filepath.Join(filepath.Dir(dir), "user.pb.go")); set {
+		t.Errorf("IsGenerated() matched a path outside the project")
+	}
+}
+
+func TestGetGitAttributesMissing(t *testing.T) {
+	attrs, err := GetGitAttributes(t.TempDir())
+	if err != nil {
+		t.Fatalf("GetGitAttributes() error = %v", err)
+	}
+	if attrs != nil {
+		t.Fatalf("GetGitAttributes() = %v, want nil", attrs)
+	}
+
+	// a nil GitAttributes leaves every path to the other checks
+	if generated, set := attrs.IsGenerated("user.pb.go"); generated || set {
+		t.Errorf("IsGenerated() on nil = (%v, %v), want (false, false)", generated, set)
+	}
+}
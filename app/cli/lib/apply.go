@@ -144,6 +144,7 @@ func MustApplyPlanAttempt(
 
 	var toRollback *types.ApplyRollbackPlan
 	var updatedFiles []string
+	var formattedFiles map[string]string
 
 	onErr := func(errMsg string, errArgs ...interface{}) {
 		term.StopSpinner()
@@ -191,7 +192,11 @@ func MustApplyPlanAttempt(
 			term.ResumeSpinner()
 		}
 
-		updatedFiles, toRollback, err = ApplyFiles(toApply, toRemove, paths)
+		// format before applying so the server stores the same bodies that are written to disk
+		var toWrite map[string]string
+		toWrite, formattedFiles = formatFilesToApply(toApply)
+
+		updatedFiles, toRollback, err = ApplyFiles(toWrite, toRemove, paths)
 
 		if err != nil {
 			onErr("failed to apply files: %s", err)
@@ -202,7 +207,7 @@ func MustApplyPlanAttempt(
 
 	onExecSuccess := func() {
 		term.StartSpinner("")
-		commitSummary, err := apiApplyPlan(planId, branch)
+		commitSummary, err := apiApplyPlan(planId, branch, formattedFiles)
 
 		if err != nil {
 			onErr("apply plan server error: %s", err)
@@ -530,7 +535,7 @@ func execApplyScript(
 	}
 }
 
-func apiApplyPlan(planId, branch string) (string, error) {
+func apiApplyPlan(planId, branch string, formattedFiles map[string]string) (string, error) {
 	log.Println("Getting API keys")
 
 	var apiKeys map[string]string
@@ -548,9 +553,10 @@ func apiApplyPlan(planId, branch string) (string, error) {
 	log.Println("Applying plan with API call")
 
 	commitSummary, apiErr := api.Client.ApplyPlan(planId, branch, shared.ApplyPlanRequest{
-		ApiKeys:     apiKeys,
-		OpenAIBase:  openAIBase,
-		OpenAIOrgId: os.Getenv("OPENAI_ORG_ID"),
+		ApiKeys:        apiKeys,
+		OpenAIBase:     openAIBase,
+		OpenAIOrgId:    os.Getenv("OPENAI_ORG_ID"),
+		FormattedFiles: formattedFiles,
 	})
 
 	if apiErr != nil {
@@ -606,6 +612,7 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 			// Compute destination path
 			dstPath := filepath.Join(fs.ProjectRoot, path)
 			content = strings.ReplaceAll(content, "\\`\\`\\`", "```")
+
 			// Check if the file exists
 			var exists bool
 			var mode os.FileMode
@@ -718,6 +725,42 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 	}, nil
 }
 
+// formatFilesToApply returns the files to write with formatters applied, along with the formatted bodies that differ from the built files so they can be sent to the server. Formatting is best-effort -- files that fail to format are applied as built.
+func formatFilesToApply(toApply map[string]string) (toWrite map[string]string, formattedFiles map[string]string) {
+	unescaped := make(map[string]string, len(toApply))
+	for path, content := range toApply {
+		if path == "_apply.sh" {
+			unescaped[path] = content
+			continue
+		}
+		unescaped[path] = strings.ReplaceAll(content, "\\`\\`\\`", "```")
+	}
+
+	toWrite, formatErrs := FormatFiles(unescaped)
+
+	if len(formatErrs) > 0 {
+		term.StopSpinner()
+		for _, formatErr := range formatErrs {
+			if formatErr.Path == FormatConfigFile {
+				fmt.Printf("⚠️  Skipping formatting: %v\n", formatErr.Err)
+			} else {
+				fmt.Printf("⚠️  Couldn't format %s, applying it unformatted: %v\n", formatErr.Path, formatErr.Err)
+			}
+		}
+		fmt.Println()
+		term.ResumeSpinner()
+	}
+
+	formattedFiles = map[string]string{}
+	for path, content := range toWrite {
+		if content != toApply[path] {
+			formattedFiles[path] = content
+		}
+	}
+
+	return toWrite, formattedFiles
+}
+
 func Rollback(rollbackPlan *types.ApplyRollbackPlan, msg bool) error {
 	errCh := make(chan error, len(rollbackPlan.ToRevert)+len(rollbackPlan.ToRemove))
 	for path, revert := range rollbackPlan.ToRevert {
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"plandex-cli/api"
@@ -124,6 +125,11 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	filesSkippedTooLarge := []filePathWithSize{}
 	filesSkippedAfterSizeLimit := []string{}
 
+	filesSkippedBinary := []string{}
+	// generated files are loaded as single-file maps unless --allow-generated is set -- files that can't be mapped are skipped
+	generatedFilesMapped := map[string]shared.GeneratedFileReason{}
+	generatedFilesSkipped := map[string]shared.GeneratedFileReason{}
+
 	var totalSize int64
 
 	numRoutines := 0
@@ -151,6 +157,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	mapInputShas := map[string]string{}
 	mapInputTokens := map[string]int{}
 	mapInputSizes := map[string]int64{}
+	mapInputGenerated := map[string]shared.GeneratedFileReason{}
 
 	toLoadMapPaths := []string{}
 	mapInputPathsForPaths := map[string]string{}
@@ -158,6 +165,9 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	currentMapInputBatch := shared.FileMapInputs{}
 	mapInputBatches := []shared.FileMapInputs{currentMapInputBatch}
 
+	currentGeneratedMapBatch := shared.FileMapInputs{}
+	generatedMapBatches := []shared.FileMapInputs{currentGeneratedMapBatch}
+
 	sem := make(chan struct{}, ContextMapMaxClientConcurrency)
 
 	if len(inputFilePaths) > 0 {
@@ -505,6 +515,9 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 							mapInputShas[path] = res.shaVal
 							mapInputTokens[path] = res.tokens
 							mapInputSizes[path] = res.size
+							if res.generatedReason != "" {
+								mapInputGenerated[path] = res.generatedReason
+							}
 
 							if len(res.mapFilesTruncatedTooLarge) > 0 {
 								mapFilesTruncatedTooLarge = append(mapFilesTruncatedTooLarge, res.mapFilesTruncatedTooLarge...)
@@ -539,15 +552,77 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 								return
 							}
 
+							if shared.IsBinaryContent(fileContent) {
+								contextMu.Lock()
+								defer contextMu.Unlock()
+								filesSkippedBinary = append(filesSkippedBinary, path)
+								totalSize -= size
+								errCh <- nil
+								return
+							}
+
+							generatedReason := getGeneratedReason(path, string(fileContent))
+
+							if generatedReason != "" && !params.AllowGenerated {
+								if !shared.HasFileMapSupport(path) {
+									contextMu.Lock()
+									defer contextMu.Unlock()
+									generatedFilesSkipped[path] = generatedReason
+									totalSize -= size
+									errCh <- nil
+									return
+								}
+
+								composite := strings.Join([]string{string(shared.ContextMapType), path}, "|")
+
+								contextMu.Lock()
+								totalSize -= size
+								if existsByComposite[composite] != nil {
+									alreadyLoadedByComposite[composite] = existsByComposite[composite]
+									contextMu.Unlock()
+									errCh <- nil
+									return
+								}
+								contextMu.Unlock()
+
+								res, err := getMapFileDetails(path, size, 0)
+								if err != nil {
+									errCh <- fmt.Errorf("failed to get map file details for %s: %v", path, err)
+									return
+								}
+
+								contextMu.Lock()
+								defer contextMu.Unlock()
+
+								if currentGeneratedMapBatch.NumFiles()+1 > shared.ContextMapMaxBatchSize || currentGeneratedMapBatch.TotalSize()+res.size > shared.ContextMapMaxBatchBytes {
+									currentGeneratedMapBatch = shared.FileMapInputs{}
+									generatedMapBatches = append(generatedMapBatches, currentGeneratedMapBatch)
+								}
+
+								currentGeneratedMapBatch[path] = res.mapContent
+								mapInputShas[path] = res.shaVal
+								mapInputTokens[path] = res.tokens
+								mapInputSizes[path] = res.size
+								generatedFilesMapped[path] = generatedReason
+
+								if len(res.mapFilesTruncatedTooLarge) > 0 {
+									mapFilesTruncatedTooLarge = append(mapFilesTruncatedTooLarge, res.mapFilesTruncatedTooLarge...)
+								}
+
+								errCh <- nil
+								return
+							}
+
 							contextMu.Lock()
 							defer contextMu.Unlock()
 
 							loadContextReq = append(loadContextReq, &shared.LoadContextParams{
-								ContextType: shared.ContextFileType,
-								Name:        path,
-								Body:        string(fileContent),
-								FilePath:    path,
-								AutoLoaded:  params.AutoLoaded,
+								ContextType:     shared.ContextFileType,
+								Name:            path,
+								Body:            string(fileContent),
+								FilePath:        path,
+								AutoLoaded:      params.AutoLoaded,
+								GeneratedReason: generatedReason,
 							})
 						}
 
@@ -622,11 +697,20 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	}
 
 	if params.DefsOnly {
-		allMapBodies, err := processMapBatches(mapInputBatches)
+		allMapBodies, allMapDeps, err := processMapBatches(mapInputBatches)
 		if err != nil {
 			onErr(fmt.Errorf("failed to process map batches: %v", err))
 		}
 
+		// change times help rank files when a map is too large for its token budget -- they're optional, so errors are just logged
+		var changedAt map[string]int64
+		if fs.ProjectRootIsGitRepo() {
+			changedAt, err = GitFileChangeTimes()
+			if err != nil {
+				log.Printf("failed to get git file change times: %v", err)
+			}
+		}
+
 		for _, inputPath := range toLoadMapPaths {
 			var name string
 			if inputPath == "." {
@@ -641,6 +725,9 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			pathShas := map[string]string{}
 			pathTokens := map[string]int{}
 			pathSizes := map[string]int64{}
+			pathDeps := shared.FileMapDepsByPath{}
+			pathChangedAt := map[string]int64{}
+			pathGenerated := map[string]shared.GeneratedFileReason{}
 			for path, body := range allMapBodies {
 				mapInputPath := mapInputPathsForPaths[path]
 				if mapInputPath == inputPath {
@@ -648,25 +735,64 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					pathShas[path] = mapInputShas[path]
 					pathTokens[path] = mapInputTokens[path]
 					pathSizes[path] = mapInputSizes[path]
+					if deps := allMapDeps[path]; deps != nil {
+						pathDeps[path] = deps
+					}
+					if t, ok := changedAt[path]; ok {
+						pathChangedAt[path] = t
+					}
+					if reason, ok := mapInputGenerated[path]; ok {
+						pathGenerated[path] = reason
+					}
 				}
 			}
 
 			// load the map even if it's empty (no paths)
 			// it needs to exist so it can be updated later
 			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
-				ContextType: shared.ContextMapType,
-				Name:        name,
-				MapBodies:   pathBodies,
-				InputShas:   pathShas,
-				InputTokens: pathTokens,
-				InputSizes:  pathSizes,
-				FilePath:    inputPath,
-				AutoLoaded:  params.AutoLoaded,
+				ContextType:    shared.ContextMapType,
+				Name:           name,
+				MapBodies:      pathBodies,
+				MapDeps:        pathDeps,
+				InputShas:      pathShas,
+				InputTokens:    pathTokens,
+				InputSizes:     pathSizes,
+				InputChangedAt: pathChangedAt,
+				InputGenerated: pathGenerated,
+				FilePath:       inputPath,
+				AutoLoaded:     params.AutoLoaded,
 			})
 
 		}
 	}
 
+	if len(generatedFilesMapped) > 0 {
+		allMapBodies, allMapDeps, err := processMapBatches(generatedMapBatches)
+		if err != nil {
+			onErr(fmt.Errorf("failed to process map batches: %v", err))
+		}
+
+		for path, reason := range generatedFilesMapped {
+			mapDeps := shared.FileMapDepsByPath{}
+			if deps := allMapDeps[path]; deps != nil {
+				mapDeps[path] = deps
+			}
+
+			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+				ContextType:    shared.ContextMapType,
+				Name:           path,
+				MapBodies:      shared.FileMapBodies{path: allMapBodies[path]},
+				MapDeps:        mapDeps,
+				InputShas:      map[string]string{path: mapInputShas[path]},
+				InputTokens:    map[string]int{path: mapInputTokens[path]},
+				InputSizes:     map[string]int64{path: mapInputSizes[path]},
+				InputGenerated: map[string]shared.GeneratedFileReason{path: reason},
+				FilePath:       path,
+				AutoLoaded:     params.AutoLoaded,
+			})
+		}
+	}
+
 	filesToLoad := map[string]string{}
 	for _, context := range loadContextReq {
 		if context.ContextType == shared.ContextFileType {
@@ -693,6 +819,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			printIgnoredMsg()
 			didOutputReason = true
 		}
+		if len(generatedFilesSkipped) > 0 || len(filesSkippedBinary) > 0 {
+			printGeneratedFilesMsg(generatedFilesMapped, generatedFilesSkipped, filesSkippedBinary)
+			didOutputReason = true
+		}
 
 		if !didOutputReason {
 			fmt.Println()
@@ -754,6 +884,10 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		printIgnoredMsg()
 	}
 
+	if len(generatedFilesMapped) > 0 || len(generatedFilesSkipped) > 0 || len(filesSkippedBinary) > 0 {
+		printGeneratedFilesMsg(generatedFilesMapped, generatedFilesSkipped, filesSkippedBinary)
+	}
+
 	if len(filesSkippedTooLarge) > 0 || len(filesSkippedAfterSizeLimit) > 0 ||
 		len(mapFilesTruncatedTooLarge) > 0 || len(mapFilesSkippedAfterSizeLimit) > 0 {
 		printSkippedFilesMsg(filesSkippedTooLarge, filesSkippedAfterSizeLimit,
@@ -29,6 +29,7 @@ type mapFileDetails struct {
 	mapFilesSkippedAfterSizeLimit []string
 	mapFilesTruncatedTooLarge     []filePathWithSize
 	mapContent                    string
+	generatedReason               shared.GeneratedFileReason
 }
 
 func getMapFileDetails(path string, size, mapSize int64) (mapFileDetails, error) {
@@ -97,6 +98,11 @@ func getMapFileDetails(path string, size, mapSize int64) (mapFileDetails, error)
 		}
 	}
 
+	if !isImage {
+		// only the mapped part of the file is checked, which is enough for headers and long lines
+		res.generatedReason = getGeneratedReason(path, res.mapContent)
+	}
+
 	return res, nil
 }
 
@@ -135,8 +141,9 @@ func getMapFileContent(path string) (mapFileContent, error) {
 	return mapFileContent{mapData: bytes, content: string(bytes), shaVal: shaVal, truncated: truncated}, nil
 }
 
-func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBodies, error) {
+func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBodies, shared.FileMapDepsByPath, error) {
 	allMapBodies := shared.FileMapBodies{}
+	allMapDeps := shared.FileMapDepsByPath{}
 
 	var mapMu sync.Mutex
 	errCh := make(chan error, len(mapInputBatches))
@@ -150,6 +157,7 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 		go func(batch shared.FileMapInputs) {
 			mapRes, apiErr := api.Client.GetFileMap(shared.GetFileMapRequest{
 				MapInputs: batch,
+				ProjectId: CurrentProjectId,
 			})
 			if apiErr != nil {
 				errCh <- fmt.Errorf("failed to get file map: %v", apiErr)
@@ -159,6 +167,9 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 			for path, bodies := range mapRes.MapBodies {
 				allMapBodies[path] = bodies
 			}
+			for path, deps := range mapRes.MapDeps {
+				allMapDeps[path] = deps
+			}
 			mapMu.Unlock()
 			errCh <- nil
 		}(batch)
@@ -167,11 +178,11 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 	for i := 0; i < len(mapInputBatches); i++ {
 		err := <-errCh
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return allMapBodies, nil
+	return allMapBodies, allMapDeps, nil
 }
 
 func readImageTokensForDefsOnly(path string, size int64, detail openai.ImageURLDetail, headerBytes int64) (int, error) {
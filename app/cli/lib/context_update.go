@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"os"
 	"plandex-cli/api"
 	"plandex-cli/fs"
@@ -297,6 +298,7 @@ type mapState struct {
 	mapInputShas         map[string]string
 	mapInputTokens       map[string]int
 	mapInputSizes        map[string]int64
+	mapInputGenerated    map[string]shared.GeneratedFileReason
 	totalMapSize         int64
 	currentMapInputBatch shared.FileMapInputs
 	mapInputBatches      []shared.FileMapInputs
@@ -631,6 +633,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					mapInputShas:         map[string]string{},
 					mapInputTokens:       map[string]int{},
 					mapInputSizes:        map[string]int64{},
+					mapInputGenerated:    map[string]shared.GeneratedFileReason{},
 					totalMapSize:         0,
 					currentMapInputBatch: currentMapInputBatch,
 					mapInputBatches:      []shared.FileMapInputs{currentMapInputBatch},
@@ -780,6 +783,9 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 						state.mapInputTokens[path] = res.tokens
 						state.currentMapInputBatch[path] = res.mapContent
 						state.mapInputSizes[path] = res.size
+						if res.generatedReason != "" {
+							state.mapInputGenerated[path] = res.generatedReason
+						}
 
 						if len(res.mapFilesTruncatedTooLarge) > 0 {
 							for _, file := range res.mapFilesTruncatedTooLarge {
@@ -824,16 +830,33 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					numMaps++
 
 					reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
-						updatedMapBodies, err := processMapBatches(state.mapInputBatches)
+						updatedMapBodies, updatedMapDeps, err := processMapBatches(state.mapInputBatches)
 						if err != nil {
 							return nil, fmt.Errorf("failed to process map batches: %v", err)
 						}
 
+						// optional, like when loading the map
+						inputChangedAt := map[string]int64{}
+						if fs.ProjectRootIsGitRepo() && len(state.mapInputShas) > 0 {
+							changedAt, err := GitFileChangeTimes()
+							if err != nil {
+								log.Printf("failed to get git file change times: %v", err)
+							}
+							for path := range state.mapInputShas {
+								if t, ok := changedAt[path]; ok {
+									inputChangedAt[path] = t
+								}
+							}
+						}
+
 						return &shared.UpdateContextParams{
 							MapBodies:       updatedMapBodies,
+							MapDeps:         updatedMapDeps,
 							InputShas:       state.mapInputShas,
 							InputTokens:     state.mapInputTokens,
 							InputSizes:      state.mapInputSizes,
+							InputChangedAt:  inputChangedAt,
+							InputGenerated:  state.mapInputGenerated,
 							RemovedMapPaths: state.removedMapPaths,
 						}, nil
 					}
@@ -0,0 +1,323 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// project file that lists formatters to run on built files before they're applied
+const FormatConfigFile = ".plandex-format.yml"
+
+const formatTimeout = 30 * time.Second
+
+type formatConfig struct {
+	Formatters []formatterConfig `yaml:"formatters"`
+}
+
+type formatterConfig struct {
+	// one of formatterPresets -- sets defaults for Files and Command
+	Preset string `yaml:"preset"`
+	// glob patterns matched against the file name, or the path relative to the project root if the pattern includes a '/'
+	Files []string `yaml:"files"`
+	// reads the file from stdin and writes the formatted file to stdout -- {file} and {dir} are replaced with the file's path and directory relative to the project root
+	Command string `yaml:"command"`
+}
+
+var formatterPresets = map[string]formatterConfig{
+	"gofmt": {
+		Files:   []string{"*.go"},
+		Command: "gofmt",
+	},
+	"goimports": {
+		Files:   []string{"*.go"},
+		Command: "goimports -srcdir {dir}",
+	},
+	"prettier": {
+		Files:   []string{"*.js", "*.jsx", "*.mjs", "*.cjs", "*.ts", "*.tsx", "*.json", "*.css", "*.scss", "*.less", "*.html", "*.vue", "*.md", "*.yaml", "*.yml", "*.graphql"},
+		Command: "prettier --stdin-filepath {file}",
+	},
+	"black": {
+		Files:   []string{"*.py", "*.pyi"},
+		Command: "black -q --stdin-filename {file} -",
+	},
+	"rustfmt": {
+		Files:   []string{"*.rs"},
+		Command: "rustfmt --edition 2021",
+	},
+	"clang-format": {
+		Files:   []string{"*.c", "*.h", "*.cc", "*.cpp", "*.cxx", "*.hpp", "*.hh", "*.m", "*.mm", "*.proto"},
+		Command: "clang-format --assume-filename={file}",
+	},
+}
+
+var (
+	formatConfigOnce   sync.Once
+	loadedFormatConfig *formatConfig
+	formatConfigErr    error
+)
+
+func getFormatConfig() (*formatConfig, error) {
+	formatConfigOnce.Do(func() {
+		if fs.ProjectRoot == "" {
+			return
+		}
+
+		bytes, err := os.ReadFile(filepath.Join(fs.ProjectRoot, FormatConfigFile))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				formatConfigErr = fmt.Errorf("error reading %s: %v", FormatConfigFile, err)
+			}
+			return
+		}
+
+		var config formatConfig
+		err = yaml.Unmarshal(bytes, &config)
+		if err != nil {
+			formatConfigErr = fmt.Errorf("error parsing %s: %v", FormatConfigFile, err)
+			return
+		}
+
+		for i, formatter := range config.Formatters {
+			if formatter.Preset != "" {
+				preset, ok := formatterPresets[formatter.Preset]
+				if !ok {
+					formatConfigErr = fmt.Errorf("unknown formatter preset '%s' in %s", formatter.Preset, FormatConfigFile)
+					return
+				}
+				if len(formatter.Files) == 0 {
+					formatter.Files = preset.Files
+				}
+				if formatter.Command == "" {
+					formatter.Command = preset.Command
+				}
+			}
+			if formatter.Command == "" || len(formatter.Files) == 0 {
+				formatConfigErr = fmt.Errorf("formatter %d in %s needs a preset, or files and a command", i+1, FormatConfigFile)
+				return
+			}
+			config.Formatters[i] = formatter
+		}
+
+		loadedFormatConfig = &config
+	})
+
+	return loadedFormatConfig, formatConfigErr
+}
+
+// HasFormatters returns true if the project configures any formatters
+func HasFormatters() bool {
+	config, err := getFormatConfig()
+	return err == nil && config != nil && len(config.Formatters) > 0
+}
+
+func (f formatterConfig) matches(path string) bool {
+	return matchesFilePatterns(f.Files, path)
+}
+
+// matchesFilePatterns matches glob patterns against the file name, or the path relative to the project root if the pattern includes a '/'
+func matchesFilePatterns(patterns []string, path string) bool {
+	slashPath := filepath.ToSlash(path)
+	base := filepath.Base(path)
+	for _, pattern := range patterns {
+		target := base
+		if strings.Contains(pattern, "/") {
+			target = slashPath
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatFile runs the first formatter configured for path on content and returns the formatted content. If no formatter matches, content is returned unchanged with an empty formatter name. On error, content is also returned unchanged, so that callers can always fall back to it.
+func FormatFile(path, content string) (string, string, error) {
+	config, err := getFormatConfig()
+	if err != nil {
+		return content, "", err
+	}
+	if config == nil {
+		return content, "", nil
+	}
+
+	for _, formatter := range config.Formatters {
+		if !formatter.matches(path) {
+			continue
+		}
+
+		formatted, err := runFormatter(formatter, path, content)
+		if err != nil {
+			return content, formatter.Command, err
+		}
+		return formatted, formatter.Command, nil
+	}
+
+	return content, "", nil
+}
+
+func runFormatter(formatter formatterConfig, path, content string) (string, error) {
+	dir := filepath.Dir(path)
+
+	args := strings.Fields(formatter.Command)
+	for i, arg := range args {
+		arg = strings.ReplaceAll(arg, "{file}", path)
+		arg = strings.ReplaceAll(arg, "{dir}", dir)
+		args[i] = arg
+	}
+
+	// prefer a project-local install, e.g. prettier from node_modules
+	bin := args[0]
+	localBin := filepath.Join(fs.ProjectRoot, "node_modules", ".bin", bin)
+	if _, err := os.Stat(localBin); err == nil && !strings.ContainsRune(bin, filepath.Separator) {
+		bin = localBin
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), formatTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, bin, args[1:]...)
+	cmd.Dir = fs.ProjectRoot
+	cmd.Stdin = strings.NewReader(content)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s timed out", args[0])
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("%s failed: %v", args[0], err)
+		}
+		return "", fmt.Errorf("%s failed: %v: %s", args[0], err, msg)
+	}
+
+	formatted := stdout.String()
+	if strings.TrimSpace(formatted) == "" && strings.TrimSpace(content) != "" {
+		// a formatter that edits in place or prints nothing would otherwise empty the file
+		return "", fmt.Errorf("%s produced no output", args[0])
+	}
+
+	return formatted, nil
+}
+
+type FormatFileError struct {
+	Path string
+	Err  error
+}
+
+// max formatter processes running at once
+const maxParallelFormatters = 8
+
+// FormatFiles returns a copy of files with each file formatted. Files that fail to format keep their content and are returned with their errors, so formatting never blocks applying changes.
+func FormatFiles(files map[string]string) (map[string]string, []FormatFileError) {
+	res := make(map[string]string, len(files))
+	for path, content := range files {
+		res[path] = content
+	}
+
+	if _, err := getFormatConfig(); err != nil {
+		return res, []FormatFileError{{Path: FormatConfigFile, Err: err}}
+	}
+	if !HasFormatters() {
+		return res, nil
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []FormatFileError
+	sem := make(chan struct{}, maxParallelFormatters)
+
+	for path, content := range files {
+		if path == "_apply.sh" {
+			continue
+		}
+		wg.Add(1)
+		go func(path, content string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			formatted, formatter, err := FormatFile(path, content)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Error formatting %s with %s: %v", path, formatter, err)
+				errs = append(errs, FormatFileError{Path: path, Err: err})
+				return
+			}
+			res[path] = formatted
+		}(path, content)
+	}
+
+	wg.Wait()
+
+	return res, errs
+}
+
+// FormattingDiffs returns a git diff of the changes that formatters will make to files when they're applied, so they can be reviewed separately from the plan's own changes
+func FormattingDiffs(files map[string]string, withColor bool) (string, []FormatFileError, error) {
+	formatted, formatErrs := FormatFiles(files)
+
+	tempDir, err := os.MkdirTemp("", "plandex-format-diff-")
+	if err != nil {
+		return "", formatErrs, fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	numChanged := 0
+	for path, content := range files {
+		if formatted[path] == content {
+			continue
+		}
+		numChanged++
+
+		for side, sideContent := range map[string]string{"a": content, "b": formatted[path]} {
+			dst := filepath.Join(tempDir, side, path)
+			err := os.MkdirAll(filepath.Dir(dst), 0755)
+			if err != nil {
+				return "", formatErrs, fmt.Errorf("error creating directory: %v", err)
+			}
+			err = os.WriteFile(dst, []byte(sideContent), 0644)
+			if err != nil {
+				return "", formatErrs, fmt.Errorf("error writing file: %v", err)
+			}
+		}
+	}
+
+	if numChanged == 0 {
+		return "", formatErrs, nil
+	}
+
+	colorArg := "--no-color"
+	if withColor {
+		colorArg = "--color=always"
+	}
+
+	// with --no-prefix, the 'a' and 'b' dirs stand in for git's usual path prefixes
+	cmd := exec.Command("git", "-C", tempDir, "diff", "--no-index", "--no-prefix", colorArg, "a", "b")
+	res, err := cmd.CombinedOutput()
+	if err != nil {
+		// exit status 1 means there are differences
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return "", formatErrs, fmt.Errorf("error getting diffs: %v: %s", err, res)
+		}
+	}
+
+	return string(res), formatErrs, nil
+}
@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"fmt"
+	"log"
+	"plandex-cli/fs"
+	"sort"
+	"sync"
+
+	"github.com/fatih/color"
+
+	shared "plandex-shared"
+)
+
+var (
+	gitAttributes     *fs.GitAttributes
+	gitAttributesOnce sync.Once
+)
+
+// getGeneratedReason checks .gitattributes first, since linguist attributes can also mark a file as not generated, then the file's path and content. content can be partial or empty.
+func getGeneratedReason(path, content string) shared.GeneratedFileReason {
+	gitAttributesOnce.Do(func() {
+		var err error
+		gitAttributes, err = fs.GetGitAttributes(fs.ProjectRoot)
+		if err != nil {
+			// optional, like git change times -- fall back to the path and content checks
+			log.Printf("failed to read .gitattributes: %v", err)
+		}
+	})
+
+	if generated, set := gitAttributes.IsGenerated(path); set {
+		if generated {
+			return shared.GeneratedReasonAttributes
+		}
+		return ""
+	}
+
+	return shared.GetGeneratedReason(path, content)
+}
+
+func printGeneratedFilesMsg(mapped, skipped map[string]shared.GeneratedFileReason, skippedBinary []string) {
+	printList := func(files map[string]shared.GeneratedFileReason) {
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for i, path := range paths {
+			if i >= maxSkippedFileList {
+				fmt.Printf("  • and %d more\n", len(paths)-maxSkippedFileList)
+				break
+			}
+			fmt.Printf("  • %s - %s\n", path, shared.GeneratedReasonDescriptions[files[path]])
+		}
+	}
+
+	if len(mapped) > 0 {
+		fmt.Println()
+		fmt.Println("ℹ️  These generated files were loaded as maps:")
+		printList(mapped)
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println()
+		fmt.Println("ℹ️  These generated files were skipped because they can't be mapped:")
+		printList(skipped)
+	}
+
+	if len(mapped) > 0 || len(skipped) > 0 {
+		fmt.Println(color.New(color.FgWhite).Sprint("Use --allow-generated to load generated files in full."))
+	}
+
+	if len(skippedBinary) > 0 {
+		sort.Strings(skippedBinary)
+		fmt.Println()
+		fmt.Println("ℹ️  These files were skipped because they're binary:")
+		for i, path := range skippedBinary {
+			if i >= maxSkippedFileList {
+				fmt.Printf("  • and %d more\n", len(skippedBinary)-maxSkippedFileList)
+				break
+			}
+			fmt.Printf("  • %s\n", path)
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -214,6 +216,54 @@ func GitCheckoutFile(path string) error {
 	return nil
 }
 
+// how far back GitFileChangeTimes looks -- files that haven't changed more recently than this are treated as equally old
+const (
+	gitChangeTimesMaxCommits = 5000
+	gitChangeTimesSince      = "1 year ago"
+)
+
+// GitFileChangeTimes returns the unix time of the latest commit touching each file under the current directory, keyed by path relative to the current directory. Only recent history is scanned, so files that haven't changed in a while are left out.
+func GitFileChangeTimes() (map[string]int64, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "log",
+		fmt.Sprintf("--max-count=%d", gitChangeTimesMaxCommits),
+		"--since="+gitChangeTimesSince,
+		"--format=@%ct",
+		"--name-only",
+		"--relative",
+		"--", ".",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting git file change times: %v", err)
+	}
+
+	changedAt := map[string]int64{}
+	var commitTime int64
+	for _, line := range strings.Split(string(res), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			commitTime, err = strconv.ParseInt(strings.TrimPrefix(line, "@"), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing git commit time %s: %v", line, err)
+			}
+			continue
+		}
+
+		// log is newest first, so the first time a path appears is its latest change
+		path := filepath.FromSlash(line)
+		if _, ok := changedAt[path]; !ok {
+			changedAt[path] = commitTime
+		}
+	}
+
+	return changedAt, nil
+}
+
 const GitLogTimestampFormat = "Mon Jan 2, 2006 | 3:04:05pm"
 
 var GitLogTimestampRegex = regexp.MustCompile(`\w{3} \w{3} \d{1,2}, \d{4} \| \d{1,2}:\d{2}:\d{2}(am|pm) UTC`)
@@ -0,0 +1,180 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// MustLoadTemplateContext loads a rendered plan template's context into the current plan with MustLoadContext
+func MustLoadTemplateContext(templateContext shared.PlanTemplateContext) {
+	sessionId := os.Getenv("PLANDEX_REPL_SESSION_ID")
+
+	if len(templateContext.Files) > 0 {
+		var paths []string
+		for _, pattern := range templateContext.Files {
+			matches, err := expandContextGlob(pattern)
+			if err != nil {
+				term.OutputErrorAndExit("Error expanding '%s': %v", pattern, err)
+			}
+			if len(matches) == 0 {
+				term.OutputErrorAndExit("No files match '%s'", pattern)
+			}
+			paths = append(paths, matches...)
+		}
+
+		MustLoadContext(paths, &types.LoadContextParams{
+			Recursive:         true,
+			SkipIgnoreWarning: true,
+			SessionId:         sessionId,
+		})
+	}
+
+	// maps are loaded one directory at a time
+	for _, dir := range templateContext.Maps {
+		MustLoadContext([]string{dir}, &types.LoadContextParams{
+			DefsOnly:          true,
+			SkipIgnoreWarning: true,
+			SessionId:         sessionId,
+		})
+	}
+
+	if len(templateContext.Trees) > 0 {
+		MustLoadContext(templateContext.Trees, &types.LoadContextParams{
+			NamesOnly:         true,
+			SkipIgnoreWarning: true,
+			SessionId:         sessionId,
+		})
+	}
+
+	for _, note := range templateContext.Notes {
+		MustLoadContext(nil, &types.LoadContextParams{
+			Note:      note,
+			SessionId: sessionId,
+		})
+	}
+}
+
+// expandContextGlob returns the paths matching a glob, or the path itself if it isn't a glob. Unlike filepath.Glob, '**' matches any number of directories. Like context loading, '**' doesn't walk into skipped directories (node_modules, .git, etc.) or paths in .plandexignore.
+func expandContextGlob(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	// walk from the longest prefix without glob characters
+	var base []string
+	for _, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			break
+		}
+		base = append(base, segment)
+	}
+
+	root := strings.Join(base, "/")
+	if root == "" {
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		} else {
+			root = "."
+		}
+	}
+	rest := segments[len(base):]
+
+	var ignored *ignore.GitIgnore
+	if fs.ProjectRoot != "" {
+		var err error
+		ignored, err = fs.GetPlandexIgnore(fs.ProjectRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	isIgnored := func(path string) bool {
+		if ignored == nil {
+			return false
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return false
+		}
+		relPath, err := filepath.Rel(fs.ProjectRoot, absPath)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			return false
+		}
+		return ignored.MatchesPath(relPath)
+	}
+
+	var res []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || fs.ShouldSkipDir(d.Name()) || isIgnored(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isIgnored(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if matchGlobSegments(rest, strings.Split(filepath.ToSlash(rel), "/")) {
+			res = append(res, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func matchGlobSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchGlobSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	matched, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !matched {
+		return false
+	}
+
+	return matchGlobSegments(pattern[1:], path[1:])
+}
@@ -35,13 +35,14 @@ type ReplSettings struct {
 }
 
 var ReplCmdAliases = map[string]string{
-	"chat":  "ch",
-	"tell":  "t",
-	"multi": "m",
-	"quit":  "q",
-	"help":  "h",
-	"run":   "r",
-	"send":  "s",
+	"chat":    "ch",
+	"tell":    "t",
+	"multi":   "m",
+	"quit":    "q",
+	"help":    "h",
+	"run":     "r",
+	"send":    "s",
+	"preview": "pv",
 }
 
 func init() {
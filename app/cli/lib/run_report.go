@@ -0,0 +1,156 @@
+package lib
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/types"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteRunReport writes the report for 'plandex run' to path, creating parent directories as needed
+func WriteRunReport(report *types.RunReport, format types.RunReportFormat, path string) error {
+	var content []byte
+	var err error
+
+	switch format {
+	case types.RunReportFormatJunit:
+		content, err = xml.MarshalIndent(runReportToJunit(report), "", "  ")
+		if err == nil {
+			content = append([]byte(xml.Header), content...)
+		}
+	default:
+		content, err = json.MarshalIndent(report, "", "  ")
+	}
+
+	if err != nil {
+		return fmt.Errorf("error marshalling run report: %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	if dir != "." {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return fmt.Errorf("error creating report directory: %v", err)
+		}
+	}
+
+	err = os.WriteFile(path, append(content, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing run report: %v", err)
+	}
+
+	return nil
+}
+
+func runReportToJunit(report *types.RunReport) junitTestSuites {
+	suite := junitTestSuite{
+		Name:      report.Name,
+		Time:      junitSeconds(report.FinishedAt.Sub(report.StartedAt)),
+		Timestamp: report.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+	}
+
+	addCase := func(c junitTestCase) {
+		suite.Tests++
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		if c.Error != nil {
+			suite.Errors++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	for i, prompt := range report.Prompts {
+		c := junitTestCase{
+			Name:      fmt.Sprintf("prompt %d", i+1),
+			Classname: report.Name + ".prompts",
+			Time:      junitSeconds(prompt.Duration),
+			SystemOut: prompt.Prompt,
+		}
+		if prompt.Status != types.RunStatusPassed {
+			c.Error = &junitProblem{Message: prompt.Error}
+		}
+		addCase(c)
+	}
+
+	if report.Apply != nil {
+		c := junitTestCase{
+			Name:      "apply",
+			Classname: report.Name + ".apply",
+			Time:      "0",
+		}
+		switch report.Apply.Status {
+		case types.RunStatusFailed:
+			c.Failure = &junitProblem{Message: report.Apply.Error, Body: report.Apply.ExecOutput}
+		case types.RunStatusError:
+			c.Error = &junitProblem{Message: report.Apply.Error}
+		}
+		addCase(c)
+	}
+
+	for _, check := range report.Checks {
+		c := junitTestCase{
+			Name:      check.Name,
+			Classname: report.Name + ".checks",
+			Time:      junitSeconds(check.Duration),
+		}
+		if check.Status != types.RunStatusPassed {
+			c.Failure = &junitProblem{
+				Message: fmt.Sprintf("'%s' exited with status %d", check.Run, check.ExitCode),
+				Body:    check.Output,
+			}
+		} else {
+			c.SystemOut = check.Output
+		}
+		addCase(c)
+	}
+
+	// errors that happen outside any step (plan creation, context loading, etc.) get a case of their own so they aren't lost
+	if report.Status == types.RunStatusError && suite.Errors == 0 {
+		addCase(junitTestCase{
+			Name:      "run",
+			Classname: report.Name,
+			Time:      "0",
+			Error:     &junitProblem{Message: strings.TrimSpace(report.Error)},
+		})
+	}
+
+	return junitTestSuites{Suites: []junitTestSuite{suite}}
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,191 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultRunCheckTimeout = 10 * time.Minute
+
+// keep the tail of check output in reports -- enough to see what failed without bloating CI artifacts
+const maxRunCheckOutput = 16 * 1024
+
+func LoadRunSpec(path string) (*types.RunSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading run spec: %v", err)
+	}
+
+	var spec types.RunSpec
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	err = dec.Decode(&spec)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing run spec: %v", err)
+	}
+
+	if spec.Name == "" {
+		spec.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if spec.Policy.MissingFile == "" {
+		spec.Policy.MissingFile = types.RunMissingFileLoad
+	}
+	if spec.Policy.OutdatedContext == "" {
+		spec.Policy.OutdatedContext = types.RunOutdatedContextUpdate
+	}
+	if spec.Policy.ExecFail == "" {
+		spec.Policy.ExecFail = types.RunExecFailRollback
+	}
+	if spec.Report.Format == "" {
+		spec.Report.Format = types.RunReportFormatJson
+	}
+
+	err = validateRunSpec(&spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+func validateRunSpec(spec *types.RunSpec) error {
+	if len(spec.Prompts) == 0 && spec.Template == "" {
+		return fmt.Errorf("run spec needs at least one prompt or a template")
+	}
+
+	for i, prompt := range spec.Prompts {
+		if strings.TrimSpace(prompt) == "" {
+			return fmt.Errorf("prompt %d is empty", i+1)
+		}
+	}
+
+	if len(spec.Vars) > 0 && spec.Template == "" {
+		return fmt.Errorf("vars can only be used with a template")
+	}
+
+	if !shared.IsValidPlanTemplateAutoMode(spec.Config.AutoMode) {
+		return fmt.Errorf("invalid auto-mode '%s'", spec.Config.AutoMode)
+	}
+
+	if !shared.IsValidPlanTemplateModelPack(spec.Config.ModelPack) {
+		return fmt.Errorf("invalid model-pack '%s'", spec.Config.ModelPack)
+	}
+
+	if spec.Config.Debug < 0 {
+		return fmt.Errorf("debug must be 0 or more")
+	}
+
+	switch spec.Policy.MissingFile {
+	case types.RunMissingFileLoad, types.RunMissingFileSkip, types.RunMissingFileOverwrite:
+	default:
+		return fmt.Errorf("invalid missing-file policy '%s' -- use load, skip, or overwrite", spec.Policy.MissingFile)
+	}
+
+	switch spec.Policy.OutdatedContext {
+	case types.RunOutdatedContextUpdate, types.RunOutdatedContextFail:
+	default:
+		return fmt.Errorf("invalid outdated-context policy '%s' -- use update or fail", spec.Policy.OutdatedContext)
+	}
+
+	switch spec.Policy.ExecFail {
+	case types.RunExecFailRollback, types.RunExecFailKeep:
+	default:
+		return fmt.Errorf("invalid exec-fail policy '%s' -- use rollback or keep", spec.Policy.ExecFail)
+	}
+
+	switch spec.Report.Format {
+	case types.RunReportFormatJson, types.RunReportFormatJunit:
+	default:
+		return fmt.Errorf("invalid report format '%s' -- use json or junit", spec.Report.Format)
+	}
+
+	for i, check := range spec.Checks {
+		if strings.TrimSpace(check.Run) == "" {
+			return fmt.Errorf("check %d has no command to run", i+1)
+		}
+		if check.Name == "" {
+			spec.Checks[i].Name = check.Run
+		}
+	}
+
+	return nil
+}
+
+// RunChecks runs each check in the project root with 'sh -c', streaming output as it goes
+func RunChecks(checks []types.RunSpecCheck) []*types.RunCheckResult {
+	var results []*types.RunCheckResult
+
+	for _, check := range checks {
+		timeout := check.Timeout
+		if timeout == 0 {
+			timeout = defaultRunCheckTimeout
+		}
+
+		fmt.Printf("🧪 %s\n", check.Name)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		var output bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", check.Run)
+		cmd.Dir = fs.ProjectRoot
+		cmd.Env = os.Environ()
+		cmd.Stdout = io.MultiWriter(&output, os.Stdout)
+		cmd.Stderr = io.MultiWriter(&output, os.Stderr)
+
+		start := time.Now()
+		err := cmd.Run()
+		duration := time.Since(start)
+		timedOut := ctx.Err() == context.DeadlineExceeded
+		cancel()
+
+		res := &types.RunCheckResult{
+			Name:     check.Name,
+			Run:      check.Run,
+			Status:   types.RunStatusPassed,
+			Duration: duration,
+		}
+
+		if err != nil {
+			res.Status = types.RunStatusFailed
+			res.ExitCode = -1
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				res.ExitCode = exitErr.ExitCode()
+			}
+			if timedOut {
+				output.WriteString(fmt.Sprintf("\ncheck timed out after %s\n", timeout))
+			} else if res.ExitCode == -1 {
+				output.WriteString("\n" + err.Error() + "\n")
+			}
+		}
+
+		out := output.String()
+		if len(out) > maxRunCheckOutput {
+			out = "...\n" + out[len(out)-maxRunCheckOutput:]
+		}
+		res.Output = out
+
+		if res.Status == types.RunStatusPassed {
+			fmt.Printf("✅ %s passed\n\n", check.Name)
+		} else {
+			fmt.Printf("❌ %s failed with exit code %d\n\n", check.Name, res.ExitCode)
+		}
+
+		results = append(results, res)
+	}
+
+	return results
+}
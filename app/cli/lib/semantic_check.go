@@ -0,0 +1,379 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+
+	"gopkg.in/yaml.v3"
+)
+
+// the plan's semantic-check config for the current command -- "auto", or empty when checks are off
+var SemanticCheck string
+
+// project file that lists custom checkers -- they're only read locally so that whoever can change a shared plan's config can't choose commands to run on this machine
+const CheckConfigFile = ".plandex-check.yml"
+
+// the server stops waiting after 3 minutes, so a check has to finish well before that
+const semanticCheckTimeout = 2 * time.Minute
+
+const (
+	maxSemanticDiagnostics   = 20
+	maxSemanticDiagnosticLen = 500
+)
+
+func SemanticCheckEnabled() bool {
+	return SemanticCheck == shared.SemanticCheckAuto
+}
+
+type checkConfig struct {
+	Checkers []checkerConfig `yaml:"checkers"`
+}
+
+type checkerConfig struct {
+	// glob patterns matched against the file name, or the path relative to the project root if the pattern includes a '/'
+	Files []string `yaml:"files"`
+	// {file} and {dir} are replaced with the built file's path and its directory, relative to the directory the checker runs in
+	Command string `yaml:"command"`
+	// the checker runs in the nearest directory above the file that contains one of these, or the project root if there are none
+	Root []string `yaml:"root"`
+}
+
+var (
+	checkConfigOnce   sync.Once
+	loadedCheckConfig *checkConfig
+	checkConfigErr    error
+)
+
+func getCheckConfig() (*checkConfig, error) {
+	checkConfigOnce.Do(func() {
+		if fs.ProjectRoot == "" {
+			return
+		}
+
+		bytes, err := os.ReadFile(filepath.Join(fs.ProjectRoot, CheckConfigFile))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				checkConfigErr = fmt.Errorf("error reading %s: %v", CheckConfigFile, err)
+			}
+			return
+		}
+
+		var config checkConfig
+		err = yaml.Unmarshal(bytes, &config)
+		if err != nil {
+			checkConfigErr = fmt.Errorf("error parsing %s: %v", CheckConfigFile, err)
+			return
+		}
+
+		for i, checker := range config.Checkers {
+			if strings.TrimSpace(checker.Command) == "" || len(checker.Files) == 0 {
+				checkConfigErr = fmt.Errorf("checker %d in %s needs files and a command", i+1, CheckConfigFile)
+				return
+			}
+		}
+
+		loadedCheckConfig = &config
+	})
+
+	return loadedCheckConfig, checkConfigErr
+}
+
+type semanticChecker struct {
+	// e.g. "go vet" -- reported to the server
+	name string
+	// {file} and {dir} are replaced with the built file's path and its directory, relative to the directory the checker runs in
+	args []string
+	// the checker runs in the nearest directory above the file that contains one of these, or the project root if there are none
+	rootMarkers []string
+}
+
+// RespondSemanticCheck type-checks a built file and sends the result back to the server, which is waiting on it to finish the build
+func RespondSemanticCheck(req *shared.SemanticCheckRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), semanticCheckTimeout)
+	defer cancel()
+
+	res := RunSemanticCheck(ctx, req)
+
+	apiErr := api.Client.RespondSemanticCheck(CurrentPlanId, CurrentBranch, res)
+	if apiErr != nil {
+		log.Printf("Error responding to semantic check %s: %v", req.Id, apiErr.Msg)
+	}
+}
+
+// RunSemanticCheck runs the configured checker against a temporary overlay of the project with the built file swapped in. It runs the same checker on the project as it is, and only returns diagnostics for the built file that weren't already there.
+func RunSemanticCheck(ctx context.Context, req *shared.SemanticCheckRequest) shared.RespondSemanticCheckRequest {
+	res := shared.RespondSemanticCheckRequest{Id: req.Id}
+
+	if fs.ProjectRoot == "" {
+		res.Error = "no project root"
+		return res
+	}
+
+	relPath := filepath.Clean(req.Path)
+	if filepath.IsAbs(relPath) {
+		var err error
+		relPath, err = filepath.Rel(fs.ProjectRoot, relPath)
+		if err != nil {
+			res.Error = fmt.Sprintf("error resolving path: %v", err)
+			return res
+		}
+	}
+	if relPath == "." || strings.HasPrefix(relPath, "..") {
+		res.Error = fmt.Sprintf("%s is outside the project", req.Path)
+		return res
+	}
+
+	checker, err := resolveSemanticChecker(relPath)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if checker == nil {
+		log.Printf("No semantic checker for %s", relPath)
+		return res
+	}
+	res.Checker = checker.name
+
+	overlayDir, err := os.MkdirTemp("", "plandex-check-")
+	if err != nil {
+		res.Error = fmt.Sprintf("error creating overlay dir: %v", err)
+		return res
+	}
+	defer os.RemoveAll(overlayDir)
+
+	err = writeSemanticCheckOverlay(overlayDir, relPath, req.Content)
+	if err != nil {
+		res.Error = fmt.Sprintf("error creating overlay: %v", err)
+		return res
+	}
+
+	runDir := semanticCheckRunDir(relPath, checker.rootMarkers)
+
+	after, err := runSemanticChecker(ctx, checker, overlayDir, runDir, relPath)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if len(after) == 0 {
+		return res
+	}
+
+	var before []string
+	if _, statErr := os.Stat(filepath.Join(fs.ProjectRoot, relPath)); statErr == nil {
+		before, err = runSemanticChecker(ctx, checker, fs.ProjectRoot, runDir, relPath)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+	}
+
+	existing := map[string]int{}
+	for _, line := range before {
+		existing[normalizeDiagnostic(line)]++
+	}
+
+	for _, line := range after {
+		key := normalizeDiagnostic(line)
+		if existing[key] > 0 {
+			existing[key]--
+			continue
+		}
+		if len(res.Diagnostics) == maxSemanticDiagnostics {
+			res.Diagnostics = append(res.Diagnostics, "...more errors omitted")
+			break
+		}
+		if len(line) > maxSemanticDiagnosticLen {
+			line = line[:maxSemanticDiagnosticLen] + "..."
+		}
+		res.Diagnostics = append(res.Diagnostics, line)
+	}
+
+	return res
+}
+
+// resolveSemanticChecker returns the first checker in the project's check config that matches relPath, or else picks one by language
+func resolveSemanticChecker(relPath string) (*semanticChecker, error) {
+	if !SemanticCheckEnabled() {
+		return nil, nil
+	}
+
+	config, err := getCheckConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		for _, checker := range config.Checkers {
+			if matchesFilePatterns(checker.Files, relPath) {
+				return &semanticChecker{name: checker.Command, args: strings.Fields(checker.Command), rootMarkers: checker.Root}, nil
+			}
+		}
+	}
+
+	return autoSemanticChecker(relPath), nil
+}
+
+func autoSemanticChecker(relPath string) *semanticChecker {
+	switch strings.ToLower(filepath.Ext(relPath)) {
+	case ".go":
+		if _, err := exec.LookPath("gopls"); err == nil {
+			return &semanticChecker{name: "gopls check", args: []string{"gopls", "check", "{file}"}, rootMarkers: []string{"go.mod"}}
+		}
+		if _, err := exec.LookPath("go"); err == nil {
+			return &semanticChecker{name: "go vet", args: []string{"go", "vet", "{dir}"}, rootMarkers: []string{"go.mod"}}
+		}
+
+	case ".ts", ".tsx", ".mts", ".cts":
+		runDir := semanticCheckRunDir(relPath, []string{"tsconfig.json"})
+		if _, err := os.Stat(filepath.Join(fs.ProjectRoot, runDir, "tsconfig.json")); err != nil {
+			// without a tsconfig, tsc can't know how the project is compiled
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(fs.ProjectRoot, runDir, "node_modules", ".bin", "tsc")); err == nil {
+			return &semanticChecker{name: "tsc --noEmit", args: []string{filepath.Join("node_modules", ".bin", "tsc"), "--noEmit", "-p", "."}, rootMarkers: []string{"tsconfig.json"}}
+		}
+		if _, err := exec.LookPath("tsc"); err == nil {
+			return &semanticChecker{name: "tsc --noEmit", args: []string{"tsc", "--noEmit", "-p", "."}, rootMarkers: []string{"tsconfig.json"}}
+		}
+
+	case ".py", ".pyi":
+		markers := []string{"pyproject.toml", "pyrightconfig.json", "setup.py"}
+		if _, err := exec.LookPath("pyright"); err == nil {
+			return &semanticChecker{name: "pyright", args: []string{"pyright", "{file}"}, rootMarkers: markers}
+		}
+		if _, err := exec.LookPath("ruff"); err == nil {
+			return &semanticChecker{name: "ruff", args: []string{"ruff", "check", "--output-format", "concise", "{file}"}, rootMarkers: markers}
+		}
+	}
+
+	return nil
+}
+
+// semanticCheckRunDir returns the directory, relative to the project root, that a checker for relPath runs in
+func semanticCheckRunDir(relPath string, rootMarkers []string) string {
+	dir := filepath.Dir(relPath)
+	for {
+		for _, marker := range rootMarkers {
+			if _, err := os.Stat(filepath.Join(fs.ProjectRoot, dir, marker)); err == nil {
+				return dir
+			}
+		}
+		if dir == "." {
+			return "."
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// writeSemanticCheckOverlay mirrors the project in overlayDir with symlinks, except for the directories on relPath, which are created so that the built file can be written in place of the original
+func writeSemanticCheckOverlay(overlayDir, relPath, content string) error {
+	parts := strings.Split(relPath, string(filepath.Separator))
+
+	srcDir := fs.ProjectRoot
+	dstDir := overlayDir
+
+	for i, part := range parts {
+		isFile := i == len(parts)-1
+
+		entries, err := os.ReadDir(srcDir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading %s: %v", srcDir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.Name() == part {
+				continue
+			}
+			err := os.Symlink(filepath.Join(srcDir, entry.Name()), filepath.Join(dstDir, entry.Name()))
+			if err != nil {
+				return fmt.Errorf("error linking %s: %v", entry.Name(), err)
+			}
+		}
+
+		if isFile {
+			return os.WriteFile(filepath.Join(dstDir, part), []byte(content), 0644)
+		}
+
+		srcDir = filepath.Join(srcDir, part)
+		dstDir = filepath.Join(dstDir, part)
+		err = os.Mkdir(dstDir, 0755)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %v", dstDir, err)
+		}
+	}
+
+	return nil
+}
+
+// runSemanticChecker runs a checker on the project at root and returns the output lines that mention the file, with root replaced by the project root so that results from the overlay and the project compare equally
+func runSemanticChecker(ctx context.Context, checker *semanticChecker, root, runDir, relPath string) ([]string, error) {
+	fileArg, err := filepath.Rel(runDir, relPath)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving path: %v", err)
+	}
+	dirArg := "." + string(filepath.Separator) + filepath.Dir(fileArg)
+	if filepath.Dir(fileArg) == "." {
+		dirArg = "."
+	}
+
+	args := make([]string, len(checker.args))
+	for i, arg := range checker.args {
+		arg = strings.ReplaceAll(arg, "{file}", fileArg)
+		arg = strings.ReplaceAll(arg, "{dir}", dirArg)
+		args[i] = arg
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = filepath.Join(root, runDir)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	// checkers exit non-zero when they find errors, so only failing to start is an error here
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%s timed out", checker.name)
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("error running %s: %v", checker.name, err)
+		}
+	}
+
+	output := out.String()
+	if root != fs.ProjectRoot {
+		output = strings.ReplaceAll(output, root, fs.ProjectRoot)
+		if resolved, err := filepath.EvalSymlinks(root); err == nil && resolved != root {
+			output = strings.ReplaceAll(output, resolved, fs.ProjectRoot)
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && (strings.Contains(line, fileArg) || strings.Contains(line, filepath.ToSlash(fileArg))) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+var diagnosticPositionRegex = regexp.MustCompile(`[:(]\d+(?:[:,.\-]\d+)*\)?`)
+
+// normalizeDiagnostic strips line and column numbers so that an error that was already there before the build still matches after lines shift
+func normalizeDiagnostic(line string) string {
+	return diagnosticPositionRegex.ReplaceAllString(line, "")
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"plandex-cli/api"
 	"plandex-cli/fs"
+	"plandex-cli/lib"
 	"plandex-cli/stream"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
@@ -66,6 +67,7 @@ func Build(params ExecParams, flags types.BuildFlags) (bool, error) {
 		ApiKeys:       params.ApiKeys,
 		OpenAIBase:    openAIBase,
 		OpenAIOrgId:   openAIOrgId,
+		SemanticCheck: lib.SemanticCheckEnabled() && !buildBg,
 	}, stream.OnStreamPlan)
 
 	term.StopSpinner()
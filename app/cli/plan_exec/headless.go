@@ -0,0 +1,261 @@
+package plan_exec
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+	"sync"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+type HeadlessTellResult struct {
+	BuiltFiles   []string
+	MissingFiles []string
+}
+
+// TellPlanHeadless sends a prompt and follows the plan stream without the stream UI, answering missing file prompts by policy. Unlike TellPlan, it returns errors rather than exiting so the caller can report them.
+func TellPlanHeadless(
+	params ExecParams,
+	prompt string,
+	flags types.TellFlags,
+	missingFilePolicy types.RunMissingFilePolicy,
+) (*HeadlessTellResult, error) {
+	contexts, apiErr := api.Client.ListContext(params.CurrentPlanId, params.CurrentBranch)
+	if apiErr != nil {
+		return nil, fmt.Errorf("error getting context: %v", apiErr.Msg)
+	}
+
+	paths, err := fs.GetProjectPaths(fs.GetBaseDirForContexts(contexts))
+	if err != nil {
+		return nil, fmt.Errorf("error getting project paths: %v", err)
+	}
+
+	anyOutdated, didUpdate, err := params.CheckOutdatedContext(contexts, paths)
+	if err != nil {
+		return nil, fmt.Errorf("error checking outdated context: %v", err)
+	}
+
+	if anyOutdated && !didUpdate {
+		return nil, fmt.Errorf("context is outdated")
+	}
+
+	flags.TellBg = false
+
+	var mu sync.Mutex
+	builtFiles := map[string]bool{}
+	var missingFiles []string
+
+	done := make(chan error, 1)
+	var doneOnce sync.Once
+	finish := func(err error) {
+		doneOnce.Do(func() {
+			done <- err
+		})
+	}
+
+	respondMissingFile := func(path string, choice shared.RespondMissingFileChoice) {
+		var body string
+		if choice == shared.RespondMissingFileChoiceLoad {
+			bytes, err := os.ReadFile(path)
+			if err != nil {
+				finish(fmt.Errorf("failed to read missing file %s: %v", path, err))
+				return
+			}
+			body = string(bytes)
+		}
+
+		apiErr := api.Client.RespondMissingFile(params.CurrentPlanId, params.CurrentBranch, shared.RespondMissingFileRequest{
+			Choice:   choice,
+			FilePath: path,
+			Body:     body,
+		})
+		if apiErr != nil {
+			finish(fmt.Errorf("error responding to missing file prompt: %v", apiErr.Msg))
+		}
+	}
+
+	var handleMsg func(msg *shared.StreamMessage)
+	handleMsg = func(msg *shared.StreamMessage) {
+		switch msg.Type {
+		case shared.StreamMessageMulti:
+			for i := range msg.StreamMessages {
+				handleMsg(&msg.StreamMessages[i])
+			}
+
+		case shared.StreamMessageReply:
+			fmt.Print(msg.ReplyChunk)
+
+		case shared.StreamMessageBuildInfo:
+			if msg.BuildInfo.Finished {
+				mu.Lock()
+				builtFiles[msg.BuildInfo.Path] = true
+				mu.Unlock()
+				fmt.Printf("\n📄 Built %s\n", msg.BuildInfo.Path)
+			}
+
+		case shared.StreamMessageConnectActive, shared.StreamMessagePromptMissingFile:
+			if msg.MissingFilePath == "" {
+				return
+			}
+
+			choice := shared.RespondMissingFileChoice(missingFilePolicy)
+			if msg.MissingFileAutoContext {
+				choice = shared.RespondMissingFileChoiceLoad
+			}
+
+			mu.Lock()
+			missingFiles = append(missingFiles, msg.MissingFilePath)
+			mu.Unlock()
+
+			fmt.Printf("\n📂 %s is missing from context -- responding with '%s'\n", msg.MissingFilePath, choice)
+
+			// respond outside the stream callback so heartbeats keep flowing while the file is sent
+			go respondMissingFile(msg.MissingFilePath, choice)
+
+		case shared.StreamMessageLoadContext:
+			go func() {
+				text, err := lib.AutoLoadContextFiles(context.Background(), msg.LoadContextFiles)
+				if err != nil {
+					finish(fmt.Errorf("failed to auto load context files: %v", err))
+					return
+				}
+				if text != "" {
+					fmt.Printf("\n%s\n", text)
+				}
+			}()
+
+		case shared.StreamMessageSemanticCheck:
+			if msg.SemanticCheck != nil {
+				go lib.RespondSemanticCheck(msg.SemanticCheck)
+			}
+
+		case shared.StreamMessageError:
+			if msg.Error != nil {
+				finish(fmt.Errorf("%s", msg.Error.Msg))
+			} else {
+				finish(fmt.Errorf("unknown stream error"))
+			}
+
+		case shared.StreamMessageAborted:
+			finish(fmt.Errorf("plan was stopped"))
+
+		case shared.StreamMessageFinished:
+			finish(nil)
+		}
+	}
+
+	var onStream types.OnStreamPlan
+	onStream = func(streamParams types.OnStreamPlanParams) {
+		if streamParams.Err != nil {
+			errMsg := strings.ToLower(streamParams.Err.Error())
+			if strings.Contains(errMsg, "missing heartbeats") || strings.Contains(errMsg, "eof") {
+				log.Println("Error in headless stream, reconnecting:", streamParams.Err)
+				apiErr := api.Client.ConnectPlan(params.CurrentPlanId, params.CurrentBranch, onStream)
+				if apiErr == nil {
+					return
+				}
+				log.Println("Error reconnecting to stream:", apiErr)
+			}
+			finish(fmt.Errorf("stream error: %v", streamParams.Err))
+			return
+		}
+
+		if streamParams.Msg == nil || streamParams.Msg.Type == shared.StreamMessageStart {
+			return
+		}
+
+		handleMsg(streamParams.Msg)
+	}
+
+	if !flags.IsUserContinue && prompt != "" {
+		color.New(term.ColorHiCyan, color.Bold).Println("💬 Prompt 👇")
+		fmt.Println()
+		fmt.Println(prompt)
+		fmt.Println()
+	}
+
+	apiErr = api.Client.TellPlan(params.CurrentPlanId, params.CurrentBranch, getTellPlanRequest(params, paths, prompt, flags), onStream)
+	if apiErr != nil {
+		return nil, fmt.Errorf("prompt error: %v", apiErr.Msg)
+	}
+
+	err = <-done
+	fmt.Println()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &HeadlessTellResult{MissingFiles: missingFiles}
+	for path := range builtFiles {
+		res.BuiltFiles = append(res.BuiltFiles, path)
+	}
+	sort.Strings(res.BuiltFiles)
+
+	return res, nil
+}
+
+// GetHeadlessOnApplyExecFail debugs failed commands up to applyFlags.AutoDebug times without prompting. Once tries run out, changes are rolled back or kept according to the exec-fail policy and onFail is called with the last failure.
+func GetHeadlessOnApplyExecFail(
+	params ExecParams,
+	applyFlags types.ApplyFlags,
+	tellFlags types.TellFlags,
+	missingFilePolicy types.RunMissingFilePolicy,
+	execFailPolicy types.RunExecFailPolicy,
+	onFail func(status int, output string, attempt int, err error),
+) types.OnApplyExecFailFn {
+	var onExecFail types.OnApplyExecFailFn
+	onExecFail = func(status int, output string, attempt int, toRollback *types.ApplyRollbackPlan, onErr types.OnErrFn, onSuccess func()) {
+		if attempt >= applyFlags.AutoDebug {
+			if execFailPolicy == types.RunExecFailKeep {
+				onSuccess()
+			} else if toRollback != nil && toRollback.HasChanges() {
+				lib.Rollback(toRollback, true)
+			}
+			onFail(status, output, attempt, nil)
+			return
+		}
+
+		if toRollback != nil && toRollback.HasChanges() {
+			lib.Rollback(toRollback, true)
+		}
+
+		color.New(term.ColorHiYellow, color.Bold).Printf("🐞 Commands failed with exit status %d -- debugging (attempt %d of %d)\n\n", status, attempt+1, applyFlags.AutoDebug)
+
+		prompt := fmt.Sprintf("Execution failed with exit status %d. Output:\n\n%s\n\n--\n\n",
+			status, output)
+
+		tellFlags.IsUserContinue = false
+		tellFlags.IsApplyDebug = true
+		tellFlags.ExecEnabled = true
+		tellFlags.IsUserDebug = false
+
+		_, err := TellPlanHeadless(params, prompt, tellFlags, missingFilePolicy)
+		if err != nil {
+			// changes were already rolled back above, so there's nothing left to keep
+			onFail(status, output, attempt, err)
+			return
+		}
+
+		lib.MustApplyPlanAttempt(lib.ApplyPlanParams{
+			PlanId:     params.CurrentPlanId,
+			Branch:     params.CurrentBranch,
+			ApplyFlags: applyFlags,
+			TellFlags:  tellFlags,
+			OnExecFail: onExecFail,
+		}, attempt+1)
+	}
+
+	return onExecFail
+}
@@ -0,0 +1,37 @@
+package plan_exec
+
+import (
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	shared "plandex-shared"
+)
+
+// PreviewTellPlan gets the token breakdown the server would use for a prompt without sending it. Context isn't checked for updates, so the preview reflects context as currently loaded.
+func PreviewTellPlan(params ExecParams, prompt string, flags types.TellFlags) *shared.TellPlanPreviewResponse {
+	if prompt == "" && flags.IsImplementationOfChat {
+		prompt = "Go ahead with the plan based on what we've discussed so far."
+	}
+
+	term.StartSpinner("")
+	defer term.StopSpinner()
+
+	contexts, apiErr := api.Client.ListContext(params.CurrentPlanId, params.CurrentBranch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting context: %v", apiErr.Msg)
+	}
+
+	paths, err := fs.GetProjectPaths(fs.GetBaseDirForContexts(contexts))
+	if err != nil {
+		term.OutputErrorAndExit("Error getting project paths: %v", err)
+	}
+
+	res, apiErr := api.Client.PreviewTellPlan(params.CurrentPlanId, params.CurrentBranch, getTellPlanRequest(params, paths, prompt, flags))
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error previewing prompt: %v", apiErr.Msg)
+	}
+
+	return res
+}
@@ -7,6 +7,7 @@ import (
 	"plandex-cli/api"
 	"plandex-cli/auth"
 	"plandex-cli/fs"
+	"plandex-cli/lib"
 	"plandex-cli/stream"
 	streamtui "plandex-cli/stream_tui"
 	"plandex-cli/term"
@@ -25,13 +26,10 @@ func TellPlan(
 
 	tellBg := flags.TellBg
 	tellStop := flags.TellStop
-	tellNoBuild := flags.TellNoBuild
 	isUserContinue := flags.IsUserContinue
 	isDebugCmd := flags.IsUserDebug
 	isChatOnly := flags.IsChatOnly
 	autoContext := flags.AutoContext
-	smartContext := flags.SmartContext
-	execEnabled := flags.ExecEnabled
 	autoApply := flags.AutoApply
 	isApplyDebug := flags.IsApplyDebug
 	isImplementationOfChat := flags.IsImplementationOfChat
@@ -93,13 +91,6 @@ func TellPlan(
 	var fn func() bool
 	fn = func() bool {
 
-		var buildMode shared.BuildMode
-		if tellNoBuild || isChatOnly {
-			buildMode = shared.BuildModeNone
-		} else {
-			buildMode = shared.BuildModeAuto
-		}
-
 		// if isUserContinue {
 		// 	term.StartSpinner("⚡️ Continuing plan...")
 		// } else {
@@ -108,47 +99,7 @@ func TellPlan(
 
 		term.StartSpinner("")
 
-		var legacyApiKey, openAIBase, openAIOrgId string
-
-		if params.ApiKeys["OPENAI_API_KEY"] != "" {
-			openAIBase = os.Getenv("OPENAI_API_BASE")
-			if openAIBase == "" {
-				openAIBase = os.Getenv("OPENAI_ENDPOINT")
-			}
-
-			legacyApiKey = params.ApiKeys["OPENAI_API_KEY"]
-			openAIOrgId = params.ApiKeys["OPENAI_ORG_ID"]
-		}
-
-		var osDetails string
-		if execEnabled {
-			osDetails = term.GetOsDetails()
-		}
-
-		isGitRepo := fs.ProjectRootIsGitRepo()
-
-		apiErr := api.Client.TellPlan(params.CurrentPlanId, params.CurrentBranch, shared.TellPlanRequest{
-			Prompt:                 prompt,
-			ConnectStream:          !tellBg,
-			AutoContinue:           !tellStop,
-			ProjectPaths:           paths.ActivePaths,
-			BuildMode:              buildMode,
-			IsUserContinue:         isUserContinue,
-			IsUserDebug:            isDebugCmd,
-			IsChatOnly:             isChatOnly,
-			AutoContext:            autoContext,
-			SmartContext:           smartContext,
-			ExecEnabled:            execEnabled,
-			OsDetails:              osDetails,
-			ApiKey:                 legacyApiKey, // deprecated
-			Endpoint:               openAIBase,   // deprecated
-			ApiKeys:                params.ApiKeys,
-			OpenAIBase:             openAIBase,
-			OpenAIOrgId:            openAIOrgId,
-			IsImplementationOfChat: isImplementationOfChat,
-			IsGitRepo:              isGitRepo,
-			SessionId:              os.Getenv("PLANDEX_REPL_SESSION_ID"),
-		}, stream.OnStreamPlan)
+		apiErr := api.Client.TellPlan(params.CurrentPlanId, params.CurrentBranch, getTellPlanRequest(params, paths, prompt, flags), stream.OnStreamPlan)
 
 		term.StopSpinner()
 
@@ -250,3 +201,55 @@ func TellPlan(
 		<-done
 	}
 }
+
+func getTellPlanRequest(params ExecParams, paths *types.ProjectPaths, prompt string, flags types.TellFlags) shared.TellPlanRequest {
+	var buildMode shared.BuildMode
+	if flags.TellNoBuild || flags.IsChatOnly {
+		buildMode = shared.BuildModeNone
+	} else {
+		buildMode = shared.BuildModeAuto
+	}
+
+	var legacyApiKey, openAIBase, openAIOrgId string
+
+	if params.ApiKeys["OPENAI_API_KEY"] != "" {
+		openAIBase = os.Getenv("OPENAI_API_BASE")
+		if openAIBase == "" {
+			openAIBase = os.Getenv("OPENAI_ENDPOINT")
+		}
+
+		legacyApiKey = params.ApiKeys["OPENAI_API_KEY"]
+		openAIOrgId = params.ApiKeys["OPENAI_ORG_ID"]
+	}
+
+	var osDetails string
+	if flags.ExecEnabled {
+		osDetails = term.GetOsDetails()
+	}
+
+	isGitRepo := fs.ProjectRootIsGitRepo()
+
+	return shared.TellPlanRequest{
+		Prompt:                 prompt,
+		ConnectStream:          !flags.TellBg,
+		AutoContinue:           !flags.TellStop,
+		ProjectPaths:           paths.ActivePaths,
+		BuildMode:              buildMode,
+		IsUserContinue:         flags.IsUserContinue,
+		IsUserDebug:            flags.IsUserDebug,
+		IsChatOnly:             flags.IsChatOnly,
+		AutoContext:            flags.AutoContext,
+		SmartContext:           flags.SmartContext,
+		ExecEnabled:            flags.ExecEnabled,
+		OsDetails:              osDetails,
+		ApiKey:                 legacyApiKey, // deprecated
+		Endpoint:               openAIBase,   // deprecated
+		ApiKeys:                params.ApiKeys,
+		OpenAIBase:             openAIBase,
+		OpenAIOrgId:            openAIOrgId,
+		IsImplementationOfChat: flags.IsImplementationOfChat,
+		IsGitRepo:              isGitRepo,
+		SessionId:              os.Getenv("PLANDEX_REPL_SESSION_ID"),
+		SemanticCheck:          lib.SemanticCheckEnabled() && !flags.TellBg && !flags.IsChatOnly,
+	}
+}
@@ -55,6 +55,7 @@ type streamUIModel struct {
 	tokensByPath   map[string]int
 	finishedByPath map[string]bool
 	removedByPath  map[string]bool
+	skippedByPath  map[string]bool
 
 	ready  bool
 	width  int
@@ -200,6 +201,7 @@ func initialModel(prestartReply, prompt string, buildOnly bool, canSendToBg bool
 		tokensByPath:    make(map[string]int),
 		finishedByPath:  make(map[string]bool),
 		removedByPath:   make(map[string]bool),
+		skippedByPath:   make(map[string]bool),
 		spinner:         s,
 		buildSpinner:    buildSpinner,
 		sharedTicker:    sharedTicker,
@@ -448,6 +448,7 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 			} else {
 				m.removedByPath[msg.BuildInfo.Path] = false
 			}
+			m.skippedByPath[msg.BuildInfo.Path] = msg.BuildInfo.Skipped
 		})
 
 		if msg.BuildInfo.Finished {
@@ -505,6 +506,12 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 			}),
 		)
 
+	case shared.StreamMessageSemanticCheck:
+		if msg.SemanticCheck == nil {
+			return m, nil
+		}
+		return m, semanticCheckCmd(msg.SemanticCheck)
+
 	case shared.StreamMessageError:
 		log.Println("Stream message error:", spew.Sdump(msg))
 
@@ -744,6 +751,14 @@ type contextLoadDoneMsg struct {
 	err  error
 }
 
+// semanticCheckCmd runs a built file's semantic check in the background -- the result goes to the server rather than the UI
+func semanticCheckCmd(req *shared.SemanticCheckRequest) tea.Cmd {
+	return func() tea.Msg {
+		lib.RespondSemanticCheck(req)
+		return nil
+	}
+}
+
 func loadContextCmd(loadContextFiles []string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -200,6 +200,7 @@ func (m streamUIModel) getRows(static bool) []string {
 		tokens := m.tokensByPath[filePath]
 		finished := m.finished || m.finishedByPath[filePath] || built
 		removed := m.removedByPath[filePath]
+		skipped := m.skippedByPath[filePath]
 
 		// Basic block label
 		icon := "📄"
@@ -210,10 +211,12 @@ func (m streamUIModel) getRows(static bool) []string {
 		}
 		block := fmt.Sprintf("%s %s", icon, label)
 
-		// Mark removed/finished/tokens
+		// Mark removed/skipped/finished/tokens
 		switch {
 		case removed:
 			block += " ❌"
+		case skipped:
+			block += " 🔒"
 		case finished:
 			block += " ✅"
 		case tokens > 0:
@@ -13,6 +13,7 @@ import (
 var openUnauthenticatedCloudURL func(msg, path string)
 var openAuthenticatedURL func(msg, path string)
 var convertTrial func()
+var beforeErrorExit func(msg string)
 
 func SetOpenUnauthenticatedCloudURLFn(fn func(msg, path string)) {
 	openUnauthenticatedCloudURL = fn
@@ -24,6 +25,11 @@ func SetConvertTrialFn(fn func()) {
 	convertTrial = fn
 }
 
+// SetBeforeErrorExitFn registers a function that runs with the error message just before OutputErrorAndExit exits
+func SetBeforeErrorExitFn(fn func(msg string)) {
+	beforeErrorExit = fn
+}
+
 func OutputNoOpenAIApiKeyMsgAndExit() {
 	fmt.Fprintln(os.Stderr, color.New(color.Bold, ColorHiRed).Sprintln("\n🚨 OPENAI_API_KEY environment variable is not set.")+color.New().Sprintln("\nSet it with:\n\nexport OPENAI_API_KEY=your-api-key\n\nThen try again.\n\n👉 If you don't have an OpenAI account, sign up here → https://platform.openai.com/signup\n\n🔑 Generate an api key here → https://platform.openai.com/api-keys"))
 	os.Exit(1)
@@ -38,6 +44,10 @@ func OutputErrorAndExit(msg string, args ...interface{}) {
 	StopSpinner()
 	msg = fmt.Sprintf(msg, args...)
 
+	if IsJsonOutput {
+		OutputJsonErrorAndExit(msg)
+	}
+
 	displayMsg := ""
 	errorParts := strings.Split(msg, ": ")
 
@@ -88,16 +98,26 @@ func OutputErrorAndExit(msg string, args ...interface{}) {
 	}
 
 	fmt.Fprintln(os.Stderr, color.New(ColorHiRed, color.Bold).Sprint(displayMsg))
+	if beforeErrorExit != nil {
+		beforeErrorExit(msg)
+	}
 	os.Exit(1)
 }
 
 func OutputUnformattedErrorAndExit(msg string) {
 	StopSpinner()
 	fmt.Fprintln(os.Stderr, msg)
+	if beforeErrorExit != nil {
+		beforeErrorExit(msg)
+	}
 	os.Exit(1)
 }
 
 func OutputNoCurrentPlanErrorAndExit() {
+	if IsJsonOutput {
+		OutputJsonErrorAndExit("no current plan")
+	}
+
 	fmt.Println("🤷‍♂️ No current plan")
 	fmt.Println()
 	PrintCmds("", "new", "cd")
@@ -42,6 +42,7 @@ var CliCommands = []CmdConfig{
 	{"new --strong", "", fmt.Sprintf("start a new plan with %s model pack", "'strong'"), true},
 	{"new --cheap", "", fmt.Sprintf("start a new plan with %s model pack", "'cheap'"), true},
 	{"new --oss", "", fmt.Sprintf("start a new plan with %s model pack", "'oss'"), true},
+	{"new --template", "", "start a new plan from a plan template (--var key=val for variables)", true},
 
 	{"plans", "pl", "list plans", true},
 	{"cd", "", "set current plan by name or index", true},
@@ -87,6 +88,7 @@ var CliCommands = []CmdConfig{
 	{"continue", "c", "continue the plan", true},
 	{"debug", "db", "repeatedly run a command and auto-apply fixes until it succeeds", true},
 	{"build", "b", "build any pending changes", true},
+	{"run", "", "run a plan from a yaml spec without prompting, then run its checks (for CI)", false},
 
 	{"convo", "", "show plan conversation", true},
 	{"convo 1", "", "show a specific message in the conversation", false},
@@ -100,6 +102,9 @@ var CliCommands = []CmdConfig{
 	{"plans --archived", "", "list archived plans", true},
 	{"archive", "arc", "archive a plan", true},
 	{"unarchive", "unarc", "unarchive a plan", true},
+	{"share", "", "share the current plan with an org member or the whole project", true},
+	{"unshare", "", "stop sharing the current plan", true},
+	{"plans --shared", "", "list plans shared with you", true},
 
 	{"models", "", "show current plan model settings", true},
 	{"models default", "", "show the default model settings for new plans", true},
@@ -111,6 +116,7 @@ var CliCommands = []CmdConfig{
 	{"model-packs create", "", "create a new custom model pack", true},
 	{"model-packs delete", "", "delete a custom model pack", true},
 	{"model-packs --custom", "", "show custom model packs only", true},
+	{"build-stats", "", "show builder strategy success rates and latency by language and file size", true},
 	{"set-model", "", "update current plan model settings", true},
 	{"set-model default", "", "update the default model settings for new plans", true},
 
@@ -123,10 +129,29 @@ var CliCommands = []CmdConfig{
 	{"stop", "", "stop an active plan stream", true},
 	{"connect", "conn", "connect to an active plan stream", true},
 
-	{"sign-in", "", "sign in, accept an invite, or create an account", true},
+	{"sign-in", "", "sign in, accept an invite, or create an account (--sso for single sign-on)", true},
 	{"invite", "", "invite a user to join your org", true},
 	{"revoke", "", "revoke an invite or remove a user from your org", true},
 	{"users", "", "list users and pending invites in your org", true},
+	{"tokens", "", "list api tokens for automation", true},
+	{"tokens create", "", "create an api token for yourself or a service account", true},
+	{"tokens revoke", "", "revoke an api token", true},
+	{"roles", "", "list org roles and their permissions", true},
+	{"roles create", "", "create a custom org role", true},
+	{"roles update", "", "update a custom org role", true},
+	{"roles delete", "", "delete a custom org role", true},
+	{"audit", "", "show the org's audit log (--json to export)", true},
+	{"webhooks", "", "list webhooks for plan events", true},
+	{"webhooks create", "", "create a webhook for plan events like finished or build failed", true},
+	{"webhooks update", "", "update or disable a webhook", true},
+	{"webhooks delete", "", "delete a webhook", true},
+	{"webhooks deliveries", "", "show a webhook's recent deliveries", true},
+	{"webhooks test", "", "send a test delivery to a webhook", true},
+	{"templates", "", "list the org's plan templates", true},
+	{"templates show", "", "show a plan template's prompt, context, and config", true},
+	{"templates create", "", "create a plan template with a prompt, context, auto mode, and model pack", true},
+	{"templates update", "", "update a plan template", true},
+	{"templates delete", "", "delete a plan template", true},
 
 	{"usage", "", "show Plandex Cloud current balance and usage report", true},
 	{"usage --today", "", "show Plandex Cloud usage for the day so far", true},
@@ -277,7 +302,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Plans ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "new --template", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive", "share", "unshare", "plans --shared", "templates", "templates create")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Changes ")
@@ -297,7 +322,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Control ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "tell", "continue", "build", "debug", "chat")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "tell", "continue", "build", "debug", "chat", "run")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Streams ")
@@ -313,7 +338,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " AI Models ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "models", "models default", "model-packs", "set-model", "set-model daily", "set-model strong", "set-model cheap", "set-model oss", "set-model default")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "models", "models default", "model-packs", "set-model", "set-model daily", "set-model strong", "set-model cheap", "set-model oss", "set-model default", "build-stats")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Custom Models ")
@@ -321,7 +346,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Accounts ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users", "tokens", "tokens create", "tokens revoke", "roles", "roles create", "audit", "webhooks", "webhooks create")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Cloud ")
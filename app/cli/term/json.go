@@ -0,0 +1,35 @@
+package term
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// IsJsonOutput is set by the global --json flag. Spinners are disabled and errors are written to stderr as {"error": "..."} so stdout only ever holds the command's JSON.
+var IsJsonOutput bool
+
+func SetIsJsonOutput(value bool) {
+	IsJsonOutput = value
+}
+
+func OutputJson(v interface{}) {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		OutputJsonErrorAndExit(fmt.Sprintf("error marshalling json output: %v", err))
+	}
+	fmt.Println(string(bytes))
+}
+
+func OutputJsonErrorAndExit(msg string) {
+	bytes, err := json.Marshal(map[string]string{"error": msg})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg)
+	} else {
+		fmt.Fprintln(os.Stderr, string(bytes))
+	}
+	if beforeErrorExit != nil {
+		beforeErrorExit(msg)
+	}
+	os.Exit(1)
+}
@@ -23,6 +23,10 @@ type ApiClient interface {
 	CreateAccount(req shared.CreateAccountRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
 	SignIn(req shared.SignInRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
 
+	ListSsoProviders(customHost string) (*shared.ListSsoProvidersResponse, *shared.ApiError)
+	StartSsoDevice(providerId, customHost string) (*shared.StartSsoDeviceResponse, *shared.ApiError)
+	PollSsoDevice(pollToken, customHost string) (*shared.PollSsoDeviceResponse, *shared.ApiError)
+
 	SignOut() *shared.ApiError
 
 	GetOrgSession() (*shared.Org, *shared.ApiError)
@@ -33,6 +37,9 @@ type ApiClient interface {
 	DeleteUser(userId string) *shared.ApiError
 
 	ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError)
+	CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) *shared.ApiError
+	DeleteOrgRole(roleId string) *shared.ApiError
 
 	InviteUser(req shared.InviteRequest) *shared.ApiError
 	ListPendingInvites() ([]*shared.Invite, *shared.ApiError)
@@ -40,6 +47,24 @@ type ApiClient interface {
 	ListAllInvites() ([]*shared.Invite, *shared.ApiError)
 	DeleteInvite(inviteId string) *shared.ApiError
 
+	CreateApiToken(req shared.CreateApiTokenRequest) (*shared.CreateApiTokenResponse, *shared.ApiError)
+	ListApiTokens() ([]*shared.ApiToken, *shared.ApiError)
+	RevokeApiToken(tokenId string) *shared.ApiError
+
+	ListAuditLog(req shared.ListAuditLogRequest) (*shared.ListAuditLogResponse, *shared.ApiError)
+
+	ListWebhooks() ([]*shared.Webhook, *shared.ApiError)
+	CreateWebhook(req shared.CreateWebhookRequest) (*shared.CreateWebhookResponse, *shared.ApiError)
+	UpdateWebhook(webhookId string, req shared.UpdateWebhookRequest) *shared.ApiError
+	DeleteWebhook(webhookId string) *shared.ApiError
+	ListWebhookDeliveries(webhookId string, limit int) ([]*shared.WebhookDelivery, *shared.ApiError)
+	TestWebhook(webhookId string) (*shared.WebhookDelivery, *shared.ApiError)
+
+	ListPlanTemplates() ([]*shared.PlanTemplate, *shared.ApiError)
+	CreatePlanTemplate(req shared.CreatePlanTemplateRequest) (*shared.PlanTemplate, *shared.ApiError)
+	UpdatePlanTemplate(templateId string, req shared.UpdatePlanTemplateRequest) *shared.ApiError
+	DeletePlanTemplate(templateId string) *shared.ApiError
+
 	CreateProject(req shared.CreateProjectRequest) (*shared.CreateProjectResponse, *shared.ApiError)
 	ListProjects() ([]*shared.Project, *shared.ApiError)
 	SetProjectPlan(projectId string, req shared.SetProjectPlanRequest) *shared.ApiError
@@ -48,6 +73,10 @@ type ApiClient interface {
 	ListPlans(projectIds []string) ([]*shared.Plan, *shared.ApiError)
 	ListArchivedPlans(projectIds []string) ([]*shared.Plan, *shared.ApiError)
 	ListPlansRunning(projectIds []string, includeRecent bool) (*shared.ListPlansRunningResponse, *shared.ApiError)
+	ListSharedPlans(projectIds []string) (*shared.ListSharedPlansResponse, *shared.ApiError)
+	SharePlan(planId string, req shared.SharePlanRequest) (*shared.PlanShare, *shared.ApiError)
+	ListPlanShares(planId string) ([]*shared.PlanShare, *shared.ApiError)
+	UnsharePlan(planId, shareId string) *shared.ApiError
 
 	GetCurrentBranchByPlanId(projectId string, req shared.GetCurrentBranchByPlanIdRequest) (map[string]*shared.Branch, *shared.ApiError)
 
@@ -55,8 +84,10 @@ type ApiClient interface {
 	CreatePlan(projectId string, req shared.CreatePlanRequest) (*shared.CreatePlanResponse, *shared.ApiError)
 
 	TellPlan(planId, branch string, req shared.TellPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
+	PreviewTellPlan(planId, branch string, req shared.TellPlanRequest) (*shared.TellPlanPreviewResponse, *shared.ApiError)
 	BuildPlan(planId, branch string, req shared.BuildPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	RespondMissingFile(planId, branch string, req shared.RespondMissingFileRequest) *shared.ApiError
+	RespondSemanticCheck(planId, branch string, req shared.RespondSemanticCheckRequest) *shared.ApiError
 
 	DeletePlan(planId string) *shared.ApiError
 	DeleteAllPlans(projectId string) *shared.ApiError
@@ -116,4 +147,6 @@ type ApiClient interface {
 	GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError)
 	AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	GetBuildStatus(planId, branch string) (*shared.GetBuildStatusResponse, *shared.ApiError)
+
+	GetBuilderStats() (*shared.GetBuilderStatsResponse, *shared.ApiError)
 }
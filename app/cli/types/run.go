@@ -0,0 +1,141 @@
+package types
+
+import (
+	"time"
+
+	shared "plandex-shared"
+)
+
+// RunSpec is the yaml spec for 'plandex run' -- it starts a plan, sends each prompt in order, applies the result, then runs the checks
+type RunSpec struct {
+	Name     string                     `yaml:"name"`
+	Template string                     `yaml:"template"`
+	Vars     map[string]string          `yaml:"vars"`
+	Context  shared.PlanTemplateContext `yaml:"context"`
+	Prompts  []string                   `yaml:"prompts"`
+	Config   RunSpecConfig              `yaml:"config"`
+	Policy   RunSpecPolicy              `yaml:"policy"`
+	Checks   []RunSpecCheck             `yaml:"checks"`
+	Report   RunSpecReport              `yaml:"report"`
+}
+
+type RunSpecConfig struct {
+	AutoMode  shared.AutoModeType `yaml:"auto-mode"`
+	ModelPack string              `yaml:"model-pack"`
+	Apply     *bool               `yaml:"apply"`
+	Commit    bool                `yaml:"commit"`
+	Exec      *bool               `yaml:"exec"`
+	Debug     int                 `yaml:"debug"`
+}
+
+type RunMissingFilePolicy string
+
+const (
+	RunMissingFileLoad      RunMissingFilePolicy = "load"
+	RunMissingFileSkip      RunMissingFilePolicy = "skip"
+	RunMissingFileOverwrite RunMissingFilePolicy = "overwrite"
+)
+
+type RunOutdatedContextPolicy string
+
+const (
+	RunOutdatedContextUpdate RunOutdatedContextPolicy = "update"
+	RunOutdatedContextFail   RunOutdatedContextPolicy = "fail"
+)
+
+type RunExecFailPolicy string
+
+const (
+	RunExecFailRollback RunExecFailPolicy = "rollback"
+	RunExecFailKeep     RunExecFailPolicy = "keep"
+)
+
+// RunSpecPolicy answers the prompts an interactive session would ask the user
+type RunSpecPolicy struct {
+	MissingFile     RunMissingFilePolicy     `yaml:"missing-file"`
+	OutdatedContext RunOutdatedContextPolicy `yaml:"outdated-context"`
+	ExecFail        RunExecFailPolicy        `yaml:"exec-fail"`
+}
+
+type RunSpecCheck struct {
+	Name    string        `yaml:"name"`
+	Run     string        `yaml:"run"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type RunReportFormat string
+
+const (
+	RunReportFormatJson  RunReportFormat = "json"
+	RunReportFormatJunit RunReportFormat = "junit"
+)
+
+type RunSpecReport struct {
+	Format RunReportFormat `yaml:"format"`
+	Path   string          `yaml:"path"`
+}
+
+type RunStatus string
+
+const (
+	RunStatusPassed RunStatus = "passed"
+	RunStatusFailed RunStatus = "failed"
+	RunStatusError  RunStatus = "error"
+)
+
+// exit codes for 'plandex run'
+const (
+	RunExitPassed = 0
+	RunExitError  = 1
+	RunExitFailed = 2
+)
+
+type RunReport struct {
+	Name       string            `json:"name"`
+	PlanId     string            `json:"planId,omitempty"`
+	Branch     string            `json:"branch,omitempty"`
+	Status     RunStatus         `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Prompts    []*RunPromptResult `json:"prompts"`
+	Apply      *RunApplyResult   `json:"apply,omitempty"`
+	Checks     []*RunCheckResult `json:"checks"`
+}
+
+type RunPromptResult struct {
+	Prompt       string        `json:"prompt"`
+	Status       RunStatus     `json:"status"`
+	Error        string        `json:"error,omitempty"`
+	BuiltFiles   []string      `json:"builtFiles,omitempty"`
+	MissingFiles []string      `json:"missingFiles,omitempty"`
+	Duration     time.Duration `json:"durationNs"`
+}
+
+type RunApplyResult struct {
+	Status       RunStatus `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	ExecStatus   int       `json:"execStatus,omitempty"`
+	ExecOutput   string    `json:"execOutput,omitempty"`
+	DebugAttempts int      `json:"debugAttempts,omitempty"`
+}
+
+type RunCheckResult struct {
+	Name     string        `json:"name"`
+	Run      string        `json:"run"`
+	Status   RunStatus     `json:"status"`
+	ExitCode int           `json:"exitCode"`
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"durationNs"`
+}
+
+func (r *RunReport) ExitCode() int {
+	switch r.Status {
+	case RunStatusPassed:
+		return RunExitPassed
+	case RunStatusFailed:
+		return RunExitFailed
+	default:
+		return RunExitError
+	}
+}
@@ -16,6 +16,7 @@ type LoadContextParams struct {
 	SkipIgnoreWarning bool
 	AutoLoaded        bool
 	SessionId         string
+	AllowGenerated    bool
 }
 
 type ContextOutdatedResult struct {
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// service accounts are stored as users so that plans, branches, locks, etc. can be owned by them like any other user -- they get a unique placeholder email on a reserved domain that can't be signed in to or auto-added to an org
+const serviceAccountDomain = "service-accounts.plandex.invalid"
+
+// how often last_used_at is written for a token -- avoids an update on every request
+const apiTokenLastUsedResolution = time.Minute
+
+type ServiceAccount struct {
+	Id        string    `db:"id"`
+	Name      string    `db:"name"`
+	OrgRoleId string    `db:"org_role_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (sa *ServiceAccount) ToApi() *shared.ServiceAccount {
+	return &shared.ServiceAccount{
+		Id:        sa.Id,
+		Name:      sa.Name,
+		OrgRoleId: sa.OrgRoleId,
+		CreatedAt: sa.CreatedAt,
+	}
+}
+
+func hashApiToken(token string) string {
+	hashBytes := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashBytes[:])
+}
+
+// CreateApiToken generates a new token, stores its hash, and returns the token itself -- it's only available here and can't be retrieved later
+func CreateApiToken(apiToken *ApiToken, tx *sqlx.Tx) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating api token: %v", err)
+	}
+
+	token := shared.ApiTokenPrefix + hex.EncodeToString(bytes)
+
+	apiToken.TokenHash = hashApiToken(token)
+	apiToken.TokenPrefix = token[:len(shared.ApiTokenPrefix)+8]
+
+	if apiToken.Permissions == nil {
+		apiToken.Permissions = pq.StringArray{}
+	}
+
+	query := `INSERT INTO api_tokens (org_id, user_id, creator_id, name, token_hash, token_prefix, permissions, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at`
+
+	err = tx.QueryRow(query, apiToken.OrgId, apiToken.UserId, apiToken.CreatorId, apiToken.Name, apiToken.TokenHash, apiToken.TokenPrefix, apiToken.Permissions, apiToken.ExpiresAt).Scan(&apiToken.Id, &apiToken.CreatedAt)
+
+	if err != nil {
+		return "", fmt.Errorf("error creating api token: %v", err)
+	}
+
+	return token, nil
+}
+
+func ValidateApiToken(token string) (*ApiToken, error) {
+	if !strings.HasPrefix(token, shared.ApiTokenPrefix) {
+		return nil, errors.New("invalid token")
+	}
+
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE token_hash = $1 AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())", hashApiToken(token))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Println("api token error - no rows found")
+			return nil, errors.New("invalid token")
+		}
+
+		return nil, fmt.Errorf("error validating api token: %v", err)
+	}
+
+	return &apiToken, nil
+}
+
+func TouchApiToken(apiToken *ApiToken) error {
+	if apiToken.LastUsedAt != nil && time.Since(*apiToken.LastUsedAt) < apiTokenLastUsedResolution {
+		return nil
+	}
+
+	_, err := Conn.Exec("UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1", apiToken.Id)
+
+	if err != nil {
+		return fmt.Errorf("error updating api token last used: %v", err)
+	}
+
+	return nil
+}
+
+func GetApiToken(orgId, id string) (*ApiToken, error) {
+	var apiToken ApiToken
+	err := Conn.Get(&apiToken, "SELECT * FROM api_tokens WHERE org_id = $1 AND id = $2 AND deleted_at IS NULL", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting api token: %v", err)
+	}
+
+	return &apiToken, nil
+}
+
+// ListApiTokens lists active tokens in an org that belong to any of the given users (including expired tokens, so they can be seen and cleaned up)
+func ListApiTokens(orgId string, userIds []string) ([]*ApiToken, error) {
+	var apiTokens []*ApiToken
+	err := Conn.Select(&apiTokens, "SELECT * FROM api_tokens WHERE org_id = $1 AND user_id = ANY($2) AND deleted_at IS NULL ORDER BY created_at", orgId, pq.Array(userIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing api tokens: %v", err)
+	}
+
+	return apiTokens, nil
+}
+
+func RevokeApiToken(id string) error {
+	_, err := Conn.Exec("UPDATE api_tokens SET deleted_at = NOW() WHERE id = $1", id)
+
+	if err != nil {
+		return fmt.Errorf("error revoking api token: %v", err)
+	}
+
+	return nil
+}
+
+func CreateServiceAccount(orgId, name, orgRoleId string, tx *sqlx.Tx) (*ServiceAccount, error) {
+	email := fmt.Sprintf("%s@%s", uuid.New().String(), serviceAccountDomain)
+
+	sa := ServiceAccount{
+		Name:      name,
+		OrgRoleId: orgRoleId,
+	}
+
+	err := tx.QueryRow("INSERT INTO users (name, email, domain, is_service_account) VALUES ($1, $2, $3, TRUE) RETURNING id, created_at", name, email, serviceAccountDomain).Scan(&sa.Id, &sa.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating service account: %v", err)
+	}
+
+	err = CreateOrgUser(orgId, sa.Id, orgRoleId, tx)
+
+	if err != nil {
+		return nil, fmt.Errorf("error adding service account to org: %v", err)
+	}
+
+	return &sa, nil
+}
+
+const serviceAccountQuery = `SELECT u.id, u.name, ou.org_role_id, u.created_at
+FROM users u
+JOIN orgs_users ou ON ou.user_id = u.id
+WHERE ou.org_id = $1 AND u.is_service_account`
+
+func ListServiceAccounts(orgId string) ([]*ServiceAccount, error) {
+	var serviceAccounts []*ServiceAccount
+	err := Conn.Select(&serviceAccounts, serviceAccountQuery+" ORDER BY u.name", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing service accounts: %v", err)
+	}
+
+	return serviceAccounts, nil
+}
+
+func GetServiceAccountByName(orgId, name string) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	err := Conn.Get(&sa, serviceAccountQuery+" AND u.name = $2", orgId, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting service account: %v", err)
+	}
+
+	return &sa, nil
+}
+
+func GetServiceAccount(orgId, id string) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	err := Conn.Get(&sa, serviceAccountQuery+" AND u.id = $2", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting service account: %v", err)
+	}
+
+	return &sa, nil
+}
+
+// DeleteServiceAccount removes a service account from the org and revokes its tokens. The user row is kept so that plans it created aren't deleted along with it.
+func DeleteServiceAccount(orgId, id string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE api_tokens SET deleted_at = NOW() WHERE org_id = $1 AND user_id = $2 AND deleted_at IS NULL", orgId, id)
+
+	if err != nil {
+		return fmt.Errorf("error revoking service account tokens: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM orgs_users WHERE org_id = $1 AND user_id = $2", orgId, id)
+
+	if err != nil {
+		return fmt.Errorf("error removing service account from org: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const maxAuditLogLimit = 1000
+
+func CreateAuditLogEntry(entry *AuditLogEntry) error {
+	query := `INSERT INTO audit_log (org_id, actor_id, actor_email, api_token_id, action, target_type, target_id, plan_id, before, after, client_ip, user_agent)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING id, seq, created_at`
+
+	err := Conn.QueryRow(query, entry.OrgId, entry.ActorId, entry.ActorEmail, entry.ApiTokenId, entry.Action, entry.TargetType, entry.TargetId, entry.PlanId, entry.Before, entry.After, entry.ClientIp, entry.UserAgent).Scan(&entry.Id, &entry.Seq, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating audit log entry: %v", err)
+	}
+
+	return nil
+}
+
+type ListAuditLogParams struct {
+	OrgId string
+
+	// exact actions, or categories like "plan" or "plan.*"
+	Actions []string
+
+	// matches the actor's id or email
+	Actor string
+
+	PlanId     string
+	TargetType string
+	TargetId   string
+	Since      *time.Time
+	Until      *time.Time
+
+	// seq cursors -- BeforeSeq pages back through older entries, AfterSeq picks up entries newer than a previous export
+	BeforeSeq int64
+	AfterSeq  int64
+
+	Limit int
+}
+
+// ListAuditLog returns matching entries newest first, and whether more entries matched than the limit
+func ListAuditLog(params ListAuditLogParams) ([]*AuditLogEntry, bool, error) {
+	conditions := []string{"org_id = $1"}
+	args := []interface{}{params.OrgId}
+
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(cond, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if len(params.Actions) > 0 {
+		var actionConds []string
+		for _, action := range params.Actions {
+			action = strings.TrimSuffix(action, ".*")
+			args = append(args, action, action+".%")
+			actionConds = append(actionConds, fmt.Sprintf("(action = $%d OR action LIKE $%d)", len(args)-1, len(args)))
+		}
+		conditions = append(conditions, "("+strings.Join(actionConds, " OR ")+")")
+	}
+
+	if params.Actor != "" {
+		addCondition("(actor_id::text = ? OR actor_email = LOWER(?))", params.Actor)
+	}
+	if params.PlanId != "" {
+		addCondition("plan_id::text = ?", params.PlanId)
+	}
+	if params.TargetType != "" {
+		addCondition("target_type = ?", params.TargetType)
+	}
+	if params.TargetId != "" {
+		addCondition("target_id = ?", params.TargetId)
+	}
+	if params.Since != nil {
+		addCondition("created_at >= ?", *params.Since)
+	}
+	if params.Until != nil {
+		addCondition("created_at < ?", *params.Until)
+	}
+	if params.BeforeSeq > 0 {
+		addCondition("seq < ?", params.BeforeSeq)
+	}
+	if params.AfterSeq > 0 {
+		addCondition("seq > ?", params.AfterSeq)
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > maxAuditLogLimit {
+		limit = maxAuditLogLimit
+	}
+
+	query := fmt.Sprintf("SELECT * FROM audit_log WHERE %s ORDER BY seq DESC LIMIT %d", strings.Join(conditions, " AND "), limit+1)
+
+	var entries []*AuditLogEntry
+	err := Conn.Select(&entries, query, args...)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("error listing audit log: %v", err)
+	}
+
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+
+	return entries, hasMore, nil
+}
+
+func ToApiAuditLogEntries(entries []*AuditLogEntry) []*shared.AuditLogEntry {
+	res := make([]*shared.AuditLogEntry, len(entries))
+	for i, entry := range entries {
+		res[i] = entry.ToApi()
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BuilderStrategyResult struct {
+	Strategy  shared.BuilderStrategy
+	Model     string
+	Success   bool
+	LatencyMs int64
+}
+
+// RecordBuilderStrategyResults adds the outcome of each strategy a build tried to the org's running totals
+func RecordBuilderStrategyResults(orgId, lang, sizeBucket string, results []BuilderStrategyResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO builder_strategy_stats (org_id, strategy, lang, model, size_bucket, attempts, successes, total_latency_ms)
+	VALUES ($1, $2, $3, $4, $5, 1, $6, $7)
+	ON CONFLICT (org_id, strategy, lang, model, size_bucket) DO UPDATE SET
+		attempts = builder_strategy_stats.attempts + 1,
+		successes = builder_strategy_stats.successes + EXCLUDED.successes,
+		total_latency_ms = builder_strategy_stats.total_latency_ms + EXCLUDED.total_latency_ms`
+
+	return WithTx(context.Background(), "record builder strategy results", func(tx *sqlx.Tx) error {
+		for _, result := range results {
+			successes := 0
+			if result.Success {
+				successes = 1
+			}
+
+			_, err := tx.Exec(query, orgId, result.Strategy, lang, result.Model, sizeBucket, successes, result.LatencyMs)
+			if err != nil {
+				return fmt.Errorf("error recording builder strategy result: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+func GetBuilderStrategyStats(orgId string) ([]*BuilderStrategyStats, error) {
+	var stats []*BuilderStrategyStats
+	err := Conn.Select(&stats, "SELECT * FROM builder_strategy_stats WHERE org_id = $1 ORDER BY lang, size_bucket, strategy, model", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting builder strategy stats: %v", err)
+	}
+
+	return stats, nil
+}
+
+func ToApiBuilderStrategyStats(stats []*BuilderStrategyStats) []*shared.BuilderStrategyStats {
+	res := make([]*shared.BuilderStrategyStats, len(stats))
+	for i, s := range stats {
+		res[i] = s.ToApi()
+	}
+	return res
+}
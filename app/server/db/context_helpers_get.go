@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+
+	shared "plandex-shared"
 )
 
 func GetPlanContexts(orgId, planId string, includeBody, includeMapParts bool) ([]*Context, error) {
@@ -108,7 +110,59 @@ func GetContext(orgId, planId, contextId string, includeBody, includeMapParts bo
 				return nil, fmt.Errorf("error unmarshalling context map parts file: %v", err)
 			}
 		}
+
+		mapDeps, err := GetContextMapDeps(orgId, planId, strings.TrimSuffix(contextId, ".meta"))
+		if err != nil {
+			return nil, err
+		}
+		context.MapDeps = mapDeps
 	}
 
 	return &context, nil
 }
+
+// GetContextMapDeps reads the imports and symbols extracted for each file in a map context. Maps stored before deps were extracted don't have any, so nil is returned without an error.
+func GetContextMapDeps(orgId, planId, contextId string) (shared.FileMapDepsByPath, error) {
+	contextDir := getPlanContextDir(orgId, planId)
+
+	mapDepsPath := filepath.Join(contextDir, contextId+".map-deps")
+	mapDepsBytes, err := os.ReadFile(mapDepsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading context map deps file: %v", err)
+	}
+
+	var mapDeps shared.FileMapDepsByPath
+	err = json.Unmarshal(mapDepsBytes, &mapDeps)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling context map deps file: %v", err)
+	}
+
+	return mapDeps, nil
+}
+
+// GetPlanMapDeps combines the deps of all the plan's map contexts so the graph spans every mapped directory
+func GetPlanMapDeps(orgId, planId string, contexts []*Context) (shared.FileMapDepsByPath, error) {
+	res := shared.FileMapDepsByPath{}
+	for _, context := range contexts {
+		if context.ContextType != shared.ContextMapType {
+			continue
+		}
+
+		mapDeps := context.MapDeps
+		if mapDeps == nil {
+			var err error
+			mapDeps, err = GetContextMapDeps(orgId, planId, context.Id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for path, deps := range mapDeps {
+			res[path] = deps
+		}
+	}
+	return res, nil
+}
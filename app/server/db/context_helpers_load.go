@@ -119,6 +119,19 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 		return nil, nil, fmt.Errorf("total context size is too large: %d", totalContextSize)
 	}
 
+	mapTokenBudget := planConfig.MapTokenBudgetFor(contextLoaderMaxTokens)
+
+	// maps too large for their budget are ranked toward the files in context
+	var mapFocusPaths []string
+	for _, context := range existingContexts {
+		if context.ContextType == shared.ContextFileType {
+			mapFocusPaths = append(mapFocusPaths, context.FilePath)
+		}
+	}
+	for path := range filesToLoad {
+		mapFocusPaths = append(mapFocusPaths, path)
+	}
+
 	existingContextsByName := make(map[string]bool)
 	for _, context := range existingContexts {
 		composite := strings.Join([]string{context.Name, string(context.ContextType)}, "|")
@@ -184,19 +197,39 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 			var mapShas map[string]string
 			var mapTokens map[string]int
 			var mapSizes map[string]int64
+			var mapDeps shared.FileMapDepsByPath
+			var mapChangedAt map[string]int64
+			var mapGenerated map[string]shared.GeneratedFileReason
 
 			if params.CachedMapsByPath != nil && params.CachedMapsByPath[contextParams.FilePath] != nil {
 				mapShas = params.CachedMapsByPath[contextParams.FilePath].MapShas
 				mapTokens = params.CachedMapsByPath[contextParams.FilePath].MapTokens
 				mapSizes = params.CachedMapsByPath[contextParams.FilePath].MapSizes
+				mapDeps = params.CachedMapsByPath[contextParams.FilePath].MapDeps
+				mapChangedAt = params.CachedMapsByPath[contextParams.FilePath].MapChangedAt
+				mapGenerated = params.CachedMapsByPath[contextParams.FilePath].MapGenerated
 			} else {
 				mapShas = contextParams.InputShas
 				mapTokens = contextParams.InputTokens
 				mapSizes = contextParams.InputSizes
+				mapDeps = contextParams.MapDeps
+				mapChangedAt = contextParams.InputChangedAt
+				mapGenerated = contextParams.InputGenerated
 			}
 
-			combinedBody := mappedFiles.CombinedMap(mapTokens)
-			numTokens = shared.GetNumTokensEstimate(combinedBody)
+			combinedBody, mapNumTokens, budgeted := shared.BudgetedMap(shared.FileMapBudgetParams{
+				Bodies:    mappedFiles,
+				Tokens:    mapTokens,
+				Deps:      mapDeps,
+				ChangedAt: mapChangedAt,
+				Focus:     mapFocusPaths,
+				MaxTokens: mapTokenBudget,
+			})
+			numTokens = mapNumTokens
+
+			if budgeted {
+				log.Printf("LoadContexts - map %s reduced to %d tokens to fit budget of %d", contextParams.FilePath, numTokens, mapTokenBudget)
+			}
 
 			autoLoaded = autoLoaded || contextParams.AutoLoaded
 
@@ -204,21 +237,25 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 
 			newContext := Context{
 				// Id generated by db layer
-				OrgId:       orgId,
-				OwnerId:     userId,
-				PlanId:      planId,
-				ProjectId:   plan.ProjectId,
-				ContextType: shared.ContextMapType,
-				Name:        contextParams.Name,
-				Url:         contextParams.Url,
-				FilePath:    contextParams.FilePath,
-				NumTokens:   numTokens,
-				Body:        combinedBody,
-				MapParts:    mappedFiles,
-				MapShas:     mapShas,
-				MapTokens:   mapTokens,
-				MapSizes:    mapSizes,
-				AutoLoaded:  autoLoaded || contextParams.AutoLoaded,
+				OrgId:        orgId,
+				OwnerId:      userId,
+				PlanId:       planId,
+				ProjectId:    plan.ProjectId,
+				ContextType:  shared.ContextMapType,
+				Name:         contextParams.Name,
+				Url:          contextParams.Url,
+				FilePath:     contextParams.FilePath,
+				NumTokens:    numTokens,
+				Body:         combinedBody,
+				MapParts:     mappedFiles,
+				MapShas:      mapShas,
+				MapTokens:    mapTokens,
+				MapSizes:     mapSizes,
+				MapDeps:      mapDeps,
+				MapChangedAt: mapChangedAt,
+				MapGenerated: mapGenerated,
+				MapBudgeted:  budgeted,
+				AutoLoaded:   autoLoaded || contextParams.AutoLoaded,
 			}
 
 			mapContextsByFilePath[contextParams.FilePath] = newContext
@@ -315,6 +352,7 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 					ForceSkipIgnore: loadParams.ForceSkipIgnore,
 					ImageDetail:     loadParams.ImageDetail,
 					AutoLoaded:      autoLoaded || loadParams.AutoLoaded,
+					GeneratedReason: loadParams.GeneratedReason,
 				}
 			}
 
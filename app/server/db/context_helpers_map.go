@@ -40,8 +40,48 @@ func GetCachedMap(orgId, projectId, filePath string) (*Context, error) {
 }
 
 type CachedMap struct {
-	MapParts  shared.FileMapBodies
-	MapShas   map[string]string
-	MapTokens map[string]int
-	MapSizes  map[string]int64
+	MapParts     shared.FileMapBodies
+	MapShas      map[string]string
+	MapTokens    map[string]int
+	MapSizes     map[string]int64
+	MapDeps      shared.FileMapDepsByPath
+	MapChangedAt map[string]int64
+	MapGenerated map[string]shared.GeneratedFileReason
+}
+
+// RefocusBudgetedMaps re-ranks maps that were reduced to fit their token budget toward the plan's current file contexts, since what's in context may have changed since the map was stored. Reduced maps are replaced in contexts with copies so the stored contexts aren't changed. Each map keeps roughly the token count it was stored with.
+func RefocusBudgetedMaps(orgId, planId string, contexts []*Context) error {
+	var focus []string
+	for _, context := range contexts {
+		if context.ContextType == shared.ContextFileType {
+			focus = append(focus, context.FilePath)
+		}
+	}
+
+	for i, context := range contexts {
+		if context.ContextType != shared.ContextMapType || !context.MapBudgeted {
+			continue
+		}
+
+		withParts, err := GetContext(orgId, planId, context.Id, false, true)
+		if err != nil {
+			return fmt.Errorf("error getting map parts: %v", err)
+		}
+
+		body, numTokens, _ := shared.BudgetedMap(shared.FileMapBudgetParams{
+			Bodies:    withParts.MapParts,
+			Tokens:    withParts.MapTokens,
+			Deps:      withParts.MapDeps,
+			ChangedAt: withParts.MapChangedAt,
+			Focus:     focus,
+			MaxTokens: context.NumTokens,
+		})
+
+		refocused := *context
+		refocused.Body = body
+		refocused.NumTokens = numTokens
+		contexts[i] = &refocused
+	}
+
+	return nil
 }
@@ -37,7 +37,7 @@ func contextRemove(params contextRemoveParams) error {
 	for _, context := range contexts {
 		filesToUpdate[context.FilePath] = ""
 		contextDir := getPlanContextDir(orgId, planId)
-		for _, ext := range []string{".meta", ".body", ".map-parts"} {
+		for _, ext := range []string{".meta", ".body", ".map-parts", ".map-deps"} {
 			numFiles++
 			go func(context *Context, dir, ext string) {
 				errCh <- os.Remove(filepath.Join(dir, context.Id+ext))
@@ -60,6 +60,19 @@ func StoreContext(context *Context, skipMapCache bool) error {
 		context.MapParts = nil
 	}
 
+	originalMapDeps := context.MapDeps
+	var mapDepsPath string
+	var mapDepsBytes []byte
+	if len(context.MapDeps) > 0 {
+		mapDepsFilename := context.Id + ".map-deps"
+		mapDepsPath = filepath.Join(contextDir, mapDepsFilename)
+		mapDepsBytes, err = json.Marshal(context.MapDeps)
+		if err != nil {
+			return fmt.Errorf("failed to marshal map deps: %v", err)
+		}
+		context.MapDeps = nil
+	}
+
 	// Convert the ModelContextPart to JSON
 	data, err := json.MarshalIndent(context, "", "  ")
 	if err != nil {
@@ -82,8 +95,15 @@ func StoreContext(context *Context, skipMapCache bool) error {
 		}
 	}
 
+	if mapDepsPath != "" {
+		if err = os.WriteFile(mapDepsPath, mapDepsBytes, 0644); err != nil {
+			return fmt.Errorf("failed to write context map deps to file %s: %v", mapDepsPath, err)
+		}
+	}
+
 	context.Body = originalBody
 	context.MapParts = originalMapParts
+	context.MapDeps = originalMapDeps
 
 	if mapPath != "" && !skipMapCache {
 		log.Println("StoreContext - context.MapParts length", len(context.MapParts))
@@ -104,16 +124,19 @@ func StoreContext(context *Context, skipMapCache bool) error {
 		log.Println("StoreContext - mapCachePath", mapCachePath)
 
 		cachedContext := Context{
-			ContextType: shared.ContextMapType,
-			FilePath:    context.FilePath,
-			Name:        context.Name,
-			Body:        context.Body,
-			NumTokens:   context.NumTokens,
-			MapParts:    context.MapParts,
-			MapShas:     context.MapShas,
-			MapTokens:   context.MapTokens,
-			MapSizes:    context.MapSizes,
-			UpdatedAt:   context.UpdatedAt,
+			ContextType:  shared.ContextMapType,
+			FilePath:     context.FilePath,
+			Name:         context.Name,
+			Body:         context.Body,
+			NumTokens:    context.NumTokens,
+			MapParts:     context.MapParts,
+			MapShas:      context.MapShas,
+			MapTokens:    context.MapTokens,
+			MapSizes:     context.MapSizes,
+			MapDeps:      context.MapDeps,
+			MapChangedAt: context.MapChangedAt,
+			MapGenerated: context.MapGenerated,
+			UpdatedAt:    context.UpdatedAt,
 		}
 
 		cachedContextBytes, err := json.MarshalIndent(cachedContext, "", "  ")
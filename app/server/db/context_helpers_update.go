@@ -58,17 +58,23 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	plannerMaxTokens := settings.GetPlannerEffectiveMaxTokens()
 	contextLoaderMaxTokens := settings.GetArchitectEffectiveMaxTokens()
 
-	if planConfig.AutoLoadContext {
-		existingContexts, err := GetPlanContexts(orgId, planId, false, false)
-		if err != nil {
-			return nil, fmt.Errorf("error getting existing contexts: %v", err)
+	existingContexts, err := GetPlanContexts(orgId, planId, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("error getting existing contexts: %v", err)
+	}
+
+	mapTokenBudget := planConfig.MapTokenBudgetFor(contextLoaderMaxTokens)
+
+	// maps too large for their budget are ranked toward the files in context
+	var mapFocusPaths []string
+	for _, context := range existingContexts {
+		if context.ContextType == shared.ContextFileType {
+			mapFocusPaths = append(mapFocusPaths, context.FilePath)
 		}
 
-		for _, context := range existingContexts {
-			if context.ContextType == shared.ContextMapType {
-				totalMapTokens += context.NumTokens
-				totalPlannerTokens -= context.NumTokens
-			}
+		if planConfig.AutoLoadContext && context.ContextType == shared.ContextMapType {
+			totalMapTokens += context.NumTokens
+			totalPlannerTokens -= context.NumTokens
 		}
 	}
 
@@ -259,6 +265,31 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 					context.MapShas[path] = params.InputShas[path]
 					context.MapTokens[path] = params.InputTokens[path]
 					context.MapSizes[path] = params.InputSizes[path]
+
+					if changedAt, ok := params.InputChangedAt[path]; ok {
+						if context.MapChangedAt == nil {
+							context.MapChangedAt = make(map[string]int64)
+						}
+						context.MapChangedAt[path] = changedAt
+					}
+
+					if reason := params.InputGenerated[path]; reason != "" {
+						if context.MapGenerated == nil {
+							context.MapGenerated = make(map[string]shared.GeneratedFileReason)
+						}
+						context.MapGenerated[path] = reason
+					} else {
+						delete(context.MapGenerated, path)
+					}
+
+					if deps := params.MapDeps[path]; deps != nil {
+						if context.MapDeps == nil {
+							context.MapDeps = make(shared.FileMapDepsByPath)
+						}
+						context.MapDeps[path] = deps
+					} else {
+						delete(context.MapDeps, path)
+					}
 				}
 
 				for _, path := range params.RemovedMapPaths {
@@ -266,6 +297,9 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 					delete(context.MapShas, path)
 					delete(context.MapTokens, path)
 					delete(context.MapSizes, path)
+					delete(context.MapDeps, path)
+					delete(context.MapChangedAt, path)
+					delete(context.MapGenerated, path)
 				}
 
 				if len(context.MapParts) > shared.MaxContextMapPaths {
@@ -282,8 +316,16 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 					return
 				}
 
-				context.Body = context.MapParts.CombinedMap(context.MapTokens)
-				newNumTokens := shared.GetNumTokensEstimate(context.Body)
+				body, newNumTokens, budgeted := shared.BudgetedMap(shared.FileMapBudgetParams{
+					Bodies:    context.MapParts,
+					Tokens:    context.MapTokens,
+					Deps:      context.MapDeps,
+					ChangedAt: context.MapChangedAt,
+					Focus:     mapFocusPaths,
+					MaxTokens: mapTokenBudget,
+				})
+				context.Body = body
+				context.MapBudgeted = budgeted
 				tokenDiff := newNumTokens - oldNumTokens
 
 				mu.Lock()
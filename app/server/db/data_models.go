@@ -5,6 +5,7 @@ import (
 
 	shared "plandex-shared"
 
+	"github.com/lib/pq"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -21,6 +22,41 @@ type AuthToken struct {
 	DeletedAt *time.Time `db:"deleted_at"`
 }
 
+type ApiToken struct {
+	Id          string         `db:"id"`
+	OrgId       string         `db:"org_id"`
+	UserId      string         `db:"user_id"`
+	CreatorId   string         `db:"creator_id"`
+	Name        string         `db:"name"`
+	TokenHash   string         `db:"token_hash"`
+	TokenPrefix string         `db:"token_prefix"`
+	Permissions pq.StringArray `db:"permissions"`
+	ExpiresAt   *time.Time     `db:"expires_at"`
+	LastUsedAt  *time.Time     `db:"last_used_at"`
+	CreatedAt   time.Time      `db:"created_at"`
+	DeletedAt   *time.Time     `db:"deleted_at"`
+}
+
+func (token *ApiToken) ToApi() *shared.ApiToken {
+	permissions := make([]shared.Permission, len(token.Permissions))
+	for i, p := range token.Permissions {
+		permissions[i] = shared.Permission(p)
+	}
+
+	return &shared.ApiToken{
+		Id:          token.Id,
+		OrgId:       token.OrgId,
+		UserId:      token.UserId,
+		CreatorId:   token.CreatorId,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Permissions: permissions,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
 type Org struct {
 	Id                 string  `db:"id"`
 	Name               string  `db:"name"`
@@ -49,6 +85,7 @@ type User struct {
 	Domain            string             `db:"domain"`
 	NumNonDraftPlans  int                `db:"num_non_draft_plans"`
 	DefaultPlanConfig *shared.PlanConfig `db:"default_plan_config"`
+	IsServiceAccount  bool               `db:"is_service_account"`
 	CreatedAt         time.Time          `db:"created_at"`
 	UpdatedAt         time.Time          `db:"updated_at"`
 }
@@ -98,6 +135,9 @@ type OrgUser struct {
 	UserId    string    `db:"user_id"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+
+	// set when the membership was provisioned by sso
+	SsoProviderId *string `db:"sso_provider_id"`
 }
 
 func (orgUser *OrgUser) ToApi() *shared.OrgUser {
@@ -154,6 +194,218 @@ func (plan *Plan) ToApi() *shared.Plan {
 	}
 }
 
+type AuditLogEntry struct {
+	Id         string                 `db:"id"`
+	Seq        int64                  `db:"seq"`
+	OrgId      string                 `db:"org_id"`
+	ActorId    string                 `db:"actor_id"`
+	ActorEmail string                 `db:"actor_email"`
+	ApiTokenId *string                `db:"api_token_id"`
+	Action     shared.AuditAction     `db:"action"`
+	TargetType shared.AuditTargetType `db:"target_type"`
+	TargetId   string                 `db:"target_id"`
+	PlanId     *string                `db:"plan_id"`
+	Before     string                 `db:"before"`
+	After      string                 `db:"after"`
+	ClientIp   string                 `db:"client_ip"`
+	UserAgent  string                 `db:"user_agent"`
+	CreatedAt  time.Time              `db:"created_at"`
+}
+
+func (entry *AuditLogEntry) ToApi() *shared.AuditLogEntry {
+	return &shared.AuditLogEntry{
+		Id:         entry.Id,
+		Seq:        entry.Seq,
+		OrgId:      entry.OrgId,
+		ActorId:    entry.ActorId,
+		ActorEmail: entry.ActorEmail,
+		ApiTokenId: entry.ApiTokenId,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetId:   entry.TargetId,
+		PlanId:     entry.PlanId,
+		Before:     entry.Before,
+		After:      entry.After,
+		ClientIp:   entry.ClientIp,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+type BuilderStrategyStats struct {
+	OrgId          string                 `db:"org_id"`
+	Strategy       shared.BuilderStrategy `db:"strategy"`
+	Lang           string                 `db:"lang"`
+	Model          string                 `db:"model"`
+	SizeBucket     string                 `db:"size_bucket"`
+	Attempts       int                    `db:"attempts"`
+	Successes      int                    `db:"successes"`
+	TotalLatencyMs int64                  `db:"total_latency_ms"`
+	CreatedAt      time.Time              `db:"created_at"`
+	UpdatedAt      time.Time              `db:"updated_at"`
+}
+
+func (stats *BuilderStrategyStats) ToApi() *shared.BuilderStrategyStats {
+	return &shared.BuilderStrategyStats{
+		Strategy:       stats.Strategy,
+		Lang:           stats.Lang,
+		Model:          stats.Model,
+		SizeBucket:     stats.SizeBucket,
+		Attempts:       stats.Attempts,
+		Successes:      stats.Successes,
+		TotalLatencyMs: stats.TotalLatencyMs,
+		UpdatedAt:      stats.UpdatedAt,
+	}
+}
+
+type PlanTemplate struct {
+	Id          string                     `db:"id"`
+	OrgId       string                     `db:"org_id"`
+	CreatorId   string                     `db:"creator_id"`
+	Name        string                     `db:"name"`
+	Description string                     `db:"description"`
+	Prompt      string                     `db:"prompt"`
+	Context     shared.PlanTemplateContext `db:"context"`
+	AutoMode    shared.AutoModeType        `db:"auto_mode"`
+	ModelPack   string                     `db:"model_pack"`
+	CreatedAt   time.Time                  `db:"created_at"`
+	UpdatedAt   time.Time                  `db:"updated_at"`
+}
+
+func (template *PlanTemplate) ToApi() *shared.PlanTemplate {
+	return &shared.PlanTemplate{
+		Id:          template.Id,
+		OrgId:       template.OrgId,
+		CreatorId:   template.CreatorId,
+		Name:        template.Name,
+		Description: template.Description,
+		Prompt:      template.Prompt,
+		Context:     template.Context,
+		AutoMode:    template.AutoMode,
+		ModelPack:   template.ModelPack,
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+}
+
+type Webhook struct {
+	Id          string         `db:"id"`
+	OrgId       string         `db:"org_id"`
+	ProjectId   *string        `db:"project_id"`
+	CreatorId   string         `db:"creator_id"`
+	Url         string         `db:"url"`
+	Description string         `db:"description"`
+	Secret      string         `db:"secret"`
+	Events      pq.StringArray `db:"events"`
+	Disabled    bool           `db:"disabled"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func (webhook *Webhook) ToApi() *shared.Webhook {
+	events := make([]shared.WebhookEvent, len(webhook.Events))
+	for i, event := range webhook.Events {
+		events[i] = shared.WebhookEvent(event)
+	}
+
+	return &shared.Webhook{
+		Id:          webhook.Id,
+		OrgId:       webhook.OrgId,
+		ProjectId:   webhook.ProjectId,
+		CreatorId:   webhook.CreatorId,
+		Url:         webhook.Url,
+		Description: webhook.Description,
+		Events:      events,
+		Disabled:    webhook.Disabled,
+		CreatedAt:   webhook.CreatedAt,
+		UpdatedAt:   webhook.UpdatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	Id             string                       `db:"id"`
+	WebhookId      string                       `db:"webhook_id"`
+	OrgId          string                       `db:"org_id"`
+	Event          shared.WebhookEvent          `db:"event"`
+	PlanId         *string                      `db:"plan_id"`
+	Payload        string                       `db:"payload"`
+	Status         shared.WebhookDeliveryStatus `db:"status"`
+	Attempts       int                          `db:"attempts"`
+	LastStatusCode *int                         `db:"last_status_code"`
+	LastError      string                       `db:"last_error"`
+	NextAttemptAt  *time.Time                   `db:"next_attempt_at"`
+	DeliveredAt    *time.Time                   `db:"delivered_at"`
+	CreatedAt      time.Time                    `db:"created_at"`
+	UpdatedAt      time.Time                    `db:"updated_at"`
+}
+
+func (delivery *WebhookDelivery) ToApi() *shared.WebhookDelivery {
+	return &shared.WebhookDelivery{
+		Id:             delivery.Id,
+		WebhookId:      delivery.WebhookId,
+		Event:          delivery.Event,
+		PlanId:         delivery.PlanId,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+type SsoIdentity struct {
+	Id           string    `db:"id"`
+	ProviderId   string    `db:"provider_id"`
+	Subject      string    `db:"subject"`
+	UserId       string    `db:"user_id"`
+	Email        string    `db:"email"`
+	LastSignInAt time.Time `db:"last_sign_in_at"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+type SsoRequest struct {
+	Id           string     `db:"id"`
+	ProviderId   string     `db:"provider_id"`
+	Kind         string     `db:"kind"`
+	TokenHash    string     `db:"token_hash"`
+	Nonce        string     `db:"nonce"`
+	CodeVerifier string     `db:"code_verifier"`
+	RedirectTo   string     `db:"redirect_to"`
+	DeviceCode   string     `db:"device_code"`
+	PollInterval int        `db:"poll_interval"`
+	LastPolledAt *time.Time `db:"last_polled_at"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	CompletedAt  *time.Time `db:"completed_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+type PlanShare struct {
+	Id        string                `db:"id"`
+	OrgId     string                `db:"org_id"`
+	PlanId    string                `db:"plan_id"`
+	UserId    *string               `db:"user_id"`
+	ProjectId *string               `db:"project_id"`
+	Level     shared.PlanShareLevel `db:"level"`
+	CreatedBy string                `db:"created_by"`
+	CreatedAt time.Time             `db:"created_at"`
+	UpdatedAt time.Time             `db:"updated_at"`
+}
+
+func (share *PlanShare) ToApi() *shared.PlanShare {
+	return &shared.PlanShare{
+		Id:        share.Id,
+		PlanId:    share.PlanId,
+		UserId:    share.UserId,
+		ProjectId: share.ProjectId,
+		Level:     share.Level,
+		CreatedBy: share.CreatedBy,
+		CreatedAt: share.CreatedAt,
+	}
+}
+
 type Branch struct {
 	Id              string            `db:"id"`
 	OrgId           string            `db:"org_id"`
@@ -384,28 +636,33 @@ type DefaultPlanSettings struct {
 // This allows us to store them in a git repo and use git to manage history.
 
 type Context struct {
-	Id              string                `json:"id"`
-	OrgId           string                `json:"orgId"`
-	OwnerId         string                `json:"ownerId"`
-	ProjectId       string                `json:"projectId"`
-	PlanId          string                `json:"planId"`
-	ContextType     shared.ContextType    `json:"contextType"`
-	Name            string                `json:"name"`
-	Url             string                `json:"url"`
-	FilePath        string                `json:"filePath"`
-	Sha             string                `json:"sha"`
-	NumTokens       int                   `json:"numTokens"`
-	Body            string                `json:"body,omitempty"`
-	BodySize        int64                 `json:"bodySize,omitempty"`
-	ForceSkipIgnore bool                  `json:"forceSkipIgnore"`
-	ImageDetail     openai.ImageURLDetail `json:"imageDetail,omitempty"`
-	MapParts        shared.FileMapBodies  `json:"mapParts,omitempty"`
-	MapShas         map[string]string     `json:"mapShas,omitempty"`
-	MapTokens       map[string]int        `json:"mapTokens,omitempty"`
-	MapSizes        map[string]int64      `json:"mapSizes,omitempty"`
-	AutoLoaded      bool                  `json:"autoLoaded"`
-	CreatedAt       time.Time             `json:"createdAt"`
-	UpdatedAt       time.Time             `json:"updatedAt"`
+	Id              string                                `json:"id"`
+	OrgId           string                                `json:"orgId"`
+	OwnerId         string                                `json:"ownerId"`
+	ProjectId       string                                `json:"projectId"`
+	PlanId          string                                `json:"planId"`
+	ContextType     shared.ContextType                    `json:"contextType"`
+	Name            string                                `json:"name"`
+	Url             string                                `json:"url"`
+	FilePath        string                                `json:"filePath"`
+	Sha             string                                `json:"sha"`
+	NumTokens       int                                   `json:"numTokens"`
+	Body            string                                `json:"body,omitempty"`
+	BodySize        int64                                 `json:"bodySize,omitempty"`
+	ForceSkipIgnore bool                                  `json:"forceSkipIgnore"`
+	ImageDetail     openai.ImageURLDetail                 `json:"imageDetail,omitempty"`
+	MapParts        shared.FileMapBodies                  `json:"mapParts,omitempty"`
+	MapShas         map[string]string                     `json:"mapShas,omitempty"`
+	MapTokens       map[string]int                        `json:"mapTokens,omitempty"`
+	MapSizes        map[string]int64                      `json:"mapSizes,omitempty"`
+	MapDeps         shared.FileMapDepsByPath              `json:"mapDeps,omitempty"`
+	MapChangedAt    map[string]int64                      `json:"mapChangedAt,omitempty"`
+	MapGenerated    map[string]shared.GeneratedFileReason `json:"mapGenerated,omitempty"`
+	MapBudgeted     bool                                  `json:"mapBudgeted,omitempty"` // true when the map was too large for its token budget and Body is ranked and reduced
+	AutoLoaded      bool                                  `json:"autoLoaded"`
+	GeneratedReason shared.GeneratedFileReason            `json:"generatedReason,omitempty"`
+	CreatedAt       time.Time                             `json:"createdAt"`
+	UpdatedAt       time.Time                             `json:"updatedAt"`
 }
 
 func (context *Context) ToMeta() *Context {
@@ -429,6 +686,10 @@ func (context *Context) ToMeta() *Context {
 		MapShas:         context.MapShas,
 		MapTokens:       context.MapTokens,
 		MapSizes:        context.MapSizes,
+		MapChangedAt:    context.MapChangedAt,
+		MapGenerated:    context.MapGenerated,
+		MapBudgeted:     context.MapBudgeted,
+		GeneratedReason: context.GeneratedReason,
 		CreatedAt:       context.CreatedAt,
 		UpdatedAt:       context.UpdatedAt,
 	}
@@ -453,6 +714,7 @@ func (context *Context) ToApi() *shared.Context {
 		MapShas:         context.MapShas,
 		MapTokens:       context.MapTokens,
 		MapSizes:        context.MapSizes,
+		GeneratedReason: context.GeneratedReason,
 		CreatedAt:       context.CreatedAt,
 		UpdatedAt:       context.UpdatedAt,
 	}
@@ -17,6 +17,9 @@ import (
 
 var Conn *sqlx.DB
 
+// ConnUrl is the connection url used for Conn, kept so that features needing a dedicated connection (like LISTEN/NOTIFY) can open their own
+var ConnUrl string
+
 const LockTimeout = 4000
 const IdleInTransactionSessionTimeout = 90000
 const StatementTimeout = 30000
@@ -47,6 +50,8 @@ func Connect() error {
 		dbUrl += fmt.Sprintf("?statement_timeout=%d&lock_timeout=%d&timezone=UTC&idle_in_transaction_session_timeout=%d", StatementTimeout, LockTimeout, IdleInTransactionSessionTimeout)
 	}
 
+	ConnUrl = dbUrl
+
 	Conn, err = sqlx.Connect("postgres", dbUrl)
 	if err != nil {
 		return err
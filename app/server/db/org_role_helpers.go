@@ -0,0 +1,186 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// the per-role permissions that are created along with a custom role and granted to the default owner and admin roles, so custom roles can be managed like the built-in ones
+var customRoleResourcePermissions = []struct {
+	name        shared.Permission
+	description string
+}{
+	{shared.PermissionInviteUser, "Invite users with the %s role to an org"},
+	{shared.PermissionRemoveUser, "Remove users with the %s role from an org"},
+	{shared.PermissionSetUserRole, "Update the role of users with the %s role in an org"},
+}
+
+func GetOrgRole(orgId, roleId string) (*OrgRole, error) {
+	var role OrgRole
+	err := Conn.Get(&role, "SELECT * FROM org_roles WHERE id = $1 AND (org_id IS NULL OR org_id = $2)", roleId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &role, nil
+}
+
+// ValidateOrgRole checks that a role is either a default role or a custom role belonging to the org
+func ValidateOrgRole(orgId, roleId string) (bool, error) {
+	role, err := GetOrgRole(orgId, roleId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return role != nil, nil
+}
+
+// GetOrgRolePermissions returns the permissions of each role, in the same name|resourceId format as GetUserPermissions
+func GetOrgRolePermissions(roleIds []string) (map[string][]shared.Permission, error) {
+	var rows []struct {
+		OrgRoleId  string         `db:"org_role_id"`
+		Name       string         `db:"name"`
+		ResourceId sql.NullString `db:"resource_id"`
+	}
+
+	query := `SELECT orp.org_role_id, p.name, p.resource_id
+	FROM org_roles_permissions orp
+	JOIN permissions p ON p.id = orp.permission_id
+	WHERE orp.org_role_id = ANY($1)
+	ORDER BY p.name`
+
+	err := Conn.Select(&rows, query, pq.Array(roleIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting org role permissions: %v", err)
+	}
+
+	res := map[string][]shared.Permission{}
+	for _, row := range rows {
+		permission := row.Name
+		if row.ResourceId.Valid {
+			permission = permission + "|" + row.ResourceId.String
+		}
+		res[row.OrgRoleId] = append(res[row.OrgRoleId], shared.Permission(permission))
+	}
+
+	return res, nil
+}
+
+func CountOrgRoleMembers(roleId string) (int, error) {
+	var count int
+	err := Conn.Get(&count, "SELECT (SELECT COUNT(*) FROM orgs_users WHERE org_role_id = $1) + (SELECT COUNT(*) FROM invites WHERE org_role_id = $1 AND accepted_at IS NULL)", roleId)
+
+	if err != nil {
+		return 0, fmt.Errorf("error counting org role members: %v", err)
+	}
+
+	return count, nil
+}
+
+func OrgRoleNameForLabel(label string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(label)), " ", "_")
+}
+
+func CreateOrgRole(role *OrgRole, permissions []shared.Permission, tx *sqlx.Tx) error {
+	err := tx.QueryRow("INSERT INTO org_roles (org_id, name, label, description) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at", role.OrgId, role.Name, role.Label, role.Description).Scan(&role.Id, &role.CreatedAt, &role.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating org role: %v", err)
+	}
+
+	for _, p := range customRoleResourcePermissions {
+		var permissionId string
+		err = tx.QueryRow("INSERT INTO permissions (name, description, resource_id) VALUES ($1, $2, $3) RETURNING id", p.name, fmt.Sprintf(p.description, role.Label), role.Id).Scan(&permissionId)
+
+		if err != nil {
+			return fmt.Errorf("error creating %s permission for org role: %v", p.name, err)
+		}
+
+		_, err = tx.Exec(`INSERT INTO org_roles_permissions (org_role_id, permission_id)
+		SELECT id, $1 FROM org_roles WHERE org_id IS NULL AND name IN ('owner', 'admin')`, permissionId)
+
+		if err != nil {
+			return fmt.Errorf("error granting %s permission for org role: %v", p.name, err)
+		}
+	}
+
+	err = setOrgRolePermissions(role.Id, permissions, tx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func UpdateOrgRole(role *OrgRole, permissions []shared.Permission, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE org_roles SET name = $1, label = $2, description = $3 WHERE id = $4", role.Name, role.Label, role.Description, role.Id)
+
+	if err != nil {
+		return fmt.Errorf("error updating org role: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles_permissions WHERE org_role_id = $1", role.Id)
+
+	if err != nil {
+		return fmt.Errorf("error clearing org role permissions: %v", err)
+	}
+
+	err = setOrgRolePermissions(role.Id, permissions, tx)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteOrgRole deletes a custom role along with the per-role permissions created for it. Callers should check that no users or invites still have the role.
+func DeleteOrgRole(roleId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("DELETE FROM permissions WHERE resource_id = $1", roleId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org role permissions: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles WHERE id = $1 AND org_id IS NOT NULL", roleId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org role: %v", err)
+	}
+
+	return nil
+}
+
+func setOrgRolePermissions(roleId string, permissions []shared.Permission, tx *sqlx.Tx) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = string(p)
+	}
+
+	_, err := tx.Exec(`INSERT INTO org_roles_permissions (org_role_id, permission_id)
+	SELECT $1, id FROM permissions WHERE name = ANY($2) AND resource_id IS NULL`, roleId, pq.Array(names))
+
+	if err != nil {
+		return fmt.Errorf("error setting org role permissions: %v", err)
+	}
+
+	return nil
+}
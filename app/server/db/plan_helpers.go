@@ -435,6 +435,17 @@ func ValidatePlanAccess(planId, userId, orgId string) (*Plan, error) {
 		return plan, nil
 	}
 
+	// plan is shared with the user or their project -- the share level is checked by the handler
+	level, err := GetPlanShareLevel(plan, userId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting plan share level: %v", err)
+	}
+
+	if level != "" {
+		return plan, nil
+	}
+
 	return nil, nil
 }
 
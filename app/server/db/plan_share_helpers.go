@@ -0,0 +1,137 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+
+	"github.com/lib/pq"
+)
+
+// UpsertPlanShare creates a share, or updates the level of an existing share for the same user or project
+func UpsertPlanShare(share *PlanShare) error {
+	var query string
+	var args []interface{}
+
+	if share.UserId != nil {
+		query = `INSERT INTO plan_shares (org_id, plan_id, user_id, level, created_by) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (plan_id, user_id) WHERE user_id IS NOT NULL DO UPDATE SET level = EXCLUDED.level
+		RETURNING id, created_at, updated_at`
+		args = []interface{}{share.OrgId, share.PlanId, *share.UserId, share.Level, share.CreatedBy}
+	} else if share.ProjectId != nil {
+		query = `INSERT INTO plan_shares (org_id, plan_id, project_id, level, created_by) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (plan_id, project_id) WHERE project_id IS NOT NULL DO UPDATE SET level = EXCLUDED.level
+		RETURNING id, created_at, updated_at`
+		args = []interface{}{share.OrgId, share.PlanId, *share.ProjectId, share.Level, share.CreatedBy}
+	} else {
+		return fmt.Errorf("plan share needs a user or project")
+	}
+
+	err := Conn.QueryRow(query, args...).Scan(&share.Id, &share.CreatedAt, &share.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error upserting plan share: %v", err)
+	}
+
+	return nil
+}
+
+func ListPlanShares(planId string) ([]*PlanShare, error) {
+	var shares []*PlanShare
+	err := Conn.Select(&shares, "SELECT * FROM plan_shares WHERE plan_id = $1 ORDER BY created_at", planId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan shares: %v", err)
+	}
+
+	return shares, nil
+}
+
+func GetPlanShare(planId, id string) (*PlanShare, error) {
+	var share PlanShare
+	err := Conn.Get(&share, "SELECT * FROM plan_shares WHERE plan_id = $1 AND id = $2", planId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting plan share: %v", err)
+	}
+
+	return &share, nil
+}
+
+func DeletePlanShare(id string) error {
+	_, err := Conn.Exec("DELETE FROM plan_shares WHERE id = $1", id)
+
+	if err != nil {
+		return fmt.Errorf("error deleting plan share: %v", err)
+	}
+
+	return nil
+}
+
+// GetPlanShareLevel returns the highest level the plan is shared with the user at, either directly or through its project -- empty if it isn't shared with them
+func GetPlanShareLevel(plan *Plan, userId string) (shared.PlanShareLevel, error) {
+	var levels []shared.PlanShareLevel
+	err := Conn.Select(&levels, "SELECT level FROM plan_shares WHERE plan_id = $1 AND (user_id = $2 OR project_id = $3)", plan.Id, userId, plan.ProjectId)
+
+	if err != nil {
+		return "", fmt.Errorf("error getting plan share level: %v", err)
+	}
+
+	return maxPlanShareLevel(levels), nil
+}
+
+// ListSharedPlans lists unarchived plans in the given projects that other users have shared with the user, along with the level each is shared at
+func ListSharedPlans(projectIds []string, userId string) ([]*Plan, map[string]shared.PlanShareLevel, error) {
+	var rows []struct {
+		Plan
+		ShareLevel shared.PlanShareLevel `db:"share_level"`
+	}
+
+	query := `SELECT p.*, s.level AS share_level
+	FROM plans p
+	JOIN plan_shares s ON s.plan_id = p.id
+	WHERE p.project_id = ANY($1)
+	AND p.owner_id != $2
+	AND p.archived_at IS NULL
+	AND (s.user_id = $2 OR s.project_id = p.project_id)
+	ORDER BY p.updated_at DESC`
+
+	err := Conn.Select(&rows, query, pq.Array(projectIds), userId)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing shared plans: %v", err)
+	}
+
+	var plans []*Plan
+	levelsByPlanId := map[string][]shared.PlanShareLevel{}
+	for i := range rows {
+		row := rows[i]
+		if _, ok := levelsByPlanId[row.Id]; !ok {
+			plan := row.Plan
+			plans = append(plans, &plan)
+		}
+		levelsByPlanId[row.Id] = append(levelsByPlanId[row.Id], row.ShareLevel)
+	}
+
+	levelByPlanId := map[string]shared.PlanShareLevel{}
+	for planId, levels := range levelsByPlanId {
+		levelByPlanId[planId] = maxPlanShareLevel(levels)
+	}
+
+	return plans, levelByPlanId, nil
+}
+
+func maxPlanShareLevel(levels []shared.PlanShareLevel) shared.PlanShareLevel {
+	var res shared.PlanShareLevel
+	for _, level := range levels {
+		if res == "" || (level.Includes(res) && level != res) {
+			res = level
+		}
+	}
+	return res
+}
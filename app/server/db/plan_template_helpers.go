@@ -0,0 +1,79 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func CreatePlanTemplate(template *PlanTemplate) error {
+	query := `INSERT INTO plan_templates (org_id, creator_id, name, description, prompt, context, auto_mode, model_pack)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at, updated_at`
+
+	err := Conn.QueryRow(query, template.OrgId, template.CreatorId, template.Name, template.Description, template.Prompt, template.Context, template.AutoMode, template.ModelPack).Scan(&template.Id, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating plan template: %v", err)
+	}
+
+	return nil
+}
+
+func ListPlanTemplates(orgId string) ([]*PlanTemplate, error) {
+	var templates []*PlanTemplate
+	err := Conn.Select(&templates, "SELECT * FROM plan_templates WHERE org_id = $1 ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan templates: %v", err)
+	}
+
+	return templates, nil
+}
+
+func GetPlanTemplate(orgId, templateId string) (*PlanTemplate, error) {
+	var template PlanTemplate
+	err := Conn.Get(&template, "SELECT * FROM plan_templates WHERE id = $1 AND org_id = $2", templateId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan template: %v", err)
+	}
+
+	return &template, nil
+}
+
+func GetPlanTemplateByName(orgId, name string) (*PlanTemplate, error) {
+	var template PlanTemplate
+	err := Conn.Get(&template, "SELECT * FROM plan_templates WHERE name = $1 AND org_id = $2", name, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting plan template: %v", err)
+	}
+
+	return &template, nil
+}
+
+func UpdatePlanTemplate(template *PlanTemplate) error {
+	_, err := Conn.Exec("UPDATE plan_templates SET name = $1, description = $2, prompt = $3, context = $4, auto_mode = $5, model_pack = $6 WHERE id = $7 AND org_id = $8", template.Name, template.Description, template.Prompt, template.Context, template.AutoMode, template.ModelPack, template.Id, template.OrgId)
+
+	if err != nil {
+		return fmt.Errorf("error updating plan template: %v", err)
+	}
+
+	return nil
+}
+
+func DeletePlanTemplate(orgId, templateId string) error {
+	_, err := Conn.Exec("DELETE FROM plan_templates WHERE id = $1 AND org_id = $2", templateId, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting plan template: %v", err)
+	}
+
+	return nil
+}
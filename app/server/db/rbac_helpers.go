@@ -74,7 +74,7 @@ func CacheOrgRoleIds() error {
 
 func cacheOrgOwnerRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'owner'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'owner'")
 
 	if err != nil {
 		return fmt.Errorf("error getting owner role id: %v", err)
@@ -87,7 +87,7 @@ func cacheOrgOwnerRoleId() error {
 
 func cacheOrgMemberRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'member'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member'")
 
 	if err != nil {
 		return fmt.Errorf("error getting member role id: %v", err)
@@ -438,6 +438,8 @@ type ApplyPlanParams struct {
 	CurrentPlanState       *shared.CurrentPlanState
 	CurrentPlanStateParams *CurrentPlanStateParams
 	CommitMsg              string
+	// bodies the client formatted before writing them to disk, by path
+	FormattedFiles map[string]string
 }
 
 func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error {
@@ -450,6 +452,14 @@ func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error
 	planId := plan.Id
 	resultsDir := getPlanResultsDir(orgId, planId)
 
+	// context for applied files should match what the client wrote to disk, so formatted bodies take precedence over the built ones
+	appliedBody := func(path string) string {
+		if body, ok := params.FormattedFiles[path]; ok {
+			return body
+		}
+		return currentPlanState.CurrentPlanFiles.Files[path]
+	}
+
 	var pendingDbResults []*PlanFileResult
 
 	planFileResults := currentPlanParams.PlanFileResults
@@ -538,7 +548,7 @@ func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error
 					ContextType: shared.ContextFileType,
 					Name:        path,
 					FilePath:    path,
-					Body:        currentPlanState.CurrentPlanFiles.Files[path],
+					Body:        appliedBody(path),
 				})
 			}
 
@@ -574,7 +584,7 @@ func ApplyPlan(repo *GitRepo, ctx context.Context, params ApplyPlanParams) error
 			for path := range pendingUpdatedFilesSet {
 				context := contextsByPath[path]
 				updateReq[context.Id] = &shared.UpdateContextParams{
-					Body: currentPlanState.CurrentPlanFiles.Files[path],
+					Body: appliedBody(path),
 				}
 			}
 
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	SsoRequestKindRedirect = "redirect"
+	SsoRequestKindDevice   = "device"
+)
+
+func hashSsoToken(token string) string {
+	hashBytes := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashBytes[:])
+}
+
+// CreateSsoRequest stores a pending sign in and returns the token that identifies it -- the state param for redirects, or the poll token for device sign ins
+func CreateSsoRequest(req *SsoRequest) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating sso request token: %v", err)
+	}
+
+	token := hex.EncodeToString(bytes)
+	req.TokenHash = hashSsoToken(token)
+
+	query := `INSERT INTO sso_requests (provider_id, kind, token_hash, nonce, code_verifier, redirect_to, device_code, poll_interval, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at`
+
+	err = Conn.QueryRow(query, req.ProviderId, req.Kind, req.TokenHash, req.Nonce, req.CodeVerifier, req.RedirectTo, req.DeviceCode, req.PollInterval, req.ExpiresAt).Scan(&req.Id, &req.CreatedAt)
+
+	if err != nil {
+		return "", fmt.Errorf("error creating sso request: %v", err)
+	}
+
+	return token, nil
+}
+
+// GetPendingSsoRequest returns an unexpired, uncompleted request for a token, or nil
+func GetPendingSsoRequest(token, kind string) (*SsoRequest, error) {
+	var req SsoRequest
+	err := Conn.Get(&req, "SELECT * FROM sso_requests WHERE token_hash = $1 AND kind = $2 AND completed_at IS NULL AND expires_at > NOW()", hashSsoToken(token), kind)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting sso request: %v", err)
+	}
+
+	return &req, nil
+}
+
+func TouchSsoRequestPoll(id string, pollInterval int) error {
+	_, err := Conn.Exec("UPDATE sso_requests SET last_polled_at = NOW(), poll_interval = $2 WHERE id = $1", id, pollInterval)
+
+	if err != nil {
+		return fmt.Errorf("error updating sso request: %v", err)
+	}
+
+	return nil
+}
+
+// CompleteSsoRequest marks a request as used -- returns false if it was already completed, so each request can only sign in once
+func CompleteSsoRequest(id string, tx *sqlx.Tx) (bool, error) {
+	res, err := tx.Exec("UPDATE sso_requests SET completed_at = NOW() WHERE id = $1 AND completed_at IS NULL", id)
+
+	if err != nil {
+		return false, fmt.Errorf("error completing sso request: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error completing sso request: %v", err)
+	}
+
+	return n > 0, nil
+}
+
+func GetSsoIdentity(providerId, subject string) (*SsoIdentity, error) {
+	var identity SsoIdentity
+	err := Conn.Get(&identity, "SELECT * FROM sso_identities WHERE provider_id = $1 AND subject = $2", providerId, subject)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting sso identity: %v", err)
+	}
+
+	return &identity, nil
+}
+
+func UpsertSsoIdentity(providerId, subject, userId, email string, tx *sqlx.Tx) error {
+	query := `INSERT INTO sso_identities (provider_id, subject, user_id, email) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (provider_id, subject) DO UPDATE SET email = EXCLUDED.email, last_sign_in_at = NOW()`
+
+	_, err := tx.Exec(query, providerId, subject, userId, email)
+
+	if err != nil {
+		return fmt.Errorf("error upserting sso identity: %v", err)
+	}
+
+	return nil
+}
+
+// GetOrgByName returns the oldest org with a name, or nil
+func GetOrgByName(name string) (*Org, error) {
+	var org Org
+	query := fmt.Sprintf("SELECT %s FROM orgs WHERE name = $1 ORDER BY created_at LIMIT 1", orgFields)
+	err := Conn.Get(&org, query, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting org by name: %v", err)
+	}
+
+	return &org, nil
+}
+
+func CreateSsoOrgUser(orgId, userId, orgRoleId, providerId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("INSERT INTO orgs_users (org_id, user_id, org_role_id, sso_provider_id) VALUES ($1, $2, $3, $4)", orgId, userId, orgRoleId, providerId)
+
+	if err != nil {
+		return fmt.Errorf("error adding sso org member: %v", err)
+	}
+
+	return nil
+}
+
+func SetOrgUserRole(orgId, userId, orgRoleId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE orgs_users SET org_role_id = $3 WHERE org_id = $1 AND user_id = $2", orgId, userId, orgRoleId)
+
+	if err != nil {
+		return fmt.Errorf("error updating org member role: %v", err)
+	}
+
+	return nil
+}
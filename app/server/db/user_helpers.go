@@ -94,7 +94,8 @@ func ListUsers(orgId string) ([]*User, error) {
 		userIds[i] = ou.UserId
 	}
 
-	err = Conn.Select(&users, "SELECT * FROM users WHERE id = ANY($1)", pq.Array(userIds))
+	// service accounts are listed separately
+	err = Conn.Select(&users, "SELECT * FROM users WHERE id = ANY($1) AND NOT is_service_account", pq.Array(userIds))
 
 	if err != nil {
 		return nil, fmt.Errorf("error listing users: %v", err)
@@ -103,6 +104,22 @@ func ListUsers(orgId string) ([]*User, error) {
 	return users, nil
 }
 
+func GetUsersById(userIds []string) (map[string]*User, error) {
+	var users []*User
+	err := Conn.Select(&users, "SELECT * FROM users WHERE id = ANY($1)", pq.Array(userIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting users: %v", err)
+	}
+
+	usersById := make(map[string]*User, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	return usersById, nil
+}
+
 func CreateUser(name, email string, tx *sqlx.Tx) (*User, error) {
 	emailSplit := strings.Split(email, "@")
 	if len(emailSplit) != 2 {
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/lib/pq"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+// deliveries are kept for this long so that failures can be investigated
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+// CreateWebhook generates the webhook's signing secret and returns it
+func CreateWebhook(webhook *Webhook) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %v", err)
+	}
+
+	webhook.Secret = webhookSecretPrefix + hex.EncodeToString(bytes)
+
+	if webhook.Events == nil {
+		webhook.Events = pq.StringArray{}
+	}
+
+	query := `INSERT INTO webhooks (org_id, project_id, creator_id, url, description, secret, events)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at`
+
+	err = Conn.QueryRow(query, webhook.OrgId, webhook.ProjectId, webhook.CreatorId, webhook.Url, webhook.Description, webhook.Secret, webhook.Events).Scan(&webhook.Id, &webhook.CreatedAt, &webhook.UpdatedAt)
+
+	if err != nil {
+		return "", fmt.Errorf("error creating webhook: %v", err)
+	}
+
+	return webhook.Secret, nil
+}
+
+func ListWebhooks(orgId string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	err := Conn.Select(&webhooks, "SELECT * FROM webhooks WHERE org_id = $1 ORDER BY created_at", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+func GetWebhook(orgId, webhookId string) (*Webhook, error) {
+	var webhook Webhook
+	err := Conn.Get(&webhook, "SELECT * FROM webhooks WHERE id = $1 AND org_id = $2", webhookId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting webhook: %v", err)
+	}
+
+	return &webhook, nil
+}
+
+func UpdateWebhook(webhook *Webhook) error {
+	_, err := Conn.Exec("UPDATE webhooks SET url = $1, description = $2, events = $3, disabled = $4 WHERE id = $5 AND org_id = $6", webhook.Url, webhook.Description, webhook.Events, webhook.Disabled, webhook.Id, webhook.OrgId)
+
+	if err != nil {
+		return fmt.Errorf("error updating webhook: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteWebhook(orgId, webhookId string) error {
+	_, err := Conn.Exec("DELETE FROM webhooks WHERE id = $1 AND org_id = $2", webhookId, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	return nil
+}
+
+// ListWebhooksForEvent returns the enabled webhooks subscribed to an event, either org-wide or for the given project
+func ListWebhooksForEvent(orgId, projectId string, event shared.WebhookEvent) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	err := Conn.Select(&webhooks, "SELECT * FROM webhooks WHERE org_id = $1 AND NOT disabled AND (project_id IS NULL OR project_id::text = $2) AND $3 = ANY(events)", orgId, projectId, string(event))
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks for event: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+func CreateWebhookDelivery(delivery *WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (webhook_id, org_id, event, plan_id, payload)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, status, next_attempt_at, created_at, updated_at`
+
+	err := Conn.QueryRow(query, delivery.WebhookId, delivery.OrgId, delivery.Event, delivery.PlanId, delivery.Payload).Scan(&delivery.Id, &delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+func ListWebhookDeliveries(orgId, webhookId string, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	err := Conn.Select(&deliveries, "SELECT * FROM webhook_deliveries WHERE org_id = $1 AND webhook_id = $2 ORDER BY created_at DESC LIMIT $3", orgId, webhookId, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+type DueWebhookDelivery struct {
+	WebhookDelivery
+	Url    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// ClaimDueWebhookDeliveries returns pending deliveries that are due, along with their webhook's url and secret. Claimed deliveries have their next attempt pushed back by lease so that other hosts don't send them at the same time.
+func ClaimDueWebhookDeliveries(limit int, lease time.Duration) ([]*DueWebhookDelivery, error) {
+	var deliveries []*DueWebhookDelivery
+
+	query := `WITH due AS (
+		SELECT d.id
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = $1 AND d.next_attempt_at <= NOW() AND NOT w.disabled
+		ORDER BY d.next_attempt_at
+		LIMIT $2
+		FOR UPDATE OF d SKIP LOCKED
+	)
+	UPDATE webhook_deliveries d
+	SET next_attempt_at = NOW() + make_interval(secs => $3)
+	FROM due, webhooks w
+	WHERE d.id = due.id AND w.id = d.webhook_id
+	RETURNING d.*, w.url, w.secret`
+
+	err := Conn.Select(&deliveries, query, shared.WebhookDeliveryStatusPending, limit, lease.Seconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookDeliveryAttempt stores the result of an attempt. nextAttemptAt is nil once the delivery succeeds or runs out of retries.
+func RecordWebhookDeliveryAttempt(delivery *WebhookDelivery) error {
+	_, err := Conn.Exec("UPDATE webhook_deliveries SET status = $1, attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5, delivered_at = $6 WHERE id = $7", delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.Id)
+
+	if err != nil {
+		return fmt.Errorf("error recording webhook delivery attempt: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteExpiredWebhookDeliveries() error {
+	_, err := Conn.Exec("DELETE FROM webhook_deliveries WHERE created_at < $1", time.Now().Add(-webhookDeliveryRetention))
+
+	if err != nil {
+		return fmt.Errorf("error deleting expired webhook deliveries: %v", err)
+	}
+
+	return nil
+}
@@ -1,96 +1,189 @@
 package email
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"net/smtp"
+	"log"
+	"mime"
+	"mime/quotedprintable"
 	"os"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To       string
+	Subject  string
+	HtmlBody string
+	TextBody string
+}
+
+// Provider sends rendered messages -- set with EMAIL_PROVIDER
+type Provider interface {
+	Name() string
+	Send(from string, msg *Message) error
+}
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ses"
+const (
+	ProviderSES    = "ses"
+	ProviderSMTP   = "smtp"
+	ProviderOutbox = "outbox"
+	ProviderNone   = "none"
 )
 
-// SendEmailViaSES sends an email using AWS SES
-func SendEmailViaSES(recipient, subject, htmlBody, textBody string) error {
-	sess, err := session.NewSession()
-	if err != nil {
-		return fmt.Errorf("error creating AWS session: %v", err)
+const defaultSESFrom = "Plandex <support@plandex.ai>"
+
+var provider Provider
+
+// Init selects the email provider and loads any template overrides. baseDir is the server's base directory, where the development outbox is kept by default.
+func Init(baseDir string) error {
+	name := os.Getenv("EMAIL_PROVIDER")
+	if name == "" {
+		name = defaultProviderName()
 	}
 
-	// Create an SES session.
-	svc := ses.New(sess)
-
-	// Assemble the email.
-	input := &ses.SendEmailInput{
-		Destination: &ses.Destination{
-			ToAddresses: []*string{
-				aws.String(recipient),
-			},
-		},
-		Message: &ses.Message{
-			Body: &ses.Body{
-				Html: &ses.Content{
-					Charset: aws.String("UTF-8"),
-					Data:    aws.String(htmlBody),
-				},
-				Text: &ses.Content{
-					Charset: aws.String("UTF-8"),
-					Data:    aws.String(textBody),
-				},
-			},
-			Subject: &ses.Content{
-				Charset: aws.String("UTF-8"),
-				Data:    aws.String(subject),
-			},
-		},
-		Source: aws.String("Plandex <support@plandex.ai>"),
+	switch name {
+	case ProviderSES:
+		provider = &sesProvider{}
+	case ProviderSMTP:
+		provider = &smtpProvider{}
+	case ProviderOutbox:
+		p, err := newOutboxProvider(baseDir)
+		if err != nil {
+			return err
+		}
+		provider = p
+	case ProviderNone:
+		provider = nil
+	default:
+		return fmt.Errorf("invalid EMAIL_PROVIDER: %s -- use ses, smtp, outbox, or none", name)
 	}
 
-	// Attempt to send the email.
-	_, err = svc.SendEmail(input)
+	err := loadTemplates(os.Getenv("EMAIL_TEMPLATES_DIR"))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Email provider: %s\n", name)
 
-	return err
+	return nil
 }
 
-func sendEmailViaSMTP(recipient, subject, htmlBody, textBody string) error {
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUser := os.Getenv("SMTP_USER")
-	smtpPassword := os.Getenv("SMTP_PASSWORD")
-	smtpFrom := os.Getenv("SMTP_FROM")
+// defaultProviderName keeps the behavior from before providers were configurable: SES on cloud, SMTP in production, and a local outbox in development
+func defaultProviderName() string {
+	switch os.Getenv("GOENV") {
+	case "production":
+		if os.Getenv("IS_CLOUD") != "" {
+			return ProviderSES
+		}
+		return ProviderSMTP
+	case "development":
+		return ProviderOutbox
+	}
+	return ProviderNone
+}
 
-	if smtpHost == "" || smtpPort == "" || smtpUser == "" || smtpPassword == "" {
-		return fmt.Errorf("SMTP settings not found in environment variables")
+func send(msg *Message) error {
+	if provider == nil {
+		log.Printf("Email not sent to %s (no email provider): %s\n", msg.To, msg.Subject)
+		return nil
 	}
 
-	if smtpFrom == "" {
-		smtpFrom = smtpUser
+	err := provider.Send(senderAddress(), msg)
+	if err != nil {
+		return fmt.Errorf("error sending email via %s: %v", provider.Name(), err)
 	}
 
-	smtpAddress := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
+	return nil
+}
 
-	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
+// senderAddress uses EMAIL_FROM if it's set, then falls back to each provider's previous default
+func senderAddress() string {
+	if from := os.Getenv("EMAIL_FROM"); from != "" {
+		return from
+	}
 
-	// Generate a MIME boundary
-	boundary := "BOUNDARY1234567890"
-	header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", smtpFrom, recipient, subject, boundary)
+	if _, ok := provider.(*smtpProvider); ok {
+		if from := os.Getenv("SMTP_FROM"); from != "" {
+			return from
+		}
+		if user := os.Getenv("SMTP_USER"); user != "" {
+			return user
+		}
+	}
 
-	// Prepare the text body part
-	textPart := fmt.Sprintf("--%s\r\nContent-Type: text/plain; charset=\"UTF-8\"\r\n\r\n%s\r\n", boundary, textBody)
+	return defaultSESFrom
+}
 
-	// Prepare the HTML body part
-	htmlPart := fmt.Sprintf("--%s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s\r\n", boundary, htmlBody)
+// buildMimeMessage renders a multipart/alternative message with text and html parts
+func buildMimeMessage(from string, msg *Message, date time.Time) ([]byte, error) {
+	boundaryBytes := make([]byte, 16)
+	_, err := rand.Read(boundaryBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error generating mime boundary: %v", err)
+	}
+	boundary := hex.EncodeToString(boundaryBytes)
 
-	// End marker for the boundary
-	endBoundary := fmt.Sprintf("--%s--", boundary)
+	messageId := fmt.Sprintf("<%s@%s>", hex.EncodeToString(boundaryBytes[:8]), senderDomain(from))
 
-	// Combine the parts to form the full email message
-	message := []byte(header + textPart + htmlPart + endBoundary)
+	var buf bytes.Buffer
 
-	err := smtp.SendMail(smtpAddress, auth, smtpFrom, []string{recipient}, message)
-	if err != nil {
-		return fmt.Errorf("error sending email via SMTP: %v", err)
+	headers := [][2]string{
+		{"From", from},
+		{"To", msg.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", messageId},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=\"%s\"", boundary)},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", msg.TextBody},
+		{"text/html", msg.HtmlBody},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\nContent-Type: %s; charset=\"UTF-8\"\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", boundary, part.contentType)
+
+		w := quotedprintable.NewWriter(&buf)
+		_, err = w.Write([]byte(part.body))
+		if err != nil {
+			return nil, fmt.Errorf("error encoding email body: %v", err)
+		}
+		err = w.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error encoding email body: %v", err)
+		}
+		buf.WriteString("\r\n")
 	}
 
-	return nil
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// envelopeAddress strips the display name from an address like 'Plandex <support@plandex.ai>'
+func envelopeAddress(address string) string {
+	if start := strings.LastIndex(address, "<"); start != -1 {
+		if end := strings.LastIndex(address, ">"); end > start {
+			return address[start+1 : end]
+		}
+	}
+	return strings.TrimSpace(address)
+}
+
+func senderDomain(from string) string {
+	address := envelopeAddress(from)
+	if at := strings.LastIndex(address, "@"); at != -1 {
+		return address[at+1:]
+	}
+	return "localhost"
 }
@@ -0,0 +1,142 @@
+package email
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "invite.subject.txt"), []byte("Join {{.OrgName}}"), 0644)
+	os.WriteFile(filepath.Join(dir, "invite.html"), []byte("<p>{{.InviterName}} invited you</p>"), 0644)
+
+	err := loadTemplates(dir)
+	if err != nil {
+		t.Fatalf("error loading templates: %v", err)
+	}
+	defer loadTemplates("")
+
+	msg, err := render(TemplateInvite, "dev@example.com", InviteData{
+		Email:            "dev@example.com",
+		InviteeFirstName: "Sam",
+		InviterName:      "<Alex>",
+		OrgName:          "Acme",
+	})
+	if err != nil {
+		t.Fatalf("error rendering: %v", err)
+	}
+
+	if msg.Subject != "Join Acme" {
+		t.Errorf("expected overridden subject, got %q", msg.Subject)
+	}
+
+	if msg.HtmlBody != "<p>&lt;Alex&gt; invited you</p>" {
+		t.Errorf("expected overridden, escaped html, got %q", msg.HtmlBody)
+	}
+
+	// no text override, so the default is used
+	if !strings.HasPrefix(msg.TextBody, "Hi Sam,") {
+		t.Errorf("expected default text body, got %q", msg.TextBody)
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	t.Setenv("EMAIL_OUTBOX_DIR", t.TempDir())
+
+	p, err := newOutboxProvider("")
+	if err != nil {
+		t.Fatalf("error creating outbox: %v", err)
+	}
+
+	err = p.Send("Plandex <support@example.com>", &Message{
+		To:       "dev@example.com",
+		Subject:  "Your Plandex Pin",
+		HtmlBody: "<p>pin</p>",
+		TextBody: "pin",
+	})
+	if err != nil {
+		t.Fatalf("error sending: %v", err)
+	}
+
+	emls, _ := filepath.Glob(filepath.Join(p.dir, "*.eml"))
+	if len(emls) != 1 {
+		t.Fatalf("expected 1 eml file, got %d", len(emls))
+	}
+
+	index, err := os.ReadFile(filepath.Join(p.dir, "index.html"))
+	if err != nil {
+		t.Fatalf("error reading index: %v", err)
+	}
+
+	if !strings.Contains(string(index), "Your Plandex Pin") || !strings.Contains(string(index), "dev@example.com") {
+		t.Errorf("expected index to list the message, got %s", index)
+	}
+}
+
+func TestBuildMimeMessage(t *testing.T) {
+	msg, err := buildMimeMessage("Plandex <support@example.com>", &Message{
+		To:       "dev@example.com",
+		Subject:  "Pin ✓",
+		HtmlBody: "<p>hi</p>",
+		TextBody: "hi",
+	}, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatalf("error building message: %v", err)
+	}
+
+	s := string(msg)
+
+	for _, expected := range []string{
+		"From: Plandex <support@example.com>\r\n",
+		"Subject: =?utf-8?q?Pin_=E2=9C=93?=\r\n",
+		"Message-ID: <",
+		"@example.com>\r\n",
+		"Content-Type: text/plain",
+		"Content-Type: text/html",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected message to contain %q", expected)
+		}
+	}
+
+	if envelopeAddress("Plandex <support@example.com>") != "support@example.com" {
+		t.Error("expected envelope address without display name")
+	}
+}
+
+func TestLoadSmtpConfigTLSMode(t *testing.T) {
+	tests := []struct {
+		port string
+		tls  string
+		want string
+	}{
+		{"465", "", smtpTLSImplicit},
+		{"587", "", smtpTLSOpportunistic},
+		{"1025", "", smtpTLSOpportunistic},
+		{"587", "STARTTLS", smtpTLSStartTLS},
+		{"465", "none", smtpTLSNone},
+	}
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+
+	for _, tt := range tests {
+		t.Setenv("SMTP_PORT", tt.port)
+		t.Setenv("SMTP_TLS", tt.tls)
+
+		config, err := loadSmtpConfig()
+		if err != nil {
+			t.Fatalf("loadSmtpConfig() error = %v", err)
+		}
+		if config.tlsMode != tt.want {
+			t.Errorf("tls mode for port %s and SMTP_TLS=%q = %s, want %s", tt.port, tt.tls, config.tlsMode, tt.want)
+		}
+	}
+
+	t.Setenv("SMTP_TLS", "opportunistic")
+	if _, err := loadSmtpConfig(); err == nil {
+		t.Errorf("loadSmtpConfig() accepted SMTP_TLS=opportunistic")
+	}
+}
@@ -0,0 +1,169 @@
+package email
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	outboxFormatFiles   = "files"
+	outboxFormatMaildir = "maildir"
+)
+
+// outboxProvider writes emails to a local directory instead of sending them. The default 'files' format writes each message as an .eml file and an .html preview, with an index.html to browse them. The 'maildir' format can be opened by mail clients like mutt.
+type outboxProvider struct {
+	dir    string
+	format string
+	mu     sync.Mutex
+}
+
+func newOutboxProvider(baseDir string) (*outboxProvider, error) {
+	dir := os.Getenv("EMAIL_OUTBOX_DIR")
+	if dir == "" {
+		dir = filepath.Join(baseDir, "outbox")
+	}
+
+	format := os.Getenv("EMAIL_OUTBOX_FORMAT")
+	if format == "" {
+		format = outboxFormatFiles
+	}
+
+	dirs := []string{dir}
+	switch format {
+	case outboxFormatFiles:
+	case outboxFormatMaildir:
+		dirs = []string{filepath.Join(dir, "tmp"), filepath.Join(dir, "new"), filepath.Join(dir, "cur")}
+	default:
+		return nil, fmt.Errorf("invalid EMAIL_OUTBOX_FORMAT: %s -- use files or maildir", format)
+	}
+
+	for _, d := range dirs {
+		err := os.MkdirAll(d, 0755)
+		if err != nil {
+			return nil, fmt.Errorf("error creating email outbox dir: %v", err)
+		}
+	}
+
+	log.Printf("Email outbox: %s\n", dir)
+
+	return &outboxProvider{dir: dir, format: format}, nil
+}
+
+func (p *outboxProvider) Name() string {
+	return ProviderOutbox
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (p *outboxProvider) Send(from string, msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	message, err := buildMimeMessage(from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s", now.Format("20060102-150405.000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+
+	if p.format == outboxFormatMaildir {
+		// maildir delivery: write to tmp, then move into new
+		tmpPath := filepath.Join(p.dir, "tmp", name)
+		err = os.WriteFile(tmpPath, message, 0644)
+		if err != nil {
+			return fmt.Errorf("error writing email to outbox: %v", err)
+		}
+
+		err = os.Rename(tmpPath, filepath.Join(p.dir, "new", name))
+		if err != nil {
+			return fmt.Errorf("error moving email into outbox: %v", err)
+		}
+
+		log.Printf("Wrote email to %s in outbox %s\n", msg.To, p.dir)
+		return nil
+	}
+
+	err = os.WriteFile(filepath.Join(p.dir, name+".eml"), message, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing email to outbox: %v", err)
+	}
+
+	preview := fmt.Sprintf("<!-- from: %s | to: %s | subject: %s -->\n%s", html.EscapeString(from), html.EscapeString(msg.To), html.EscapeString(msg.Subject), msg.HtmlBody)
+
+	err = os.WriteFile(filepath.Join(p.dir, name+".html"), []byte(preview), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing email preview to outbox: %v", err)
+	}
+
+	err = p.writeIndex()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Wrote email to %s in outbox -- browse it at file://%s\n", msg.To, filepath.Join(p.dir, "index.html"))
+
+	return nil
+}
+
+var previewHeader = regexp.MustCompile(`^<!-- from: (.*) \| to: (.*) \| subject: (.*) -->`)
+
+// writeIndex lists the outbox's messages, newest first
+func (p *outboxProvider) writeIndex() error {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return fmt.Errorf("error reading email outbox: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".html") && entry.Name() != "index.html" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	var rows strings.Builder
+	for _, name := range names {
+		to, subject := "", ""
+
+		content, err := os.ReadFile(filepath.Join(p.dir, name))
+		if err == nil {
+			firstLine := strings.SplitN(string(content), "\n", 2)[0]
+			if m := previewHeader.FindStringSubmatch(firstLine); m != nil {
+				to, subject = m[2], m[3]
+			}
+		}
+
+		base := strings.TrimSuffix(name, ".html")
+		fmt.Fprintf(&rows, "<tr><td>%s</td><td>%s</td><td><a href=\"%s\">%s</a></td><td><a href=\"%s.eml\">eml</a></td></tr>\n", html.EscapeString(base[:22]), to, html.EscapeString(name), subject, html.EscapeString(base))
+	}
+
+	index := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Plandex email outbox</title></head>
+<body style="font-family: sans-serif">
+<h1>Plandex email outbox</h1>
+<table cellpadding="6">
+<tr><th align="left">Sent</th><th align="left">To</th><th align="left">Subject</th><th></th></tr>
+%s</table>
+</body>
+</html>
+`, rows.String())
+
+	err = os.WriteFile(filepath.Join(p.dir, "index.html"), []byte(index), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing email outbox index: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+type sesProvider struct{}
+
+func (p *sesProvider) Name() string {
+	return ProviderSES
+}
+
+// Send sends an email using AWS SES
+func (p *sesProvider) Send(from string, msg *Message) error {
+	sess, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+
+	// Create an SES session.
+	svc := ses.New(sess)
+
+	// Assemble the email.
+	input := &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			ToAddresses: []*string{
+				aws.String(msg.To),
+			},
+		},
+		Message: &ses.Message{
+			Body: &ses.Body{
+				Html: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(msg.HtmlBody),
+				},
+				Text: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(msg.TextBody),
+				},
+			},
+			Subject: &ses.Content{
+				Charset: aws.String("UTF-8"),
+				Data:    aws.String(msg.Subject),
+			},
+		},
+		Source: aws.String(from),
+	}
+
+	// Attempt to send the email.
+	_, err = svc.SendEmail(input)
+
+	return err
+}
+
+// SendEmailViaSES sends an email using AWS SES regardless of the configured provider
+func SendEmailViaSES(recipient, subject, htmlBody, textBody string) error {
+	from := os.Getenv("EMAIL_FROM")
+	if from == "" {
+		from = defaultSESFrom
+	}
+
+	return (&sesProvider{}).Send(from, &Message{
+		To:       recipient,
+		Subject:  subject,
+		HtmlBody: htmlBody,
+		TextBody: textBody,
+	})
+}
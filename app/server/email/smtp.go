@@ -0,0 +1,214 @@
+package email
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	smtpTLSStartTLS = "starttls"
+	smtpTLSImplicit = "tls"
+	smtpTLSNone     = "none"
+
+	// starttls if the server advertises it, otherwise unencrypted -- the default on ports other than 465
+	smtpTLSOpportunistic = "opportunistic"
+)
+
+const smtpTimeout = 30 * time.Second
+
+type smtpProvider struct{}
+
+type smtpConfig struct {
+	host     string
+	port     string
+	user     string
+	password string
+
+	// starttls, tls, or none -- defaults to tls on port 465 and opportunistic starttls otherwise
+	tlsMode string
+
+	// plain, login, or cram-md5
+	authMode string
+
+	insecureSkipVerify bool
+}
+
+func loadSmtpConfig() (*smtpConfig, error) {
+	config := &smtpConfig{
+		host:               os.Getenv("SMTP_HOST"),
+		port:               os.Getenv("SMTP_PORT"),
+		user:               os.Getenv("SMTP_USER"),
+		password:           os.Getenv("SMTP_PASSWORD"),
+		tlsMode:            strings.ToLower(os.Getenv("SMTP_TLS")),
+		authMode:           strings.ToLower(os.Getenv("SMTP_AUTH")),
+		insecureSkipVerify: os.Getenv("SMTP_INSECURE_SKIP_VERIFY") == "1",
+	}
+
+	if config.host == "" || config.port == "" {
+		return nil, fmt.Errorf("SMTP_HOST and SMTP_PORT are required")
+	}
+
+	if config.user != "" && config.password == "" {
+		return nil, fmt.Errorf("SMTP_PASSWORD is required when SMTP_USER is set")
+	}
+
+	switch config.tlsMode {
+	case "":
+		if config.port == "465" {
+			config.tlsMode = smtpTLSImplicit
+		} else {
+			config.tlsMode = smtpTLSOpportunistic
+		}
+	case smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone:
+	default:
+		return nil, fmt.Errorf("invalid SMTP_TLS: %s -- use starttls, tls, or none", config.tlsMode)
+	}
+
+	if config.authMode == "" {
+		config.authMode = "plain"
+	}
+
+	switch config.authMode {
+	case "plain", "login", "cram-md5":
+	default:
+		return nil, fmt.Errorf("invalid SMTP_AUTH: %s -- use plain, login, or cram-md5", config.authMode)
+	}
+
+	return config, nil
+}
+
+func (p *smtpProvider) Name() string {
+	return ProviderSMTP
+}
+
+func (p *smtpProvider) Send(from string, msg *Message) error {
+	config, err := loadSmtpConfig()
+	if err != nil {
+		return err
+	}
+
+	message, err := buildMimeMessage(from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(config.host, config.port)
+	tlsConfig := &tls.Config{
+		ServerName:         config.host,
+		InsecureSkipVerify: config.insecureSkipVerify,
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	if config.tlsMode == smtpTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server: %v", err)
+	}
+
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, config.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %v", err)
+	}
+	defer client.Close()
+
+	if config.tlsMode == smtpTLSStartTLS || config.tlsMode == smtpTLSOpportunistic {
+		ok, _ := client.Extension("STARTTLS")
+		if !ok && config.tlsMode == smtpTLSStartTLS {
+			return fmt.Errorf("SMTP server doesn't support STARTTLS -- set SMTP_TLS=none to send without encryption")
+		}
+		if ok {
+			err = client.StartTLS(tlsConfig)
+			if err != nil {
+				return fmt.Errorf("error starting TLS: %v", err)
+			}
+		}
+	}
+
+	if config.user != "" {
+		var auth smtp.Auth
+		switch config.authMode {
+		case "login":
+			auth = &loginAuth{username: config.user, password: config.password}
+		case "cram-md5":
+			auth = smtp.CRAMMD5Auth(config.user, config.password)
+		default:
+			auth = smtp.PlainAuth("", config.user, config.password, config.host)
+		}
+
+		err = client.Auth(auth)
+		if err != nil {
+			return fmt.Errorf("error authenticating with SMTP server: %v", err)
+		}
+	}
+
+	err = client.Mail(envelopeAddress(from))
+	if err != nil {
+		return fmt.Errorf("error setting sender: %v", err)
+	}
+
+	err = client.Rcpt(envelopeAddress(msg.To))
+	if err != nil {
+		return fmt.Errorf("error setting recipient: %v", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message data: %v", err)
+	}
+
+	_, err = w.Write(message)
+	if err != nil {
+		return fmt.Errorf("error writing message: %v", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+
+	return client.Quit()
+}
+
+// loginAuth implements the LOGIN mechanism, which some servers (like Office 365) require instead of PLAIN
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// same rule as smtp.PlainAuth
+	isLocalhost := server.Name == "localhost" || server.Name == "127.0.0.1" || server.Name == "::1"
+	if !server.TLS && !isLocalhost {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+
+	return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+}
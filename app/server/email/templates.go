@@ -0,0 +1,182 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"os"
+	"path/filepath"
+	textTemplate "text/template"
+)
+
+const (
+	TemplateVerification = "verification"
+	TemplateSignIn       = "sign_in"
+	TemplateInvite       = "invite"
+)
+
+type emailTemplate struct {
+	subject *textTemplate.Template
+	html    *htmlTemplate.Template
+	text    *textTemplate.Template
+}
+
+type templateSource struct {
+	subject string
+	html    string
+	text    string
+}
+
+var defaultTemplates = map[string]templateSource{
+	TemplateVerification: {
+		subject: "Your Plandex Pin",
+		html: `<p>Hi there,</p>
+<p>Welcome to Plandex! Your pin is:<br><br>
+<strong style="font-size: 18px;">{{.Pin}}</strong></p>
+<p>It will be valid for the next {{.ValidMinutes}} minutes.</p>
+<p style="color: #666; font-size: 12px; margin-top: 20px;">If you didn't request this, you can safely ignore the email.</p>`,
+		text: `Hi there,
+
+Welcome to Plandex! Your pin is:
+
+{{.Pin}}
+
+It will be valid for the next {{.ValidMinutes}} minutes.
+
+If you didn't request this, you can safely ignore the email.`,
+	},
+	TemplateSignIn: {
+		subject: "Your Plandex Sign In Pin",
+		html: `<p>Hi there,</p>
+<p>Your Plandex sign in pin is:<br><br>
+<strong style="font-size: 18px;">{{.Pin}}</strong></p>
+<p>It will be valid for the next {{.ValidMinutes}} minutes.</p>
+<p style="color: #666; font-size: 12px; margin-top: 20px;">If you didn't try to sign in, you can safely ignore the email.</p>`,
+		text: `Hi there,
+
+Your Plandex sign in pin is:
+
+{{.Pin}}
+
+It will be valid for the next {{.ValidMinutes}} minutes.
+
+If you didn't try to sign in, you can safely ignore the email.`,
+	},
+	TemplateInvite: {
+		subject: "{{.InviteeFirstName}}, you've been invited to join {{.OrgName}} on Plandex",
+		html:    `<p>Hi {{.InviteeFirstName}},</p><p>{{.InviterName}} has invited you to join the org <strong>{{.OrgName}}</strong> on <a href="https://plandex.ai">Plandex.</a></p><p>Plandex is a terminal-based AI programming engine for complex tasks.</p><p>To accept the invite, first <a href="https://docs.plandex.ai/install/">install Plandex</a>, then open a terminal and run 'plandex sign-in'. Enter '{{.Email}}' when asked for your email and follow the prompts from there.</p><p>If you have questions, feedback, or run into a problem, you can reply directly to this email, <a href="https://github.com/plandex-ai/plandex/discussions">start a discussion</a>, or <a href="https://github.com/plandex-ai/plandex/issues">open an issue.</a></p>`,
+		text: `Hi {{.InviteeFirstName}},
+
+{{.InviterName}} has invited you to join the org {{.OrgName}} on Plandex.
+
+Plandex is a terminal-based AI programming engine for complex tasks.
+
+To accept the invite, first install Plandex (https://docs.plandex.ai/install/), then open a terminal and run 'plandex sign-in'. Enter '{{.Email}}' when asked for your email and follow the prompts from there.
+
+If you have questions, feedback, or run into a problem, you can reply directly to this email, start a discussion (https://github.com/plandex-ai/plandex/discussions), or open an issue (https://github.com/plandex-ai/plandex/issues).`,
+	},
+}
+
+type PinData struct {
+	Email        string
+	Pin          string
+	ValidMinutes int
+}
+
+type InviteData struct {
+	Email            string
+	InviteeFirstName string
+	InviterName      string
+	OrgName          string
+}
+
+var templates map[string]*emailTemplate
+
+// loadTemplates parses the default templates, replacing any part that has an override in dir. Overrides are named <template>.subject.txt, <template>.html, and <template>.txt.
+func loadTemplates(dir string) error {
+	res := map[string]*emailTemplate{}
+
+	for name, source := range defaultTemplates {
+		if dir != "" {
+			for _, override := range []struct {
+				file string
+				dest *string
+			}{
+				{name + ".subject.txt", &source.subject},
+				{name + ".html", &source.html},
+				{name + ".txt", &source.text},
+			} {
+				bytes, err := os.ReadFile(filepath.Join(dir, override.file))
+				if err != nil {
+					if os.IsNotExist(err) {
+						continue
+					}
+					return fmt.Errorf("error reading email template %s: %v", override.file, err)
+				}
+				*override.dest = string(bytes)
+			}
+		}
+
+		t := &emailTemplate{}
+		var err error
+
+		t.subject, err = textTemplate.New(name + ".subject").Parse(source.subject)
+		if err != nil {
+			return fmt.Errorf("error parsing %s email subject template: %v", name, err)
+		}
+
+		t.html, err = htmlTemplate.New(name + ".html").Parse(source.html)
+		if err != nil {
+			return fmt.Errorf("error parsing %s email html template: %v", name, err)
+		}
+
+		t.text, err = textTemplate.New(name + ".txt").Parse(source.text)
+		if err != nil {
+			return fmt.Errorf("error parsing %s email text template: %v", name, err)
+		}
+
+		res[name] = t
+	}
+
+	templates = res
+
+	return nil
+}
+
+func render(name, to string, data interface{}) (*Message, error) {
+	if templates == nil {
+		err := loadTemplates("")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("email template %s not found", name)
+	}
+
+	var subject, html, text bytes.Buffer
+
+	err := t.subject.Execute(&subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering %s email subject: %v", name, err)
+	}
+
+	err = t.html.Execute(&html, data)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering %s email html: %v", name, err)
+	}
+
+	err = t.text.Execute(&text, data)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering %s email text: %v", name, err)
+	}
+
+	return &Message{
+		To:       to,
+		Subject:  subject.String(),
+		HtmlBody: html.String(),
+		TextBody: text.String(),
+	}, nil
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+func CreateApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for CreateApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if auth.ApiToken != nil {
+		log.Println("Api tokens can't create other api tokens")
+		http.Error(w, "Api tokens can't be used to create other api tokens", http.StatusForbidden)
+		return
+	}
+
+	var req shared.CreateApiTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.ServiceAccountName = strings.TrimSpace(req.ServiceAccountName)
+
+	if req.Name == "" {
+		log.Println("Token name is required")
+		http.Error(w, "Token name is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.ExpiresInDays < 0 {
+		log.Println("Invalid expiration")
+		http.Error(w, "Invalid expiration", http.StatusBadRequest)
+		return
+	}
+
+	// a token can't be granted anything its creator couldn't do
+	for _, permission := range req.Permissions {
+		if !auth.HasPermission(permission) {
+			log.Printf("User does not have permission: %v\n", permission)
+			http.Error(w, "User does not have permission: "+string(permission), http.StatusForbidden)
+			return
+		}
+	}
+
+	var serviceAccount *db.ServiceAccount
+	var orgRoleId string
+
+	if req.ServiceAccountName != "" {
+		if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+			log.Println("User does not have permission to manage service accounts")
+			http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+			return
+		}
+
+		serviceAccount, err = db.GetServiceAccountByName(auth.OrgId, req.ServiceAccountName)
+		if err != nil {
+			log.Printf("Error getting service account: %v\n", err)
+			http.Error(w, "Error getting service account: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if serviceAccount == nil {
+			orgRoleId = req.OrgRoleId
+			if orgRoleId == "" {
+				orgRoleId, err = db.GetOrgMemberRoleId()
+				if err != nil {
+					log.Printf("Error getting member role id: %v\n", err)
+					http.Error(w, "Error getting member role id: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+			} else {
+				validRole, err := db.ValidateOrgRole(auth.OrgId, orgRoleId)
+				if err != nil {
+					log.Printf("Error validating org role: %v\n", err)
+					http.Error(w, "Error validating org role: "+err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				if !validRole {
+					log.Printf("Invalid org role: %v\n", orgRoleId)
+					http.Error(w, "Invalid org role: "+orgRoleId, http.StatusBadRequest)
+					return
+				}
+			}
+		} else if req.OrgRoleId != "" && req.OrgRoleId != serviceAccount.OrgRoleId {
+			log.Println("Service account already exists with a different role")
+			http.Error(w, "Service account already exists with a different role", http.StatusBadRequest)
+			return
+		} else {
+			orgRoleId = serviceAccount.OrgRoleId
+		}
+
+		// same rule as inviting a user with this role -- a token for an existing account grants its role just as creating one does
+		if !auth.HasPermissionForResource(shared.PermissionInviteUser, orgRoleId) {
+			log.Printf("User does not have permission to create service account tokens with role: %v\n", orgRoleId)
+			http.Error(w, "User does not have permission to create service account tokens with role: "+orgRoleId, http.StatusForbidden)
+			return
+		}
+	}
+
+	apiToken := &db.ApiToken{
+		OrgId:     auth.OrgId,
+		UserId:    auth.User.Id,
+		CreatorId: auth.User.Id,
+		Name:      req.Name,
+	}
+
+	for _, permission := range req.Permissions {
+		apiToken.Permissions = append(apiToken.Permissions, string(permission))
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	var token string
+
+	err = db.WithTx(r.Context(), "create api token", func(tx *sqlx.Tx) error {
+		var err error
+
+		if req.ServiceAccountName != "" && serviceAccount == nil {
+			serviceAccount, err = db.CreateServiceAccount(auth.OrgId, req.ServiceAccountName, orgRoleId, tx)
+			if err != nil {
+				log.Printf("Error creating service account: %v\n", err)
+				return fmt.Errorf("error creating service account: %v", err)
+			}
+		}
+
+		if serviceAccount != nil {
+			apiToken.UserId = serviceAccount.Id
+		}
+
+		token, err = db.CreateApiToken(apiToken, tx)
+		if err != nil {
+			log.Printf("Error creating api token: %v\n", err)
+			return fmt.Errorf("error creating api token: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error creating api token: %v\n", err)
+		http.Error(w, "Error creating api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiApiToken := apiToken.ToApi()
+	if serviceAccount != nil {
+		apiApiToken.ServiceAccountName = serviceAccount.Name
+	}
+
+	resp := shared.CreateApiTokenResponse{
+		Token:    token,
+		ApiToken: apiApiToken,
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionApiTokenCreate,
+		targetType: shared.AuditTargetApiToken,
+		targetId:   apiToken.Id,
+		after:      auditJson(apiApiToken),
+	})
+
+	w.Write(bytes)
+
+	log.Println("Successfully created api token")
+}
+
+func ListApiTokensHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for ListApiTokensHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	userIds := []string{auth.User.Id}
+	serviceAccountsById := map[string]*db.ServiceAccount{}
+
+	if auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		serviceAccounts, err := db.ListServiceAccounts(auth.OrgId)
+		if err != nil {
+			log.Printf("Error listing service accounts: %v\n", err)
+			http.Error(w, "Error listing service accounts: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, sa := range serviceAccounts {
+			userIds = append(userIds, sa.Id)
+			serviceAccountsById[sa.Id] = sa
+		}
+	}
+
+	apiTokens, err := db.ListApiTokens(auth.OrgId, userIds)
+	if err != nil {
+		log.Printf("Error listing api tokens: %v\n", err)
+		http.Error(w, "Error listing api tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiApiTokens := make([]*shared.ApiToken, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		apiApiToken := apiToken.ToApi()
+		if sa, ok := serviceAccountsById[apiToken.UserId]; ok {
+			apiApiToken.ServiceAccountName = sa.Name
+		}
+		apiApiTokens = append(apiApiTokens, apiApiToken)
+	}
+
+	bytes, err := json.Marshal(apiApiTokens)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed api tokens")
+}
+
+func RevokeApiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for RevokeApiTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	tokenId := mux.Vars(r)["tokenId"]
+
+	apiToken, err := db.GetApiToken(auth.OrgId, tokenId)
+	if err != nil {
+		log.Printf("Error getting api token: %v\n", err)
+		http.Error(w, "Error getting api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if apiToken == nil {
+		log.Println("Api token not found")
+		http.Error(w, "Api token not found", http.StatusNotFound)
+		return
+	}
+
+	if apiToken.UserId != auth.User.Id {
+		// only service account tokens can be revoked by someone other than their owner
+		serviceAccount, err := db.GetServiceAccount(auth.OrgId, apiToken.UserId)
+		if err != nil {
+			log.Printf("Error getting service account: %v\n", err)
+			http.Error(w, "Error getting service account: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if serviceAccount == nil || !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+			log.Println("User does not have permission to revoke api token")
+			http.Error(w, "User does not have permission to revoke api token", http.StatusForbidden)
+			return
+		}
+	}
+
+	err = db.RevokeApiToken(apiToken.Id)
+	if err != nil {
+		log.Printf("Error revoking api token: %v\n", err)
+		http.Error(w, "Error revoking api token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionApiTokenRevoke,
+		targetType: shared.AuditTargetApiToken,
+		targetId:   apiToken.Id,
+		before:     auditJson(apiToken.ToApi()),
+	})
+
+	log.Println("Successfully revoked api token")
+}
+
+func ListServiceAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for ListServiceAccountsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		log.Println("User does not have permission to manage service accounts")
+		http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+		return
+	}
+
+	serviceAccounts, err := db.ListServiceAccounts(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing service accounts: %v\n", err)
+		http.Error(w, "Error listing service accounts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiServiceAccounts := make([]*shared.ServiceAccount, 0, len(serviceAccounts))
+	for _, sa := range serviceAccounts {
+		apiServiceAccounts = append(apiServiceAccounts, sa.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiServiceAccounts)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed service accounts")
+}
+
+func DeleteServiceAccountHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for DeleteServiceAccountHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageServiceAccounts) {
+		log.Println("User does not have permission to manage service accounts")
+		http.Error(w, "User does not have permission to manage service accounts", http.StatusForbidden)
+		return
+	}
+
+	serviceAccountId := mux.Vars(r)["serviceAccountId"]
+
+	serviceAccount, err := db.GetServiceAccount(auth.OrgId, serviceAccountId)
+	if err != nil {
+		log.Printf("Error getting service account: %v\n", err)
+		http.Error(w, "Error getting service account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if serviceAccount == nil {
+		log.Println("Service account not found")
+		http.Error(w, "Service account not found", http.StatusNotFound)
+		return
+	}
+
+	// same rule as removing a user with this role
+	removePermission := shared.Permission(strings.Join([]string{string(shared.PermissionRemoveUser), serviceAccount.OrgRoleId}, "|"))
+	if !auth.HasPermission(removePermission) {
+		log.Printf("User does not have permission to delete service account with role: %v\n", serviceAccount.OrgRoleId)
+		http.Error(w, "User does not have permission to delete service account with role: "+serviceAccount.OrgRoleId, http.StatusForbidden)
+		return
+	}
+
+	err = db.WithTx(r.Context(), "delete service account", func(tx *sqlx.Tx) error {
+		return db.DeleteServiceAccount(auth.OrgId, serviceAccount.Id, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error deleting service account: %v\n", err)
+		http.Error(w, "Error deleting service account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionServiceAccountDelete,
+		targetType: shared.AuditTargetServiceAccount,
+		targetId:   serviceAccount.Id,
+		before:     auditJson(serviceAccount.ToApi()),
+	})
+
+	log.Println("Successfully deleted service account")
+}
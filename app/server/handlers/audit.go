@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func ListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListAuditLogHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionViewAuditLog) {
+		return
+	}
+
+	query := r.URL.Query()
+
+	params := db.ListAuditLogParams{
+		OrgId:      auth.OrgId,
+		Actor:      query.Get("actor"),
+		PlanId:     query.Get("planId"),
+		TargetType: query.Get("targetType"),
+		TargetId:   query.Get("targetId"),
+	}
+
+	if actions := query.Get("action"); actions != "" {
+		params.Actions = strings.Split(actions, ",")
+	}
+
+	for _, p := range []struct {
+		name string
+		dest **time.Time
+	}{{"since", &params.Since}, {"until", &params.Until}} {
+		if v := query.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "Invalid "+p.name+" -- use RFC3339 format", http.StatusBadRequest)
+				return
+			}
+			*p.dest = &t
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dest *int64
+	}{{"beforeSeq", &params.BeforeSeq}, {"afterSeq", &params.AfterSeq}} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid "+p.name, http.StatusBadRequest)
+				return
+			}
+			*p.dest = n
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		params.Limit = n
+	}
+
+	entries, hasMore, err := db.ListAuditLog(params)
+	if err != nil {
+		log.Printf("Error listing audit log: %v\n", err)
+		http.Error(w, "Error listing audit log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.ListAuditLogResponse{
+		Entries: db.ToApiAuditLogEntries(entries),
+		HasMore: hasMore,
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully listed %d audit log entries\n", len(entries))
+
+	w.Write(bytes)
+}
+
+type auditParams struct {
+	action     shared.AuditAction
+	targetType shared.AuditTargetType
+	targetId   string
+	planId     string
+
+	// short summaries of the target's state before and after the action
+	before string
+	after  string
+}
+
+// recordAudit appends to the org's audit log. It's called after an action succeeds, so a failure to record is logged rather than failing the request.
+func recordAudit(r *http.Request, auth *types.ServerAuth, params auditParams) {
+	entry := &db.AuditLogEntry{
+		OrgId:      auth.OrgId,
+		ActorId:    auth.User.Id,
+		ActorEmail: auth.User.Email,
+		Action:     params.action,
+		TargetType: params.targetType,
+		TargetId:   params.targetId,
+		Before:     params.before,
+		After:      params.after,
+		ClientIp:   clientIp(r),
+		UserAgent:  r.UserAgent(),
+	}
+
+	if auth.ApiToken != nil {
+		entry.ApiTokenId = &auth.ApiToken.Id
+	}
+
+	if params.planId != "" {
+		entry.PlanId = &params.planId
+	}
+
+	err := db.CreateAuditLogEntry(entry)
+	if err != nil {
+		log.Printf("Error recording audit log entry %s: %v\n", params.action, err)
+	}
+}
+
+// auditJson summarizes a value for an audit entry
+func auditJson(v interface{}) string {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Error marshalling audit summary: %v\n", err)
+		return ""
+	}
+	return string(bytes)
+}
+
+var (
+	trustedProxiesOnce sync.Once
+	trustedProxies     []*net.IPNet
+)
+
+// getTrustedProxies parses TRUSTED_PROXIES, a comma-separated list of ips or cidr ranges for the proxies or load balancers in front of the server
+func getTrustedProxies() []*net.IPNet {
+	trustedProxiesOnce.Do(func() {
+		for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			if !strings.Contains(entry, "/") {
+				if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+					entry += "/32"
+				} else {
+					entry += "/128"
+				}
+			}
+
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				log.Printf("Ignoring invalid TRUSTED_PROXIES entry %s: %v\n", entry, err)
+				continue
+			}
+			trustedProxies = append(trustedProxies, ipNet)
+		}
+	})
+
+	return trustedProxies
+}
+
+func isTrustedProxy(ip net.IP, proxies []*net.IPNet) bool {
+	for _, ipNet := range proxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIp returns the request's remote address. Forwarding headers are only used when the request comes from a trusted proxy, since clients can set them to anything.
+func clientIp(r *http.Request) string {
+	return clientIpWithProxies(r, getTrustedProxies())
+}
+
+func clientIpWithProxies(r *http.Request, proxies []*net.IPNet) string {
+	remoteIp, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIp = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(remoteIp)
+	if ip == nil || !isTrustedProxy(ip, proxies) {
+		return remoteIp
+	}
+
+	// each proxy appends the address it received the request from, so the right-most hop that isn't one of ours is the client -- anything left of it could be spoofed
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				// a malformed hop can't be trusted, or anything before it
+				break
+			}
+			if !isTrustedProxy(hop, proxies) {
+				return hop.String()
+			}
+		}
+	}
+
+	if realIp := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-Ip"))); realIp != nil {
+		return realIp.String()
+	}
+
+	return remoteIp
+}
@@ -58,6 +58,11 @@ func GetAuthHeader(r *http.Request) (*shared.AuthHeader, error) {
 	// strip off the "Bearer " prefix
 	encoded := strings.TrimPrefix(authHeader, "Bearer ")
 
+	// api tokens can be sent as-is -- the org comes from the token
+	if strings.HasPrefix(encoded, shared.ApiTokenPrefix) {
+		return &shared.AuthHeader{Token: encoded}, nil
+	}
+
 	// decode the base64-encoded credentials
 	bytes, err := base64.URLEncoding.DecodeString(encoded)
 
@@ -387,6 +392,11 @@ func ValidateAndSignIn(w http.ResponseWriter, r *http.Request, req shared.SignIn
 		return nil, fmt.Errorf("error validating and signing in: %v", err)
 	}
 
+	return sessionForUser(w, r, user, token, signInCodeOrgId)
+}
+
+// sessionForUser sets the auth cookie for browsers and builds the session response for a newly created auth token. If onlyOrgId is set, the session is limited to that org.
+func sessionForUser(w http.ResponseWriter, r *http.Request, user *db.User, token, onlyOrgId string) (*shared.SessionResponse, error) {
 	// get orgs
 	orgs, err := db.GetAccessibleOrgsForUser(user)
 
@@ -395,10 +405,10 @@ func ValidateAndSignIn(w http.ResponseWriter, r *http.Request, req shared.SignIn
 		return nil, fmt.Errorf("error getting orgs for user: %v", err)
 	}
 
-	if req.IsSignInCode {
+	if onlyOrgId != "" {
 		filteredOrgs := []*db.Org{}
 		for _, org := range orgs {
-			if org.Id == signInCodeOrgId {
+			if org.Id == onlyOrgId {
 				filteredOrgs = append(filteredOrgs, org)
 			}
 		}
@@ -459,8 +469,22 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
-	// validate the token
-	authToken, err := db.ValidateAuthToken(parsed.Token)
+	// validate the token -- either a session token or a long-lived api token
+	var authToken *db.AuthToken
+	var apiToken *db.ApiToken
+	var userId string
+
+	if strings.HasPrefix(parsed.Token, shared.ApiTokenPrefix) {
+		apiToken, err = db.ValidateApiToken(parsed.Token)
+		if err == nil {
+			userId = apiToken.UserId
+		}
+	} else {
+		authToken, err = db.ValidateAuthToken(parsed.Token)
+		if err == nil {
+			userId = authToken.UserId
+		}
+	}
 
 	if err != nil {
 		log.Printf("error validating auth token: %v\n", err)
@@ -473,7 +497,26 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
-	user, err := db.GetUser(authToken.UserId)
+	if apiToken != nil {
+		// api tokens are scoped to a single org
+		if parsed.OrgId == "" {
+			parsed.OrgId = apiToken.OrgId
+		} else if parsed.OrgId != apiToken.OrgId {
+			log.Println("api token org mismatch")
+			if raiseErr {
+				http.Error(w, "api token not valid for org", http.StatusUnauthorized)
+			}
+			return nil
+		}
+
+		err = db.TouchApiToken(apiToken)
+		if err != nil {
+			// not fatal -- just log
+			log.Printf("error updating api token last used: %v\n", err)
+		}
+	}
+
+	user, err := db.GetUser(userId)
 
 	if err != nil {
 		log.Printf("error getting user: %v\n", err)
@@ -483,9 +526,18 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
+	if user == nil {
+		log.Println("user not found")
+		if raiseErr {
+			http.Error(w, "user not found", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
 	if !requireOrg {
 		return &types.ServerAuth{
 			AuthToken: authToken,
+			ApiToken:  apiToken,
 			User:      user,
 		}
 	}
@@ -499,7 +551,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 	}
 
 	// validate the org membership
-	isMember, err := db.ValidateOrgMembership(authToken.UserId, parsed.OrgId)
+	isMember, err := db.ValidateOrgMembership(userId, parsed.OrgId)
 
 	if err != nil {
 		log.Printf("error validating org membership: %v\n", err)
@@ -509,7 +561,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
-	if !isMember {
+	if !isMember && apiToken == nil {
 		// check if there's an invite for this user and accept it if so (adds the user to the org)
 		invite, err := db.GetActiveInviteByEmail(parsed.OrgId, user.Email)
 
@@ -524,7 +576,7 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		if invite != nil {
 			log.Println("accepting invite")
 
-			err := db.AcceptInvite(r.Context(), invite, authToken.UserId)
+			err := db.AcceptInvite(r.Context(), invite, userId)
 
 			if err != nil {
 				log.Printf("error accepting invite: %v\n", err)
@@ -543,8 +595,16 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		}
 	}
 
+	if !isMember && apiToken != nil {
+		log.Println("api token user is not a member of the org")
+		if raiseErr {
+			http.Error(w, "not a member of org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
 	// get user permissions
-	permissions, err := db.GetUserPermissions(authToken.UserId, parsed.OrgId)
+	permissions, err := db.GetUserPermissions(userId, parsed.OrgId)
 
 	if err != nil {
 		log.Printf("error getting user permissions: %v\n", err)
@@ -557,11 +617,16 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 	// build the permissions map
 	permissionsMap := make(shared.Permissions)
 	for _, permission := range permissions {
+		// a scoped api token only gets the permissions it was granted, and never more than its user has
+		if apiToken != nil && len(apiToken.Permissions) > 0 && !apiTokenHasScope(apiToken, permission) {
+			continue
+		}
 		permissionsMap[permission] = true
 	}
 
 	auth := &types.ServerAuth{
 		AuthToken:   authToken,
+		ApiToken:    apiToken,
 		User:        user,
 		OrgId:       parsed.OrgId,
 		Permissions: permissionsMap,
@@ -579,12 +644,22 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
-	log.Printf("UserId: %s, Email: %s, OrgId: %s\n", authToken.UserId, user.Email, parsed.OrgId)
+	log.Printf("UserId: %s, Email: %s, OrgId: %s\n", userId, user.Email, parsed.OrgId)
 
 	return auth
 
 }
 
+func apiTokenHasScope(apiToken *db.ApiToken, permission string) bool {
+	name := strings.Split(permission, "|")[0]
+	for _, scope := range apiToken.Permissions {
+		if scope == name || scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func authorizeProject(w http.ResponseWriter, projectId string, auth *types.ServerAuth) bool {
 	return authorizeProjectOptional(w, projectId, auth, true)
 }
@@ -720,3 +795,82 @@ func authorizePlanArchive(w http.ResponseWriter, planId string, auth *types.Serv
 
 	return plan
 }
+
+// authorizeOrgPermission checks a permission that custom org roles can withhold, like telling or applying
+func authorizeOrgPermission(w http.ResponseWriter, auth *types.ServerAuth, permission shared.Permission) bool {
+	if !auth.HasPermission(permission) {
+		log.Printf("User does not have permission: %v\n", permission)
+		http.Error(w, "Your org role doesn't have permission: "+string(permission), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// authorizePlanShareLevel authorizes an action on a plan that a collaborator needs at least the given share level for. Owners and plans shared with the whole org keep full access.
+func authorizePlanShareLevel(w http.ResponseWriter, planId string, auth *types.ServerAuth, level shared.PlanShareLevel) *db.Plan {
+	plan := authorizePlan(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if plan.OwnerId == auth.User.Id || plan.SharedWithOrgAt != nil {
+		return plan
+	}
+
+	if !hasPlanShareLevel(w, plan, auth, level) {
+		return nil
+	}
+
+	return plan
+}
+
+func authorizePlanContribute(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	return authorizePlanShareLevel(w, planId, auth, shared.PlanShareLevelContributor)
+}
+
+func authorizePlanManageShares(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan := authorizePlan(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionManageAnyPlanShares) {
+		log.Println("User does not have permission to manage plan shares")
+		http.Error(w, "User does not have permission to manage plan shares", http.StatusForbidden)
+		return nil
+	}
+
+	return plan
+}
+
+// hasPlanShareLevel writes an error and returns false if the user isn't allowed the given share level on a plan they don't own
+func hasPlanShareLevel(w http.ResponseWriter, plan *db.Plan, auth *types.ServerAuth, level shared.PlanShareLevel) bool {
+	if auth.HasPermission(shared.PermissionUpdateAnyPlan) {
+		return true
+	}
+
+	shareLevel, err := db.GetPlanShareLevel(plan, auth.User.Id)
+
+	if err != nil {
+		log.Printf("error getting plan share level: %v\n", err)
+		http.Error(w, "error getting plan share level", http.StatusInternalServerError)
+		return false
+	}
+
+	if shareLevel == "" {
+		log.Println("User does not have permission to update plan")
+		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
+		return false
+	}
+
+	if !shareLevel.Includes(level) {
+		log.Printf("Plan is shared with user as %s, needs %s\n", shareLevel, level)
+		http.Error(w, fmt.Sprintf("Plan is shared with you as %s -- this requires %s access", shareLevel, level), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
@@ -91,7 +91,11 @@ func CreateBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -171,7 +175,11 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	if authorizePlanContribute(w, planId, auth) == nil {
 		return
 	}
 
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+
+	shared "plandex-shared"
+)
+
+func GetBuilderStatsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for GetBuilderStatsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	stats, err := db.GetBuilderStrategyStats(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting builder stats: %v\n", err)
+		http.Error(w, "Error getting builder stats: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.GetBuilderStatsResponse{
+		Stats: db.ToApiBuilderStrategyStats(stats),
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling builder stats: %v\n", err)
+		http.Error(w, "Error marshalling builder stats: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully got %d builder stats rows\n", len(stats))
+
+	w.Write(bytes)
+}
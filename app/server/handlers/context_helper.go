@@ -54,6 +54,12 @@ func loadContexts(
 				return nil, nil
 			}
 
+			if len(context.MapDeps) > shared.MaxContextMapPaths {
+				log.Printf("Error: Too many map deps to load (found %d, limit is %d)\n", len(context.MapDeps), shared.MaxContextMapPaths)
+				http.Error(w, fmt.Sprintf("Too many map deps to load (found %d, limit is %d)", len(context.MapDeps), shared.MaxContextMapPaths), http.StatusBadRequest)
+				return nil, nil
+			}
+
 			// these are already mapped, so they shouldn't be anywhere close to the input limit, but we'll use it for the sanity check
 			for _, body := range context.MapBodies {
 				if len(body) > shared.MaxContextMapSingleInputSize {
@@ -62,12 +62,18 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results := make(chan shared.FileMapBodies)
+	results := make(chan *projectMapResult)
+
+	var cacheKey string
+	if req.ProjectId != "" {
+		cacheKey = auth.OrgId + "/" + req.ProjectId
+	}
 
 	err := queueProjectMapJob(projectMapJob{
-		inputs:  req.MapInputs,
-		ctx:     r.Context(),
-		results: results,
+		inputs:   req.MapInputs,
+		cacheKey: cacheKey,
+		ctx:      r.Context(),
+		results:  results,
 	})
 	if err != nil {
 		log.Println("GetFileMapHandler: map queue is full")
@@ -79,14 +85,15 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	case <-r.Context().Done():
 		http.Error(w, "Request was cancelled", http.StatusRequestTimeout)
 		return
-	case maps := <-results:
-		if maps == nil {
+	case res := <-results:
+		if res == nil {
 			http.Error(w, "Mapping timed out", http.StatusRequestTimeout)
 			return
 		}
 
 		resp := shared.GetFileMapResponse{
-			MapBodies: maps,
+			MapBodies: res.bodies,
+			MapDeps:   res.deps,
 		}
 		respBytes, err := json.Marshal(resp)
 		if err != nil {
@@ -113,7 +120,11 @@ func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId, "branchName: ", branchName)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -141,10 +152,13 @@ func LoadCachedFileMapHandler(w http.ResponseWriter, r *http.Request) {
 				mu.Lock()
 				cachedMetaByPath[path] = cachedContext.ToMeta().ToApi()
 				cachedMapsByPath[path] = &db.CachedMap{
-					MapParts:  cachedContext.MapParts,
-					MapShas:   cachedContext.MapShas,
-					MapTokens: cachedContext.MapTokens,
-					MapSizes:  cachedContext.MapSizes,
+					MapParts:     cachedContext.MapParts,
+					MapShas:      cachedContext.MapShas,
+					MapTokens:    cachedContext.MapTokens,
+					MapSizes:     cachedContext.MapSizes,
+					MapDeps:      cachedContext.MapDeps,
+					MapChangedAt: cachedContext.MapChangedAt,
+					MapGenerated: cachedContext.MapGenerated,
 				}
 				mu.Unlock()
 			}
@@ -19,9 +19,16 @@ var fileMapMaxConcurrency = 2  // set to half of available CPUs below
 const mapJobTimeout = 60 * time.Second
 
 type projectMapJob struct {
-	inputs  shared.FileMapInputs
-	ctx     context.Context
-	results chan shared.FileMapBodies
+	inputs shared.FileMapInputs
+	// keys parsed trees cached between jobs so unchanged parts of files aren't mapped again -- empty disables the cache
+	cacheKey string
+	ctx      context.Context
+	results  chan *projectMapResult
+}
+
+type projectMapResult struct {
+	bodies shared.FileMapBodies
+	deps   shared.FileMapDepsByPath
 }
 
 var projectMapQueue = make(chan projectMapJob, fileMapMaxQueueSize)
@@ -47,9 +54,10 @@ func processProjectMapQueue() {
 		}
 		ctxWithTimeout, cancel := context.WithTimeout(job.ctx, mapJobTimeout)
 		mapWorker(projectMapJob{
-			inputs:  job.inputs,
-			ctx:     ctxWithTimeout,
-			results: job.results,
+			inputs:   job.inputs,
+			cacheKey: job.cacheKey,
+			ctx:      ctxWithTimeout,
+			results:  job.results,
 		})
 		cancel()
 	}
@@ -67,6 +75,7 @@ func queueProjectMapJob(job projectMapJob) error {
 func mapWorker(job projectMapJob) {
 	sem := make(chan struct{}, fileMapMaxConcurrency)
 	maps := make(shared.FileMapBodies)
+	deps := make(shared.FileMapDepsByPath)
 	wg := sync.WaitGroup{}
 	var mu sync.Mutex
 
@@ -88,7 +97,7 @@ func mapWorker(job projectMapJob) {
 			if job.ctx.Err() != nil {
 				return
 			}
-			fileMap, err := file_map.MapFile(job.ctx, path, []byte(input))
+			fileMap, err := file_map.MapFileIncremental(job.ctx, job.cacheKey, path, []byte(input))
 			if err != nil {
 				// Skip files that can't be parsed, just log the error
 				log.Printf("Error mapping file %s: %v", path, err)
@@ -99,6 +108,9 @@ func mapWorker(job projectMapJob) {
 			}
 			mu.Lock()
 			maps[path] = fileMap.String()
+			if fileMap.Deps != nil {
+				deps[path] = fileMap.Deps
+			}
 			mu.Unlock()
 		}(path, input)
 	}
@@ -110,5 +122,8 @@ func mapWorker(job projectMapJob) {
 		return
 	}
 
-	job.results <- maps
+	job.results <- &projectMapResult{
+		bodies: maps,
+		deps:   deps,
+	}
 }
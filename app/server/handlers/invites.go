@@ -62,6 +62,20 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	req.Email = strings.ToLower(req.Email)
 
+	// custom roles from other orgs share the permission names, so make sure the role belongs to this org
+	validRole, err := db.ValidateOrgRole(auth.OrgId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error validating org role: %v\n", err)
+		http.Error(w, "Error validating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !validRole {
+		log.Printf("Invalid org role: %v\n", req.OrgRoleId)
+		http.Error(w, "Invalid org role: "+req.OrgRoleId, http.StatusBadRequest)
+		return
+	}
+
 	// ensure current user can invite target user
 	permission := shared.Permission(strings.Join([]string{string(shared.PermissionInviteUser), req.OrgRoleId}, "|"))
 
@@ -125,15 +139,17 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	newInvite := &db.Invite{
+		OrgId:     auth.OrgId,
+		OrgRoleId: req.OrgRoleId,
+		Email:     req.Email,
+		Name:      req.Name,
+		InviterId: currentUserId,
+	}
+
 	err = db.WithTx(r.Context(), "invite user", func(tx *sqlx.Tx) error {
 
-		err = db.CreateInvite(&db.Invite{
-			OrgId:     auth.OrgId,
-			OrgRoleId: req.OrgRoleId,
-			Email:     req.Email,
-			Name:      req.Name,
-			InviterId: currentUserId,
-		}, tx)
+		err = db.CreateInvite(newInvite, tx)
 
 		if err != nil {
 			log.Printf("Error creating invite: %v\n", err)
@@ -156,6 +172,13 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionInviteCreate,
+		targetType: shared.AuditTargetInvite,
+		targetId:   newInvite.Id,
+		after:      auditJson(map[string]string{"email": req.Email, "orgRoleId": req.OrgRoleId}),
+	})
+
 	log.Println("Successfully created invite")
 }
 
@@ -403,5 +426,12 @@ func DeleteInviteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionInviteDelete,
+		targetType: shared.AuditTargetInvite,
+		targetId:   inviteId,
+		before:     auditJson(map[string]string{"email": invite.Email, "orgRoleId": invite.OrgRoleId}),
+	})
+
 	log.Println("Successfully deleted invite")
 }
@@ -20,6 +20,10 @@ func CreateCustomModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageCustomModels) {
+		return
+	}
+
 	var model shared.AvailableModel
 	if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
 		log.Printf("Error decoding request body: %v\n", err)
@@ -91,6 +95,10 @@ func DeleteAvailableModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageCustomModels) {
+		return
+	}
+
 	modelId := mux.Vars(r)["modelId"]
 	if err := db.DeleteAvailableModel(modelId); err != nil {
 		log.Printf("Error deleting custom model: %v\n", err)
@@ -111,6 +119,10 @@ func CreateModelPackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageCustomModels) {
+		return
+	}
+
 	var ms shared.ModelPack
 	if err := json.NewDecoder(r.Body).Decode(&ms); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -175,6 +187,10 @@ func DeleteModelPackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageCustomModels) {
+		return
+	}
+
 	setId := mux.Vars(r)["setId"]
 
 	log.Printf("Deleting model pack with id: %s\n", setId)
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+func CreateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	var req shared.CreateOrgRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Error decoding request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Label = strings.TrimSpace(req.Label)
+
+	if !validateOrgRoleRequest(w, auth, "", req.Label, req.Permissions) {
+		return
+	}
+
+	role := &db.OrgRole{
+		OrgId:       &auth.OrgId,
+		Name:        db.OrgRoleNameForLabel(req.Label),
+		Label:       req.Label,
+		Description: req.Description,
+	}
+
+	err = db.WithTx(r.Context(), "create org role", func(tx *sqlx.Tx) error {
+		return db.CreateOrgRole(role, req.Permissions, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error creating org role: %v\n", err)
+		http.Error(w, "Error creating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = req.Permissions
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionOrgRoleCreate,
+		targetType: shared.AuditTargetOrgRole,
+		targetId:   role.Id,
+		after:      auditJson(apiRole),
+	})
+
+	w.Write(bytes)
+
+	log.Println("Successfully created org role")
+}
+
+func UpdateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	roleId := mux.Vars(r)["roleId"]
+
+	log.Println("roleId: ", roleId)
+
+	role := getCustomOrgRole(w, auth, roleId)
+	if role == nil {
+		return
+	}
+
+	var req shared.UpdateOrgRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Error decoding request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Label = strings.TrimSpace(req.Label)
+
+	if !validateOrgRoleRequest(w, auth, role.Id, req.Label, req.Permissions) {
+		return
+	}
+
+	// for the audit log
+	before := role.ToApi()
+	prevPermissions, err := db.GetOrgRolePermissions([]string{role.Id})
+	if err != nil {
+		log.Printf("Error getting org role permissions: %v\n", err)
+		http.Error(w, "Error getting org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	before.Permissions = prevPermissions[role.Id]
+
+	role.Name = db.OrgRoleNameForLabel(req.Label)
+	role.Label = req.Label
+	role.Description = req.Description
+
+	err = db.WithTx(r.Context(), "update org role", func(tx *sqlx.Tx) error {
+		return db.UpdateOrgRole(role, req.Permissions, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error updating org role: %v\n", err)
+		http.Error(w, "Error updating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	after := role.ToApi()
+	after.Permissions = req.Permissions
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionOrgRoleUpdate,
+		targetType: shared.AuditTargetOrgRole,
+		targetId:   role.Id,
+		before:     auditJson(before),
+		after:      auditJson(after),
+	})
+
+	log.Println("Successfully updated org role")
+}
+
+func DeleteOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	roleId := mux.Vars(r)["roleId"]
+
+	log.Println("roleId: ", roleId)
+
+	role := getCustomOrgRole(w, auth, roleId)
+	if role == nil {
+		return
+	}
+
+	numMembers, err := db.CountOrgRoleMembers(role.Id)
+	if err != nil {
+		log.Printf("Error counting org role members: %v\n", err)
+		http.Error(w, "Error counting org role members: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if numMembers > 0 {
+		log.Printf("Org role %s still has %d users or invites\n", role.Id, numMembers)
+		http.Error(w, fmt.Sprintf("Role '%s' still has %d users or pending invites -- change their role first", role.Label, numMembers), http.StatusBadRequest)
+		return
+	}
+
+	err = db.WithTx(r.Context(), "delete org role", func(tx *sqlx.Tx) error {
+		return db.DeleteOrgRole(role.Id, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error deleting org role: %v\n", err)
+		http.Error(w, "Error deleting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionOrgRoleDelete,
+		targetType: shared.AuditTargetOrgRole,
+		targetId:   role.Id,
+		before:     auditJson(role.ToApi()),
+	})
+
+	log.Println("Successfully deleted org role")
+}
+
+func authorizeManageOrgRoles(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't manage org roles",
+		})
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgRoles) {
+		log.Println("User cannot manage org roles")
+		http.Error(w, "User cannot manage org roles", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// getCustomOrgRole gets a role that belongs to the org -- default roles are shared by all orgs and can't be changed
+func getCustomOrgRole(w http.ResponseWriter, auth *types.ServerAuth, roleId string) *db.OrgRole {
+	role, err := db.GetOrgRole(auth.OrgId, roleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if role == nil {
+		log.Println("Org role not found")
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return nil
+	}
+
+	if role.OrgId == nil {
+		log.Println("Default org roles can't be changed")
+		http.Error(w, "Default org roles can't be changed", http.StatusBadRequest)
+		return nil
+	}
+
+	return role
+}
+
+func validateOrgRoleRequest(w http.ResponseWriter, auth *types.ServerAuth, roleId, label string, permissions []shared.Permission) bool {
+	if label == "" {
+		log.Println("Role label is required")
+		http.Error(w, "Role label is required", http.StatusBadRequest)
+		return false
+	}
+
+	name := db.OrgRoleNameForLabel(label)
+
+	roles, err := db.ListOrgRoles(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing org roles: %v\n", err)
+		http.Error(w, "Error listing org roles: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	for _, role := range roles {
+		if role.Id != roleId && (role.Name == name || strings.EqualFold(role.Label, label)) {
+			log.Printf("Org role %s already exists\n", label)
+			http.Error(w, "A role named '"+label+"' already exists", http.StatusBadRequest)
+			return false
+		}
+	}
+
+	for _, permission := range permissions {
+		if !shared.IsCustomRolePermission(permission) {
+			log.Printf("Permission can't be given to a custom role: %v\n", permission)
+			http.Error(w, "Permission can't be given to a custom role: "+string(permission), http.StatusBadRequest)
+			return false
+		}
+
+		// a role can't be granted anything its creator couldn't do
+		if !auth.HasPermission(permission) {
+			log.Printf("User does not have permission: %v\n", permission)
+			http.Error(w, "User does not have permission: "+string(permission), http.StatusForbidden)
+			return false
+		}
+	}
+
+	return true
+}
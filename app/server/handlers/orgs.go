@@ -9,6 +9,7 @@ import (
 	"os"
 	"plandex-server/db"
 	"plandex-server/hooks"
+	"plandex-server/types"
 
 	shared "plandex-shared"
 
@@ -100,7 +101,7 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// create a new org
-		org, err = db.CreateOrg(&req, auth.AuthToken.UserId, domain, tx)
+		org, err = db.CreateOrg(&req, auth.User.Id, domain, tx)
 
 		if err != nil {
 			log.Printf("Error creating org: %v\n", err)
@@ -158,6 +159,14 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the new org's log records its creation
+	recordAudit(r, &types.ServerAuth{User: auth.User, ApiToken: auth.ApiToken, OrgId: org.Id}, auditParams{
+		action:     shared.AuditActionOrgCreate,
+		targetType: shared.AuditTargetOrg,
+		targetId:   org.Id,
+		after:      auditJson(map[string]interface{}{"name": org.Name, "autoAddDomainUsers": org.AutoAddDomainUsers}),
+	})
+
 	log.Println("Successfully created org")
 
 	w.Write(bytes)
@@ -238,9 +247,24 @@ func ListOrgRolesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var roleIds []string
+	for _, role := range roles {
+		roleIds = append(roleIds, role.Id)
+	}
+
+	permissionsByRoleId, err := db.GetOrgRolePermissions(roleIds)
+
+	if err != nil {
+		log.Printf("Error getting org role permissions: %v\n", err)
+		http.Error(w, "Error getting org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	var apiRoles []*shared.OrgRole
 	for _, role := range roles {
-		apiRoles = append(apiRoles, role.ToApi())
+		apiRole := role.ToApi()
+		apiRole.Permissions = permissionsByRoleId[role.Id]
+		apiRoles = append(apiRoles, apiRole)
 	}
 
 	bytes, err := json.Marshal(apiRoles)
@@ -66,7 +66,11 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -79,6 +83,27 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Config == nil {
+		log.Println("Missing plan config")
+		http.Error(w, "Missing plan config", http.StatusBadRequest)
+		return
+	}
+
+	err = req.Config.Validate()
+	if err != nil {
+		log.Println("Invalid plan config: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// for the audit log
+	prevConfig, err := db.GetPlanConfig(planId)
+	if err != nil {
+		log.Println("Error getting plan config: ", err)
+		http.Error(w, "Error getting plan config", http.StatusInternalServerError)
+		return
+	}
+
 	err = db.StorePlanConfig(planId, req.Config)
 	if err != nil {
 		log.Println("Error storing plan config: ", err)
@@ -86,6 +111,15 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanConfig,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     auditJson(prevConfig),
+		after:      auditJson(req.Config),
+	})
+
 	log.Println("UpdatePlanConfigHandler processed successfully")
 }
 
@@ -135,6 +169,27 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Config == nil {
+		log.Println("Missing plan config")
+		http.Error(w, "Missing plan config", http.StatusBadRequest)
+		return
+	}
+
+	err = req.Config.Validate()
+	if err != nil {
+		log.Println("Invalid plan config: ", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// for the audit log
+	prevConfig, err := db.GetDefaultPlanConfig(auth.User.Id)
+	if err != nil {
+		log.Println("Error getting default plan config: ", err)
+		http.Error(w, "Error getting default plan config", http.StatusInternalServerError)
+		return
+	}
+
 	err = db.WithTx(r.Context(), "update default plan config", func(tx *sqlx.Tx) error {
 
 		err := db.StoreDefaultPlanConfig(auth.User.Id, req.Config, tx)
@@ -152,5 +207,13 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionDefaultConfig,
+		targetType: shared.AuditTargetUser,
+		targetId:   auth.User.Id,
+		before:     auditJson(prevConfig),
+		after:      auditJson(req.Config),
+	})
+
 	log.Println("UpdateDefaultPlanConfigHandler processed successfully")
 }
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func SharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	plan := authorizePlanManageShares(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	var req shared.SharePlanRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Error decoding request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !req.Level.IsValid() {
+		log.Printf("Invalid share level: %v\n", req.Level)
+		http.Error(w, "Invalid share level: "+string(req.Level), http.StatusBadRequest)
+		return
+	}
+
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	if (req.Email == "") == !req.WholeProject {
+		log.Println("Share needs either an email or the whole project")
+		http.Error(w, "Share needs either an email or the whole project", http.StatusBadRequest)
+		return
+	}
+
+	share := &db.PlanShare{
+		OrgId:     auth.OrgId,
+		PlanId:    plan.Id,
+		Level:     req.Level,
+		CreatedBy: auth.User.Id,
+	}
+
+	var user *db.User
+
+	if req.WholeProject {
+		share.ProjectId = &plan.ProjectId
+	} else {
+		user, err = db.GetUserByEmail(req.Email)
+		if err != nil {
+			log.Printf("Error getting user: %v\n", err)
+			http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		isMember := false
+		if user != nil {
+			isMember, err = db.ValidateOrgMembership(user.Id, auth.OrgId)
+			if err != nil {
+				log.Printf("Error validating org membership: %v\n", err)
+				http.Error(w, "Error validating org membership: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if !isMember {
+			log.Printf("User %s is not a member of the org\n", req.Email)
+			http.Error(w, "No user in org with email "+req.Email, http.StatusNotFound)
+			return
+		}
+
+		if user.Id == plan.OwnerId {
+			log.Println("Can't share a plan with its owner")
+			http.Error(w, "Can't share a plan with its owner", http.StatusBadRequest)
+			return
+		}
+
+		share.UserId = &user.Id
+	}
+
+	err = db.UpsertPlanShare(share)
+	if err != nil {
+		log.Printf("Error sharing plan: %v\n", err)
+		http.Error(w, "Error sharing plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiShare := share.ToApi()
+	if user != nil {
+		apiShare.UserEmail = user.Email
+		apiShare.UserName = user.Name
+	}
+
+	bytes, err := json.Marshal(apiShare)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanShare,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		after:      auditJson(apiShare),
+	})
+
+	w.Write(bytes)
+
+	log.Println("Successfully shared plan")
+}
+
+func ListPlanSharesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanSharesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	shares, err := db.ListPlanShares(planId)
+	if err != nil {
+		log.Printf("Error listing plan shares: %v\n", err)
+		http.Error(w, "Error listing plan shares: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var userIds []string
+	for _, share := range shares {
+		if share.UserId != nil {
+			userIds = append(userIds, *share.UserId)
+		}
+	}
+
+	usersById, err := db.GetUsersById(userIds)
+	if err != nil {
+		log.Printf("Error getting users: %v\n", err)
+		http.Error(w, "Error getting users: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiShares := make([]*shared.PlanShare, 0, len(shares))
+	for _, share := range shares {
+		apiShare := share.ToApi()
+		if share.UserId != nil {
+			if user, ok := usersById[*share.UserId]; ok {
+				apiShare.UserEmail = user.Email
+				apiShare.UserName = user.Name
+			}
+		}
+		apiShares = append(apiShares, apiShare)
+	}
+
+	bytes, err := json.Marshal(apiShares)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed plan shares")
+}
+
+func UnsharePlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UnsharePlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	shareId := vars["shareId"]
+
+	log.Println("planId: ", planId, "shareId: ", shareId)
+
+	if authorizePlanManageShares(w, planId, auth) == nil {
+		return
+	}
+
+	share, err := db.GetPlanShare(planId, shareId)
+	if err != nil {
+		log.Printf("Error getting plan share: %v\n", err)
+		http.Error(w, "Error getting plan share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if share == nil {
+		log.Println("Plan share not found")
+		http.Error(w, "Plan share not found", http.StatusNotFound)
+		return
+	}
+
+	err = db.DeletePlanShare(share.Id)
+	if err != nil {
+		log.Printf("Error deleting plan share: %v\n", err)
+		http.Error(w, "Error deleting plan share: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanUnshare,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     auditJson(share.ToApi()),
+	})
+
+	log.Println("Successfully unshared plan")
+}
+
+func ListSharedPlansHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListSharedPlansHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	projectIds := r.URL.Query()["projectId"]
+
+	log.Println("projectIds: ", projectIds)
+
+	res := shared.ListSharedPlansResponse{
+		Plans:             []*shared.Plan{},
+		LevelByPlanId:     map[string]shared.PlanShareLevel{},
+		OwnerNameByPlanId: map[string]string{},
+	}
+
+	authorizedProjectIds := []string{}
+	for _, projectId := range projectIds {
+		if authorizeProjectOptional(w, projectId, auth, false) {
+			authorizedProjectIds = append(authorizedProjectIds, projectId)
+		}
+	}
+
+	if len(authorizedProjectIds) > 0 {
+		plans, levelByPlanId, err := db.ListSharedPlans(authorizedProjectIds, auth.User.Id)
+		if err != nil {
+			log.Printf("Error listing shared plans: %v\n", err)
+			http.Error(w, "Error listing shared plans: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var ownerIds []string
+		for _, plan := range plans {
+			ownerIds = append(ownerIds, plan.OwnerId)
+		}
+
+		ownersById, err := db.GetUsersById(ownerIds)
+		if err != nil {
+			log.Printf("Error getting plan owners: %v\n", err)
+			http.Error(w, "Error getting plan owners: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, plan := range plans {
+			res.Plans = append(res.Plans, plan.ToApi())
+			res.LevelByPlanId[plan.Id] = levelByPlanId[plan.Id]
+			if owner, ok := ownersById[plan.OwnerId]; ok {
+				res.OwnerNameByPlanId[plan.Id] = owner.Name
+			}
+		}
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed shared plans")
+}
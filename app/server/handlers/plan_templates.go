@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for ListPlanTemplatesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	templates, err := db.ListPlanTemplates(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing plan templates: %v\n", err)
+		http.Error(w, "Error listing plan templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiTemplates []*shared.PlanTemplate
+	for _, template := range templates {
+		apiTemplates = append(apiTemplates, template.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiTemplates)
+	if err != nil {
+		log.Printf("Error marshalling plan templates: %v\n", err)
+		http.Error(w, "Error marshalling plan templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully listed plan templates")
+
+	w.Write(bytes)
+}
+
+func GetPlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for GetPlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	template := getPlanTemplateForRequest(w, r, auth.OrgId)
+	if template == nil {
+		return
+	}
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling plan template: %v\n", err)
+		http.Error(w, "Error marshalling plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully got plan template")
+
+	w.Write(bytes)
+}
+
+func CreatePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for CreatePlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManagePlanTemplates) {
+		return
+	}
+
+	var req shared.CreatePlanTemplateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template := &db.PlanTemplate{
+		OrgId:       auth.OrgId,
+		CreatorId:   auth.User.Id,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Prompt:      req.Prompt,
+		Context:     req.Context,
+		AutoMode:    req.AutoMode,
+		ModelPack:   req.ModelPack,
+	}
+
+	if !validatePlanTemplate(w, template) {
+		return
+	}
+
+	err = db.CreatePlanTemplate(template)
+	if err != nil {
+		log.Printf("Error creating plan template: %v\n", err)
+		http.Error(w, "Error creating plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanTemplateCreate,
+		targetType: shared.AuditTargetPlanTemplate,
+		targetId:   template.Id,
+		after:      auditJson(template.ToApi()),
+	})
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully created plan template")
+
+	w.Write(bytes)
+}
+
+func UpdatePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for UpdatePlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManagePlanTemplates) {
+		return
+	}
+
+	template := getPlanTemplateForRequest(w, r, auth.OrgId)
+	if template == nil {
+		return
+	}
+
+	var req shared.UpdatePlanTemplateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := auditJson(template.ToApi())
+
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		template.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Prompt != nil {
+		template.Prompt = *req.Prompt
+	}
+	if req.Context != nil {
+		template.Context = *req.Context
+	}
+	if req.AutoMode != nil {
+		template.AutoMode = *req.AutoMode
+	}
+	if req.ModelPack != nil {
+		template.ModelPack = *req.ModelPack
+	}
+
+	if !validatePlanTemplate(w, template) {
+		return
+	}
+
+	err = db.UpdatePlanTemplate(template)
+	if err != nil {
+		log.Printf("Error updating plan template: %v\n", err)
+		http.Error(w, "Error updating plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanTemplateUpdate,
+		targetType: shared.AuditTargetPlanTemplate,
+		targetId:   template.Id,
+		before:     before,
+		after:      auditJson(template.ToApi()),
+	})
+
+	log.Println("Successfully updated plan template")
+}
+
+func DeletePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for DeletePlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManagePlanTemplates) {
+		return
+	}
+
+	template := getPlanTemplateForRequest(w, r, auth.OrgId)
+	if template == nil {
+		return
+	}
+
+	err := db.DeletePlanTemplate(auth.OrgId, template.Id)
+	if err != nil {
+		log.Printf("Error deleting plan template: %v\n", err)
+		http.Error(w, "Error deleting plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanTemplateDelete,
+		targetType: shared.AuditTargetPlanTemplate,
+		targetId:   template.Id,
+		before:     auditJson(template.ToApi()),
+	})
+
+	log.Println("Successfully deleted plan template")
+}
+
+func getPlanTemplateForRequest(w http.ResponseWriter, r *http.Request, orgId string) *db.PlanTemplate {
+	templateId := mux.Vars(r)["templateId"]
+
+	template, err := db.GetPlanTemplate(orgId, templateId)
+	if err != nil {
+		log.Printf("Error getting plan template: %v\n", err)
+		http.Error(w, "Error getting plan template: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if template == nil {
+		log.Println("Plan template not found")
+		http.Error(w, "Plan template not found", http.StatusNotFound)
+		return nil
+	}
+
+	return template
+}
+
+func validatePlanTemplate(w http.ResponseWriter, template *db.PlanTemplate) bool {
+	if !shared.IsValidPlanTemplateName(template.Name) {
+		log.Printf("Invalid plan template name: %s\n", template.Name)
+		http.Error(w, fmt.Sprintf("Invalid template name '%s' -- use up to %d lowercase letters, numbers, '-', '_', or '.'", template.Name, shared.MaxPlanTemplateNameLength), http.StatusBadRequest)
+		return false
+	}
+
+	if !shared.IsValidPlanTemplateAutoMode(template.AutoMode) {
+		log.Printf("Invalid plan template auto mode: %s\n", template.AutoMode)
+		http.Error(w, "Invalid auto mode: "+string(template.AutoMode), http.StatusBadRequest)
+		return false
+	}
+
+	if !shared.IsValidPlanTemplateModelPack(template.ModelPack) {
+		log.Printf("Invalid plan template model pack: %s\n", template.ModelPack)
+		http.Error(w, "Invalid model pack: "+template.ModelPack, http.StatusBadRequest)
+		return false
+	}
+
+	existing, err := db.GetPlanTemplateByName(template.OrgId, template.Name)
+	if err != nil {
+		log.Printf("Error checking plan template name: %v\n", err)
+		http.Error(w, "Error checking plan template name: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if existing != nil && existing.Id != template.Id {
+		log.Printf("Plan template %s already exists\n", template.Name)
+		http.Error(w, "A template named '"+template.Name+"' already exists", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/webhooks"
 	"time"
 
 	shared "plandex-shared"
@@ -116,7 +117,11 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionApplyPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -221,6 +226,7 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 			CurrentPlanState:       currentPlan,
 			CurrentPlanStateParams: &currentPlanParams,
 			CommitMsg:              commitMsg,
+			FormattedFiles:         requestBody.FormattedFiles,
 		})
 	})
 
@@ -230,6 +236,22 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanApply,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		after:      auditJson(map[string]string{"branch": branch, "commitMsg": commitMsg}),
+	})
+
+	webhooks.DispatchPlanEvent(shared.WebhookEventChangesApplied, webhooks.PlanEventParams{
+		OrgId:     auth.OrgId,
+		UserId:    auth.User.Id,
+		PlanId:    planId,
+		Branch:    branch,
+		CommitMsg: commitMsg,
+	})
+
 	w.Write([]byte(commitMsg))
 
 	log.Println("Successfully applied plan", planId)
@@ -248,7 +270,11 @@ func RejectAllChangesHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	if authorizePlanContribute(w, planId, auth) == nil {
 		return
 	}
 
@@ -283,6 +309,14 @@ func RejectAllChangesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanRejectAll,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		after:      auditJson(map[string]string{"branch": branch}),
+	})
+
 	log.Println("Successfully rejected all changes for plan", planId)
 }
 
@@ -300,7 +334,11 @@ func RejectFileHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	if authorizePlanContribute(w, planId, auth) == nil {
 		return
 	}
 
@@ -343,6 +381,14 @@ func RejectFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanRejectFiles,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		after:      auditJson(map[string]interface{}{"branch": branch, "paths": []string{req.FilePath}}),
+	})
+
 	log.Println("Successfully rejected plan file", req.FilePath)
 }
 
@@ -360,7 +406,11 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	if authorizePlanContribute(w, planId, auth) == nil {
 		return
 	}
 
@@ -413,6 +463,14 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanRejectFiles,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		after:      auditJson(map[string]interface{}{"branch": branch, "paths": req.Paths}),
+	})
+
 	log.Println("Successfully rejected plan files", req.Paths)
 }
 
@@ -461,6 +519,14 @@ func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanArchive,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     plan.Name,
+	})
+
 	log.Println("Successfully archived plan", planId)
 }
 
@@ -509,6 +575,14 @@ func UnarchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanUnarchive,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     plan.Name,
+	})
+
 	log.Println("Successfully unarchived plan", planId)
 }
 
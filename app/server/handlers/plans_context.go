@@ -164,7 +164,11 @@ func LoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -224,7 +228,11 @@ func UpdateContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -330,7 +338,11 @@ func DeleteContextHandler(w http.ResponseWriter, r *http.Request) {
 	branchName := vars["branch"]
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 
 	if plan == nil {
 		return
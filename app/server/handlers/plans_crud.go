@@ -62,6 +62,22 @@ func CreatePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var template *db.PlanTemplate
+	if requestBody.TemplateId != "" {
+		template, err = db.GetPlanTemplate(auth.OrgId, requestBody.TemplateId)
+		if err != nil {
+			log.Printf("Error getting plan template: %v\n", err)
+			http.Error(w, "Error getting plan template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if template == nil {
+			log.Println("Plan template not found")
+			http.Error(w, "Plan template not found", http.StatusNotFound)
+			return
+		}
+	}
+
 	name := requestBody.Name
 	if name == "" {
 		name = "draft"
@@ -119,6 +135,20 @@ func CreatePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	auditAfter := map[string]string{"name": plan.Name, "projectId": projectId}
+	if template != nil {
+		auditAfter["templateId"] = template.Id
+		auditAfter["template"] = template.Name
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanCreate,
+		targetType: shared.AuditTargetPlan,
+		targetId:   plan.Id,
+		planId:     plan.Id,
+		after:      auditJson(auditAfter),
+	})
+
 	w.Write(bytes)
 
 	log.Printf("Successfully created plan: %v\n", plan)
@@ -167,7 +197,7 @@ func RenamePlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanContribute(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -200,6 +230,15 @@ func RenamePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanRename,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     plan.Name,
+		after:      requestBody.Name,
+	})
+
 	log.Println("Successfully renamed plan")
 }
 
@@ -257,6 +296,14 @@ func DeletePlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanDelete,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     auditJson(map[string]string{"name": plan.Name, "projectId": plan.ProjectId}),
+	})
+
 	log.Println("Successfully deleted plan", planId)
 }
 
@@ -285,6 +332,12 @@ func DeleteAllPlansHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanDeleteAll,
+		targetType: shared.AuditTargetProject,
+		targetId:   projectId,
+	})
+
 	log.Println("Successfully deleted all plans")
 }
 
@@ -430,6 +483,17 @@ func ListPlansRunningHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// include plans shared with the user so collaborators can connect to their streams
+	sharedPlans, _, err := db.ListSharedPlans(projectIds, auth.User.Id)
+
+	if err != nil {
+		log.Printf("Error listing shared plans: %v\n", err)
+		http.Error(w, "Error listing shared plans: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	plans = append(plans, sharedPlans...)
+
 	var planIds []string
 	for _, plan := range plans {
 		planIds = append(planIds, plan.Id)
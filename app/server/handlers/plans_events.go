@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/host"
+	modelPlan "plandex-server/model/plan"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+// PlanEventsHandler streams an active plan as server-sent events, for clients other than the CLI. Each shared.StreamMessage becomes an event named for its type, with the message json as data and its seq as the event id, so standard SSE clients can resume with Last-Event-ID.
+func PlanEventsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for PlanEventsHandler", "ip:", host.Ip)
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	log.Println("planId: ", planId)
+	log.Println("branch: ", branch)
+	active := modelPlan.GetActivePlan(planId, branch)
+	isProxy := r.URL.Query().Get("proxy") == "true"
+
+	if active == nil {
+		if isProxy {
+			log.Println("No active plan on proxied request")
+			http.Error(w, "No active plan", http.StatusNotFound)
+			return
+		}
+
+		log.Println("No active plan -- proxying request")
+
+		proxyActivePlanMethod(w, r, planId, branch, "events")
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	resumeSeq, err := getResumeSeq(r)
+	if err != nil {
+		log.Printf("Error getting resume seq: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disable response buffering in nginx and similar proxies
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	streamActivePlan(r.Context(), auth, active, planId, branch, true, false, resumeSeq, func(msg string) error {
+		return sendSSEMessage(w, msg)
+	})
+
+	log.Println("Successfully processed request for PlanEventsHandler")
+}
+
+func sendSSEMessage(w http.ResponseWriter, msg string) error {
+	var buf bytes.Buffer
+
+	if msg == string(shared.StreamMessageHeartbeat) {
+		// comment lines keep the connection alive without dispatching an event
+		buf.WriteString(": heartbeat\n\n")
+	} else {
+		var streamMsg shared.StreamMessage
+		err := json.Unmarshal([]byte(msg), &streamMsg)
+		if err != nil {
+			return fmt.Errorf("error unmarshalling stream message: %v", err)
+		}
+
+		// batched messages are unpacked so each one gets its own typed event
+		events := []shared.StreamMessage{streamMsg}
+		if streamMsg.Type == shared.StreamMessageMulti {
+			events = streamMsg.StreamMessages
+		}
+
+		for i, event := range events {
+			event.Seq = streamMsg.Seq
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("error marshalling event: %v", err)
+			}
+
+			// only the last event of a batch gets the id so a resumed client replays the whole batch rather than part of it
+			if i == len(events)-1 && streamMsg.Seq > 0 {
+				fmt.Fprintf(&buf, "id: %d\n", streamMsg.Seq)
+			}
+			fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	if err != nil {
+		log.Printf("Response stream manager: error writing event to client: %v\n", err)
+		return err
+	} else if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -32,11 +32,6 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	plan := authorizePlanExecUpdate(w, planId, auth)
-	if plan == nil {
-		return
-	}
-
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.Printf("Error reading request body: %v\n", err)
@@ -55,6 +50,21 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// collaborators with comment access can chat, but not make changes
+	level := shared.PlanShareLevelContributor
+	if requestBody.IsChatOnly {
+		level = shared.PlanShareLevelComment
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanExecUpdate(w, planId, auth, level)
+	if plan == nil {
+		return
+	}
+
 	_, apiErr := hooks.ExecHook(hooks.WillTellPlan, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
@@ -84,13 +94,86 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requestBody.SemanticCheck && requestBody.ConnectStream {
+		modelPlan.UpdateActivePlan(planId, branch, func(ap *types.ActivePlan) {
+			ap.SemanticCheck = true
+		})
+	}
+
 	if requestBody.ConnectStream {
-		startResponseStream(r.Context(), w, auth, planId, branch, false)
+		startResponseStream(r.Context(), w, auth, planId, branch, false, requestBody.SemanticCheck, nil)
+	} else {
+		modelPlan.UpdateActivePlan(planId, branch, func(ap *types.ActivePlan) {
+			ap.IsBackground = true
+		})
 	}
 
 	log.Println("Successfully processed request for TellPlanHandler")
 }
 
+func TellPlanPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for TellPlanPreviewHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var requestBody shared.TellPlanRequest
+	if err := json.Unmarshal(body, &requestBody); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	// same access as sending the prompt
+	level := shared.PlanShareLevelContributor
+	if requestBody.IsChatOnly {
+		level = shared.PlanShareLevelComment
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanExecUpdate(w, planId, auth, level)
+	if plan == nil {
+		return
+	}
+
+	res, err := modelPlan.PreviewTell(r.Context(), plan, branch, auth, &requestBody)
+	if err != nil {
+		log.Printf("Error previewing tell: %v\n", err)
+		http.Error(w, "Error previewing prompt: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for TellPlanPreviewHandler")
+}
+
 func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for BuildPlanHandler", "ip:", host.Ip)
 	auth := Authenticate(w, r, true)
@@ -103,7 +186,12 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 
 	log.Println("planId: ", planId)
-	plan := authorizePlanExecUpdate(w, planId, auth)
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanExecUpdate(w, planId, auth, shared.PlanShareLevelContributor)
 	if plan == nil {
 		return
 	}
@@ -152,8 +240,18 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requestBody.SemanticCheck && requestBody.ConnectStream {
+		modelPlan.UpdateActivePlan(planId, branch, func(ap *types.ActivePlan) {
+			ap.SemanticCheck = true
+		})
+	}
+
 	if requestBody.ConnectStream {
-		startResponseStream(r.Context(), w, auth, planId, branch, false)
+		startResponseStream(r.Context(), w, auth, planId, branch, false, requestBody.SemanticCheck, nil)
+	} else {
+		modelPlan.UpdateActivePlan(planId, branch, func(ap *types.ActivePlan) {
+			ap.IsBackground = true
+		})
 	}
 
 	log.Println("Successfully processed request for BuildPlanHandler")
@@ -195,7 +293,14 @@ func ConnectPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startResponseStream(r.Context(), w, auth, planId, branch, true)
+	resumeSeq, err := getResumeSeq(r)
+	if err != nil {
+		log.Printf("Error getting resume seq: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	startResponseStream(r.Context(), w, auth, planId, branch, true, false, resumeSeq)
 
 	log.Println("Successfully processed request for ConnectPlanHandler")
 }
@@ -226,7 +331,7 @@ func StopPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if authorizePlan(w, planId, auth) == nil {
+	if authorizePlanShareLevel(w, planId, auth, shared.PlanShareLevelComment) == nil {
 		return
 	}
 
@@ -303,7 +408,7 @@ func RespondMissingFileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanShareLevel(w, planId, auth, shared.PlanShareLevelComment)
 	if plan == nil {
 		return
 	}
@@ -398,7 +503,7 @@ func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanShareLevel(w, planId, auth, shared.PlanShareLevelComment)
 	if plan == nil {
 		return
 	}
@@ -495,6 +600,69 @@ func AutoLoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully processed request for AutoLoadContextHandler")
 }
 
+func RespondSemanticCheckHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RespondSemanticCheckHandler", "ip:", host.Ip)
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	log.Println("planId: ", planId)
+	log.Println("branch: ", branch)
+	isProxy := r.URL.Query().Get("proxy") == "true"
+
+	active := modelPlan.GetActivePlan(planId, branch)
+	if active == nil {
+		if isProxy {
+			log.Println("No active plan on proxied request")
+			http.Error(w, "No active plan", http.StatusNotFound)
+			return
+		}
+
+		proxyActivePlanMethod(w, r, planId, branch, "respond_semantic_check")
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// check results decide whether built files are accepted, so they can only come from the user who started the build or a contributor
+	var plan *db.Plan
+	if auth.User.Id == active.UserId {
+		plan = authorizePlan(w, planId, auth)
+	} else {
+		plan = authorizePlanContribute(w, planId, auth)
+	}
+	if plan == nil {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var requestBody shared.RespondSemanticCheckRequest
+	if err := json.Unmarshal(body, &requestBody); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("semantic check %s: checker %q, %d diagnostics\n", requestBody.Id, requestBody.Checker, len(requestBody.Diagnostics))
+
+	if !active.RespondSemanticCheck(requestBody) {
+		// the build stopped waiting -- not an error for the client
+		log.Printf("No build waiting on semantic check %s\n", requestBody.Id)
+	}
+
+	log.Println("Successfully processed request for RespondSemanticCheckHandler")
+}
+
 func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 	// logs are too chatty on this function, uncomment if you need to debug
 	// log.Println("Received request for GetBuildStatusHandler", "ip:", host.Ip)
@@ -513,7 +681,7 @@ func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		proxyActivePlanMethod(w, r, planId, branch, "auto_load_context")
+		proxyActivePlanMethod(w, r, planId, branch, "build_status")
 		return
 	}
 
@@ -544,15 +712,13 @@ func GetBuildStatusHandler(w http.ResponseWriter, r *http.Request) {
 	// log.Println("Successfully processed request for GetBuildStatusHandler")
 }
 
-func authorizePlanExecUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+func authorizePlanExecUpdate(w http.ResponseWriter, planId string, auth *types.ServerAuth, level shared.PlanShareLevel) *db.Plan {
 	plan := authorizePlan(w, planId, auth)
 	if plan == nil {
 		return nil
 	}
 
-	if plan.OwnerId != auth.User.Id && !auth.HasPermission(shared.PermissionUpdateAnyPlan) {
-		log.Println("User does not have permission to update plan")
-		http.Error(w, "User does not have permission to update plan", http.StatusForbidden)
+	if plan.OwnerId != auth.User.Id && !hasPlanShareLevel(w, plan, auth, level) {
 		return nil
 	}
 
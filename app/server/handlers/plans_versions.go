@@ -93,7 +93,11 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId)
 
-	if authorizePlan(w, planId, auth) == nil {
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	if authorizePlanContribute(w, planId, auth) == nil {
 		return
 	}
 
@@ -115,6 +119,8 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx, cancel := context.WithCancel(r.Context())
 
+	var prevSha string
+
 	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
 		OrgId:    auth.OrgId,
 		UserId:   auth.User.Id,
@@ -125,6 +131,13 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 		Ctx:      ctx,
 		CancelFn: cancel,
 	}, func(repo *db.GitRepo) error {
+		var err error
+		// for the audit log
+		prevSha, _, err = repo.GetLatestCommit(branch)
+		if err != nil {
+			return err
+		}
+
 		return repo.GitRewindToSha(branch, requestBody.Sha)
 	})
 
@@ -178,6 +191,15 @@ func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanRewind,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     auditJson(map[string]string{"branch": branch, "sha": prevSha}),
+		after:      auditJson(map[string]string{"branch": branch, "sha": sha}),
+	})
+
 	w.Write(bytes)
 
 	log.Println("Successfully processed request for RewindPlanHandler")
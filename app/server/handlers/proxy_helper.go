@@ -8,6 +8,7 @@ import (
 	"os"
 	"plandex-server/db"
 	"plandex-server/host"
+	"plandex-server/streambus"
 	"time"
 
 	shared "plandex-shared"
@@ -28,6 +29,11 @@ func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branc
 		return
 	}
 
+	if streambus.Enabled() {
+		forwardActivePlanMethod(w, r, planId, branch, modelStream)
+		return
+	}
+
 	if modelStream.InternalIp == host.Ip {
 		// No active plan for this plan or else we wouldn't be calling proxyActivePlanMethod -- set the model stream to finished because something went wrong
 		err := db.SetModelStreamFinished(modelStream.Id)
@@ -47,6 +53,9 @@ func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branc
 		log.Printf("Forwarding request to %s\n", modelStream.InternalIp)
 		proxyUrl := fmt.Sprintf("http://%s:%s/plans/%s/%s/%s", modelStream.InternalIp, os.Getenv("PORT"), planId, branch, method)
 		proxyUrl += "?proxy=true"
+		if r.URL.RawQuery != "" {
+			proxyUrl += "&" + r.URL.RawQuery
+		}
 
 		log.Printf("Proxy url: %s\n", proxyUrl)
 		proxyRequest(w, r, proxyUrl)
@@ -54,6 +63,43 @@ func proxyActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branc
 	}
 }
 
+func forwardActivePlanMethod(w http.ResponseWriter, r *http.Request, planId, branch string, modelStream *db.ModelStream) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	log.Printf("Forwarding request for plan %s over stream bus\n", planId)
+
+	handled, err := streambus.Forward(w, r, planId, branch, body)
+	if err != nil {
+		log.Printf("Error forwarding request over stream bus: %v\n", err)
+		if !handled {
+			http.Error(w, "Error forwarding request", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !handled {
+		// no instance responded for the plan, so the stream is orphaned
+		err := db.SetModelStreamFinished(modelStream.Id)
+		if err != nil {
+			log.Printf("Error setting model stream %s to finished: %v\n", modelStream.Id, err)
+		}
+
+		err = db.SetPlanStatus(planId, branch, shared.PlanStatusError, "No active stream for plan")
+		if err != nil {
+			log.Printf("Error setting plan %s status to error: %v\n", planId, err)
+		}
+
+		log.Printf("No instance responded for active plan %s\n", planId)
+		http.Error(w, "No active plan for plan", http.StatusNotFound)
+	}
+}
+
 func proxyRequest(w http.ResponseWriter, originalRequest *http.Request, url string) {
 	client := &http.Client{
 		Timeout: time.Second * 10,
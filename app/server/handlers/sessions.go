@@ -18,6 +18,10 @@ import (
 func CreateEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for CreateEmailVerificationHandler")
 
+	if ssoSignInDisabledError(w) {
+		return
+	}
+
 	// read the request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -104,7 +108,7 @@ func CreateEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		err = email.SendVerificationEmail(req.Email, string(pinBytes))
+		err = email.SendVerificationEmail(req.Email, string(pinBytes), hasAccount)
 
 		if err != nil {
 			log.Printf("Error sending verification email: %v\n", err)
@@ -227,6 +231,11 @@ func SignInHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// sign in codes are still allowed since they come from an existing session
+	if !req.IsSignInCode && ssoSignInDisabledError(w) {
+		return
+	}
+
 	log.Println("Validating and signing in")
 	resp, err := ValidateAndSignIn(w, r, req)
 
@@ -257,6 +266,12 @@ func SignOutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if auth.AuthToken == nil {
+		log.Println("Can't sign out with an api token")
+		http.Error(w, "Can't sign out with an api token -- revoke it instead", http.StatusBadRequest)
+		return
+	}
+
 	_, err := db.Conn.Exec("UPDATE auth_tokens SET deleted_at = NOW() WHERE token_hash = $1", auth.AuthToken.TokenHash)
 
 	if err != nil {
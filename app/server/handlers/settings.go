@@ -90,7 +90,11 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	if !authorizeOrgPermission(w, auth, shared.PermissionTellPlan) {
+		return
+	}
+
+	plan := authorizePlanContribute(w, planId, auth)
 
 	if plan == nil {
 		return
@@ -108,6 +112,7 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 
 	var commitMsg string
+	var originalSettings *shared.PlanSettings
 
 	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
 		OrgId:    auth.OrgId,
@@ -119,7 +124,8 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		Ctx:      ctx,
 		CancelFn: cancel,
 	}, func(repo *db.GitRepo) error {
-		originalSettings, err := db.GetPlanSettings(plan, true)
+		var err error
+		originalSettings, err = db.GetPlanSettings(plan, true)
 
 		if err != nil {
 			return fmt.Errorf("error getting settings: %v", err)
@@ -154,6 +160,15 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionPlanSettings,
+		targetType: shared.AuditTargetPlan,
+		targetId:   planId,
+		planId:     planId,
+		before:     auditJson(originalSettings),
+		after:      auditJson(req.Settings),
+	})
+
 	res := shared.UpdateSettingsResponse{
 		Msg: commitMsg,
 	}
@@ -258,6 +273,14 @@ func UpdateDefaultSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionOrgDefaultSettings,
+		targetType: shared.AuditTargetOrg,
+		targetId:   auth.OrgId,
+		before:     auditJson(originalSettings),
+		after:      auditJson(req.Settings),
+	})
+
 	commitMsg := getUpdateCommitMsg(req.Settings, originalSettings, true)
 
 	res := shared.UpdateSettingsResponse{
@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"plandex-server/db"
+	"plandex-server/sso"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+// how long a browser has to finish signing in with the provider
+const ssoRedirectExpiration = 10 * time.Minute
+
+const ssoStateCookie = "ssoState"
+
+func ListSsoProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListSsoProvidersHandler")
+
+	res := shared.ListSsoProvidersResponse{
+		Providers:  []*shared.SsoProvider{},
+		RequireSso: sso.RequireSso(),
+	}
+
+	if sso.Active != nil {
+		for _, p := range sso.Active.Providers {
+			res.Providers = append(res.Providers, &shared.SsoProvider{Id: p.Id, Name: p.Name})
+		}
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+}
+
+func StartSsoDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for StartSsoDeviceHandler")
+
+	provider := getSsoProvider(w, r)
+	if provider == nil {
+		return
+	}
+
+	deviceAuth, err := provider.StartDeviceAuth(r.Context())
+	if err != nil {
+		log.Printf("Error starting device authorization: %v\n", err)
+		http.Error(w, "Error starting device authorization: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	expiresIn := deviceAuth.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = 600
+	}
+
+	ssoReq := &db.SsoRequest{
+		ProviderId:   provider.Id,
+		Kind:         db.SsoRequestKindDevice,
+		DeviceCode:   deviceAuth.DeviceCode,
+		PollInterval: deviceAuth.Interval,
+		ExpiresAt:    time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}
+
+	pollToken, err := db.CreateSsoRequest(ssoReq)
+	if err != nil {
+		log.Printf("Error creating sso request: %v\n", err)
+		http.Error(w, "Error creating sso request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.StartSsoDeviceResponse{
+		PollToken:               pollToken,
+		UserCode:                deviceAuth.UserCode,
+		VerificationUri:         deviceAuth.VerificationUri,
+		VerificationUriComplete: deviceAuth.VerificationUriComplete,
+		Interval:                deviceAuth.Interval,
+		ExpiresAt:               ssoReq.ExpiresAt,
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Started sso device sign in with provider %s\n", provider.Id)
+
+	w.Write(bytes)
+}
+
+func PollSsoDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for PollSsoDeviceHandler")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var req shared.PollSsoDeviceRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ssoReq, err := db.GetPendingSsoRequest(req.PollToken, db.SsoRequestKindDevice)
+	if err != nil {
+		log.Printf("Error getting sso request: %v\n", err)
+		http.Error(w, "Error getting sso request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if ssoReq == nil {
+		http.Error(w, "Sign in request not found or expired", http.StatusNotFound)
+		return
+	}
+
+	provider := sso.GetProvider(ssoReq.ProviderId)
+	if provider == nil {
+		http.Error(w, "SSO provider is no longer configured", http.StatusNotFound)
+		return
+	}
+
+	res := shared.PollSsoDeviceResponse{}
+
+	// don't pass polls on to the provider faster than it allows
+	if ssoReq.LastPolledAt != nil && time.Since(*ssoReq.LastPolledAt) < time.Duration(ssoReq.PollInterval)*time.Second {
+		res.Status = shared.SsoDeviceStatusSlowDown
+		writeSsoPollResponse(w, res)
+		return
+	}
+
+	tok, err := provider.PollDeviceToken(r.Context(), ssoReq.DeviceCode)
+
+	switch err {
+	case nil:
+	case sso.ErrAuthorizationPending, sso.ErrSlowDown:
+		interval := ssoReq.PollInterval
+		res.Status = shared.SsoDeviceStatusPending
+		if err == sso.ErrSlowDown {
+			interval += 5
+			res.Status = shared.SsoDeviceStatusSlowDown
+		}
+
+		err = db.TouchSsoRequestPoll(ssoReq.Id, interval)
+		if err != nil {
+			log.Printf("Error updating sso request: %v\n", err)
+			http.Error(w, "Error updating sso request: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeSsoPollResponse(w, res)
+		return
+	case sso.ErrAccessDenied:
+		http.Error(w, "Sign in was denied", http.StatusForbidden)
+		return
+	case sso.ErrExpiredToken:
+		http.Error(w, "Sign in request expired", http.StatusGone)
+		return
+	default:
+		log.Printf("Error polling device token: %v\n", err)
+		http.Error(w, "Error polling device token: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	identity, err := provider.Identify(r.Context(), tok, "")
+	if err != nil {
+		log.Printf("Error verifying sso identity: %v\n", err)
+		http.Error(w, "Error verifying sso identity: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	session, status, err := signInWithSso(w, r, provider, identity, ssoReq)
+	if err != nil {
+		log.Printf("Error signing in with sso: %v\n", err)
+		http.Error(w, "Error signing in with sso: "+err.Error(), status)
+		return
+	}
+
+	res.Status = shared.SsoDeviceStatusComplete
+	res.Session = session
+
+	log.Printf("Successfully signed in with sso device flow | provider: %s\n", provider.Id)
+
+	writeSsoPollResponse(w, res)
+}
+
+func SsoLoginHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SsoLoginHandler")
+
+	provider := getSsoProvider(w, r)
+	if provider == nil {
+		return
+	}
+
+	nonce, err := randomSsoValue()
+	if err != nil {
+		log.Printf("Error generating nonce: %v\n", err)
+		http.Error(w, "Error generating nonce: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	codeVerifier, err := randomSsoValue()
+	if err != nil {
+		log.Printf("Error generating code verifier: %v\n", err)
+		http.Error(w, "Error generating code verifier: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ssoReq := &db.SsoRequest{
+		ProviderId:   provider.Id,
+		Kind:         db.SsoRequestKindRedirect,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		RedirectTo:   safeSsoRedirect(r.URL.Query().Get("redirect")),
+		ExpiresAt:    time.Now().Add(ssoRedirectExpiration),
+	}
+
+	state, err := db.CreateSsoRequest(ssoReq)
+	if err != nil {
+		log.Printf("Error creating sso request: %v\n", err)
+		http.Error(w, "Error creating sso request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// tie the state to this browser so a callback started elsewhere can't sign it in
+	setSsoStateCookie(w, state)
+
+	authUrl, err := provider.AuthCodeUrl(r.Context(), ssoCallbackUrl(r, provider.Id), state, nonce, codeVerifier)
+	if err != nil {
+		log.Printf("Error building authorization url: %v\n", err)
+		http.Error(w, "Error building authorization url: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	http.Redirect(w, r, authUrl, http.StatusFound)
+}
+
+func SsoCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SsoCallbackHandler")
+
+	provider := getSsoProvider(w, r)
+	if provider == nil {
+		return
+	}
+
+	query := r.URL.Query()
+
+	if errCode := query.Get("error"); errCode != "" {
+		log.Printf("SSO provider returned error: %s %s\n", errCode, query.Get("error_description"))
+		http.Error(w, "Sign in failed: "+errCode, http.StatusUnauthorized)
+		return
+	}
+
+	if !checkSsoStateCookie(w, r, query.Get("state")) {
+		http.Error(w, "Sign in request doesn't match this browser", http.StatusBadRequest)
+		return
+	}
+
+	ssoReq, err := db.GetPendingSsoRequest(query.Get("state"), db.SsoRequestKindRedirect)
+	if err != nil {
+		log.Printf("Error getting sso request: %v\n", err)
+		http.Error(w, "Error getting sso request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if ssoReq == nil || ssoReq.ProviderId != provider.Id {
+		http.Error(w, "Sign in request not found or expired", http.StatusBadRequest)
+		return
+	}
+
+	tok, err := provider.ExchangeCode(r.Context(), query.Get("code"), ssoReq.CodeVerifier, ssoCallbackUrl(r, provider.Id))
+	if err != nil {
+		log.Printf("Error exchanging authorization code: %v\n", err)
+		http.Error(w, "Error exchanging authorization code: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	identity, err := provider.Identify(r.Context(), tok, ssoReq.Nonce)
+	if err != nil {
+		log.Printf("Error verifying sso identity: %v\n", err)
+		http.Error(w, "Error verifying sso identity: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// make sure the session is set as a cookie even if the browser sent no accept header
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", "text/html")
+	}
+
+	_, status, err := signInWithSso(w, r, provider, identity, ssoReq)
+	if err != nil {
+		log.Printf("Error signing in with sso: %v\n", err)
+		http.Error(w, "Error signing in with sso: "+err.Error(), status)
+		return
+	}
+
+	log.Printf("Successfully signed in with sso redirect flow | provider: %s\n", provider.Id)
+
+	if ssoReq.RedirectTo != "" {
+		http.Redirect(w, r, ssoReq.RedirectTo, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("Signed in. You can close this window."))
+}
+
+// signInWithSso provisions the user and their org memberships, then creates a session. The returned status is the http status to use on error.
+func signInWithSso(w http.ResponseWriter, r *http.Request, provider *sso.Provider, identity *sso.Identity, ssoReq *db.SsoRequest) (*shared.SessionResponse, int, error) {
+	var user *db.User
+	var token string
+	alreadyCompleted := false
+
+	err := db.WithTx(r.Context(), "sso sign in", func(tx *sqlx.Tx) error {
+		ok, err := db.CompleteSsoRequest(ssoReq.Id, tx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			alreadyCompleted = true
+			return fmt.Errorf("sign in request was already used")
+		}
+
+		user, err = provisionSsoUser(provider, identity, tx)
+		if err != nil {
+			return fmt.Errorf("error provisioning user: %v", err)
+		}
+
+		token, _, err = db.CreateAuthToken(user.Id, tx)
+		if err != nil {
+			return fmt.Errorf("error creating auth token: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if alreadyCompleted {
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	session, err := sessionForUser(w, r, user, token, "")
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return session, http.StatusOK, nil
+}
+
+func getSsoProvider(w http.ResponseWriter, r *http.Request) *sso.Provider {
+	providerId := mux.Vars(r)["providerId"]
+
+	provider := sso.GetProvider(providerId)
+	if provider == nil {
+		log.Printf("SSO provider not found: %s\n", providerId)
+		http.Error(w, "SSO provider not found", http.StatusNotFound)
+		return nil
+	}
+
+	return provider
+}
+
+func writeSsoPollResponse(w http.ResponseWriter, res shared.PollSsoDeviceResponse) {
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+}
+
+func ssoCallbackUrl(r *http.Request, providerId string) string {
+	base := ""
+	if sso.Active != nil {
+		base = sso.Active.BaseUrl
+	}
+
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
+	}
+
+	return base + "/accounts/sso/" + url.PathEscape(providerId) + "/callback"
+}
+
+// safeSsoRedirect only allows redirects to paths on this server, falling back to the configured default
+func safeSsoRedirect(redirect string) string {
+	if strings.HasPrefix(redirect, "/") && !strings.HasPrefix(redirect, "//") && !strings.HasPrefix(redirect, "/\\") {
+		return redirect
+	}
+
+	if sso.Active != nil {
+		return sso.Active.DefaultRedirectUrl
+	}
+
+	return ""
+}
+
+// setSsoStateCookie stores a hash of the redirect state in a short-lived cookie that the callback requires
+func setSsoStateCookie(w http.ResponseWriter, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Path:     "/",
+		Value:    hashSsoState(state),
+		Secure:   os.Getenv("GOENV") != "development",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(ssoRedirectExpiration.Seconds()),
+	})
+}
+
+// checkSsoStateCookie returns true if the browser's state cookie matches the callback's state, and clears the cookie either way
+func checkSsoStateCookie(w http.ResponseWriter, r *http.Request, state string) bool {
+	cookie, err := r.Cookie(ssoStateCookie)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Path:     "/",
+		Value:    "",
+		Secure:   os.Getenv("GOENV") != "development",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+
+	if err != nil || state == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(hashSsoState(state))) == 1
+}
+
+func hashSsoState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomSsoValue() (string, error) {
+	bytes, err := shared.GetRandomAlphanumeric(43)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// ssoSignInDisabledError returns true and writes an error if email pin sign in is disabled because the server requires sso
+func ssoSignInDisabledError(w http.ResponseWriter) bool {
+	if !sso.RequireSso() {
+		return false
+	}
+
+	log.Println("Email sign in is disabled -- sso is required")
+	http.Error(w, "Email sign in is disabled on this server. Sign in with SSO instead.", http.StatusForbidden)
+	return true
+}
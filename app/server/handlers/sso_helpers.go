@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/sso"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// provisionSsoUser finds or creates the user for an sso identity, then syncs their memberships in the orgs the provider maps to
+func provisionSsoUser(provider *sso.Provider, identity *sso.Identity, tx *sqlx.Tx) (*db.User, error) {
+	var user *db.User
+
+	existing, err := db.GetSsoIdentity(provider.Id, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		user, err = db.GetUser(existing.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// link to an existing account with the same email, but only if the provider says it's verified -- otherwise anyone who can set that email with the provider could take the account over
+	if user == nil {
+		user, err = db.GetUserByEmail(identity.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		if user != nil && !identity.EmailVerified {
+			log.Printf("Not linking sso identity to existing user with unverified email | provider: %s | email: %s\n", provider.Id, identity.Email)
+			return nil, fmt.Errorf("an account for %s already exists, and the provider didn't verify the email, so it can't be linked", identity.Email)
+		}
+	}
+
+	// sso users only join orgs through the provider's org mappings, not through domain auto-join
+	if user == nil {
+		log.Printf("Creating user for sso identity | provider: %s | email: %s\n", provider.Id, identity.Email)
+
+		user, err = db.CreateUser(identity.Name, identity.Email, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if user.IsServiceAccount {
+		return nil, fmt.Errorf("can't sign in as a service account")
+	}
+
+	err = db.UpsertSsoIdentity(provider.Id, identity.Subject, user.Id, identity.Email, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mapping := range provider.Orgs {
+		err = provisionSsoOrg(provider, mapping, identity, user, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func provisionSsoOrg(provider *sso.Provider, mapping *sso.OrgMapping, identity *sso.Identity, user *db.User, tx *sqlx.Tx) error {
+	var org *db.Org
+	var err error
+
+	if mapping.OrgId != "" {
+		org, err = db.GetOrg(mapping.OrgId)
+	} else {
+		org, err = db.GetOrgByName(mapping.Org)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting org for sso mapping: %v", err)
+	}
+
+	matches := mapping.Matches(identity)
+
+	if org == nil {
+		if !matches || !mapping.CreateIfMissing {
+			return nil
+		}
+
+		log.Printf("Creating org %s for sso user %s\n", mapping.Org, user.Id)
+
+		// the first user provisioned into a new org owns it
+		_, err = db.CreateOrg(&shared.CreateOrgRequest{Name: mapping.Org}, user.Id, nil, tx)
+		if err != nil {
+			return fmt.Errorf("error creating org for sso mapping: %v", err)
+		}
+
+		return nil
+	}
+
+	orgUser, err := db.GetOrgUser(user.Id, org.Id)
+	if err != nil {
+		return err
+	}
+
+	isOwner := org.OwnerId == user.Id
+
+	if !matches {
+		// only memberships sso created are removed -- invited members are left alone
+		if orgUser != nil && mapping.Deprovision && !isOwner && orgUser.SsoProviderId != nil && *orgUser.SsoProviderId == provider.Id {
+			log.Printf("Deprovisioning sso user %s from org %s\n", user.Id, org.Id)
+			return db.DeleteOrgUser(org.Id, user.Id, tx)
+		}
+		return nil
+	}
+
+	roleId, err := resolveSsoOrgRole(org.Id, mapping.RoleFor(identity))
+	if err != nil {
+		return err
+	}
+
+	if orgUser == nil {
+		log.Printf("Provisioning sso user %s into org %s\n", user.Id, org.Id)
+		return db.CreateSsoOrgUser(org.Id, user.Id, roleId, provider.Id, tx)
+	}
+
+	if mapping.SyncRole && !isOwner && orgUser.OrgRoleId != roleId {
+		log.Printf("Syncing org role for sso user %s in org %s\n", user.Id, org.Id)
+		return db.SetOrgUserRole(org.Id, user.Id, roleId, tx)
+	}
+
+	return nil
+}
+
+// resolveSsoOrgRole finds a default or custom role in the org by name or label
+func resolveSsoOrgRole(orgId, role string) (string, error) {
+	roles, err := db.ListOrgRoles(orgId)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range roles {
+		if strings.EqualFold(r.Name, role) || strings.EqualFold(r.Label, role) {
+			return r.Id, nil
+		}
+	}
+
+	return "", fmt.Errorf("org role %s not found in org %s", role, orgId)
+}
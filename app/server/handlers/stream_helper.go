@@ -9,6 +9,7 @@ import (
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
 	"plandex-server/types"
+	"strconv"
 	"time"
 
 	shared "plandex-shared"
@@ -16,7 +17,8 @@ import (
 
 const HeartbeatInterval = 5 * time.Second
 
-func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *types.ServerAuth, planId, branch string, isConnect bool) {
+// if resumeSeq is set, the client is reconnecting after a disconnect and only needs the messages it missed. semanticChecker is set for the stream of the cli that requested semantic checks.
+func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *types.ServerAuth, planId, branch string, isConnect, semanticChecker bool, resumeSeq *int64) {
 	log.Println("Response stream manager: starting plan stream")
 
 	active := modelPlan.GetActivePlan(planId, branch)
@@ -30,6 +32,15 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 	w.Header().Set("Transfer-Encoding", "chunked")
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
+	streamActivePlan(reqCtx, auth, active, planId, branch, isConnect, semanticChecker, resumeSeq, func(msg string) error {
+		return sendStreamMessage(w, msg)
+	})
+}
+
+// streamSender writes a single json-encoded stream message (or a heartbeat) to the client in whatever wire format the endpoint uses
+type streamSender func(msg string) error
+
+func streamActivePlan(reqCtx context.Context, auth *types.ServerAuth, active *types.ActivePlan, planId, branch string, isConnect, semanticChecker bool, resumeSeq *int64, send streamSender) {
 	// send initial message to client
 	msg := shared.StreamMessage{
 		Type: shared.StreamMessageStart,
@@ -43,23 +54,56 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 	}
 
 	log.Println("Response stream manager: sending initial message")
-	err = sendStreamMessage(w, string(bytes))
+	err = send(string(bytes))
 	if err != nil {
 		log.Println("Response stream manager: error sending initial message:", err)
 		return
 	}
 
-	if isConnect {
-		time.Sleep(100 * time.Millisecond)
-		err = initConnectActive(auth, planId, branch, w)
+	var subscriptionId string
+	var ch chan string
 
-		if err != nil {
-			log.Println("Response stream manager: error initializing connection to active plan:", err)
-			return
+	// messages with a seq up to this one were already sent from the replay buffer
+	var replayedSeq int64
+
+	if isConnect && resumeSeq != nil {
+		// subscribe before reading the replay buffer so no messages are missed in between -- duplicates are skipped below
+		subscriptionId, ch = modelPlan.SubscribePlan(reqCtx, planId, branch, semanticChecker)
+
+		msgs, lastSeq, ok := active.StreamMessagesSince(*resumeSeq)
+		if ok {
+			log.Printf("Response stream manager: replaying %d messages after seq %d\n", len(msgs), *resumeSeq)
+			for _, msg := range msgs {
+				err = send(msg)
+				if err != nil {
+					modelPlan.UnsubscribePlan(planId, branch, subscriptionId)
+					return
+				}
+			}
+			replayedSeq = lastSeq
+		} else {
+			log.Printf("Response stream manager: can't resume from seq %d -- sending full connect state\n", *resumeSeq)
+			err = initConnectActive(auth, planId, branch, send)
+			if err != nil {
+				log.Println("Response stream manager: error initializing connection to active plan:", err)
+				modelPlan.UnsubscribePlan(planId, branch, subscriptionId)
+				return
+			}
 		}
+	} else {
+		if isConnect {
+			time.Sleep(100 * time.Millisecond)
+			err = initConnectActive(auth, planId, branch, send)
+
+			if err != nil {
+				log.Println("Response stream manager: error initializing connection to active plan:", err)
+				return
+			}
+		}
+
+		subscriptionId, ch = modelPlan.SubscribePlan(reqCtx, planId, branch, semanticChecker)
 	}
 
-	subscriptionId, ch := modelPlan.SubscribePlan(reqCtx, planId, branch)
 	defer func() {
 		log.Println("Response stream manager: client stream closed")
 		modelPlan.UnsubscribePlan(planId, branch, subscriptionId)
@@ -94,13 +138,21 @@ func startResponseStream(reqCtx context.Context, w http.ResponseWriter, auth *ty
 			log.Println("Response stream manager: request context done")
 			return
 		case msg := <-chHeartbeat:
-			err = sendStreamMessage(w, msg)
+			err = send(msg)
 			if err != nil {
 				return
 			}
 		case msg := <-ch:
+			if replayedSeq > 0 {
+				seq := streamMessageSeq(msg)
+				if seq <= replayedSeq {
+					continue
+				}
+				replayedSeq = 0
+			}
+
 			// log.Println("Response stream manager: sending message:", msg)
-			err = sendStreamMessage(w, msg)
+			err = send(msg)
 			if err != nil {
 				return
 			}
@@ -124,7 +176,35 @@ func sendStreamMessage(w http.ResponseWriter, msg string) error {
 	return nil
 }
 
-func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.ResponseWriter) error {
+// getResumeSeq returns the seq a client wants to resume a stream from, or nil if it isn't resuming
+func getResumeSeq(r *http.Request) (*int64, error) {
+	lastEventId := r.Header.Get(shared.STREAM_RESUME_HEADER)
+	if lastEventId == "" {
+		return nil, nil
+	}
+
+	seq, err := strconv.ParseInt(lastEventId, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header", shared.STREAM_RESUME_HEADER)
+	}
+
+	log.Printf("Resuming stream from seq %d\n", seq)
+
+	return &seq, nil
+}
+
+func streamMessageSeq(msg string) int64 {
+	var seqOnly struct {
+		Seq int64 `json:"seq"`
+	}
+	err := json.Unmarshal([]byte(msg), &seqOnly)
+	if err != nil {
+		return 0
+	}
+	return seqOnly.Seq
+}
+
+func initConnectActive(auth *types.ServerAuth, planId, branch string, send streamSender) error {
 	log.Println("Response stream manager: initializing connection to active plan")
 
 	active := modelPlan.GetActivePlan(planId, branch)
@@ -178,7 +258,7 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 	}
 
 	log.Println("Response stream manager: sending connect message")
-	err = sendStreamMessage(w, string(bytes))
+	err = send(string(bytes))
 
 	if err != nil {
 		return fmt.Errorf("error sending connect message: %v", err)
@@ -214,7 +294,7 @@ func initConnectActive(auth *types.ServerAuth, planId, branch string, w http.Res
 				return fmt.Errorf("error marshalling message: %v", err)
 			}
 
-			err = sendStreamMessage(w, string(bytes))
+			err = send(string(bytes))
 
 			if err != nil {
 				return fmt.Errorf("error sending message: %v", err)
@@ -219,5 +219,12 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionOrgRemoveUser,
+		targetType: shared.AuditTargetUser,
+		targetId:   userId,
+		before:     auditJson(map[string]string{"orgRoleId": orgUser.OrgRoleId}),
+	})
+
 	log.Println("Successfully processed request for DeleteOrgUserHandler")
 }
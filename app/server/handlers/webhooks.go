@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/webhooks"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+const defaultWebhookDeliveriesLimit = 50
+const maxWebhookDeliveriesLimit = 500
+
+func ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for ListWebhooksHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageWebhooks) {
+		return
+	}
+
+	webhookList, err := db.ListWebhooks(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v\n", err)
+		http.Error(w, "Error listing webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiWebhooks []*shared.Webhook
+	for _, webhook := range webhookList {
+		apiWebhooks = append(apiWebhooks, webhook.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiWebhooks)
+	if err != nil {
+		log.Printf("Error marshalling webhooks: %v\n", err)
+		http.Error(w, "Error marshalling webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully listed webhooks")
+
+	w.Write(bytes)
+}
+
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for CreateWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageWebhooks) {
+		return
+	}
+
+	var req shared.CreateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Url = strings.TrimSpace(req.Url)
+
+	err = validateWebhook(req.Url, req.Events)
+	if err != nil {
+		log.Printf("Invalid webhook: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	webhook := &db.Webhook{
+		OrgId:       auth.OrgId,
+		CreatorId:   auth.User.Id,
+		Url:         req.Url,
+		Description: strings.TrimSpace(req.Description),
+		Events:      webhookEvents(req.Events),
+	}
+
+	if req.ProjectId != "" {
+		exists, err := db.ProjectExists(auth.OrgId, req.ProjectId)
+		if err != nil {
+			log.Printf("Error checking project: %v\n", err)
+			http.Error(w, "Error checking project: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !exists {
+			log.Printf("Project not found: %s\n", req.ProjectId)
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+
+		webhook.ProjectId = &req.ProjectId
+	}
+
+	secret, err := db.CreateWebhook(webhook)
+	if err != nil {
+		log.Printf("Error creating webhook: %v\n", err)
+		http.Error(w, "Error creating webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionWebhookCreate,
+		targetType: shared.AuditTargetWebhook,
+		targetId:   webhook.Id,
+		after:      auditJson(webhook.ToApi()),
+	})
+
+	res := shared.CreateWebhookResponse{
+		Secret:  secret,
+		Webhook: webhook.ToApi(),
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully created webhook")
+
+	w.Write(bytes)
+}
+
+func UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for UpdateWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageWebhooks) {
+		return
+	}
+
+	webhook := getWebhookForRequest(w, r, auth.OrgId)
+	if webhook == nil {
+		return
+	}
+
+	var req shared.UpdateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := auditJson(webhook.ToApi())
+
+	if req.Url != nil {
+		webhook.Url = strings.TrimSpace(*req.Url)
+	}
+	if req.Description != nil {
+		webhook.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Events != nil {
+		webhook.Events = webhookEvents(req.Events)
+	}
+	if req.Disabled != nil {
+		webhook.Disabled = *req.Disabled
+	}
+
+	err = validateWebhook(webhook.Url, webhook.ToApi().Events)
+	if err != nil {
+		log.Printf("Invalid webhook: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = db.UpdateWebhook(webhook)
+	if err != nil {
+		log.Printf("Error updating webhook: %v\n", err)
+		http.Error(w, "Error updating webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionWebhookUpdate,
+		targetType: shared.AuditTargetWebhook,
+		targetId:   webhook.Id,
+		before:     before,
+		after:      auditJson(webhook.ToApi()),
+	})
+
+	log.Println("Successfully updated webhook")
+}
+
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for DeleteWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageWebhooks) {
+		return
+	}
+
+	webhook := getWebhookForRequest(w, r, auth.OrgId)
+	if webhook == nil {
+		return
+	}
+
+	err := db.DeleteWebhook(auth.OrgId, webhook.Id)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v\n", err)
+		http.Error(w, "Error deleting webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, auth, auditParams{
+		action:     shared.AuditActionWebhookDelete,
+		targetType: shared.AuditTargetWebhook,
+		targetId:   webhook.Id,
+		before:     auditJson(webhook.ToApi()),
+	})
+
+	log.Println("Successfully deleted webhook")
+}
+
+func ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for ListWebhookDeliveriesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageWebhooks) {
+		return
+	}
+
+	webhook := getWebhookForRequest(w, r, auth.OrgId)
+	if webhook == nil {
+		return
+	}
+
+	limit := defaultWebhookDeliveriesLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxWebhookDeliveriesLimit)
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(auth.OrgId, webhook.Id, limit)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v\n", err)
+		http.Error(w, "Error listing webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiDeliveries []*shared.WebhookDelivery
+	for _, delivery := range deliveries {
+		apiDeliveries = append(apiDeliveries, delivery.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiDeliveries)
+	if err != nil {
+		log.Printf("Error marshalling webhook deliveries: %v\n", err)
+		http.Error(w, "Error marshalling webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully listed webhook deliveries")
+
+	w.Write(bytes)
+}
+
+func TestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for TestWebhookHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeOrgPermission(w, auth, shared.PermissionManageWebhooks) {
+		return
+	}
+
+	webhook := getWebhookForRequest(w, r, auth.OrgId)
+	if webhook == nil {
+		return
+	}
+
+	delivery, err := webhooks.Ping(webhook, auth.User.Id, auth.User.Email)
+	if err != nil {
+		log.Printf("Error testing webhook: %v\n", err)
+		http.Error(w, "Error testing webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(delivery.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling webhook delivery: %v\n", err)
+		http.Error(w, "Error marshalling webhook delivery: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully tested webhook")
+
+	w.Write(bytes)
+}
+
+func getWebhookForRequest(w http.ResponseWriter, r *http.Request, orgId string) *db.Webhook {
+	webhookId := mux.Vars(r)["webhookId"]
+
+	webhook, err := db.GetWebhook(orgId, webhookId)
+	if err != nil {
+		log.Printf("Error getting webhook: %v\n", err)
+		http.Error(w, "Error getting webhook: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if webhook == nil {
+		log.Println("Webhook not found")
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil
+	}
+
+	return webhook
+}
+
+func validateWebhook(rawUrl string, events []shared.WebhookEvent) error {
+	err := webhooks.ValidateUrl(rawUrl)
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+
+	for _, event := range events {
+		if !shared.IsValidWebhookEvent(event) {
+			return fmt.Errorf("invalid webhook event: %s", event)
+		}
+	}
+
+	return nil
+}
+
+func webhookEvents(events []shared.WebhookEvent) pq.StringArray {
+	res := pq.StringArray{}
+	for _, event := range events {
+		res = append(res, string(event))
+	}
+	return res
+}
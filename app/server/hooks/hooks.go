@@ -109,6 +109,15 @@ type DidFinishBuilderRunParams struct {
 	BuildWholeFileStartedAt  time.Time
 	BuildWholeFileFinishedAt time.Time
 
+	DidSemanticCheck        bool
+	SemanticChecker         string
+	SemanticCheckErrors     []string
+	SemanticCheckFixSuccess bool
+
+	// set when builder strategy stats led the build to start strategies early
+	FastApplyFirst bool
+	RacedFallbacks bool
+
 	StartedAt  time.Time
 	FinishedAt time.Time
 }
@@ -31,6 +31,14 @@ func LoadIp() error {
 	} else if os.Getenv("IP") != "" {
 		Ip = os.Getenv("IP")
 		return nil
+	} else if os.Getenv("STREAM_BUS") != "" {
+		// with a stream bus, requests are routed by plan rather than by ip, so the hostname is only recorded for debugging
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("error getting hostname: %v", err)
+		}
+		Ip = hostname
+		return nil
 	}
 
 	return nil
@@ -17,8 +17,14 @@ func main() {
 	routes.AddHealthRoutes(r)
 	routes.AddApiRoutes(r)
 	routes.AddProxyableApiRoutes(r)
+	routes.AddApiRoutesWithPrefix(r, routes.ApiVersionPrefix)
+	routes.AddProxyableApiRoutesWithPrefix(r, routes.ApiVersionPrefix)
+	routes.AddApiSpecRoutes(r)
 	setup.MustLoadIp()
 	setup.MustInitDb()
+	setup.MustInitSso()
+	setup.MustInitEmail()
+	setup.MustInitStreamBus(r)
 	setup.StartServer(r, nil)
 	os.Exit(0)
 }
@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE name = 'manage_service_accounts';
+
+DROP TABLE IF EXISTS api_tokens;
+
+DELETE FROM users WHERE is_service_account = TRUE;
+ALTER TABLE users DROP COLUMN is_service_account;
@@ -0,0 +1,3 @@
+UPDATE permissions SET description = 'Unshare a plan any user shared' WHERE name = 'manage_any_plan_shares';
+
+DROP TABLE IF EXISTS plan_shares;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS plan_shares (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+  project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+  level VARCHAR(32) NOT NULL,
+  created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  CHECK ((user_id IS NULL) <> (project_id IS NULL))
+);
+CREATE TRIGGER update_plan_shares_modtime BEFORE UPDATE ON plan_shares FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX plan_shares_user_idx ON plan_shares(plan_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX plan_shares_project_idx ON plan_shares(plan_id, project_id) WHERE project_id IS NOT NULL;
+CREATE INDEX plan_shares_org_user_idx ON plan_shares(org_id, user_id);
+CREATE INDEX plan_shares_org_project_idx ON plan_shares(org_id, project_id);
+
+-- manage_any_plan_shares is already granted to owners and admins by the rbac migration -- it now covers sharing as well as unsharing
+UPDATE permissions SET description = 'Share or unshare any plan in the org' WHERE name = 'manage_any_plan_shares';
@@ -0,0 +1,8 @@
+UPDATE orgs_users SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+UPDATE invites SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+DELETE FROM permissions WHERE resource_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+DELETE FROM org_roles WHERE org_id IS NOT NULL;
+
+DELETE FROM permissions WHERE name IN ('manage_org_roles', 'tell_plan', 'apply_plan', 'manage_custom_models');
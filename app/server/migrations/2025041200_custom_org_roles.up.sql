@@ -0,0 +1,16 @@
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_org_roles', 'Create, update, and delete custom org roles', NULL),
+  ('tell_plan', 'Send prompts to a plan, build, reject, or rewind its changes, and update its context, config, and branches', NULL),
+  ('apply_plan', 'Apply a plan''s pending changes', NULL),
+  ('manage_custom_models', 'Add and remove custom models and model packs', NULL);
+
+-- every built-in role could already do these, so they keep them
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL AND p.name IN ('tell_plan', 'apply_plan', 'manage_custom_models');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL AND r.name IN ('owner', 'admin') AND p.name = 'manage_org_roles';
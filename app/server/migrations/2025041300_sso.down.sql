@@ -0,0 +1,4 @@
+ALTER TABLE orgs_users DROP COLUMN IF EXISTS sso_provider_id;
+
+DROP TABLE IF EXISTS sso_requests;
+DROP TABLE IF EXISTS sso_identities;
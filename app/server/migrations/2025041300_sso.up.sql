@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS sso_identities (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  provider_id VARCHAR(255) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  last_sign_in_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_sso_identities_modtime BEFORE UPDATE ON sso_identities FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX sso_identities_subject_idx ON sso_identities(provider_id, subject);
+CREATE INDEX sso_identities_user_idx ON sso_identities(user_id);
+
+CREATE TABLE IF NOT EXISTS sso_requests (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  provider_id VARCHAR(255) NOT NULL,
+  kind VARCHAR(32) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL,
+  nonce VARCHAR(255) NOT NULL DEFAULT '',
+  code_verifier VARCHAR(255) NOT NULL DEFAULT '',
+  redirect_to TEXT NOT NULL DEFAULT '',
+  device_code TEXT NOT NULL DEFAULT '',
+  poll_interval INTEGER NOT NULL DEFAULT 5,
+  last_polled_at TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL,
+  completed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX sso_requests_token_idx ON sso_requests(token_hash);
+
+ALTER TABLE orgs_users ADD COLUMN sso_provider_id VARCHAR(255);
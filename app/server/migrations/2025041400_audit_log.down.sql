@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'view_audit_log';
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS prevent_audit_log_changes();
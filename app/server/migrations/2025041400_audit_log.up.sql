@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  seq BIGSERIAL NOT NULL,
+  org_id UUID NOT NULL,
+  actor_id UUID NOT NULL,
+  actor_email VARCHAR(255) NOT NULL,
+  api_token_id UUID,
+  action VARCHAR(64) NOT NULL,
+  target_type VARCHAR(32) NOT NULL,
+  target_id VARCHAR(255) NOT NULL DEFAULT '',
+  plan_id UUID,
+  before TEXT NOT NULL DEFAULT '',
+  after TEXT NOT NULL DEFAULT '',
+  client_ip VARCHAR(64) NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- no foreign keys, so entries outlive the users, plans, and orgs they refer to
+CREATE UNIQUE INDEX audit_log_seq_idx ON audit_log(seq);
+CREATE INDEX audit_log_org_seq_idx ON audit_log(org_id, seq DESC);
+CREATE INDEX audit_log_org_plan_idx ON audit_log(org_id, plan_id);
+CREATE INDEX audit_log_org_actor_idx ON audit_log(org_id, actor_id);
+
+-- the log is append-only
+CREATE OR REPLACE FUNCTION prevent_audit_log_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_changes();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log FOR EACH STATEMENT EXECUTE FUNCTION prevent_audit_log_changes();
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('view_audit_log', 'View and export the org''s audit log', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL AND r.name IN ('owner', 'admin') AND p.name = 'view_audit_log';
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS builder_strategy_stats (
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  strategy VARCHAR(32) NOT NULL,
+  lang VARCHAR(64) NOT NULL DEFAULT '',
+  model VARCHAR(255) NOT NULL DEFAULT '',
+  size_bucket VARCHAR(16) NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  successes INTEGER NOT NULL DEFAULT 0,
+  total_latency_ms BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (org_id, strategy, lang, model, size_bucket)
+);
+
+CREATE TRIGGER update_builder_strategy_stats_modtime BEFORE UPDATE ON builder_strategy_stats FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -45,6 +45,12 @@ func Build(
 		return 0, err
 	}
 
+	allowGeneratedEdits, err := planAllowsGeneratedEdits(plan.Id)
+	if err != nil {
+		return onErr(fmt.Errorf("error getting plan config: %v", err))
+	}
+	state.allowGeneratedEdits = allowGeneratedEdits
+
 	pendingBuildsByPath, err := state.loadPendingBuilds(sessionId)
 	if err != nil {
 		return onErr(err)
@@ -209,6 +215,13 @@ func (fileState *activeBuildStreamFileState) buildFile() {
 	// 	log.Println(k)
 	// }
 
+	if !activeBuild.IsResetOp && !fileState.allowGeneratedEdits {
+		if reason := fileState.generatedReason(); reason != "" {
+			fileState.skipGeneratedFile(reason)
+			return
+		}
+	}
+
 	if activeBuild.IsMoveOp {
 		log.Printf("File %s is a move operation. Moving to %s\n", filePath, activeBuild.MoveDestination)
 
@@ -318,6 +331,13 @@ func (fileState *activeBuildStreamFileState) buildFile() {
 		return
 	}
 
+	if activeBuild.IsRefactorOp() {
+		log.Printf("File %s is a refactor operation.\n", filePath)
+		activePlan.DidEditFiles = true
+		fileState.buildRefactor()
+		return
+	}
+
 	if fileState.preBuildState == "" {
 		log.Printf("File %s not found in model context or current plan. Creating new file.\n", filePath)
 
@@ -0,0 +1,73 @@
+package plan
+
+import (
+	"log"
+	"plandex-server/db"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// generatedReasonsByPath collects the generated files in context, whether they were loaded in full or are part of a map. The client detects these when loading context, since detection can depend on .gitattributes.
+func generatedReasonsByPath(contexts []*db.Context) map[string]shared.GeneratedFileReason {
+	res := map[string]shared.GeneratedFileReason{}
+	for _, context := range contexts {
+		switch context.ContextType {
+		case shared.ContextFileType:
+			if context.GeneratedReason != "" {
+				res[context.FilePath] = context.GeneratedReason
+			}
+		case shared.ContextMapType:
+			for path, reason := range context.MapGenerated {
+				res[path] = reason
+			}
+		}
+	}
+	return res
+}
+
+// generatedReason checks the plan's context first, then falls back to the file's path and current content, since a build can run for a file that isn't in context or without context loaded at all
+func (fileState *activeBuildStreamFileState) generatedReason() shared.GeneratedFileReason {
+	if reason := generatedReasonsByPath(fileState.modelContext)[fileState.filePath]; reason != "" {
+		return reason
+	}
+	if reason := shared.GetGeneratedPathReason(fileState.filePath); reason != "" {
+		return reason
+	}
+	return shared.GetGeneratedContentReason(fileState.preBuildState)
+}
+
+// planAllowsGeneratedEdits reads allow-generated-edits from the config stored with the plan, so a request can't turn it on by itself
+func planAllowsGeneratedEdits(planId string) (bool, error) {
+	config, err := db.GetPlanConfig(planId)
+	if err != nil {
+		return false, err
+	}
+	return config.AllowGeneratedEdits, nil
+}
+
+// skipGeneratedFile finishes a build for a generated file without changing it. The model is told not to edit generated files, so this only catches edits it makes anyway.
+func (fileState *activeBuildStreamFileState) skipGeneratedFile(reason shared.GeneratedFileReason) {
+	filePath := fileState.filePath
+
+	log.Printf("File %s is generated (%s) and allow-generated-edits is off. Skipping build.\n", filePath, reason)
+
+	activePlan := GetActivePlan(fileState.plan.Id, fileState.branch)
+	if activePlan == nil {
+		log.Printf("Active plan not found for plan ID %s and branch %s\n", fileState.plan.Id, fileState.branch)
+		return
+	}
+
+	activePlan.Stream(shared.StreamMessage{
+		Type: shared.StreamMessageBuildInfo,
+		BuildInfo: &shared.BuildInfo{
+			Path:     filePath,
+			Finished: true,
+			Skipped:  true,
+		},
+	})
+
+	time.Sleep(200 * time.Millisecond)
+
+	fileState.onBuildProcessed(fileState.activeBuild)
+}
@@ -123,7 +123,7 @@ func (state *activeBuildStreamFileState) loadBuildFile(activeBuild *types.Active
 
 	convoMessageId := activeBuild.ReplyId
 
-	parser, lang, fallbackParser, fallbackLang := syntax.GetParserForPath(filePath)
+	parser, lang, fallbackParser, fallbackLang := syntax.GetBuildParserForPath(filePath)
 
 	if parser != nil {
 		validationRes, err := syntax.ValidateWithParsers(activePlan.Ctx, lang, parser, fallbackLang, fallbackParser, state.preBuildState)
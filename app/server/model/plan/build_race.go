@@ -9,6 +9,8 @@ import (
 	"plandex-server/utils"
 	"strings"
 	"time"
+
+	shared "plandex-shared"
 )
 
 type raceResult struct {
@@ -26,6 +28,9 @@ type buildRaceParams struct {
 	didCallFastApply bool
 	fastApplyCh      chan string
 
+	// start the fallbacks right away instead of waiting for the validation loop to fail
+	raceFallbacks bool
+
 	sessionId string
 }
 
@@ -85,6 +90,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			default:
 			}
 
+			startedAt := time.Now()
 			content, err := fileState.buildWholeFileFallback(buildCtx, proposedContent, desc, comments, sessionId)
 
 			if err != nil {
@@ -94,9 +100,11 @@ func (fileState *activeBuildStreamFileState) buildRace(
 				}
 
 				log.Printf("buildRace - whole file build failed: %v", err)
+				fileState.recordBuilderStrategyResult(shared.BuilderStrategyWholeFile, false, startedAt)
 				sendErr(fmt.Errorf("error building whole file: %w", err))
 			} else {
 				log.Printf("buildRace - whole file build succeeded")
+				fileState.recordBuilderStrategyResult(shared.BuilderStrategyWholeFile, true, startedAt)
 				sendRes(raceResult{content: content, valid: true})
 			}
 		}()
@@ -137,6 +145,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 
 			if len(fastApplySyntaxErrors) > 0 {
 				log.Printf("buildRace - fast apply succeeded, but has %d syntax errors", len(fastApplySyntaxErrors))
+				fileState.recordBuilderStrategyResult(shared.BuilderStrategyFastApply, false, fileState.builderRun.FastApplyStartedAt)
 				sendErr(fmt.Errorf("fast apply succeeded, but has %d syntax errors", len(fastApplySyntaxErrors)))
 				onFail()
 				return
@@ -164,6 +173,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 				}
 
 				log.Printf("buildRace - fast apply validation failed with error: %v", err)
+				fileState.recordBuilderStrategyResult(shared.BuilderStrategyFastApply, false, fileState.builderRun.FastApplyStartedAt)
 				sendErr(fmt.Errorf("fast apply validation failed: %w", err))
 				onFail()
 				return
@@ -172,10 +182,12 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			if validateResult.valid {
 				log.Printf("buildRace - fast apply validation succeeded")
 				fileState.builderRun.FastApplySuccess = true
+				fileState.recordBuilderStrategyResult(shared.BuilderStrategyFastApply, true, fileState.builderRun.FastApplyStartedAt)
 				sendRes(raceResult{content: validateResult.updated, valid: validateResult.valid})
 			} else {
 				log.Printf("buildRace - fast apply validation failed with problem: %s", validateResult.problem)
 				fileState.builderRun.FastApplyFailureResponse = validateResult.problem
+				fileState.recordBuilderStrategyResult(shared.BuilderStrategyFastApply, false, fileState.builderRun.FastApplyStartedAt)
 				sendErr(fmt.Errorf("fast apply validation failed: %s", validateResult.problem))
 				onFail()
 				return
@@ -205,7 +217,14 @@ func (fileState *activeBuildStreamFileState) buildRace(
 		return false
 	}
 
-	fileState.builderRun.AutoApplyValidationStartedAt = time.Now()
+	// started before the validation loop, which can also start them when it streams an incorrect marker
+	if params.raceFallbacks {
+		log.Printf("buildRace - validation often fails for files like this one, racing fallbacks")
+		startFallbacks("")
+	}
+
+	validationStartedAt := time.Now()
+	fileState.builderRun.AutoApplyValidationStartedAt = validationStartedAt
 
 	go func() {
 		log.Printf("buildRace - starting validation loop")
@@ -230,9 +249,11 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			}
 
 			log.Printf("buildRace - validation loop failed: %v", err)
+			fileState.recordBuilderStrategyResult(shared.BuilderStrategyValidate, false, validationStartedAt)
 			sendErr(fmt.Errorf("error building validate loop: %w", err))
 		} else {
 			log.Printf("buildRace - validation loop finished, valid: %v", validateResult.valid)
+			fileState.recordBuilderStrategyResult(shared.BuilderStrategyValidate, validateResult.valid, validationStartedAt)
 			if validateResult.valid {
 				log.Printf("buildRace - validation loop succeeded, valid: %v", validateResult.valid)
 				sendRes(raceResult{content: validateResult.updated, valid: validateResult.valid})
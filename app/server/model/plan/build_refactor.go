@@ -0,0 +1,122 @@
+package plan
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/db"
+	diff_pkg "plandex-server/diff"
+	"plandex-server/syntax"
+	"plandex-server/types"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// buildRefactor runs a rename or move symbol op with tree-sitter instead of the builder model, so the result is deterministic. It produces ordinary replacements that are reviewed like any other change. If the refactor can't run, e.g. because the symbol isn't defined in the file, the file is left unchanged and the error is stored on the result.
+func (fileState *activeBuildStreamFileState) buildRefactor() {
+	filePath := fileState.filePath
+	activeBuild := fileState.activeBuild
+	planId := fileState.plan.Id
+	branch := fileState.branch
+	originalFile := fileState.preBuildState
+
+	activePlan := GetActivePlan(planId, branch)
+	if activePlan == nil {
+		log.Printf("Active plan not found for plan ID %s and branch %s\n", planId, branch)
+		fileState.onBuildFileError(fmt.Errorf("active plan not found for plan ID %s and branch %s", planId, branch))
+		return
+	}
+
+	updated := originalFile
+	var summary string
+	var refactorErr error
+
+	switch {
+	case activeBuild.IsRenameOp:
+		summary = fmt.Sprintf("Rename %s to %s", activeBuild.Symbol, activeBuild.NewSymbol)
+
+		var num int
+		var res string
+		res, num, refactorErr = syntax.RenameSymbol(activePlan.Ctx, fileState.parser, originalFile, activeBuild.Symbol, activeBuild.NewSymbol)
+		if refactorErr == nil {
+			log.Printf("buildRefactor - %s - renamed %d identifiers\n", filePath, num)
+			updated = res
+		}
+
+	case activeBuild.IsMoveSymbolOp:
+		summary = fmt.Sprintf("Move %s to %s", activeBuild.Symbol, activeBuild.MoveDestination)
+
+		var remaining, definition string
+		remaining, definition, refactorErr = syntax.ExtractDefinition(activePlan.Ctx, fileState.parser, originalFile, activeBuild.Symbol)
+		if refactorErr == nil {
+			updated = remaining
+
+			// like a file move, the destination is built separately since builds run one path at a time
+			fileState.activeBuildStreamState.queueBuilds([]*types.ActiveBuild{{
+				ReplyId:          activeBuild.ReplyId,
+				Path:             activeBuild.MoveDestination,
+				FileContent:      definition,
+				IsInsertSymbolOp: true,
+				Symbol:           activeBuild.Symbol,
+			}})
+		}
+
+	case activeBuild.IsInsertSymbolOp:
+		summary = fmt.Sprintf("Move %s into this file", activeBuild.Symbol)
+
+		trimmed := strings.TrimRight(originalFile, "\n")
+		if trimmed == "" {
+			updated = activeBuild.FileContent
+		} else {
+			updated = trimmed + "\n\n" + activeBuild.FileContent
+		}
+	}
+
+	if refactorErr != nil {
+		log.Printf("buildRefactor - %s - %s failed, leaving the file unchanged: %v\n", filePath, summary, refactorErr)
+	}
+
+	buildInfo := &shared.BuildInfo{
+		Path:      filePath,
+		NumTokens: 0,
+		Finished:  true,
+	}
+	log.Printf("streaming build info for refactored file %s\n", filePath)
+	activePlan.Stream(shared.StreamMessage{
+		Type:      shared.StreamMessageBuildInfo,
+		BuildInfo: buildInfo,
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	res := db.PlanFileResult{
+		TypeVersion:    1,
+		OrgId:          fileState.plan.OrgId,
+		PlanId:         fileState.plan.Id,
+		PlanBuildId:    fileState.build.Id,
+		ConvoMessageId: fileState.convoMessageId,
+		Path:           filePath,
+	}
+
+	if refactorErr != nil {
+		res.Error = fmt.Sprintf("%s: %v", summary, refactorErr)
+	}
+
+	if originalFile == "" {
+		// a symbol moved into a new file
+		res.Content = updated
+	} else {
+		replacements, err := diff_pkg.GetDiffReplacements(originalFile, updated)
+		if err != nil {
+			log.Printf("buildRefactor - error getting diff replacements: %v\n", err)
+			fileState.onBuildFileError(fmt.Errorf("error getting diff replacements: %v", err))
+			return
+		}
+		for _, replacement := range replacements {
+			replacement.Summary = summary
+		}
+		res.Replacements = replacements
+	}
+
+	fileState.onFinishBuildFile(&res)
+}
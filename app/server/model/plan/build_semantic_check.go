@@ -0,0 +1,109 @@
+package plan
+
+import (
+	"context"
+	"log"
+	"plandex-server/syntax"
+	"plandex-server/types"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+// the checker runs on the client, since only the client has the project -- if there's no response in time, the build finishes without it
+const semanticCheckTimeout = 3 * time.Minute
+
+const maxSemanticCheckFixAttempts = 2
+
+// semanticCheck asks the client to type-check the built file against the project and returns the errors the file introduced. It returns nil if the check couldn't run, so a missing toolchain, a slow client, or a client that has disconnected never blocks a build.
+func (fileState *activeBuildStreamFileState) semanticCheck(ctx context.Context, activePlan *types.ActivePlan, updated string) []string {
+	filePath := fileState.filePath
+	id := uuid.New().String()
+
+	ch, done := activePlan.WaitForSemanticCheck(id)
+	defer done()
+	if ch == nil {
+		// e.g. the client detached with plandex tell --bg, or disconnected
+		log.Printf("semanticCheck - %s - no client connected, skipping check\n", filePath)
+		return nil
+	}
+
+	log.Printf("semanticCheck - %s - requesting check %s\n", filePath, id)
+	activePlan.Stream(shared.StreamMessage{
+		Type: shared.StreamMessageSemanticCheck,
+		SemanticCheck: &shared.SemanticCheckRequest{
+			Id:      id,
+			Path:    filePath,
+			Content: updated,
+		},
+	})
+
+	select {
+	case <-ctx.Done():
+		log.Printf("semanticCheck - %s - context canceled\n", filePath)
+		return nil
+	case <-time.After(semanticCheckTimeout):
+		log.Printf("semanticCheck - %s - timed out waiting for the client\n", filePath)
+		return nil
+	case res := <-ch:
+		if res.Error != "" {
+			log.Printf("semanticCheck - %s - client error: %s\n", filePath, res.Error)
+			return nil
+		}
+		if res.Checker == "" {
+			log.Printf("semanticCheck - %s - no checker available\n", filePath)
+			return nil
+		}
+
+		log.Printf("semanticCheck - %s - %s reported %d new errors\n", filePath, res.Checker, len(res.Diagnostics))
+		fileState.builderRun.DidSemanticCheck = true
+		fileState.builderRun.SemanticChecker = res.Checker
+		return res.Diagnostics
+	}
+}
+
+// semanticCheckAndFix runs the semantic check on a built file and, if it finds new errors, sends them through the validation loop to be fixed. The check is advisory: if the errors can't be fixed, the build keeps the file it already had.
+func (fileState *activeBuildStreamFileState) semanticCheckAndFix(ctx context.Context, activePlan *types.ActivePlan, updated, proposedContent, desc string) string {
+	filePath := fileState.filePath
+
+	if updated == fileState.preBuildState {
+		return updated
+	}
+
+	semanticErrors := fileState.semanticCheck(ctx, activePlan, updated)
+	if len(semanticErrors) == 0 {
+		return updated
+	}
+	fileState.builderRun.SemanticCheckErrors = semanticErrors
+
+	log.Printf("semanticCheckAndFix - %s - fixing %d semantic errors\n", filePath, len(semanticErrors))
+
+	res, err := fileState.buildValidateLoop(ctx, buildValidateLoopParams{
+		originalFile:    fileState.preBuildState,
+		updated:         updated,
+		proposedContent: proposedContent,
+		desc:            desc,
+		reasons:         []syntax.NeedsVerifyReason{syntax.NeedsVerifyReasonSemanticErrors},
+		semanticErrors:  semanticErrors,
+		maxAttempts:     maxSemanticCheckFixAttempts,
+		sessionId:       activePlan.SessionId,
+		semanticCheck: func(ctx context.Context, updated string) []string {
+			return fileState.semanticCheck(ctx, activePlan, updated)
+		},
+	})
+	if err != nil {
+		log.Printf("semanticCheckAndFix - %s - error fixing semantic errors: %v\n", filePath, err)
+		return updated
+	}
+
+	if !res.valid {
+		log.Printf("semanticCheckAndFix - %s - semantic errors remain, keeping the build result\n", filePath)
+		return updated
+	}
+
+	log.Printf("semanticCheckAndFix - %s - semantic errors fixed\n", filePath)
+	fileState.builderRun.SemanticCheckFixSuccess = true
+	return res.updated
+}
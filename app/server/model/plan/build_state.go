@@ -5,6 +5,7 @@ import (
 	"plandex-server/hooks"
 	"plandex-server/model"
 	"plandex-server/types"
+	"sync"
 
 	shared "plandex-shared"
 
@@ -24,6 +25,9 @@ type activeBuildStreamState struct {
 	settings      *shared.PlanSettings
 	modelContext  []*db.Context
 	convo         []*db.ConvoMessage
+
+	// from the plan's allow-generated-edits config -- otherwise builds for generated files are skipped
+	allowGeneratedEdits bool
 }
 
 type activeBuildStreamFileState struct {
@@ -44,4 +48,7 @@ type activeBuildStreamFileState struct {
 	contextPart                *db.Context
 
 	builderRun hooks.DidFinishBuilderRunParams
+
+	strategyResults   []db.BuilderStrategyResult
+	strategyResultsMu sync.Mutex
 }
@@ -0,0 +1,173 @@
+package plan
+
+import (
+	"log"
+	"path/filepath"
+	"plandex-server/db"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// stats are read on every build, so they're cached per org -- a few minutes of staleness doesn't matter for rates built up over many builds
+const builderStatsCacheTTL = 5 * time.Minute
+
+// below this many attempts, a strategy's success rate isn't trusted
+const minBuilderStrategyAttempts = 10
+
+// a strategy that succeeds less often than this is treated as one that often fails
+const unreliableBuilderStrategyRate = 0.5
+
+type cachedBuilderStats struct {
+	stats     []*shared.BuilderStrategyStats
+	fetchedAt time.Time
+}
+
+var (
+	builderStatsCache   = map[string]*cachedBuilderStats{}
+	builderStatsCacheMu sync.Mutex
+)
+
+func getBuilderStrategyStats(orgId string) []*shared.BuilderStrategyStats {
+	builderStatsCacheMu.Lock()
+	defer builderStatsCacheMu.Unlock()
+
+	cached, ok := builderStatsCache[orgId]
+	if ok && time.Since(cached.fetchedAt) < builderStatsCacheTTL {
+		return cached.stats
+	}
+
+	stats, err := db.GetBuilderStrategyStats(orgId)
+	if err != nil {
+		// cache the miss too, so a db problem doesn't add a query to every build
+		log.Printf("Error getting builder strategy stats: %v\n", err)
+	}
+
+	res := db.ToApiBuilderStrategyStats(stats)
+	builderStatsCache[orgId] = &cachedBuilderStats{stats: res, fetchedAt: time.Now()}
+	return res
+}
+
+type builderStrategyChoice struct {
+	// start fast apply alongside auto apply rather than waiting for auto apply to fail
+	fastApplyFirst bool
+	// start the fallbacks alongside the validation loop rather than waiting for it to fail
+	raceFallbacks bool
+}
+
+// chooseBuilderStrategies uses the org's history for files of the same language and size to decide which strategies to start up front. With too little history, it keeps the default order.
+func (fileState *activeBuildStreamFileState) chooseBuilderStrategies() builderStrategyChoice {
+	var choice builderStrategyChoice
+
+	allStats := getBuilderStrategyStats(fileState.currentOrgId)
+	if len(allStats) == 0 {
+		return choice
+	}
+
+	lang := fileState.strategyLang()
+	sizeBucket := fileState.strategySizeBucket()
+
+	find := func(strategy shared.BuilderStrategy) *shared.BuilderStrategyStats {
+		model := fileState.strategyModel(strategy)
+		for _, stats := range allStats {
+			if stats.Strategy == strategy && stats.Lang == lang && stats.Model == model && stats.SizeBucket == sizeBucket {
+				if stats.Attempts < minBuilderStrategyAttempts {
+					return nil
+				}
+				return stats
+			}
+		}
+		return nil
+	}
+
+	// a fallback is worth starting early unless history says it does no better
+	worthTrying := func(fallback *shared.BuilderStrategyStats, than *shared.BuilderStrategyStats) bool {
+		return fallback == nil || fallback.SuccessRate() > than.SuccessRate()
+	}
+
+	autoApply := find(shared.BuilderStrategyAutoApply)
+	validate := find(shared.BuilderStrategyValidate)
+	fastApply := find(shared.BuilderStrategyFastApply)
+	wholeFile := find(shared.BuilderStrategyWholeFile)
+
+	if autoApply != nil && autoApply.SuccessRate() < unreliableBuilderStrategyRate && worthTrying(fastApply, autoApply) {
+		choice.fastApplyFirst = true
+	}
+
+	if validate != nil && validate.SuccessRate() < unreliableBuilderStrategyRate && (worthTrying(fastApply, validate) || worthTrying(wholeFile, validate)) {
+		choice.raceFallbacks = true
+	}
+
+	if choice.fastApplyFirst || choice.raceFallbacks {
+		log.Printf("chooseBuilderStrategies - %s (%s, %s) - fastApplyFirst: %t, raceFallbacks: %t\n", fileState.filePath, lang, sizeBucket, choice.fastApplyFirst, choice.raceFallbacks)
+	}
+
+	return choice
+}
+
+func (fileState *activeBuildStreamFileState) strategyLang() string {
+	if fileState.language != "" {
+		return string(fileState.language)
+	}
+	return filepath.Ext(fileState.filePath)
+}
+
+func (fileState *activeBuildStreamFileState) strategySizeBucket() string {
+	return shared.GetBuilderSizeBucket(shared.GetNumTokensEstimate(fileState.preBuildState))
+}
+
+// strategyModel returns the model pack model a strategy calls, so that stats are kept separately for each model
+func (fileState *activeBuildStreamFileState) strategyModel(strategy shared.BuilderStrategy) string {
+	if fileState.settings == nil || fileState.settings.ModelPack == nil {
+		return ""
+	}
+
+	switch strategy {
+	case shared.BuilderStrategyValidate:
+		return string(fileState.settings.ModelPack.Builder.BaseModelConfig.ModelName)
+	case shared.BuilderStrategyWholeFile:
+		return string(fileState.settings.ModelPack.GetWholeFileBuilder().BaseModelConfig.ModelName)
+	}
+	return ""
+}
+
+// recordBuilderStrategyResult is called when a strategy finishes. Strategies that are canceled because another one won the race aren't recorded, since they didn't fail.
+func (fileState *activeBuildStreamFileState) recordBuilderStrategyResult(strategy shared.BuilderStrategy, success bool, startedAt time.Time) {
+	fileState.strategyResultsMu.Lock()
+	defer fileState.strategyResultsMu.Unlock()
+
+	var latencyMs int64
+	if !startedAt.IsZero() {
+		latencyMs = time.Since(startedAt).Milliseconds()
+	}
+
+	fileState.strategyResults = append(fileState.strategyResults, db.BuilderStrategyResult{
+		Strategy:  strategy,
+		Model:     fileState.strategyModel(strategy),
+		Success:   success,
+		LatencyMs: latencyMs,
+	})
+}
+
+func (fileState *activeBuildStreamFileState) storeBuilderStrategyResults() {
+	fileState.strategyResultsMu.Lock()
+	results := fileState.strategyResults
+	fileState.strategyResults = nil
+	fileState.strategyResultsMu.Unlock()
+
+	if len(results) == 0 {
+		return
+	}
+
+	orgId := fileState.currentOrgId
+	lang := fileState.strategyLang()
+	sizeBucket := fileState.strategySizeBucket()
+
+	go func() {
+		err := db.RecordBuilderStrategyResults(orgId, lang, sizeBucket, results)
+		if err != nil {
+			log.Printf("Error recording builder strategy results: %v\n", err)
+		}
+	}()
+}
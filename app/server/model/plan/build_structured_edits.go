@@ -38,6 +38,11 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 	proposedContent := activeBuild.FileContent
 	desc := activeBuild.FileDescription
 
+	strategyChoice := fileState.chooseBuilderStrategies()
+	fileState.builderRun.FastApplyFirst = strategyChoice.fastApplyFirst
+	fileState.builderRun.RacedFallbacks = strategyChoice.raceFallbacks
+	defer fileState.storeBuilderStrategyResults()
+
 	descLower := strings.ToLower(desc)
 	isReplaceOrRemove := strings.Contains(descLower, "type: replace") || strings.Contains(descLower, "type: remove") || strings.Contains(descLower, "type: overwrite")
 
@@ -86,13 +91,14 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 		}()
 	}
 
-	if isReplaceOrRemove {
+	if isReplaceOrRemove || strategyChoice.fastApplyFirst {
 		callFastApply()
 	}
 
 	log.Printf("buildStructuredEdits - %s - applying changes\n", filePath)
 	// Apply plan logic
 	log.Printf("buildStructuredEdits - %s - calling ApplyChanges\n", filePath)
+	autoApplyStartedAt := time.Now()
 	autoApplyRes = syntax.ApplyChanges(
 		buildCtx,
 		syntax.ApplyChangesParams{
@@ -114,6 +120,7 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 
 	autoApplyHasSyntaxErrors := len(autoApplySyntaxErrors) > 0
 	autoApplyIsValid := !autoApplyHasSyntaxErrors && !hasNeedsVerifyReasons
+	fileState.recordBuilderStrategyResult(shared.BuilderStrategyAutoApply, autoApplyIsValid, autoApplyStartedAt)
 
 	if !autoApplyIsValid && !calledFastApply {
 		callFastApply()
@@ -147,6 +154,8 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 			didCallFastApply: calledFastApply,
 			fastApplyCh:      fastApplyCh,
 
+			raceFallbacks: strategyChoice.raceFallbacks,
+
 			sessionId: activePlan.SessionId,
 		}
 
@@ -165,6 +174,10 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 		updated = buildRaceResult.content
 	}
 
+	if activePlan.SemanticCheck {
+		updated = fileState.semanticCheckAndFix(buildCtx, activePlan, updated, proposedContent, desc)
+	}
+
 	// output diff and store build results
 	buildInfo := &shared.BuildInfo{
 		Path:      filePath,
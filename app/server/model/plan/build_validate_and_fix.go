@@ -28,11 +28,15 @@ type buildValidateLoopParams struct {
 	desc                       string
 	syntaxErrors               []string
 	reasons                    []syntax.NeedsVerifyReason
+	semanticErrors             []string
 	initialPhaseOnStream       func(chunk string, buffer string) bool
 	validateOnlyOnFinalAttempt bool
 	maxAttempts                int
 	isInitial                  bool
 	sessionId                  string
+
+	// when set, each attempt is re-checked with it and only passes once it reports no errors
+	semanticCheck func(ctx context.Context, updated string) []string
 }
 
 type buildValidateLoopResult struct {
@@ -53,6 +57,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 	desc := params.desc
 
 	syntaxErrors := params.syntaxErrors
+	semanticErrors := params.semanticErrors
 	numAttempts := 0
 
 	problems := []string{}
@@ -112,6 +117,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 			onStream:        onStream,
 			syntaxErrors:    syntaxErrors,
 			reasons:         reasons,
+			semanticErrors:  semanticErrors,
 			modelConfig:     &modelConfig,
 			validateOnly:    isLastAttempt && params.validateOnlyOnFinalAttempt,
 			phase:           currentAttempt,
@@ -135,7 +141,12 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 		syntaxErrors = fileState.validateSyntax(ctx, updated)
 		log.Printf("Found %d syntax errors after attempt %d", len(syntaxErrors), currentAttempt)
 
-		if res.valid && len(syntaxErrors) == 0 {
+		if params.semanticCheck != nil && len(syntaxErrors) == 0 {
+			semanticErrors = params.semanticCheck(ctx, updated)
+			log.Printf("Found %d semantic errors after attempt %d", len(semanticErrors), currentAttempt)
+		}
+
+		if res.valid && len(syntaxErrors) == 0 && len(semanticErrors) == 0 {
 			log.Printf("Validation succeeded in attempt %d", currentAttempt)
 			return buildValidateLoopResult{
 				valid:   res.valid,
@@ -165,6 +176,7 @@ type buildValidateParams struct {
 	desc            string
 	syntaxErrors    []string
 	reasons         []syntax.NeedsVerifyReason
+	semanticErrors  []string
 	onStream        func(chunk string, buffer string) bool
 	phase           int
 	modelConfig     *shared.ModelRoleConfig
@@ -219,6 +231,7 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 		Diff:                 diff,
 		SyntaxErrors:         syntaxErrors,
 		Reasons:              reasons,
+		SemanticErrors:       params.semanticErrors,
 	})
 
 	// log.Printf("Prompt to LLM: %s", promptText)
@@ -5,7 +5,9 @@ import (
 	"log"
 	"plandex-server/db"
 	"plandex-server/shutdown"
+	"plandex-server/streambus"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -26,6 +28,10 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 
 	activePlans.Set(key, activePlan)
 
+	if streambus.Enabled() {
+		go streambus.ServePlan(activePlan.Ctx, planId, branch)
+	}
+
 	go func() {
 		for {
 			select {
@@ -37,6 +43,8 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 					log.Printf("Error setting plan %s status to stopped: %v\n", planId, err)
 				}
 
+				dispatchPlanWebhook(activePlan, shared.WebhookEventPlanStopped, nil)
+
 				DeleteActivePlan(orgId, userId, planId, branch)
 
 				return
@@ -52,6 +60,8 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to ready: %v\n", planId, err)
 					}
 
+					dispatchPlanWebhook(activePlan, shared.WebhookEventPlanFinished, nil)
+
 					// cancel *after* the DeleteActivePlan call
 					// allows queued operations to complete
 					DeleteActivePlan(orgId, userId, planId, branch)
@@ -65,6 +75,8 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to error: %v\n", planId, err)
 					}
 
+					dispatchPlanWebhook(activePlan, shared.WebhookEventExecFailed, apiErr)
+
 					log.Println("Sending error message to client")
 					activePlan.Stream(shared.StreamMessage{
 						Type:  shared.StreamMessageError,
@@ -92,6 +104,40 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 	return activePlan
 }
 
+// dispatchPlanWebhook sends a lifecycle event to the org's webhooks. A failed build is reported as plan.build_failed rather than plan.exec_failed.
+func dispatchPlanWebhook(activePlan *types.ActivePlan, event shared.WebhookEvent, apiErr *shared.ApiError) {
+	params := webhooks.PlanEventParams{
+		OrgId:      activePlan.OrgId,
+		UserId:     activePlan.UserId,
+		PlanId:     activePlan.Id,
+		Branch:     activePlan.Branch,
+		Background: activePlan.IsBackground,
+	}
+
+	if apiErr != nil {
+		params.Error = apiErr.Msg
+
+		// build queues are updated under the active plans lock, so read them the same way
+		var failedPath string
+		UpdateActivePlan(activePlan.Id, activePlan.Branch, func(ap *types.ActivePlan) {
+			for path, builds := range ap.BuildQueuesByPath {
+				for _, build := range builds {
+					if build.Error != nil {
+						failedPath = path
+					}
+				}
+			}
+		})
+
+		if failedPath != "" {
+			event = shared.WebhookEventBuildFailed
+			params.FilePath = failedPath
+		}
+	}
+
+	webhooks.DispatchPlanEvent(event, params)
+}
+
 func DeleteActivePlan(orgId, userId, planId, branch string) {
 	log.Printf("Deleting active plan %s - %s - %s\n", planId, branch, orgId)
 
@@ -136,7 +182,7 @@ func UpdateActivePlan(planId, branch string, fn func(*types.ActivePlan)) {
 	activePlans.Update(strings.Join([]string{planId, branch}, "|"), fn)
 }
 
-func SubscribePlan(ctx context.Context, planId, branch string) (string, chan string) {
+func SubscribePlan(ctx context.Context, planId, branch string, semanticChecker bool) (string, chan string) {
 	log.Printf("Subscribing to plan %s\n", planId)
 	var id string
 	var ch chan string
@@ -148,7 +194,7 @@ func SubscribePlan(ctx context.Context, planId, branch string) (string, chan str
 	}
 
 	UpdateActivePlan(planId, branch, func(activePlan *types.ActivePlan) {
-		id, ch = activePlan.Subscribe(ctx)
+		id, ch = activePlan.Subscribe(ctx, semanticChecker)
 	})
 	return id, ch
 }
@@ -52,6 +52,8 @@ func (state *activeTellStreamState) queuePendingBuilds() {
 		branch:        branch,
 		settings:      state.settings,
 		modelContext:  state.modelContext,
+
+		allowGeneratedEdits: state.allowGeneratedEdits,
 	}
 
 	for _, pendingBuilds := range pendingBuildsByPath {
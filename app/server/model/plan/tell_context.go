@@ -13,6 +13,9 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
+// generated files are listed so the model knows not to edit them -- a project map can include many, so the list is capped
+const maxGeneratedPathsListed = 50
+
 type formatModelContextParams struct {
 	includeMaps          bool
 	smartContextEnabled  bool
@@ -70,6 +73,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 	totalTokens := 0
 
 	type toLoad struct {
+		ContextId   string
 		FilePath    string
 		Name        string
 		Url         string
@@ -123,6 +127,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		}
 
 		toLoadAll = append(toLoadAll, toLoad{
+			ContextId:   part.Id,
 			FilePath:    part.FilePath,
 			NumTokens:   part.NumTokens,
 			Body:        part.Body,
@@ -207,16 +212,25 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		})
 	}
 
-	for _, part := range toLoadAll {
+	for i, part := range toLoadAll {
 		totalTokens += part.NumTokens
 
 		if maxTokens > 0 && totalTokens > maxTokens {
 			if verboseLogging {
 				log.Printf("Tell plan - formatModelContext - total tokens: %d\n", totalTokens)
 			}
+			if state.isPreview {
+				for _, dropped := range toLoadAll[i:] {
+					state.addPreviewContext(dropped.ContextId, dropped.Name, dropped.FilePath, dropped.ContextType, dropped.NumTokens, dropped.IsPending, shared.TellPlanPreviewContextDropped)
+				}
+			}
 			break
 		}
 
+		if state.isPreview {
+			state.addPreviewContext(part.ContextId, part.Name, part.FilePath, part.ContextType, part.NumTokens, part.IsPending, shared.TellPlanPreviewContextIncluded)
+		}
+
 		var message string
 		var fmtStr string
 		var args []any
@@ -283,6 +297,30 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		log.Println(contextBodies)
 	}
 
+	if !state.allowGeneratedEdits {
+		generated := generatedReasonsByPath(state.modelContext)
+		if len(generated) > 0 {
+			paths := make([]string, 0, len(generated))
+			for path := range generated {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			if len(paths) > maxGeneratedPathsListed {
+				paths = paths[:maxGeneratedPathsListed]
+			}
+
+			contextBodies = append(contextBodies, "*Generated files:*\n")
+			for _, path := range paths {
+				contextBodies = append(contextBodies, fmt.Sprintf("- %s (%s)", path, shared.GeneratedReasonDescriptions[generated[path]]))
+			}
+			if len(generated) > len(paths) {
+				contextBodies = append(contextBodies, fmt.Sprintf("- ...and %d more", len(generated)-len(paths)))
+			}
+			contextBodies = append(contextBodies, "These files are generated, vendored, or lockfiles. Do NOT edit, move, or remove them—changes to them will be skipped. If one needs to change, update the source it's generated from (like a schema, a .proto file, or a package manifest) instead, and regenerate it with a command if commands can be run.")
+		}
+	}
+
 	var execScriptLines []string
 
 	if includeApplyScript &&
@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"fmt"
+	"log"
+	"plandex-server/types"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	mapGraphMaxDepth = 2
+	mapGraphMaxFiles = 40
+)
+
+// formatMapGraph lists mapped files reachable from the files mentioned in the prompt through imports and symbol references, so the context phase can follow relationships that the map's signatures don't show. Returns nil if the prompt doesn't mention any mapped files.
+func (state *activeTellStreamState) formatMapGraph() *types.ExtendedChatMessagePart {
+	if len(state.mapDeps) == 0 {
+		return nil
+	}
+
+	graph := state.mapDeps.BuildGraph()
+
+	mentioned := graph.PathsMentionedIn(state.req.Prompt)
+	if len(mentioned) == 0 {
+		return nil
+	}
+
+	reachable := graph.Reachable(mentioned, mapGraphMaxDepth, mapGraphMaxFiles)
+	if len(reachable) == len(mentioned) {
+		return nil
+	}
+
+	log.Printf("Tell plan - formatMapGraph - mentioned: %v, reachable: %d\n", mentioned, len(reachable))
+
+	lines := []string{
+		"### DEPENDENCY GRAPH ###",
+		"",
+		"Files mentioned in the prompt, followed by mapped files reachable from them through imports and symbol references, nearest first. 'dependency of' means the listed file is imported or used by the file it was reached from. 'depends on' means the listed file imports or uses the file it was reached from.",
+		"",
+	}
+
+	for _, r := range reachable {
+		if r.From == "" {
+			lines = append(lines, fmt.Sprintf("- `%s` (mentioned in prompt)", r.Path))
+			continue
+		}
+
+		relation := "dependency of"
+		if r.IsDependent {
+			relation = "depends on"
+		}
+
+		var details []string
+		if r.Edge != nil {
+			if r.Edge.Import {
+				details = append(details, "import")
+			}
+			if len(r.Edge.Symbols) > 0 {
+				details = append(details, "uses "+strings.Join(r.Edge.Symbols, ", "))
+			}
+		}
+
+		line := fmt.Sprintf("- `%s` — %s `%s`", r.Path, relation, r.From)
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, "; ") + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", "### END OF DEPENDENCY GRAPH ###\n\n")
+
+	return &types.ExtendedChatMessagePart{
+		Type: openai.ChatMessagePartTypeText,
+		Text: strings.Join(lines, "\n"),
+	}
+}
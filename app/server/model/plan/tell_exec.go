@@ -139,23 +139,9 @@ func execTellPlan(params execTellPlanParams) {
 
 	activatePaths, activatePathsOrdered := state.resolveCurrentStage()
 
-	var tentativeModelConfig shared.ModelRoleConfig
-	var tentativeMaxTokens int
-	if state.currentStage.TellStage == shared.TellStagePlanning {
-		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
-			log.Println("Tell plan - isContextStage - setting modelConfig to context loader")
-			tentativeModelConfig = state.settings.ModelPack.GetArchitect()
-			tentativeMaxTokens = state.settings.GetArchitectEffectiveMaxTokens()
-		} else {
-			plannerConfig := state.settings.ModelPack.Planner
-			tentativeModelConfig = plannerConfig.ModelRoleConfig
-			tentativeMaxTokens = state.settings.GetPlannerEffectiveMaxTokens()
-		}
-	} else if state.currentStage.TellStage == shared.TellStageImplementation {
-		tentativeModelConfig = state.settings.ModelPack.GetCoder()
-		tentativeMaxTokens = tentativeModelConfig.GetFinalLargeContextFallback().BaseModelConfig.MaxTokens
-	} else {
-		log.Printf("Tell plan - execTellPlan - unknown tell stage: %s\n", state.currentStage.TellStage)
+	tentativeModelConfig, tentativeMaxTokens, err := state.resolveTentativeModelConfig()
+	if err != nil {
+		log.Printf("Tell plan - execTellPlan - %v\n", err)
 		active.StreamDoneCh <- &shared.ApiError{
 			Type:   shared.ApiErrorTypeOther,
 			Status: http.StatusInternalServerError,
@@ -170,76 +156,10 @@ func execTellPlan(params execTellPlanParams) {
 		return
 	}
 
-	var planStageSharedMsgs []*types.ExtendedChatMessagePart
-	var planningPhaseOnlyMsgs []*types.ExtendedChatMessagePart
-	var implementationMsgs []*types.ExtendedChatMessagePart
-
-	if state.currentStage.TellStage == shared.TellStageImplementation {
-		implementationMsgs = state.formatModelContext(formatModelContextParams{
-			includeMaps:         false,
-			smartContextEnabled: req.SmartContext,
-			includeApplyScript:  req.ExecEnabled,
-		})
-	} else if state.currentStage.TellStage == shared.TellStagePlanning {
-		// add the shared context between planning and context phases first so it can be cached
-		// this is just for the map and any manually loaded contexts - auto contexts will be added later
-		planStageSharedMsgs = state.formatModelContext(formatModelContextParams{
-			includeMaps:         true,
-			smartContextEnabled: req.SmartContext,
-			includeApplyScript:  req.ExecEnabled,
-			baseOnly:            true,
-			cacheControl:        true,
-		})
-
-		if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
-			if req.AutoContext {
-				msg := types.ExtendedChatMessage{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: []types.ExtendedChatMessagePart{},
-				}
-				for _, part := range planStageSharedMsgs {
-					msg.Content = append(msg.Content, *part)
-				}
-				sharedMsgsTokens := model.GetMessagesTokenEstimate(msg)
-
-				tokensRemaining := tentativeMaxTokens - (sharedMsgsTokens + tokensWithoutContext)
-
-				if tokensRemaining < 0 {
-					log.Println("tokensRemaining is negative")
-					active.StreamDoneCh <- &shared.ApiError{
-						Type:   shared.ApiErrorTypeOther,
-						Status: http.StatusInternalServerError,
-						Msg:    "Max tokens exceeded before adding context",
-					}
-					return
-				}
-
-				planningPhaseOnlyMsgs = state.formatModelContext(formatModelContextParams{
-					includeMaps:          false,
-					smartContextEnabled:  req.SmartContext,
-					includeApplyScript:   false, // already included in planStageSharedMsgs
-					activeOnly:           true,
-					activatePaths:        activatePaths,
-					activatePathsOrdered: activatePathsOrdered,
-					maxTokens:            int(float64(tokensRemaining) * 0.95), // leave a little extra room
-				})
-			} else {
-				// if auto context is disabled, just dump in any remaining auto contexts, since all basic contexts have already been added in planStageSharedMsgs
-				planningPhaseOnlyMsgs = state.formatModelContext(formatModelContextParams{
-					includeMaps:         false,
-					smartContextEnabled: req.SmartContext,
-					includeApplyScript:  false, // already included in planStageSharedMsgs
-					autoOnly:            true,
-				})
-			}
-		}
-	}
-
-	getTellSysPromptParams := getTellSysPromptParams{
-		planStageSharedMsgs:   planStageSharedMsgs,
-		planningPhaseOnlyMsgs: planningPhaseOnlyMsgs,
-		implementationMsgs:    implementationMsgs,
-		contextTokenLimit:     tentativeMaxTokens,
+	getTellSysPromptParams, apiErr := state.resolveTellSysPromptParams(tentativeMaxTokens, tokensWithoutContext, activatePaths, activatePathsOrdered)
+	if apiErr != nil {
+		active.StreamDoneCh <- apiErr
+		return
 	}
 
 	// log.Println("getTellSysPromptParams:\n", spew.Sdump(getTellSysPromptParams))
@@ -337,18 +257,8 @@ func execTellPlan(params execTellPlanParams) {
 	log.Println("Tell plan - state.currentStage.TellStage:", state.currentStage.TellStage)
 	log.Println("Tell plan - state.currentStage.PlanningPhase:", state.currentStage.PlanningPhase)
 
-	if state.currentStage.TellStage == shared.TellStagePlanning {
-		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
-			log.Println("Tell plan - isContextStage - setting modelConfig to context loader")
-			modelConfig = state.settings.ModelPack.GetArchitect().GetRoleForInputTokens(requestTokens)
-			log.Println("Tell plan - got modelConfig for context phase")
-		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
-			modelConfig = state.settings.ModelPack.Planner.GetRoleForInputTokens(requestTokens).ModelRoleConfig
-			log.Println("Tell plan - got modelConfig for tasks phase")
-		}
-	} else if state.currentStage.TellStage == shared.TellStageImplementation {
-		modelConfig = state.settings.ModelPack.GetCoder().GetRoleForInputTokens(requestTokens)
-		log.Println("Tell plan - got modelConfig for implementation stage")
+	if roleConfig, ok := state.getModelConfigForInputTokens(requestTokens); ok {
+		modelConfig = roleConfig
 	}
 
 	log.Println("Tell plan - modelConfig:", spew.Sdump(modelConfig))
@@ -385,7 +295,7 @@ func execTellPlan(params execTellPlanParams) {
 		"tokens":   requestTokens,
 	}))
 
-	_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+	_, apiErr = hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
 		WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
@@ -483,6 +393,7 @@ func (state *activeTellStreamState) dryRunCalculateTokensWithoutContext(tentativ
 		modelContext:        state.modelContext,
 		activePlan:          state.activePlan,
 		tenativeModelConfig: state.tenativeModelConfig,
+		allowGeneratedEdits: state.allowGeneratedEdits,
 	}
 
 	sysParts, err := clone.getTellSysPrompt(getTellSysPromptParams{
@@ -542,3 +453,119 @@ func (state *activeTellStreamState) dryRunCalculateTokensWithoutContext(tentativ
 
 	return true, model.GetMessagesTokenEstimate(clone.messages...) + model.TokensPerRequest
 }
+
+// resolveTentativeModelConfig returns the base model config for the current stage along with the token limit used to budget context before the final model (which may be a large context fallback) is chosen
+func (state *activeTellStreamState) resolveTentativeModelConfig() (shared.ModelRoleConfig, int, error) {
+	if state.currentStage.TellStage == shared.TellStagePlanning {
+		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
+			log.Println("Tell plan - isContextStage - setting modelConfig to context loader")
+			return state.settings.ModelPack.GetArchitect(), state.settings.GetArchitectEffectiveMaxTokens(), nil
+		}
+		plannerConfig := state.settings.ModelPack.Planner
+		return plannerConfig.ModelRoleConfig, state.settings.GetPlannerEffectiveMaxTokens(), nil
+	} else if state.currentStage.TellStage == shared.TellStageImplementation {
+		coderConfig := state.settings.ModelPack.GetCoder()
+		return coderConfig, coderConfig.GetFinalLargeContextFallback().BaseModelConfig.MaxTokens, nil
+	}
+
+	return shared.ModelRoleConfig{}, 0, fmt.Errorf("unknown tell stage: %s", state.currentStage.TellStage)
+}
+
+// getModelConfigForInputTokens picks the model for the current stage given the final request size, following large context fallbacks as needed
+func (state *activeTellStreamState) getModelConfigForInputTokens(requestTokens int) (shared.ModelRoleConfig, bool) {
+	if state.currentStage.TellStage == shared.TellStagePlanning {
+		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
+			log.Println("Tell plan - got modelConfig for context phase")
+			return state.settings.ModelPack.GetArchitect().GetRoleForInputTokens(requestTokens), true
+		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
+			log.Println("Tell plan - got modelConfig for tasks phase")
+			return state.settings.ModelPack.Planner.GetRoleForInputTokens(requestTokens).ModelRoleConfig, true
+		}
+	} else if state.currentStage.TellStage == shared.TellStageImplementation {
+		log.Println("Tell plan - got modelConfig for implementation stage")
+		return state.settings.ModelPack.GetCoder().GetRoleForInputTokens(requestTokens), true
+	}
+
+	return shared.ModelRoleConfig{}, false
+}
+
+// resolveTellSysPromptParams formats the plan context for the current stage. During the tasks phase with auto context, activated files are only added up to the tokens remaining after everything else in the request.
+func (state *activeTellStreamState) resolveTellSysPromptParams(tentativeMaxTokens, tokensWithoutContext int, activatePaths map[string]bool, activatePathsOrdered []string) (getTellSysPromptParams, *shared.ApiError) {
+	req := state.req
+
+	var planStageSharedMsgs []*types.ExtendedChatMessagePart
+	var planningPhaseOnlyMsgs []*types.ExtendedChatMessagePart
+	var implementationMsgs []*types.ExtendedChatMessagePart
+
+	if state.currentStage.TellStage == shared.TellStageImplementation {
+		implementationMsgs = state.formatModelContext(formatModelContextParams{
+			includeMaps:         false,
+			smartContextEnabled: req.SmartContext,
+			includeApplyScript:  req.ExecEnabled,
+		})
+	} else if state.currentStage.TellStage == shared.TellStagePlanning {
+		// add the shared context between planning and context phases first so it can be cached
+		// this is just for the map and any manually loaded contexts - auto contexts will be added later
+		planStageSharedMsgs = state.formatModelContext(formatModelContextParams{
+			includeMaps:         true,
+			smartContextEnabled: req.SmartContext,
+			includeApplyScript:  req.ExecEnabled,
+			baseOnly:            true,
+			cacheControl:        true,
+		})
+
+		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
+			// kept out of planStageSharedMsgs since it changes with every prompt
+			if graphMsg := state.formatMapGraph(); graphMsg != nil {
+				planningPhaseOnlyMsgs = append(planningPhaseOnlyMsgs, graphMsg)
+			}
+		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
+			if req.AutoContext {
+				msg := types.ExtendedChatMessage{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: []types.ExtendedChatMessagePart{},
+				}
+				for _, part := range planStageSharedMsgs {
+					msg.Content = append(msg.Content, *part)
+				}
+				sharedMsgsTokens := model.GetMessagesTokenEstimate(msg)
+
+				tokensRemaining := tentativeMaxTokens - (sharedMsgsTokens + tokensWithoutContext)
+
+				if tokensRemaining < 0 {
+					log.Println("tokensRemaining is negative")
+					return getTellSysPromptParams{}, &shared.ApiError{
+						Type:   shared.ApiErrorTypeOther,
+						Status: http.StatusInternalServerError,
+						Msg:    "Max tokens exceeded before adding context",
+					}
+				}
+
+				planningPhaseOnlyMsgs = state.formatModelContext(formatModelContextParams{
+					includeMaps:          false,
+					smartContextEnabled:  req.SmartContext,
+					includeApplyScript:   false, // already included in planStageSharedMsgs
+					activeOnly:           true,
+					activatePaths:        activatePaths,
+					activatePathsOrdered: activatePathsOrdered,
+					maxTokens:            int(float64(tokensRemaining) * 0.95), // leave a little extra room
+				})
+			} else {
+				// if auto context is disabled, just dump in any remaining auto contexts, since all basic contexts have already been added in planStageSharedMsgs
+				planningPhaseOnlyMsgs = state.formatModelContext(formatModelContextParams{
+					includeMaps:         false,
+					smartContextEnabled: req.SmartContext,
+					includeApplyScript:  false, // already included in planStageSharedMsgs
+					autoOnly:            true,
+				})
+			}
+		}
+	}
+
+	return getTellSysPromptParams{
+		planStageSharedMsgs:   planStageSharedMsgs,
+		planningPhaseOnlyMsgs: planningPhaseOnlyMsgs,
+		implementationMsgs:    implementationMsgs,
+		contextTokenLimit:     tentativeMaxTokens,
+	}, nil
+}
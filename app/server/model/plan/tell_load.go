@@ -38,6 +38,7 @@ func (state *activeTellStreamState) loadTellPlan() error {
 	}
 
 	var modelContext []*db.Context
+	var mapDeps shared.FileMapDepsByPath
 	var convo []*db.ConvoMessage
 	var promptMsg *db.ConvoMessage
 	var summaries []*db.ConvoSummary
@@ -45,6 +46,7 @@ func (state *activeTellStreamState) loadTellPlan() error {
 	var settings *shared.PlanSettings
 	var latestSummaryTokens int
 	var currentPlan *shared.CurrentPlanState
+	var allowGeneratedEdits bool
 
 	log.Printf("[TellLoad] Tell plan - loadTellPlan - iteration: %d, missingFileResponse: %s, req.IsUserContinue: %t, lockScope: %s\n", iteration, missingFileResponse, req.IsUserContinue, lockScope)
 
@@ -131,9 +133,27 @@ func (state *activeTellStreamState) loadTellPlan() error {
 				// 	log.Printf("[TellLoad] Tell plan - loadTellPlan - part: %s - %s - %s - %d tokens\n", part.ContextType, part.Name, part.FilePath, part.NumTokens)
 				// }
 
+				err = db.RefocusBudgetedMaps(currentOrgId, planId, res)
+				if err != nil {
+					log.Printf("Error refocusing budgeted maps: %v\n", err)
+					errCh <- fmt.Errorf("error refocusing budgeted maps: %v", err)
+					return
+				}
+
 				modelContext = res
 			}
 
+			// the dependency graph is only used by the context phase, which requires auto context
+			if req.AutoContext {
+				res, err := db.GetPlanMapDeps(currentOrgId, planId, modelContext)
+				if err != nil {
+					log.Printf("Error getting plan map deps: %v\n", err)
+					errCh <- fmt.Errorf("error getting plan map deps: %v", err)
+					return
+				}
+				mapDeps = res
+			}
+
 			errCh <- nil
 		}()
 
@@ -245,7 +265,18 @@ func (state *activeTellStreamState) loadTellPlan() error {
 			errCh <- nil
 		}()
 
-		for i := 0; i < 4; i++ {
+		go func() {
+			res, err := planAllowsGeneratedEdits(planId)
+			if err != nil {
+				log.Printf("Error getting plan config: %v\n", err)
+				errCh <- fmt.Errorf("error getting plan config: %v", err)
+				return
+			}
+			allowGeneratedEdits = res
+			errCh <- nil
+		}()
+
+		for i := 0; i < 5; i++ {
 			err = <-errCh
 			if err != nil {
 				active.StreamDoneCh <- &shared.ApiError{
@@ -283,43 +314,17 @@ func (state *activeTellStreamState) loadTellPlan() error {
 	}
 
 	state.modelContext = modelContext
+	state.mapDeps = mapDeps
 	state.convo = convo
 	state.promptConvoMessage = promptMsg
 	state.summaries = summaries
 	state.latestSummaryTokens = latestSummaryTokens
 	state.settings = settings
+	state.allowGeneratedEdits = allowGeneratedEdits
 	state.currentPlanState = currentPlan
 	state.subtasks = subtasks
 
-	for _, subtask := range state.subtasks {
-		if !subtask.IsFinished {
-			state.currentSubtask = subtask
-			break
-		}
-	}
-
-	log.Printf("[TellLoad] Subtasks: %+v", state.subtasks)
-	log.Printf("[TellLoad] Current subtask: %+v", state.currentSubtask)
-
-	state.hasContextMap = false
-	state.contextMapEmpty = true
-	for _, context := range state.modelContext {
-		if context.ContextType == shared.ContextMapType {
-			state.hasContextMap = true
-			if context.NumTokens > 0 {
-				state.contextMapEmpty = false
-			}
-			break
-		}
-	}
-
-	state.hasAssistantReply = false
-	for _, convoMessage := range state.convo {
-		if convoMessage.Role == openai.ChatMessageRoleAssistant {
-			state.hasAssistantReply = true
-			break
-		}
-	}
+	state.resolveLoadedFlags()
 
 	if iteration == 0 && missingFileResponse == "" {
 		UpdateActivePlan(planId, branch, func(ap *types.ActivePlan) {
@@ -373,3 +378,36 @@ func (state *activeTellStreamState) setActivePlan() error {
 
 	return nil
 }
+
+// resolveLoadedFlags sets the current subtask and the context map and assistant reply flags from the loaded plan state
+func (state *activeTellStreamState) resolveLoadedFlags() {
+	for _, subtask := range state.subtasks {
+		if !subtask.IsFinished {
+			state.currentSubtask = subtask
+			break
+		}
+	}
+
+	log.Printf("[TellLoad] Subtasks: %+v", state.subtasks)
+	log.Printf("[TellLoad] Current subtask: %+v", state.currentSubtask)
+
+	state.hasContextMap = false
+	state.contextMapEmpty = true
+	for _, context := range state.modelContext {
+		if context.ContextType == shared.ContextMapType {
+			state.hasContextMap = true
+			if context.NumTokens > 0 {
+				state.contextMapEmpty = false
+			}
+			break
+		}
+	}
+
+	state.hasAssistantReply = false
+	for _, convoMessage := range state.convo {
+		if convoMessage.Role == openai.ChatMessageRoleAssistant {
+			state.hasAssistantReply = true
+			break
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"plandex-server/model"
+	"plandex-server/types"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const previewPromptMessageId = "preview"
+
+// PreviewTell runs the same stage, context, and conversation accounting as a tell and returns a token breakdown. Nothing is stored, the plan isn't activated, and no model is called.
+func PreviewTell(ctx context.Context, plan *db.Plan, branch string, auth *types.ServerAuth, req *shared.TellPlanRequest) (*shared.TellPlanPreviewResponse, error) {
+	log.Printf("PreviewTell: Called with plan ID %s on branch %s\n", plan.Id, branch)
+
+	planId := plan.Id
+	currentOrgId := auth.OrgId
+
+	var settings *shared.PlanSettings
+	var modelContext []*db.Context
+	var mapDeps shared.FileMapDepsByPath
+	var convo []*db.ConvoMessage
+	var summaries []*db.ConvoSummary
+	var subtasks []*db.Subtask
+	var currentPlan *shared.CurrentPlanState
+	var allowGeneratedEdits bool
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    currentOrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   branch,
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+		Reason:   "preview tell",
+	}, func(repo *db.GitRepo) error {
+		var err error
+
+		settings, err = db.GetPlanSettings(plan, true)
+		if err != nil {
+			return fmt.Errorf("error getting plan settings: %v", err)
+		}
+
+		modelContext, err = db.GetPlanContexts(currentOrgId, planId, true, false)
+		if err != nil {
+			return fmt.Errorf("error getting plan contexts: %v", err)
+		}
+
+		err = db.RefocusBudgetedMaps(currentOrgId, planId, modelContext)
+		if err != nil {
+			return fmt.Errorf("error refocusing budgeted maps: %v", err)
+		}
+
+		if req.AutoContext {
+			mapDeps, err = db.GetPlanMapDeps(currentOrgId, planId, modelContext)
+			if err != nil {
+				return fmt.Errorf("error getting plan map deps: %v", err)
+			}
+		}
+
+		convo, err = db.GetPlanConvo(currentOrgId, planId)
+		if err != nil {
+			return fmt.Errorf("error getting plan convo: %v", err)
+		}
+
+		var convoMessageIds []string
+		for _, convoMessage := range convo {
+			convoMessageIds = append(convoMessageIds, convoMessage.Id)
+		}
+
+		summaries, err = db.GetPlanSummaries(planId, convoMessageIds)
+		if err != nil {
+			return fmt.Errorf("error getting plan summaries: %v", err)
+		}
+
+		subtasks, err = db.GetPlanSubtasks(currentOrgId, planId)
+		if err != nil {
+			return fmt.Errorf("error getting plan subtasks: %v", err)
+		}
+
+		allowGeneratedEdits, err = planAllowsGeneratedEdits(planId)
+		if err != nil {
+			return fmt.Errorf("error getting plan config: %v", err)
+		}
+
+		currentPlan, err = db.GetCurrentPlanState(db.CurrentPlanStateParams{
+			OrgId:    currentOrgId,
+			PlanId:   planId,
+			Contexts: modelContext,
+		})
+		if err != nil {
+			return fmt.Errorf("error getting current plan state: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the prompt isn't stored, so stand in for the message a tell would add to the conversation
+	var promptMsg *db.ConvoMessage
+	if !req.IsUserContinue {
+		promptMsg = &db.ConvoMessage{
+			Id:      previewPromptMessageId,
+			OrgId:   currentOrgId,
+			PlanId:  planId,
+			UserId:  auth.User.Id,
+			Role:    openai.ChatMessageRoleUser,
+			Tokens:  shared.GetNumTokensEstimate(req.Prompt),
+			Num:     len(convo) + 1,
+			Message: req.Prompt,
+			Flags: shared.ConvoMessageFlags{
+				IsApplyDebug: req.IsApplyDebug,
+				IsUserDebug:  req.IsUserDebug,
+				IsChat:       req.IsChatOnly,
+			},
+			CreatedAt: time.Now(),
+		}
+		convo = append(convo, promptMsg)
+	}
+
+	var latestSummaryTokens int
+	if len(summaries) > 0 {
+		latestSummaryTokens = shared.GetNumTokensEstimate(summaries[len(summaries)-1].Summary)
+	}
+
+	// errors go to this channel instead of a live plan stream
+	previewActive := &types.ActivePlan{
+		StreamDoneCh: make(chan *shared.ApiError, 1),
+		SkippedPaths: map[string]bool{},
+	}
+
+	state := &activeTellStreamState{
+		activePlan:          previewActive,
+		req:                 req,
+		auth:                auth,
+		currentOrgId:        currentOrgId,
+		currentUserId:       auth.User.Id,
+		plan:                plan,
+		branch:              branch,
+		modelContext:        modelContext,
+		mapDeps:             mapDeps,
+		convo:               convo,
+		promptConvoMessage:  promptMsg,
+		summaries:           summaries,
+		latestSummaryTokens: latestSummaryTokens,
+		settings:            settings,
+		currentPlanState:    currentPlan,
+		subtasks:            subtasks,
+		allowGeneratedEdits: allowGeneratedEdits,
+		isPreview:           true,
+	}
+	state.execTellPlanParams = execTellPlanParams{
+		plan:   plan,
+		branch: branch,
+		auth:   auth,
+		req:    req,
+	}
+	state.resolveLoadedFlags()
+
+	activatePaths, activatePathsOrdered := state.resolveCurrentStage()
+
+	res := &shared.TellPlanPreviewResponse{
+		Stage:          state.currentStage,
+		Contexts:       []*shared.TellPlanPreviewContext{},
+		MaxConvoTokens: settings.GetPlannerMaxConvoTokens(),
+	}
+
+	// fills in the per-context list with whatever was formatted before returning, including on errors
+	finish := func(errMsg string) *shared.TellPlanPreviewResponse {
+		res.Error = errMsg
+		res.Contexts = state.getPreviewContexts()
+		return res
+	}
+
+	streamErrMsg := func() string {
+		select {
+		case apiErr := <-previewActive.StreamDoneCh:
+			if apiErr != nil {
+				return apiErr.Msg
+			}
+		default:
+		}
+		return "Error previewing prompt"
+	}
+
+	tentativeModelConfig, tentativeMaxTokens, err := state.resolveTentativeModelConfig()
+	if err != nil {
+		return nil, err
+	}
+	state.tenativeModelConfig = &tentativeModelConfig
+
+	res.MaxTokens = tentativeMaxTokens
+	res.BaseModelName = tentativeModelConfig.BaseModelConfig.ModelName
+	setPreviewModel(res, tentativeModelConfig)
+
+	ok, tokensWithoutContext := state.dryRunCalculateTokensWithoutContext(tentativeMaxTokens, "")
+	if !ok {
+		return finish(streamErrMsg()), nil
+	}
+
+	sysPromptParams, apiErr := state.resolveTellSysPromptParams(tentativeMaxTokens, tokensWithoutContext, activatePaths, activatePathsOrdered)
+	if apiErr != nil {
+		return finish(apiErr.Msg), nil
+	}
+
+	sysParts, err := state.getTellSysPrompt(sysPromptParams)
+	if err != nil {
+		if err.Error() == AllTasksCompletedMsg {
+			return finish("There's no current task to implement. Try a prompt instead of the 'continue' command."), nil
+		}
+		return nil, fmt.Errorf("error getting tell sys prompt: %v", err)
+	}
+
+	contextMsg := types.ExtendedChatMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: []types.ExtendedChatMessagePart{},
+	}
+	for _, parts := range [][]*types.ExtendedChatMessagePart{sysPromptParams.planStageSharedMsgs, sysPromptParams.planningPhaseOnlyMsgs, sysPromptParams.implementationMsgs} {
+		for _, part := range parts {
+			contextMsg.Content = append(contextMsg.Content, *part)
+		}
+	}
+	if len(contextMsg.Content) > 0 {
+		res.ContextTokens = model.GetMessagesTokenEstimate(contextMsg) - (model.TokensPerMessage + model.TokensPerName)
+	}
+
+	state.messages = []types.ExtendedChatMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: sysParts,
+		},
+	}
+	res.SystemPromptTokens = model.GetMessagesTokenEstimate(state.messages...) - res.ContextTokens
+
+	promptMessage, ok := state.resolvePromptMessage("")
+	if !ok {
+		return finish(streamErrMsg()), nil
+	}
+	res.PromptTokens = model.GetMessagesTokenEstimate(*promptMessage)
+
+	state.tokensBeforeConvo =
+		model.GetMessagesTokenEstimate(state.messages...) +
+			model.GetMessagesTokenEstimate(*promptMessage) +
+			state.latestSummaryTokens +
+			model.TokensPerRequest
+
+	convoRes, apiErr := state.resolveConvoSummary()
+	for _, msg := range convoRes.convo {
+		if state.promptConvoMessage != nil && msg.Id == state.promptConvoMessage.Id {
+			continue
+		}
+		res.ConvoMessages++
+	}
+	res.ConvoTokens = convoRes.fullConversationTokens
+	if state.promptConvoMessage != nil {
+		res.ConvoTokens -= state.promptConvoMessage.Tokens + model.TokensPerMessage + model.TokensPerName
+	}
+	if convoRes.summary != nil {
+		res.WillSummarize = true
+		res.SummaryTokens = convoRes.summary.Tokens
+		res.ConvoTokensAfterSummary = convoRes.conversationTokens
+	} else {
+		res.SummaryTokens = state.latestSummaryTokens
+	}
+
+	if state.tokensBeforeConvo > settings.GetPlannerEffectiveMaxTokens() {
+		res.TotalTokens = state.tokensBeforeConvo
+		return finish("Token limit exceeded before adding conversation"), nil
+	}
+
+	if apiErr != nil {
+		res.TotalTokens = state.tokensBeforeConvo + convoRes.conversationTokens
+		return finish(apiErr.Msg), nil
+	}
+
+	if !state.addConversationMessages() {
+		return finish(streamErrMsg()), nil
+	}
+
+	// execTellPlan appends the prompt message a second time for a new prompt, so it's counted the same way here to pick the same model
+	state.messages = append(state.messages, *promptMessage, *promptMessage)
+
+	requestTokens := model.GetMessagesTokenEstimate(state.messages...) + model.TokensPerRequest
+	res.TotalTokens = requestTokens
+
+	if modelConfig, ok := state.getModelConfigForInputTokens(requestTokens); ok {
+		setPreviewModel(res, modelConfig)
+	}
+
+	return finish(""), nil
+}
+
+func setPreviewModel(res *shared.TellPlanPreviewResponse, modelConfig shared.ModelRoleConfig) {
+	res.ModelProvider = modelConfig.BaseModelConfig.Provider
+	res.ModelName = modelConfig.BaseModelConfig.ModelName
+	res.ModelMaxTokens = modelConfig.BaseModelConfig.MaxTokens
+	res.ReservedOutputTokens = modelConfig.GetReservedOutputTokens()
+	res.IsFallback = modelConfig.BaseModelConfig.ModelName != res.BaseModelName
+}
+
+func (state *activeTellStreamState) addPreviewContext(contextId, name, filePath string, contextType shared.ContextType, numTokens int, isPending bool, status shared.TellPlanPreviewContextStatus) {
+	for _, existing := range state.previewContexts {
+		if existing.ContextId == contextId && existing.FilePath == filePath && existing.Name == name {
+			// a part can be formatted more than once per request -- included wins
+			if status == shared.TellPlanPreviewContextIncluded {
+				existing.Status = status
+			}
+			return
+		}
+	}
+
+	state.previewContexts = append(state.previewContexts, &shared.TellPlanPreviewContext{
+		ContextId:   contextId,
+		Name:        name,
+		FilePath:    filePath,
+		ContextType: contextType,
+		NumTokens:   numTokens,
+		IsPending:   isPending,
+		Status:      status,
+	})
+}
+
+// getPreviewContexts lists every plan context in order, marking any that weren't formatted for the current stage as skipped, followed by pending files that aren't in context
+func (state *activeTellStreamState) getPreviewContexts() []*shared.TellPlanPreviewContext {
+	byId := map[string]*shared.TellPlanPreviewContext{}
+	for _, previewContext := range state.previewContexts {
+		if previewContext.ContextId != "" {
+			byId[previewContext.ContextId] = previewContext
+		}
+	}
+
+	res := []*shared.TellPlanPreviewContext{}
+	for _, context := range state.modelContext {
+		previewContext, ok := byId[context.Id]
+		if !ok {
+			previewContext = &shared.TellPlanPreviewContext{
+				ContextId:   context.Id,
+				Name:        context.Name,
+				FilePath:    context.FilePath,
+				ContextType: context.ContextType,
+				NumTokens:   context.NumTokens,
+				Status:      shared.TellPlanPreviewContextSkipped,
+			}
+		}
+		res = append(res, previewContext)
+	}
+
+	for _, previewContext := range state.previewContexts {
+		if previewContext.ContextId == "" {
+			res = append(res, previewContext)
+		}
+	}
+
+	return res
+}
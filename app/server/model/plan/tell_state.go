@@ -27,6 +27,7 @@ type activeTellStreamState struct {
 	iteration             int
 	replyId               string
 	modelContext          []*db.Context
+	mapDeps               shared.FileMapDepsByPath
 	hasContextMap         bool
 	contextMapEmpty       bool
 	convo                 []*db.ConvoMessage
@@ -58,6 +59,13 @@ type activeTellStreamState struct {
 	modelConfig         *shared.ModelRoleConfig
 
 	skipConvoMessages map[string]bool
+
+	// from the plan's stored config, not the request
+	allowGeneratedEdits bool
+
+	// set when previewing a tell -- context formatting records what's included or dropped instead of the request being sent
+	isPreview       bool
+	previewContexts []*shared.TellPlanPreviewContext
 }
 
 type chunkProcessor struct {
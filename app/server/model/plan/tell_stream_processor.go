@@ -474,6 +474,8 @@ func (state *activeTellStreamState) handleNewOperations(parserRes *types.ReplyPa
 				branch:        branch,
 				settings:      settings,
 				modelContext:  state.modelContext,
+
+				allowGeneratedEdits: state.allowGeneratedEdits,
 			}
 
 			var opContentTokens int
@@ -495,6 +497,10 @@ func (state *activeTellStreamState) handleNewOperations(parserRes *types.ReplyPa
 				IsMoveOp:          op.Type == shared.OperationTypeMove,
 				IsRemoveOp:        op.Type == shared.OperationTypeRemove,
 				IsResetOp:         op.Type == shared.OperationTypeReset,
+				IsRenameOp:        op.Type == shared.OperationTypeRename,
+				IsMoveSymbolOp:    op.Type == shared.OperationTypeMoveSymbol,
+				Symbol:            op.Symbol,
+				NewSymbol:         op.NewSymbol,
 			}})
 		}
 		processor.replyOperations = append(processor.replyOperations, op)
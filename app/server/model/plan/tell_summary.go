@@ -18,10 +18,22 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
-func (state *activeTellStreamState) addConversationMessages() bool {
+type convoSummaryResult struct {
+	convo []*db.ConvoMessage
+
+	// tokens for the full conversation, before any summary is applied
+	fullConversationTokens int
+
+	// tokens for the conversation that will be sent, with the summary applied if there is one
+	conversationTokens int
+
+	summary *db.ConvoSummary
+}
+
+// resolveConvoSummary works out how many conversation tokens will be sent and, if the conversation is over the limit, which stored summary will replace the earlier messages
+func (state *activeTellStreamState) resolveConvoSummary() (convoSummaryResult, *shared.ApiError) {
 	summaries := state.summaries
 	tokensBeforeConvo := state.tokensBeforeConvo
-	active := GetActivePlan(state.plan.Id, state.branch)
 
 	convo := []*db.ConvoMessage{}
 	for _, msg := range state.convo {
@@ -31,11 +43,6 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 		convo = append(convo, msg)
 	}
 
-	if active == nil {
-		log.Println("summarizeMessagesIfNeeded - Active plan not found")
-		return false
-	}
-
 	conversationTokens := 0
 	tokensUpToTimestamp := make(map[int64]int)
 	for _, convoMessage := range convo {
@@ -45,6 +52,11 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 		// log.Printf("Timestamp: %s | Tokens: %d | Total: %d | conversationTokens\n", convoMessage.Timestamp, convoMessage.Tokens, conversationTokens)
 	}
 
+	res := convoSummaryResult{
+		convo:                  convo,
+		fullConversationTokens: conversationTokens,
+	}
+
 	log.Printf("Conversation tokens: %d\n", conversationTokens)
 	log.Printf("Max conversation tokens: %d\n", state.settings.GetPlannerMaxConvoTokens())
 
@@ -86,12 +98,11 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 				log.Println("tokensUpToTimestamp:")
 				log.Println(spew.Sdump(tokensUpToTimestamp))
 
-				active.StreamDoneCh <- &shared.ApiError{
+				return res, &shared.ApiError{
 					Type:   shared.ApiErrorTypeOther,
 					Status: http.StatusInternalServerError,
 					Msg:    "Conversation summary timestamp not found in conversation",
 				}
-				return false
 			}
 
 			updatedConversationTokens := (conversationTokens - tokens) + s.Tokens
@@ -113,15 +124,45 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 		if summary == nil && tokensBeforeConvo+conversationTokens > state.settings.GetPlannerEffectiveMaxTokens() {
 			err := errors.New("couldn't get under token limit with conversation summary")
 			log.Printf("Error: %v\n", err)
-			active.StreamDoneCh <- &shared.ApiError{
+			return res, &shared.ApiError{
 				Type:   shared.ApiErrorTypeOther,
 				Status: http.StatusInternalServerError,
 				Msg:    "Exceeded token limit",
 			}
-			return false
 		}
 	}
 
+	res.conversationTokens = conversationTokens
+	res.summary = summary
+
+	if summary != nil && (tokensBeforeConvo+conversationTokens) > state.settings.GetPlannerEffectiveMaxTokens() {
+		return res, &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Token limit still exceeded after summarizing conversation",
+		}
+	}
+
+	return res, nil
+}
+
+func (state *activeTellStreamState) addConversationMessages() bool {
+	summaries := state.summaries
+	active := state.activePlan
+
+	if active == nil {
+		log.Println("summarizeMessagesIfNeeded - Active plan not found")
+		return false
+	}
+
+	convoSummaryRes, apiErr := state.resolveConvoSummary()
+	if apiErr != nil {
+		active.StreamDoneCh <- apiErr
+		return false
+	}
+	convo := convoSummaryRes.convo
+	summary := convoSummaryRes.summary
+
 	var latestSummary *db.ConvoSummary
 	if len(summaries) > 0 {
 		latestSummary = summaries[len(summaries)-1]
@@ -158,14 +199,6 @@ func (state *activeTellStreamState) addConversationMessages() bool {
 			}
 		}
 	} else {
-		if (tokensBeforeConvo + conversationTokens) > state.settings.GetPlannerEffectiveMaxTokens() {
-			active.StreamDoneCh <- &shared.ApiError{
-				Type:   shared.ApiErrorTypeOther,
-				Status: http.StatusInternalServerError,
-				Msg:    "Token limit still exceeded after summarizing conversation",
-			}
-			return false
-		}
 		state.summarizedToMessageId = summary.LatestConvoMessageId
 		state.messages = append(state.messages, types.ExtendedChatMessage{
 			Role: openai.ChatMessageRoleAssistant,
@@ -238,6 +238,9 @@ When choosing which files to load, you MUST:
 
 IMPORTANT NOTE ON CODEBASE MAPS:
 For many file types, codebase maps will include files in the project, along with important symbols and definitions from those files. For other file types, the file path will be listed with '[NO MAP]' below it. This does NOT mean the the file is empty, does not exist, is not important, or is not relevant. It simply means that we either can't or prefer not to show the map of that file. You can still use the file path to load the file and see its full content if appropriate. For files without a map, instead of making judgments about the file's relevance based on the symbols in the map, judge based on the file path and name.
+
+IMPORTANT NOTE ON THE DEPENDENCY GRAPH:
+If the user's prompt mentions files in the codebase map, a '### DEPENDENCY GRAPH ###' section may be included in context. It lists the mentioned files followed by files connected to them through imports and symbol references, nearest first, along with the symbols that connect them. Use it to find the dependencies a change will rely on and the dependents it could affect, even when their names don't look related. Files near the top of the graph are strong candidates for the '### Files' section, but only include the ones that are actually relevant to the task. The graph is built from file paths and symbol names, so it can miss some relationships and include some that aren't real.
 --
 
 When assessing relevant context, you MUST follow these rules:
@@ -16,12 +16,14 @@ type ValidationPromptParams struct {
 	Diff                 string
 	Reasons              []syntax.NeedsVerifyReason
 	SyntaxErrors         []string
+	SemanticErrors       []string
 }
 
 // GetValidationReplacementsXmlPrompt constructs the complete prompt string for XML responses.
 func GetValidationReplacementsXmlPrompt(params ValidationPromptParams) (string, int) {
 	reasons := params.Reasons
 	syntaxErrs := params.SyntaxErrors
+	semanticErrs := params.SemanticErrors
 	path := params.Path
 	originalWithLineNums := params.OriginalWithLineNums
 	desc := params.Desc
@@ -49,6 +51,9 @@ Diff of applied changes:
 		syntax.NeedsVerifyReasonAmbiguousLocation: "Changes were applied to an ambiguous location. This may indicate incorrect anchor spacing/indentation, wrong anchor ordering, or missing context.",
 		syntax.NeedsVerifyReasonCodeRemoved:       "Code was removed or replaced. Verify if this was intentional according to the plan.",
 		syntax.NeedsVerifyReasonCodeDuplicated:    "Code may have been duplicated. Verify if this was intentional according to the plan.",
+		syntax.NeedsVerifyReasonCommentsRemoved:   "Comments from the original file were removed without the plan asking for it, so they were restored above the code they document. Verify they still belong there, and only remove them if the plan intended it.",
+		syntax.NeedsVerifyReasonHeaderRemoved:     "The file's header comment (like a license or copyright notice) was removed without the plan asking for it, so it was restored at the top of the file. Keep it unless the plan intended to remove it.",
+		syntax.NeedsVerifyReasonSemanticErrors:    "A type checker run against the project found new errors in the resulting file. Verify whether the changes were applied correctly and whether they reference anything that doesn't exist.",
 	}
 
 	for _, reason := range reasons {
@@ -64,6 +69,13 @@ Diff of applied changes:
 		))
 	}
 
+	if len(semanticErrs) > 0 {
+		parts = append(parts, fmt.Sprintf(
+			"Checking the resulting file against the rest of the project reported these errors, which weren't present before the changes:\n%s\n\nInclude an assessment of what caused these errors. If they were caused by the changes, for example by a misspelled identifier, a wrong function signature, or a missing import, they MUST be fixed in your replacements, even if the fix goes slightly beyond the proposed changes. Some errors may refer to code in other files that the plan hasn't updated yet—leave those alone.",
+			strings.Join(semanticErrs, "\n"),
+		))
+	}
+
 	s += strings.Join(parts, "\n\n")
 
 	s += `
@@ -71,7 +83,7 @@ Diff of applied changes:
 
 Your first task is to examine whether the changes were applied as described in the proposed changes explanation. Do NOT evaluate:
 - Code quality
-- Missing imports
+- Missing imports (unless reported by a type checker above)
 - Unused variables
 - Best practices
 - Potential bugs
@@ -82,7 +94,7 @@ a. Whether the changes were applied at the correct location, *exactly* as specif
 b. Whether the changes included *all* the specified additions/modifications
 c. Whether *any* unintended changes were made to surrounding code
 d. Whether *any* specified code was accidentally removed or duplicated
-e. Any syntax errors that have been previously specified
+e. Any syntax or type checker errors that have been previously specified
 
 --
 
@@ -23,6 +23,8 @@ You can create special subtasks for file operations that move, remove, or reset
 
 In most cases, these special file operations are *not* used when initially implementing a plan, since in that case you are only creating files and updating them, and possibly writing to the _apply.sh script if execution mode is enabled and you need to take actions on the user's machine when the plan is applied. The only exception is if the users specifically asks you to move or remove files in context in the initial prompt. Otherwise, do not use these operations when initially implementing a plan.
 
+There is also a '### Refactor' operation that renames a symbol across files in context, or moves a top-level definition from one file to another. Refactor operations are executed exactly and mechanically, so prefer them over rewriting every usage by hand when a change is *only* a rename or a move. Unlike the other file operations, they are useful when initially implementing a plan as well as when revising one.
+
 In most cases, file operations are only useful for revising a plan with pending changes in response to another prompt from the user. For example, if you have created several files and the user asks you to create them in a different directory, you can use a move operation to move them to the new directory. Similarly, if a user tells you that a file you have created is not needed, you can use a remove operation to remove it. Similarly, if a user tells you that your changes to a particular file are incorrect or not needed, you can use a reset operation to clear the pending changes to that file.
 
 You MUST NOT implement any file operations in this section. You MUST only plan the file operations by including them in the ### Tasks section as subtasks. They will be implemented in subsequent responses.
@@ -89,6 +91,28 @@ Rules for the Reset Changes section:
 - Each path must be on its own line
 - You MUST end the '### Reset Changes' section with a <EndPlandexFileOps/> tag
 
+*Refactor Section:*
+
+Use the '### Refactor' section to rename a symbol or to move a top-level definition to another file. These operations are executed mechanically with a parser, so they are exact and don't require you to write out every changed line:
+
+### Refactor
+- rename ` + "`getUser` → `loadUser` in `db/users.go`, `api/handlers.go`" + `
+- move ` + "`formatDate` from `utils/helpers.ts` → `utils/dates.ts`" + `
+<EndPlandexFileOps/>
+
+Rules for the Refactor section:
+- Each line must start with a dash (-)
+- A rename line is: rename, the current name, →, the new name, 'in', and a comma-separated list of every file where the symbol should be renamed
+- A move line is: move, the name of the definition, 'from', the source file, →, the destination file
+- Names and paths must be wrapped in backticks (` + "`" + `)
+- Names and paths must be separated by → (Unicode arrow, NOT ->)
+- A rename changes *every* identifier with that exact name in the listed files, including unrelated identifiers that happen to share the name—only use it for names that are unambiguous in those files
+- A rename doesn't change strings or comments—update these with a normal code block if needed
+- A move can only move a single top-level definition (a function, class, type, etc.) along with the comments directly above it. The source and destination files must be in the same language. The destination file can be a file in context, a file with pending changes, or a new file
+- A move appends the definition to the end of the destination file and doesn't update imports—after a move, update imports and any references in other files with normal code blocks
+- All paths MUST match a path in context or that has pending changes (except for a move destination, which can be a new file)
+- You MUST end the '### Refactor' section with a <EndPlandexFileOps/> tag
+
 ## Important Notes
 
 1. These sections can only operate on files that are:
@@ -104,9 +128,9 @@ Rules for the Reset Changes section:
   - Move a file to a path that is *already* in context or pending (and would therefore overwrite the existing file)
 
 3. Format Rules:
-  - Section headers must be exactly as shown (### Move Files, ### Remove Files, ### Reset Changes)
+  - Section headers must be exactly as shown (### Move Files, ### Remove Files, ### Reset Changes, ### Refactor)
   - All file paths must be wrapped in backticks (` + "`" + `)
-  - Move operations must use the → arrow character (Unicode arrow, NOT ->)
+  - Move and refactor operations must use the → arrow character (Unicode arrow, NOT ->)
   - Each operation must be on its own line starting with a dash (-)
   - Empty lines between operations are allowed
   - No additional text or comments are allowed within these sections
@@ -125,17 +149,18 @@ Use special sections to perform file operations on files in context or with pend
 Key instructions for file operations:
 
 - ONLY use on files that are in context or have pending changes
-- Three available sections with exact formatting:
+- Four available sections with exact formatting:
     - '### Move Files' (using ` + "`source` → `dest`" + ` format)
     - '### Remove Files' (using backtick paths)
     - '### Reset Changes' (using backtick paths)
+    - '### Refactor' (using ` + "rename `old` → `new` in `path`, `path`" + ` or ` + "move `name` from `source` → `dest`" + ` format)
 - Every path MUST be wrapped in backticks (` + "`" + `)
 - Every line MUST start with a dash (-)
 - Can ONLY operate on individual files (not directories)
 - DO NOT UNDER ANY CIRCUMSTANCES:
     - Include comments or additional text in these sections
     - Use on files not in context or pending
-- These sections are for REVISING plans, not initial implementation
+- Apart from '### Refactor', these sections are for REVISING plans, not initial implementation
 - When making changes, choose between:
     - Iterating on current pending changes
     - Using '### Reset Changes' to start fresh on a file
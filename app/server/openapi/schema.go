@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Description          string             `json:"description,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// schemaRegistry builds json schemas from go types by reflection, following the same rules as encoding/json. Named struct types are added to the components section and referenced by name.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (sr *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	s := sr.schemaForNonPointer(t)
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+	return s
+}
+
+func (sr *schemaRegistry) schemaForNonPointer(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	// types with custom json encoding (like decimal.Decimal) are encoded as strings
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		if t.Kind() != reflect.Struct {
+			return sr.schemaForKind(t)
+		}
+		return &Schema{Type: "string"}
+	}
+
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		return sr.refFor(t)
+	}
+
+	return sr.schemaForKind(t)
+}
+
+func (sr *schemaRegistry) schemaForKind(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: sr.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: sr.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return sr.structSchema(t)
+	default:
+		// interfaces and anything else can hold any json value
+		return &Schema{}
+	}
+}
+
+func (sr *schemaRegistry) refFor(t reflect.Type) *Schema {
+	name, ok := sr.names[t]
+	if !ok {
+		name = sr.nameFor(t)
+		sr.names[t] = name
+		// placeholder so recursive types resolve to the same ref
+		sr.schemas[name] = &Schema{}
+		*sr.schemas[name] = *sr.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// types from the shared package keep their names since they make up most of the api -- anything else is prefixed with its package name to avoid collisions (e.g. db.Plan vs shared.Plan)
+func (sr *schemaRegistry) nameFor(t reflect.Type) string {
+	name := nonIdentChars.ReplaceAllString(t.Name(), "_")
+
+	pkg := t.PkgPath()
+	if pkg != "" && pkg != "plandex-shared" {
+		parts := strings.Split(pkg, "/")
+		pkgName := []rune(parts[len(parts)-1])
+		pkgName[0] = unicode.ToUpper(pkgName[0])
+		name = string(pkgName) + name
+	}
+
+	base := name
+	for i := 2; ; i++ {
+		if _, exists := sr.schemas[name]; !exists {
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+}
+
+func (sr *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// embedded structs without a json name have their fields promoted
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := sr.structSchema(ft)
+				for propName, prop := range embedded.Properties {
+					s.Properties[propName] = prop
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := sr.schemaFor(field.Type)
+		if strings.Contains(opts, "string") {
+			prop = &Schema{Type: "string"}
+		}
+		s.Properties[name] = prop
+
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
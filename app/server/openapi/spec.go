@@ -0,0 +1,267 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const OpenApiVersion = "3.0.3"
+
+// RouteDoc describes the request and response bodies of a route. Request and Response are zero values of the go types that are sent and received as json (e.g. shared.TellPlanRequest{}) -- leave them nil for routes without a json body. ResponseContentType overrides the response content type for routes that don't return json.
+type RouteDoc struct {
+	Summary             string
+	Tags                []string
+	Request             any
+	Response            any
+	ResponseContentType string
+	Streaming           bool
+}
+
+type Document struct {
+	OpenApi    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers,omitempty"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+	Security   []map[string][]string           `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	Url string `json:"url"`
+}
+
+type Operation struct {
+	OperationId string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type GenerateParams struct {
+	Router  *mux.Router
+	Prefix  string
+	Title   string
+	Version string
+	Docs    map[string]RouteDoc
+}
+
+var pathVarRegex = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+
+// DocKey is the key for a route in GenerateParams.Docs -- the method and path template without the version prefix, e.g. "POST /plans/{planId}/{branch}/tell"
+func DocKey(method, path string) string {
+	return method + " " + path
+}
+
+// Generate builds an OpenAPI document from the routes registered on the router under the given prefix. Every route is included -- docs add request and response schemas where they're known.
+func Generate(params GenerateParams) (*Document, error) {
+	doc := &Document{
+		OpenApi: OpenApiVersion,
+		Info: Info{
+			Title:   params.Title,
+			Version: params.Version,
+		},
+		Servers: []Server{{Url: params.Prefix}},
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "base64url-encoded json of shared.AuthHeader",
+				},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+	}
+
+	registry := newSchemaRegistry()
+
+	err := walkOperations(params.Router, params.Prefix, func(method, path string) {
+		routeDoc := params.Docs[DocKey(method, path)]
+
+		op := Operation{
+			OperationId: operationId(method, path),
+			Summary:     routeDoc.Summary,
+			Tags:        routeDoc.Tags,
+			Responses:   map[string]Response{},
+		}
+
+		for _, match := range pathVarRegex.FindAllStringSubmatch(path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+
+		if routeDoc.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/json": {Schema: registry.schemaFor(reflect.TypeOf(routeDoc.Request))},
+				},
+			}
+		}
+
+		okResponse := Response{Description: "OK"}
+		if routeDoc.Streaming {
+			contentType := routeDoc.ResponseContentType
+			if contentType == "" {
+				contentType = "text/plain"
+			}
+			okResponse.Description = "Stream of json-encoded shared.StreamMessage values"
+			okResponse.Content = map[string]MediaType{
+				contentType: {Schema: &Schema{Type: "string"}},
+			}
+		} else if routeDoc.Response != nil {
+			okResponse.Content = map[string]MediaType{
+				"application/json": {Schema: registry.schemaFor(reflect.TypeOf(routeDoc.Response))},
+			}
+		} else if routeDoc.ResponseContentType != "" {
+			okResponse.Content = map[string]MediaType{
+				routeDoc.ResponseContentType: {Schema: &Schema{Type: "string"}},
+			}
+		}
+		op.Responses[fmt.Sprintf("%d", http.StatusOK)] = okResponse
+
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content: map[string]MediaType{
+				"application/json": {Schema: registry.schemaFor(reflect.TypeOf(shared.ApiError{}))},
+				"text/plain":       {Schema: &Schema{Type: "string"}},
+			},
+		}
+
+		specPath := pathVarRegex.ReplaceAllString(path, "{$1}")
+		if _, ok := doc.Paths[specPath]; !ok {
+			doc.Paths[specPath] = map[string]Operation{}
+		}
+		doc.Paths[specPath][strings.ToLower(method)] = op
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error walking routes: %v", err)
+	}
+
+	doc.Components.Schemas = registry.schemas
+
+	return doc, nil
+}
+
+// Undocumented returns the doc keys of routes under the prefix that are missing from params.Docs, sorted
+func Undocumented(params GenerateParams) ([]string, error) {
+	var missing []string
+
+	err := walkOperations(params.Router, params.Prefix, func(method, path string) {
+		key := DocKey(method, path)
+		if _, ok := params.Docs[key]; !ok {
+			missing = append(missing, key)
+		}
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error walking routes: %v", err)
+	}
+
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// walkOperations calls fn with the method and unprefixed path template of each route under the prefix
+func walkOperations(router *mux.Router, prefix string, fn func(method, path string)) error {
+	return router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			// routes without a path (e.g. subrouters) aren't part of the api
+			return nil
+		}
+
+		if prefix != "" && !strings.HasPrefix(tpl, prefix+"/") {
+			return nil
+		}
+		path := strings.TrimPrefix(tpl, prefix)
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			fn(method, path)
+		}
+
+		return nil
+	})
+}
+
+// operationId builds a stable id from the method and path, e.g. "POST /plans/{planId}/{branch}/tell" -> "postPlansPlanIdBranchTell"
+func operationId(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+
+	words := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '_' || r == '-'
+	})
+
+	for _, word := range words {
+		if idx := strings.Index(word, ":"); idx >= 0 {
+			word = word[:idx]
+		}
+		if word == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return sb.String()
+}
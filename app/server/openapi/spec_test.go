@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ThingInner struct {
+	Name string `json:"name"`
+}
+
+type ThingRequest struct {
+	Id        string            `json:"id"`
+	Note      string            `json:"note,omitempty"`
+	Inner     *ThingInner       `json:"inner"`
+	Items     []ThingInner      `json:"items"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Hidden    string            `json:"-"`
+	NoTag     int
+}
+
+func TestGenerate(t *testing.T) {
+	r := mux.NewRouter()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r.HandleFunc("/v2/things/{thingId}", noop).Methods("POST")
+	r.HandleFunc("/v2/things", noop).Methods("GET")
+	r.HandleFunc("/things", noop).Methods("GET")
+
+	doc, err := Generate(GenerateParams{
+		Router: r,
+		Prefix: "/v2",
+		Docs: map[string]RouteDoc{
+			DocKey("POST", "/things/{thingId}"): {Request: ThingRequest{}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+
+	if len(doc.Paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(doc.Paths))
+	}
+
+	op, ok := doc.Paths["/things/{thingId}"]["post"]
+	if !ok {
+		t.Fatalf("missing POST /things/{thingId}")
+	}
+
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "thingId" {
+		t.Errorf("expected thingId path parameter, got %+v", op.Parameters)
+	}
+
+	if op.RequestBody == nil || op.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/OpenapiThingRequest" {
+		t.Fatalf("expected request body ref, got %+v", op.RequestBody)
+	}
+
+	schema := doc.Components.Schemas["OpenapiThingRequest"]
+	if schema == nil {
+		t.Fatalf("missing request schema")
+	}
+
+	for _, name := range []string{"id", "note", "inner", "items", "labels", "createdAt", "NoTag"} {
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("missing property %s", name)
+		}
+	}
+	if _, ok := schema.Properties["Hidden"]; ok {
+		t.Errorf("json:\"-\" field should be skipped")
+	}
+
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	if !required["id"] || required["note"] || required["inner"] {
+		t.Errorf("unexpected required fields: %v", schema.Required)
+	}
+
+	if schema.Properties["createdAt"].Format != "date-time" {
+		t.Errorf("expected date-time format for time.Time, got %+v", schema.Properties["createdAt"])
+	}
+
+	if schema.Properties["items"].Items.Ref != "#/components/schemas/OpenapiThingInner" {
+		t.Errorf("expected items to reference inner schema, got %+v", schema.Properties["items"].Items)
+	}
+}
+
+func TestUndocumented(t *testing.T) {
+	r := mux.NewRouter()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r.HandleFunc("/v2/things/{thingId}", noop).Methods("POST", "DELETE")
+	r.HandleFunc("/v2/things", noop).Methods("GET")
+	r.HandleFunc("/things", noop).Methods("GET")
+
+	missing, err := Undocumented(GenerateParams{
+		Router: r,
+		Prefix: "/v2",
+		Docs: map[string]RouteDoc{
+			DocKey("POST", "/things/{thingId}"): {Request: ThingRequest{}},
+			// routes only need an entry, even without schemas
+			DocKey("GET", "/things"): {},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Undocumented returned error: %v", err)
+	}
+
+	if len(missing) != 1 || missing[0] != "DELETE /things/{thingId}" {
+		t.Errorf("expected only DELETE /things/{thingId} to be undocumented, got %v", missing)
+	}
+}
@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/openapi"
+	"sync"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+// ApiVersionPrefix is the prefix for the versioned api described by the OpenAPI spec. Routes are also served without a prefix for existing CLI versions.
+const ApiVersionPrefix = "/v2"
+
+const ApiSpecVersion = "2.0.0"
+
+const (
+	tagAccounts = "accounts"
+	tagOrgs     = "orgs"
+	tagProjects = "projects"
+	tagPlans    = "plans"
+	tagContext  = "context"
+	tagExec     = "exec"
+	tagModels   = "models"
+	tagSettings = "settings"
+)
+
+// apiRouteDocs adds request and response schemas to the routes in addApiRoutes and addProxyableApiRoutes -- routes missing from here are still included in the spec, just without body schemas, but api_spec_test.go requires an entry for every route
+var apiRouteDocs = map[string]openapi.RouteDoc{
+	"POST /accounts/email_verifications":           {Tags: []string{tagAccounts}, Summary: "Send an email verification pin", Request: shared.CreateEmailVerificationRequest{}, Response: shared.CreateEmailVerificationResponse{}},
+	"POST /accounts/email_verifications/check_pin": {Tags: []string{tagAccounts}, Summary: "Check an email verification pin", Request: shared.VerifyEmailPinRequest{}},
+	"POST /accounts/sign_in_codes":                 {Tags: []string{tagAccounts}, Summary: "Create a sign in code for another device", ResponseContentType: "text/plain"},
+	"POST /accounts/sign_in":                       {Tags: []string{tagAccounts}, Summary: "Sign in", Request: shared.SignInRequest{}, Response: shared.SessionResponse{}},
+	"POST /accounts/sign_out":                      {Tags: []string{tagAccounts}, Summary: "Sign out"},
+	"POST /accounts":                               {Tags: []string{tagAccounts}, Summary: "Create an account", Request: shared.CreateAccountRequest{}, Response: shared.SessionResponse{}},
+	"GET /accounts/sso/providers":                  {Tags: []string{tagAccounts}, Summary: "List configured sso providers", Response: shared.ListSsoProvidersResponse{}},
+	"POST /accounts/sso/device/poll":               {Tags: []string{tagAccounts}, Summary: "Poll an sso device sign in", Request: shared.PollSsoDeviceRequest{}, Response: shared.PollSsoDeviceResponse{}},
+	"POST /accounts/sso/{providerId}/device":       {Tags: []string{tagAccounts}, Summary: "Start an sso device sign in", Response: shared.StartSsoDeviceResponse{}},
+	"GET /accounts/sso/{providerId}/login":         {Tags: []string{tagAccounts}, Summary: "Redirect a browser to the sso provider to sign in", ResponseContentType: "text/html"},
+	"GET /accounts/sso/{providerId}/callback":      {Tags: []string{tagAccounts}, Summary: "Complete a browser sso sign in", ResponseContentType: "text/html"},
+
+	"GET /orgs/session":           {Tags: []string{tagOrgs}, Summary: "Get the current org", Response: shared.Org{}},
+	"GET /orgs":                   {Tags: []string{tagOrgs}, Summary: "List orgs", Response: []*shared.Org{}},
+	"POST /orgs":                  {Tags: []string{tagOrgs}, Summary: "Create an org", Request: shared.CreateOrgRequest{}, Response: shared.CreateOrgResponse{}},
+	"GET /users":                  {Tags: []string{tagOrgs}, Summary: "List users in the current org", Response: shared.ListUsersResponse{}},
+	"DELETE /orgs/users/{userId}": {Tags: []string{tagOrgs}, Summary: "Remove a user from the current org"},
+	"GET /orgs/roles":             {Tags: []string{tagOrgs}, Summary: "List org roles", Response: []*shared.OrgRole{}},
+	"POST /orgs/roles":            {Tags: []string{tagOrgs}, Summary: "Create a custom org role", Request: shared.CreateOrgRoleRequest{}, Response: shared.OrgRole{}},
+	"PUT /orgs/roles/{roleId}":    {Tags: []string{tagOrgs}, Summary: "Update a custom org role's label, description, and permissions", Request: shared.UpdateOrgRoleRequest{}},
+	"DELETE /orgs/roles/{roleId}": {Tags: []string{tagOrgs}, Summary: "Delete a custom org role"},
+	"POST /invites":               {Tags: []string{tagOrgs}, Summary: "Invite a user", Request: shared.InviteRequest{}},
+	"GET /invites/pending":        {Tags: []string{tagOrgs}, Summary: "List pending invites", Response: []*shared.Invite{}},
+	"GET /invites/accepted":       {Tags: []string{tagOrgs}, Summary: "List accepted invites", Response: []*shared.Invite{}},
+	"GET /invites/all":            {Tags: []string{tagOrgs}, Summary: "List all invites", Response: []*shared.Invite{}},
+	"DELETE /invites/{inviteId}":  {Tags: []string{tagOrgs}, Summary: "Delete an invite"},
+
+	"POST /api_tokens":                            {Tags: []string{tagOrgs}, Summary: "Create an api token for the current user or a service account", Request: shared.CreateApiTokenRequest{}, Response: shared.CreateApiTokenResponse{}},
+	"GET /api_tokens":                             {Tags: []string{tagOrgs}, Summary: "List api tokens", Response: []*shared.ApiToken{}},
+	"DELETE /api_tokens/{tokenId}":                {Tags: []string{tagOrgs}, Summary: "Revoke an api token"},
+	"GET /service_accounts":                       {Tags: []string{tagOrgs}, Summary: "List service accounts", Response: []*shared.ServiceAccount{}},
+	"DELETE /service_accounts/{serviceAccountId}": {Tags: []string{tagOrgs}, Summary: "Remove a service account and revoke its tokens"},
+	"GET /audit_log":                              {Tags: []string{tagOrgs}, Summary: "List audit log entries, newest first (filters: action, actor, planId, targetType, targetId, since, until, beforeSeq, afterSeq, limit)", Response: shared.ListAuditLogResponse{}},
+	"GET /webhooks":                               {Tags: []string{tagOrgs}, Summary: "List webhooks", Response: []*shared.Webhook{}},
+	"POST /webhooks":                              {Tags: []string{tagOrgs}, Summary: "Create a webhook -- the response includes its signing secret, which can't be retrieved later", Request: shared.CreateWebhookRequest{}, Response: shared.CreateWebhookResponse{}},
+	"PUT /webhooks/{webhookId}":                   {Tags: []string{tagOrgs}, Summary: "Update a webhook", Request: shared.UpdateWebhookRequest{}},
+	"DELETE /webhooks/{webhookId}":                {Tags: []string{tagOrgs}, Summary: "Delete a webhook"},
+	"GET /webhooks/{webhookId}/deliveries":        {Tags: []string{tagOrgs}, Summary: "List a webhook's recent deliveries, newest first (filters: limit)", Response: []*shared.WebhookDelivery{}},
+	"POST /webhooks/{webhookId}/test":             {Tags: []string{tagOrgs}, Summary: "Send a test delivery to a webhook", Response: shared.WebhookDelivery{}},
+	"GET /plan_templates":                         {Tags: []string{tagOrgs}, Summary: "List the org's plan templates", Response: []*shared.PlanTemplate{}},
+	"POST /plan_templates":                        {Tags: []string{tagOrgs}, Summary: "Create a plan template", Request: shared.CreatePlanTemplateRequest{}, Response: shared.PlanTemplate{}},
+	"GET /plan_templates/{templateId}":            {Tags: []string{tagOrgs}, Summary: "Get a plan template", Response: shared.PlanTemplate{}},
+	"PUT /plan_templates/{templateId}":            {Tags: []string{tagOrgs}, Summary: "Update a plan template", Request: shared.UpdatePlanTemplateRequest{}},
+	"DELETE /plan_templates/{templateId}":         {Tags: []string{tagOrgs}, Summary: "Delete a plan template"},
+	"GET /builder_stats":                          {Tags: []string{tagOrgs}, Summary: "Get the org's builder strategy success rates and latency by language, model, and file size", Response: shared.GetBuilderStatsResponse{}},
+
+	"POST /projects":                                    {Tags: []string{tagProjects}, Summary: "Create a project", Request: shared.CreateProjectRequest{}, Response: shared.CreateProjectResponse{}},
+	"GET /projects":                                     {Tags: []string{tagProjects}, Summary: "List projects", Response: []shared.Project{}},
+	"PUT /projects/{projectId}/set_plan":                {Tags: []string{tagProjects}, Summary: "Set a project's current plan", Request: shared.SetProjectPlanRequest{}},
+	"PUT /projects/{projectId}/rename":                  {Tags: []string{tagProjects}, Summary: "Rename a project", Request: shared.RenameProjectRequest{}},
+	"POST /projects/{projectId}/plans/current_branches": {Tags: []string{tagProjects}, Summary: "Get current branches for plans", Request: shared.GetCurrentBranchByPlanIdRequest{}, Response: map[string]*shared.Branch{}},
+
+	"GET /plans":                               {Tags: []string{tagPlans}, Summary: "List plans", Response: []*shared.Plan{}},
+	"GET /plans/archive":                       {Tags: []string{tagPlans}, Summary: "List archived plans", Response: []*shared.Plan{}},
+	"GET /plans/ps":                            {Tags: []string{tagPlans}, Summary: "List running plans", Response: shared.ListPlansRunningResponse{}},
+	"GET /plans/shared":                        {Tags: []string{tagPlans}, Summary: "List plans shared with the current user", Response: shared.ListSharedPlansResponse{}},
+	"POST /projects/{projectId}/plans":         {Tags: []string{tagPlans}, Summary: "Create a plan", Request: shared.CreatePlanRequest{}, Response: shared.CreatePlanResponse{}},
+	"DELETE /projects/{projectId}/plans":       {Tags: []string{tagPlans}, Summary: "Delete the current user's plans in a project"},
+	"GET /plans/{planId}":                      {Tags: []string{tagPlans}, Summary: "Get a plan", Response: db.Plan{}},
+	"DELETE /plans/{planId}":                   {Tags: []string{tagPlans}, Summary: "Delete a plan"},
+	"PATCH /plans/{planId}/archive":            {Tags: []string{tagPlans}, Summary: "Archive a plan"},
+	"PATCH /plans/{planId}/unarchive":          {Tags: []string{tagPlans}, Summary: "Unarchive a plan"},
+	"POST /plans/{planId}/shares":              {Tags: []string{tagPlans}, Summary: "Share a plan with a user or the plan's project", Request: shared.SharePlanRequest{}, Response: shared.PlanShare{}},
+	"GET /plans/{planId}/shares":               {Tags: []string{tagPlans}, Summary: "List a plan's shares", Response: []*shared.PlanShare{}},
+	"DELETE /plans/{planId}/shares/{shareId}":  {Tags: []string{tagPlans}, Summary: "Remove a plan share"},
+	"PATCH /plans/{planId}/rename":             {Tags: []string{tagPlans}, Summary: "Rename a plan", Request: shared.RenamePlanRequest{}},
+	"GET /plans/{planId}/config":               {Tags: []string{tagPlans}, Summary: "Get a plan's config", Response: shared.GetPlanConfigResponse{}},
+	"PUT /plans/{planId}/config":               {Tags: []string{tagPlans}, Summary: "Update a plan's config", Request: shared.UpdatePlanConfigRequest{}},
+	"GET /plans/{planId}/branches":             {Tags: []string{tagPlans}, Summary: "List branches", Response: []*db.Branch{}},
+	"DELETE /plans/{planId}/branches/{branch}": {Tags: []string{tagPlans}, Summary: "Delete a branch"},
+
+	"GET /plans/{planId}/current_plan/{sha}":             {Tags: []string{tagPlans}, Summary: "Get the plan state at a commit", Response: shared.CurrentPlanState{}},
+	"GET /plans/{planId}/{branch}/current_plan":          {Tags: []string{tagPlans}, Summary: "Get the current plan state", Response: shared.CurrentPlanState{}},
+	"PATCH /plans/{planId}/{branch}/apply":               {Tags: []string{tagPlans}, Summary: "Apply pending changes", Request: shared.ApplyPlanRequest{}, ResponseContentType: "text/plain"},
+	"PATCH /plans/{planId}/{branch}/reject_all":          {Tags: []string{tagPlans}, Summary: "Reject all pending changes"},
+	"PATCH /plans/{planId}/{branch}/reject_file":         {Tags: []string{tagPlans}, Summary: "Reject pending changes to a file", Request: shared.RejectFileRequest{}},
+	"PATCH /plans/{planId}/{branch}/reject_files":        {Tags: []string{tagPlans}, Summary: "Reject pending changes to files", Request: shared.RejectFilesRequest{}},
+	"GET /plans/{planId}/{branch}/diffs":                 {Tags: []string{tagPlans}, Summary: "Get pending changes as a git diff", ResponseContentType: "text/plain"},
+	"GET /plans/{planId}/{branch}/convo":                 {Tags: []string{tagPlans}, Summary: "List conversation messages", Response: []*shared.ConvoMessage{}},
+	"PATCH /plans/{planId}/{branch}/rewind":              {Tags: []string{tagPlans}, Summary: "Rewind to a previous version", Request: shared.RewindPlanRequest{}, Response: shared.RewindPlanResponse{}},
+	"GET /plans/{planId}/{branch}/logs":                  {Tags: []string{tagPlans}, Summary: "List plan history", Response: shared.LogResponse{}},
+	"POST /plans/{planId}/{branch}/branches":             {Tags: []string{tagPlans}, Summary: "Create a branch", Request: shared.CreateBranchRequest{}},
+	"GET /plans/{planId}/{branch}/status":                {Tags: []string{tagPlans}, Summary: "Get the latest conversation summary", ResponseContentType: "text/plain"},
+	"GET /plans/{planId}/{branch}/settings":              {Tags: []string{tagSettings}, Summary: "Get plan settings", Response: shared.PlanSettings{}},
+	"PUT /plans/{planId}/{branch}/settings":              {Tags: []string{tagSettings}, Summary: "Update plan settings", Request: shared.UpdateSettingsRequest{}, Response: shared.UpdateSettingsResponse{}},
+	"POST /plans/{planId}/{branch}/load_cached_file_map": {Tags: []string{tagContext}, Summary: "Load cached file maps", Request: shared.LoadCachedFileMapRequest{}, Response: shared.LoadCachedFileMapResponse{}},
+
+	"GET /plans/{planId}/{branch}/context":                  {Tags: []string{tagContext}, Summary: "List context", Response: []*shared.Context{}},
+	"POST /plans/{planId}/{branch}/context":                 {Tags: []string{tagContext}, Summary: "Load context", Request: shared.LoadContextRequest{}, Response: shared.LoadContextResponse{}},
+	"PUT /plans/{planId}/{branch}/context":                  {Tags: []string{tagContext}, Summary: "Update context", Request: shared.UpdateContextRequest{}, Response: shared.UpdateContextResponse{}},
+	"DELETE /plans/{planId}/{branch}/context":               {Tags: []string{tagContext}, Summary: "Remove context", Request: shared.DeleteContextRequest{}, Response: shared.DeleteContextResponse{}},
+	"GET /plans/{planId}/{branch}/context/{contextId}/body": {Tags: []string{tagContext}, Summary: "Get a context item's body", Response: shared.GetContextBodyResponse{}},
+	"POST /file_map": {Tags: []string{tagContext}, Summary: "Build file maps", Request: shared.GetFileMapRequest{}, Response: shared.GetFileMapResponse{}},
+
+	"POST /plans/{planId}/{branch}/tell":                   {Tags: []string{tagExec}, Summary: "Send a prompt", Request: shared.TellPlanRequest{}, Streaming: true},
+	"POST /plans/{planId}/{branch}/tell/preview":           {Tags: []string{tagExec}, Summary: "Preview a prompt's token budget without sending it", Request: shared.TellPlanRequest{}, Response: shared.TellPlanPreviewResponse{}},
+	"PATCH /plans/{planId}/{branch}/build":                 {Tags: []string{tagExec}, Summary: "Build pending changes", Request: shared.BuildPlanRequest{}, Streaming: true},
+	"PATCH /plans/{planId}/{branch}/connect":               {Tags: []string{tagExec}, Summary: "Connect to an active plan's stream", Streaming: true},
+	"GET /plans/{planId}/{branch}/events":                  {Tags: []string{tagExec}, Summary: "Follow an active plan's stream as server-sent events", Streaming: true, ResponseContentType: "text/event-stream"},
+	"DELETE /plans/{planId}/{branch}/stop":                 {Tags: []string{tagExec}, Summary: "Stop an active plan"},
+	"POST /plans/{planId}/{branch}/respond_missing_file":   {Tags: []string{tagExec}, Summary: "Respond to a missing file prompt", Request: shared.RespondMissingFileRequest{}},
+	"POST /plans/{planId}/{branch}/auto_load_context":      {Tags: []string{tagExec}, Summary: "Load context requested by the model", Request: shared.LoadContextRequest{}, Response: shared.LoadContextResponse{}},
+	"POST /plans/{planId}/{branch}/respond_semantic_check": {Tags: []string{tagExec}, Summary: "Send the result of a built file's semantic check", Request: shared.RespondSemanticCheckRequest{}},
+	"GET /plans/{planId}/{branch}/build_status":            {Tags: []string{tagExec}, Summary: "Get build status", Response: shared.GetBuildStatusResponse{}},
+
+	"GET /custom_models":              {Tags: []string{tagModels}, Summary: "List custom models", Response: []*db.AvailableModel{}},
+	"POST /custom_models":             {Tags: []string{tagModels}, Summary: "Create a custom model", Request: shared.AvailableModel{}},
+	"DELETE /custom_models/{modelId}": {Tags: []string{tagModels}, Summary: "Delete a custom model"},
+	"GET /model_sets":                 {Tags: []string{tagModels}, Summary: "List model packs", Response: []*shared.ModelPack{}},
+	"POST /model_sets":                {Tags: []string{tagModels}, Summary: "Create a model pack", Request: shared.ModelPack{}},
+	"DELETE /model_sets/{setId}":      {Tags: []string{tagModels}, Summary: "Delete a model pack"},
+	"GET /default_settings":           {Tags: []string{tagSettings}, Summary: "Get org default plan settings", Response: shared.PlanSettings{}},
+	"PUT /default_settings":           {Tags: []string{tagSettings}, Summary: "Update org default plan settings", Request: shared.UpdateSettingsRequest{}, Response: shared.UpdateSettingsResponse{}},
+	"GET /default_plan_config":        {Tags: []string{tagSettings}, Summary: "Get default plan config", Response: shared.GetDefaultPlanConfigResponse{}},
+	"PUT /default_plan_config":        {Tags: []string{tagSettings}, Summary: "Update default plan config", Request: shared.UpdateDefaultPlanConfigRequest{}},
+
+	"GET /openapi.json": {Summary: "Get this OpenAPI spec", ResponseContentType: "application/json"},
+}
+
+// AddApiSpecRoutes serves the OpenAPI spec for the versioned api. The spec is generated from the router on first request, so this can be called before or after the api routes are added.
+func AddApiSpecRoutes(r *mux.Router) {
+	var once sync.Once
+	var specBytes []byte
+	var specErr error
+
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			var doc *openapi.Document
+			doc, specErr = openapi.Generate(openapi.GenerateParams{
+				Router:  r,
+				Prefix:  ApiVersionPrefix,
+				Title:   "Plandex API",
+				Version: ApiSpecVersion,
+				Docs:    apiRouteDocs,
+			})
+			if specErr != nil {
+				return
+			}
+			specBytes, specErr = json.MarshalIndent(doc, "", "  ")
+		})
+
+		if specErr != nil {
+			log.Printf("Error generating api spec: %v\n", specErr)
+			http.Error(w, "Error generating api spec", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(specBytes)
+	}
+
+	r.HandleFunc("/openapi.json", handler).Methods("GET")
+	r.HandleFunc(ApiVersionPrefix+"/openapi.json", handler).Methods("GET")
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"plandex-server/openapi"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestApiRouteDocsCoverRoutes(t *testing.T) {
+	r := mux.NewRouter()
+	AddApiRoutesWithPrefix(r, ApiVersionPrefix)
+	AddProxyableApiRoutesWithPrefix(r, ApiVersionPrefix)
+	AddApiSpecRoutes(r)
+
+	missing, err := openapi.Undocumented(openapi.GenerateParams{
+		Router: r,
+		Prefix: ApiVersionPrefix,
+		Docs:   apiRouteDocs,
+	})
+	if err != nil {
+		t.Fatalf("Undocumented returned error: %v", err)
+	}
+
+	for _, key := range missing {
+		t.Errorf("route %s is missing from apiRouteDocs", key)
+	}
+
+	// entries for routes that no longer exist
+	registered := map[string]bool{}
+	err = r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(tpl, ApiVersionPrefix+"/") {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			registered[openapi.DocKey(method, strings.TrimPrefix(tpl, ApiVersionPrefix))] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking routes: %v", err)
+	}
+	for key := range apiRouteDocs {
+		if !registered[key] {
+			t.Errorf("apiRouteDocs has %s, which isn't a registered route", key)
+		}
+	}
+}
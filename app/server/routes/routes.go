@@ -77,6 +77,12 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/accounts/sign_out", handlers.SignOutHandler).Methods("POST")
 	r.HandleFunc(prefix+"/accounts", handlers.CreateAccountHandler).Methods("POST")
 
+	r.HandleFunc(prefix+"/accounts/sso/providers", handlers.ListSsoProvidersHandler).Methods("GET")
+	r.HandleFunc(prefix+"/accounts/sso/device/poll", handlers.PollSsoDeviceHandler).Methods("POST")
+	r.HandleFunc(prefix+"/accounts/sso/{providerId}/device", handlers.StartSsoDeviceHandler).Methods("POST")
+	r.HandleFunc(prefix+"/accounts/sso/{providerId}/login", handlers.SsoLoginHandler).Methods("GET")
+	r.HandleFunc(prefix+"/accounts/sso/{providerId}/callback", handlers.SsoCallbackHandler).Methods("GET")
+
 	r.HandleFunc(prefix+"/orgs/session", handlers.GetOrgSessionHandler).Methods("GET")
 	r.HandleFunc(prefix+"/orgs", handlers.ListOrgsHandler).Methods("GET")
 	r.HandleFunc(prefix+"/orgs", handlers.CreateOrgHandler).Methods("POST")
@@ -84,6 +90,9 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/users", handlers.ListUsersHandler).Methods("GET")
 	r.HandleFunc(prefix+"/orgs/users/{userId}", handlers.DeleteOrgUserHandler).Methods("DELETE")
 	r.HandleFunc(prefix+"/orgs/roles", handlers.ListOrgRolesHandler).Methods("GET")
+	r.HandleFunc(prefix+"/orgs/roles", handlers.CreateOrgRoleHandler).Methods("POST")
+	r.HandleFunc(prefix+"/orgs/roles/{roleId}", handlers.UpdateOrgRoleHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/orgs/roles/{roleId}", handlers.DeleteOrgRoleHandler).Methods("DELETE")
 
 	r.HandleFunc(prefix+"/invites", handlers.InviteUserHandler).Methods("POST")
 	r.HandleFunc(prefix+"/invites/pending", handlers.ListPendingInvitesHandler).Methods("GET")
@@ -91,6 +100,29 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/invites/all", handlers.ListAllInvitesHandler).Methods("GET")
 	r.HandleFunc(prefix+"/invites/{inviteId}", handlers.DeleteInviteHandler).Methods("DELETE")
 
+	r.HandleFunc(prefix+"/api_tokens", handlers.CreateApiTokenHandler).Methods("POST")
+	r.HandleFunc(prefix+"/api_tokens", handlers.ListApiTokensHandler).Methods("GET")
+	r.HandleFunc(prefix+"/api_tokens/{tokenId}", handlers.RevokeApiTokenHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/service_accounts", handlers.ListServiceAccountsHandler).Methods("GET")
+	r.HandleFunc(prefix+"/service_accounts/{serviceAccountId}", handlers.DeleteServiceAccountHandler).Methods("DELETE")
+
+	r.HandleFunc(prefix+"/audit_log", handlers.ListAuditLogHandler).Methods("GET")
+
+	r.HandleFunc(prefix+"/webhooks", handlers.ListWebhooksHandler).Methods("GET")
+	r.HandleFunc(prefix+"/webhooks", handlers.CreateWebhookHandler).Methods("POST")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}", handlers.UpdateWebhookHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}", handlers.DeleteWebhookHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}/deliveries", handlers.ListWebhookDeliveriesHandler).Methods("GET")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}/test", handlers.TestWebhookHandler).Methods("POST")
+
+	r.HandleFunc(prefix+"/plan_templates", handlers.ListPlanTemplatesHandler).Methods("GET")
+	r.HandleFunc(prefix+"/plan_templates", handlers.CreatePlanTemplateHandler).Methods("POST")
+	r.HandleFunc(prefix+"/plan_templates/{templateId}", handlers.GetPlanTemplateHandler).Methods("GET")
+	r.HandleFunc(prefix+"/plan_templates/{templateId}", handlers.UpdatePlanTemplateHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/plan_templates/{templateId}", handlers.DeletePlanTemplateHandler).Methods("DELETE")
+
+	r.HandleFunc(prefix+"/builder_stats", handlers.GetBuilderStatsHandler).Methods("GET")
+
 	r.HandleFunc(prefix+"/projects", handlers.CreateProjectHandler).Methods("POST")
 	r.HandleFunc(prefix+"/projects", handlers.ListProjectsHandler).Methods("GET")
 	r.HandleFunc(prefix+"/projects/{projectId}/set_plan", handlers.ProjectSetPlanHandler).Methods("PUT")
@@ -101,10 +133,11 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans", handlers.ListPlansHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/archive", handlers.ListArchivedPlansHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/ps", handlers.ListPlansRunningHandler).Methods("GET")
+	r.HandleFunc(prefix+"/plans/shared", handlers.ListSharedPlansHandler).Methods("GET")
 
 	r.HandleFunc(prefix+"/projects/{projectId}/plans", handlers.CreatePlanHandler).Methods("POST")
 
-	r.HandleFunc(prefix+"/projects/{projectId}/plans", handlers.CreatePlanHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/projects/{projectId}/plans", handlers.DeleteAllPlansHandler).Methods("DELETE")
 
 	r.HandleFunc(prefix+"/plans/{planId}", handlers.GetPlanHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}", handlers.DeletePlanHandler).Methods("DELETE")
@@ -115,6 +148,10 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans/{planId}/archive", handlers.ArchivePlanHandler).Methods("PATCH")
 	r.HandleFunc(prefix+"/plans/{planId}/unarchive", handlers.UnarchivePlanHandler).Methods("PATCH")
 
+	r.HandleFunc(prefix+"/plans/{planId}/shares", handlers.SharePlanHandler).Methods("POST")
+	r.HandleFunc(prefix+"/plans/{planId}/shares", handlers.ListPlanSharesHandler).Methods("GET")
+	r.HandleFunc(prefix+"/plans/{planId}/shares/{shareId}", handlers.UnsharePlanHandler).Methods("DELETE")
+
 	r.HandleFunc(prefix+"/plans/{planId}/rename", handlers.RenamePlanHandler).Methods("PATCH")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/reject_all", handlers.RejectAllChangesHandler).Methods("PATCH")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/reject_file", handlers.RejectFileHandler).Methods("PATCH")
@@ -141,6 +178,7 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/status", handlers.GetPlanStatusHandler).Methods("GET")
 
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/tell", handlers.TellPlanHandler).Methods("POST")
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/tell/preview", handlers.TellPlanPreviewHandler).Methods("POST")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/build", handlers.BuildPlanHandler).Methods("PATCH")
 
 	r.HandleFunc(prefix+"/custom_models", handlers.ListCustomModelsHandler).Methods("GET")
@@ -166,11 +204,14 @@ func addApiRoutes(r *mux.Router, prefix string) {
 
 func addProxyableApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/connect", handlers.ConnectPlanHandler).Methods("PATCH")
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/events", handlers.PlanEventsHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/stop", handlers.StopPlanHandler).Methods("DELETE")
 
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/respond_missing_file", handlers.RespondMissingFileHandler).Methods("POST")
 
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/auto_load_context", handlers.AutoLoadContextHandler).Methods("POST")
 
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/respond_semantic_check", handlers.RespondSemanticCheckHandler).Methods("POST")
+
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/build_status", handlers.GetBuildStatusHandler).Methods("GET")
 }
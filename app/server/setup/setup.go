@@ -7,9 +7,13 @@ import (
 	"os"
 	"os/signal"
 	"plandex-server/db"
+	"plandex-server/email"
 	"plandex-server/host"
 	"plandex-server/model/plan"
 	"plandex-server/shutdown"
+	"plandex-server/sso"
+	"plandex-server/streambus"
+	"plandex-server/webhooks"
 	"syscall"
 	"time"
 )
@@ -38,6 +42,30 @@ func MustInitDb() {
 	}
 }
 
+func MustInitStreamBus(handler http.Handler) {
+	err := streambus.Init()
+	if err != nil {
+		log.Fatal("Error initializing stream bus: ", err)
+	}
+
+	streambus.SetHandler(handler)
+	RegisterShutdownHook(streambus.Close)
+}
+
+func MustInitSso() {
+	err := sso.Init()
+	if err != nil {
+		log.Fatal("Error initializing sso: ", err)
+	}
+}
+
+func MustInitEmail() {
+	err := email.Init(db.BaseDir)
+	if err != nil {
+		log.Fatal("Error initializing email: ", err)
+	}
+}
+
 var shutdownHooks []func()
 
 func RegisterShutdownHook(hook func()) {
@@ -67,6 +95,9 @@ func StartServer(handler http.Handler, configureFn func(handler http.Handler) ht
 	shutdown.ShutdownCtx, shutdown.ShutdownCancel = context.WithCancel(context.Background())
 	defer shutdown.ShutdownCancel()
 
+	// needs the shutdown context, so it's started here rather than from main
+	webhooks.StartWorker()
+
 	// Ensure database connection is closed
 	defer func() {
 		log.Println("Closing database connection...")
@@ -0,0 +1,200 @@
+package sso
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config is loaded from the json file at SSO_CONFIG_FILE, or from inline json in SSO_CONFIG
+type Config struct {
+	// disables email pin sign in and account creation so that sso is the only way to sign in
+	RequireSso bool `json:"requireSso"`
+
+	// public base url of this server (e.g. https://plandex.example.com) -- redirect flow callbacks are built from it
+	BaseUrl string `json:"baseUrl"`
+
+	// where browsers are sent after a redirect sign in if the request didn't include a redirect path
+	DefaultRedirectUrl string `json:"defaultRedirectUrl"`
+
+	Providers []*Provider `json:"providers"`
+}
+
+type Provider struct {
+	Id           string   `json:"id"`
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientId     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes"`
+
+	// claim names -- default to the standard email, name, and groups claims
+	EmailClaim  string `json:"emailClaim"`
+	NameClaim   string `json:"nameClaim"`
+	GroupsClaim string `json:"groupsClaim"`
+
+	// if set, only users with an email on one of these domains can sign in
+	AllowedDomains []string `json:"allowedDomains"`
+
+	// orgs that users from this provider are provisioned into
+	Orgs []*OrgMapping `json:"orgs"`
+
+	mu        sync.Mutex
+	discovery *discoveryDoc
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+type OrgMapping struct {
+	// the org to provision into, by id or name
+	OrgId string `json:"orgId"`
+	Org   string `json:"org"`
+
+	// create the org (owned by the first user provisioned into it) if no org with this name exists
+	CreateIfMissing bool `json:"createIfMissing"`
+
+	// only users in at least one of these groups are provisioned -- leave empty to provision every user from the provider
+	Groups []string `json:"groups"`
+
+	// checked in order, first match wins
+	Roles []*RoleMapping `json:"roles"`
+
+	// role for users that match no role mapping -- defaults to member
+	DefaultRole string `json:"defaultRole"`
+
+	// update the role of existing members on every sign in to match the mappings
+	SyncRole bool `json:"syncRole"`
+
+	// remove members that were provisioned by sso when they no longer match Groups
+	Deprovision bool `json:"deprovision"`
+}
+
+type RoleMapping struct {
+	// claim to match -- defaults to the provider's groups claim
+	Claim string `json:"claim"`
+	Value string `json:"value"`
+
+	// name or label of a default or custom org role
+	Role string `json:"role"`
+}
+
+var Active *Config
+
+func Enabled() bool {
+	return Active != nil && len(Active.Providers) > 0
+}
+
+func RequireSso() bool {
+	return Enabled() && Active.RequireSso
+}
+
+func GetProvider(id string) *Provider {
+	if Active == nil {
+		return nil
+	}
+
+	for _, p := range Active.Providers {
+		if p.Id == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func Init() error {
+	var bytes []byte
+	var err error
+
+	if path := os.Getenv("SSO_CONFIG_FILE"); path != "" {
+		bytes, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading sso config file: %v", err)
+		}
+	} else if inline := os.Getenv("SSO_CONFIG"); inline != "" {
+		bytes = []byte(inline)
+	} else {
+		log.Println("No sso config -- sign in is by email pin only")
+		return nil
+	}
+
+	var config Config
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return fmt.Errorf("error parsing sso config: %v", err)
+	}
+
+	err = config.validate()
+	if err != nil {
+		return fmt.Errorf("invalid sso config: %v", err)
+	}
+
+	Active = &config
+
+	log.Printf("Initialized sso with %d provider(s) | requireSso: %v\n", len(config.Providers), config.RequireSso)
+
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.RequireSso && len(c.Providers) == 0 {
+		return fmt.Errorf("requireSso is set but no providers are configured")
+	}
+
+	c.BaseUrl = strings.TrimSuffix(c.BaseUrl, "/")
+
+	ids := map[string]bool{}
+
+	for _, p := range c.Providers {
+		if p.Id == "" {
+			return fmt.Errorf("provider id is required")
+		}
+		if ids[p.Id] {
+			return fmt.Errorf("duplicate provider id: %s", p.Id)
+		}
+		ids[p.Id] = true
+
+		if p.Issuer == "" || p.ClientId == "" {
+			return fmt.Errorf("provider %s: issuer and clientId are required", p.Id)
+		}
+
+		if p.Name == "" {
+			p.Name = p.Id
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "email", "profile"}
+		}
+		if p.EmailClaim == "" {
+			p.EmailClaim = "email"
+		}
+		if p.NameClaim == "" {
+			p.NameClaim = "name"
+		}
+		if p.GroupsClaim == "" {
+			p.GroupsClaim = "groups"
+		}
+
+		for _, m := range p.Orgs {
+			if m.OrgId == "" && m.Org == "" {
+				return fmt.Errorf("provider %s: org mappings need an orgId or org name", p.Id)
+			}
+			if m.DefaultRole == "" {
+				m.DefaultRole = "member"
+			}
+			for _, rm := range m.Roles {
+				if rm.Claim == "" {
+					rm.Claim = p.GroupsClaim
+				}
+				if rm.Value == "" || rm.Role == "" {
+					return fmt.Errorf("provider %s: role mappings need a value and role", p.Id)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package sso
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// allowed clock skew when checking exp, iat, and nbf
+const clockSkew = 2 * time.Minute
+
+var errUnknownKey = errors.New("unknown signing key")
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJwks returns the signing keys in a jwks document by kid
+func parseJwks(bytes []byte) (map[string]interface{}, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	err := json.Unmarshal(bytes, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jwks: %v", err)
+	}
+
+	keys := map[string]interface{}{}
+
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding rsa modulus: %v", err)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding rsa exponent: %v", err)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding ec x: %v", err)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding ec y: %v", err)
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+
+	return keys, nil
+}
+
+// verifyJwt checks a jwt's signature against the given keys and returns its claims. Only asymmetric algorithms are accepted.
+func verifyJwt(raw string, keys map[string]interface{}) (map[string]interface{}, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed jwt")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding jwt header: %v", err)
+	}
+
+	var header jwtHeader
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jwt header: %v", err)
+	}
+
+	key, ok := keys[header.Kid]
+	if !ok {
+		// a single key without a kid is fine to use for tokens without one
+		if header.Kid == "" && len(keys) == 1 {
+			for _, k := range keys {
+				key = k
+			}
+		} else {
+			return nil, errUnknownKey
+		}
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding jwt signature: %v", err)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	err = verifySignature(header.Alg, key, signed, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding jwt payload: %v", err)
+	}
+
+	var claims map[string]interface{}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jwt claims: %v", err)
+	}
+
+	return claims, nil
+}
+
+func verifySignature(alg string, key interface{}, signed, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported jwt algorithm: %s", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s doesn't match rsa key", alg)
+		}
+		err := rsa.VerifyPKCS1v15(k, hash, digest, sig)
+		if err != nil {
+			return fmt.Errorf("invalid jwt signature")
+		}
+
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s doesn't match ec key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid jwt signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("invalid jwt signature")
+		}
+
+	default:
+		return fmt.Errorf("unsupported key type")
+	}
+
+	return nil
+}
+
+// validateIdTokenClaims checks the standard id token claims (https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation)
+func validateIdTokenClaims(claims map[string]interface{}, issuer, clientId, nonce string, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != issuer {
+		return fmt.Errorf("unexpected issuer: %s", iss)
+	}
+
+	audOk := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		audOk = aud == clientId
+	case []interface{}:
+		for _, a := range aud {
+			if s, _ := a.(string); s == clientId {
+				audOk = true
+				break
+			}
+		}
+		if len(aud) > 1 {
+			if azp, _ := claims["azp"].(string); azp != clientId {
+				audOk = false
+			}
+		}
+	}
+	if !audOk {
+		return fmt.Errorf("id token wasn't issued for this client")
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("id token has no expiration")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return fmt.Errorf("id token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("id token not valid yet")
+	}
+
+	if nonce != "" {
+		if n, _ := claims["nonce"].(string); n != nonce {
+			return fmt.Errorf("id token nonce mismatch")
+		}
+	}
+
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return fmt.Errorf("id token has no subject")
+	}
+
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
@@ -0,0 +1,31 @@
+package sso
+
+// Matches reports whether a user should be provisioned into the mapped org
+func (m *OrgMapping) Matches(identity *Identity) bool {
+	if len(m.Groups) == 0 {
+		return true
+	}
+
+	for _, g := range m.Groups {
+		for _, ug := range identity.Groups {
+			if g == ug {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// RoleFor returns the name or label of the org role a user maps to
+func (m *OrgMapping) RoleFor(identity *Identity) string {
+	for _, rm := range m.Roles {
+		for _, v := range claimStrings(identity.Claims, rm.Claim) {
+			if v == rm.Value {
+				return rm.Role
+			}
+		}
+	}
+
+	return m.DefaultRole
+}
@@ -0,0 +1,466 @@
+package sso
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// refetch keys at most this often when a token is signed with an unknown key (e.g. after the provider rotates keys)
+const keysRefreshInterval = 5 * time.Minute
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("slow down")
+	ErrAccessDenied         = errors.New("access denied")
+	ErrExpiredToken         = errors.New("device code expired")
+)
+
+type discoveryDoc struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	UserinfoEndpoint            string `json:"userinfo_endpoint"`
+	JwksUri                     string `json:"jwks_uri"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IdToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type DeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// Identity is a verified user from a provider
+type Identity struct {
+	ProviderId string
+	Subject    string
+	Email      string
+	// only true if the provider sent email_verified: true -- an identity without the claim can still sign in, but isn't linked to an existing account by email
+	EmailVerified bool
+	Name          string
+	Groups        []string
+	Claims        map[string]interface{}
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discoveryDoc, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discoveryUrl := strings.TrimSuffix(p.Issuer, "/") + "/.well-known/openid-configuration"
+
+	var doc discoveryDoc
+	err := getJson(ctx, discoveryUrl, "", &doc)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching openid configuration for %s: %v", p.Id, err)
+	}
+
+	if doc.Issuer != p.Issuer {
+		return nil, fmt.Errorf("openid configuration issuer %s doesn't match configured issuer %s", doc.Issuer, p.Issuer)
+	}
+
+	p.discovery = &doc
+
+	return p.discovery, nil
+}
+
+func (p *Provider) getKeys(ctx context.Context, refresh bool) (map[string]interface{}, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && (!refresh || time.Since(p.keysAt) < keysRefreshInterval) {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, doc.JwksUri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating jwks request: %v", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching jwks: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading jwks: %v", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("error fetching jwks: %s", resp.Status)
+	}
+
+	keys, err := parseJwks(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	return p.keys, nil
+}
+
+// AuthCodeUrl is where a browser is sent to start the redirect flow. The code challenge is the S256 PKCE challenge for codeVerifier.
+func (p *Provider) AuthCodeUrl(ctx context.Context, redirectUri, state, nonce, codeVerifier string) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientId)
+	params.Set("redirect_uri", redirectUri)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return doc.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+func (p *Provider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectUri string) (*TokenResponse, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+	params.Set("redirect_uri", redirectUri)
+	params.Set("code_verifier", codeVerifier)
+
+	tok, err := p.postToken(ctx, doc.TokenEndpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok.Error != "" {
+		return nil, fmt.Errorf("error exchanging code: %s %s", tok.Error, tok.ErrorDescription)
+	}
+
+	return tok, nil
+}
+
+func (p *Provider) StartDeviceAuth(ctx context.Context) (*DeviceAuthResponse, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("provider %s doesn't support the device authorization flow", p.Id)
+	}
+
+	params := url.Values{}
+	params.Set("client_id", p.ClientId)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	if p.ClientSecret != "" {
+		params.Set("client_secret", p.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.DeviceAuthorizationEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating device authorization request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending device authorization request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading device authorization response: %v", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("device authorization failed: %s %s", resp.Status, string(bytes))
+	}
+
+	var res DeviceAuthResponse
+	err = json.Unmarshal(bytes, &res)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing device authorization response: %v", err)
+	}
+
+	if res.Interval == 0 {
+		res.Interval = 5
+	}
+
+	return &res, nil
+}
+
+// PollDeviceToken checks whether the user has finished a device flow. It returns ErrAuthorizationPending or ErrSlowDown while waiting.
+func (p *Provider) PollDeviceToken(ctx context.Context, deviceCode string) (*TokenResponse, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	params.Set("device_code", deviceCode)
+
+	tok, err := p.postToken(ctx, doc.TokenEndpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok.Error {
+	case "":
+		return tok, nil
+	case "authorization_pending":
+		return nil, ErrAuthorizationPending
+	case "slow_down":
+		return nil, ErrSlowDown
+	case "access_denied":
+		return nil, ErrAccessDenied
+	case "expired_token":
+		return nil, ErrExpiredToken
+	default:
+		return nil, fmt.Errorf("error polling device token: %s %s", tok.Error, tok.ErrorDescription)
+	}
+}
+
+// Identify verifies the id token in a token response and returns the user it identifies. The nonce is checked for the redirect flow -- pass an empty nonce for the device flow.
+func (p *Provider) Identify(ctx context.Context, tok *TokenResponse, nonce string) (*Identity, error) {
+	if tok.IdToken == "" {
+		return nil, fmt.Errorf("token response has no id token -- make sure the openid scope is requested")
+	}
+
+	keys, err := p.getKeys(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := verifyJwt(tok.IdToken, keys)
+	if err == errUnknownKey {
+		keys, err = p.getKeys(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		claims, err = verifyJwt(tok.IdToken, keys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error verifying id token: %v", err)
+	}
+
+	err = validateIdTokenClaims(claims, p.Issuer, p.ClientId, nonce, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// some providers only include email or groups in userinfo
+	if claimString(claims, p.EmailClaim) == "" || claims[p.GroupsClaim] == nil {
+		err = p.mergeUserinfo(ctx, tok.AccessToken, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	identity := &Identity{
+		ProviderId: p.Id,
+		Subject:    claimString(claims, "sub"),
+		Email:      strings.ToLower(claimString(claims, p.EmailClaim)),
+		Name:       claimString(claims, p.NameClaim),
+		Groups:     claimStrings(claims, p.GroupsClaim),
+		Claims:     claims,
+	}
+
+	if identity.Email == "" {
+		return nil, fmt.Errorf("provider didn't return an email for the user")
+	}
+
+	verified, ok := claims["email_verified"].(bool)
+	if ok && !verified {
+		return nil, fmt.Errorf("email %s isn't verified with the provider", identity.Email)
+	}
+	identity.EmailVerified = verified
+
+	if len(p.AllowedDomains) > 0 {
+		domain := identity.Email[strings.LastIndex(identity.Email, "@")+1:]
+		allowed := false
+		for _, d := range p.AllowedDomains {
+			if strings.EqualFold(d, domain) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("email domain %s isn't allowed", domain)
+		}
+	}
+
+	if identity.Name == "" {
+		identity.Name = identity.Email[:strings.Index(identity.Email, "@")]
+	}
+
+	return identity, nil
+}
+
+func (p *Provider) mergeUserinfo(ctx context.Context, accessToken string, claims map[string]interface{}) error {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+
+	if doc.UserinfoEndpoint == "" || accessToken == "" {
+		return nil
+	}
+
+	var userinfo map[string]interface{}
+	err = getJson(ctx, doc.UserinfoEndpoint, accessToken, &userinfo)
+	if err != nil {
+		return fmt.Errorf("error fetching userinfo: %v", err)
+	}
+
+	// userinfo must be about the same user as the id token
+	if sub, _ := userinfo["sub"].(string); sub != claimString(claims, "sub") {
+		return fmt.Errorf("userinfo subject doesn't match id token")
+	}
+
+	for k, v := range userinfo {
+		if _, ok := claims[k]; !ok {
+			claims[k] = v
+		}
+	}
+
+	return nil
+}
+
+func (p *Provider) postToken(ctx context.Context, tokenEndpoint string, params url.Values) (*TokenResponse, error) {
+	// public clients identify themselves in the body, confidential clients with basic auth
+	if p.ClientSecret == "" {
+		params.Set("client_id", p.ClientId)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientId), url.QueryEscape(p.ClientSecret))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending token request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading token response: %v", err)
+	}
+
+	var tok TokenResponse
+	err = json.Unmarshal(bytes, &tok)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token response (%s): %v", resp.Status, err)
+	}
+
+	if resp.StatusCode >= 400 && tok.Error == "" {
+		return nil, fmt.Errorf("token request failed: %s", resp.Status)
+	}
+
+	return &tok, nil
+}
+
+func getJson(ctx context.Context, u, bearer string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("request to %s failed: %s", u, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// claimStrings reads a claim that may be a string or a list of strings
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var res []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
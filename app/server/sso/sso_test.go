@@ -0,0 +1,106 @@
+package sso
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func signTestJwt(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	payload, _ := json.Marshal(claims)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("error signing: %v", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+
+	keys, err := parseJwks(jwks)
+	if err != nil {
+		t.Fatalf("error parsing jwks: %v", err)
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":    "https://idp.example.com",
+		"aud":    "plandex",
+		"sub":    "user-1",
+		"exp":    float64(now.Add(time.Hour).Unix()),
+		"nonce":  "n1",
+		"groups": []string{"eng", "admins"},
+	}
+
+	raw := signTestJwt(t, key, "k1", claims)
+
+	verified, err := verifyJwt(raw, keys)
+	if err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	if err := validateIdTokenClaims(verified, "https://idp.example.com", "plandex", "n1", now); err != nil {
+		t.Errorf("expected valid claims, got %v", err)
+	}
+
+	if err := validateIdTokenClaims(verified, "https://idp.example.com", "plandex", "other", now); err == nil {
+		t.Errorf("expected nonce mismatch")
+	}
+
+	if err := validateIdTokenClaims(verified, "https://idp.example.com", "plandex", "n1", now.Add(2*time.Hour)); err == nil {
+		t.Errorf("expected expired token")
+	}
+
+	if _, err := verifyJwt(raw[:len(raw)-4]+"AAAA", keys); err == nil {
+		t.Errorf("expected tampered signature to fail")
+	}
+
+	if _, err := verifyJwt(signTestJwt(t, key, "k2", claims), keys); err != errUnknownKey {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+
+	identity := &Identity{Groups: claimStrings(verified, "groups"), Claims: verified}
+
+	mapping := &OrgMapping{
+		Groups:      []string{"eng"},
+		DefaultRole: "member",
+		Roles: []*RoleMapping{
+			{Claim: "groups", Value: "owners", Role: "owner"},
+			{Claim: "groups", Value: "admins", Role: "admin"},
+		},
+	}
+
+	if !mapping.Matches(identity) {
+		t.Errorf("expected mapping to match")
+	}
+
+	if role := mapping.RoleFor(identity); role != "admin" {
+		t.Errorf("expected admin role, got %s", role)
+	}
+}
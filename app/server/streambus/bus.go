@@ -0,0 +1,74 @@
+package streambus
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Bus is a pub/sub transport that lets any server instance talk to the instance that owns an active plan. When a bus is configured, requests for an active plan that lands on the wrong instance are forwarded over the bus instead of being proxied to the owner's internal ip.
+type Bus interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	Close() error
+}
+
+const (
+	BusTypeNone     = ""
+	BusTypePostgres = "postgres"
+)
+
+var Active Bus
+
+func Enabled() bool {
+	return Active != nil
+}
+
+func Init() error {
+	busType := os.Getenv("STREAM_BUS")
+
+	switch busType {
+	case BusTypeNone:
+		log.Println("No stream bus configured -- active plan requests will be proxied by internal ip")
+		return nil
+	case BusTypePostgres:
+		bus, err := NewPostgresBus()
+		if err != nil {
+			return fmt.Errorf("error initializing postgres stream bus: %v", err)
+		}
+		Active = bus
+		log.Println("Initialized postgres stream bus")
+		return nil
+	default:
+		return fmt.Errorf("unknown STREAM_BUS type: %s", busType)
+	}
+}
+
+func Close() {
+	if Active == nil {
+		return
+	}
+
+	err := Active.Close()
+	if err != nil {
+		log.Printf("Error closing stream bus: %v\n", err)
+	}
+}
+
+// channel names need to be valid postgres identifiers (max 63 bytes), so plan ids and branch names are hashed
+func planChannel(prefix, planId, branch string) string {
+	h := sha1.Sum([]byte(planId + "|" + branch))
+	return prefix + hex.EncodeToString(h[:])[:32]
+}
+
+func requestChannel(planId, branch string) string {
+	return planChannel("px_req_", planId, branch)
+}
+
+func responseChannel(requestId string) string {
+	h := sha1.Sum([]byte(requestId))
+	return "px_res_" + hex.EncodeToString(h[:])[:32]
+}
@@ -0,0 +1,340 @@
+package streambus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// postgres limits NOTIFY payloads to 8000 bytes -- larger messages are split into chunks that are sent in a single transaction so they're delivered together and in order
+const maxChunkSize = 5000
+
+// a subscriber whose buffer fills up is disconnected rather than holding up the others -- its channel is closed, and callers can resubscribe or resume
+const subscriberBufferSize = 100
+const pendingChunkTimeout = 1 * time.Minute
+const listenerPingInterval = 90 * time.Second
+
+type envelope struct {
+	Id    string `json:"id"`
+	Index int    `json:"i"`
+	Total int    `json:"n"`
+	Data  []byte `json:"d"`
+}
+
+type pendingMessage struct {
+	parts     [][]byte
+	received  int
+	createdAt time.Time
+}
+
+type postgresSubscriber struct {
+	ch chan []byte
+	// guarded by the bus's mu, which is held whenever ch is sent on or closed
+	closed bool
+}
+
+type PostgresBus struct {
+	listener *pq.Listener
+
+	// mu guards subscribers and pending, and is never held while calling into the listener -- the listener's goroutine can block until notifications are dispatched, and dispatching takes mu
+	mu          sync.Mutex
+	subscribers map[string]map[string]*postgresSubscriber
+	pending     map[string]*pendingMessage
+
+	// listenMu serializes Listen and Unlisten calls and guards the number of subscriptions on each channel
+	listenMu   sync.Mutex
+	listenRefs map[string]int
+
+	cancelFn context.CancelFunc
+}
+
+func NewPostgresBus() (*PostgresBus, error) {
+	if db.Conn == nil || db.ConnUrl == "" {
+		return nil, errors.New("db not initialized")
+	}
+
+	listener := pq.NewListener(db.ConnUrl, 1*time.Second, 30*time.Second, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Stream bus listener event %d error: %v\n", ev, err)
+		}
+		if ev == pq.ListenerEventReconnected {
+			log.Println("Stream bus listener reconnected -- notifications sent while disconnected were lost")
+		}
+	})
+
+	err := listener.Ping()
+	if err != nil {
+		return nil, fmt.Errorf("error pinging stream bus listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bus := &PostgresBus{
+		listener:    listener,
+		subscribers: map[string]map[string]*postgresSubscriber{},
+		pending:     map[string]*pendingMessage{},
+		listenRefs:  map[string]int{},
+		cancelFn:    cancel,
+	}
+
+	go bus.dispatch(ctx)
+
+	return bus, nil
+}
+
+func (b *PostgresBus) Publish(ctx context.Context, channel string, payload []byte) error {
+	id := uuid.New().String()
+
+	total := (len(payload) + maxChunkSize - 1) / maxChunkSize
+	if total == 0 {
+		total = 1
+	}
+
+	tx, err := db.Conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting stream bus transaction: %v", err)
+	}
+
+	// Ensure that rollback is attempted in case of failure
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr.Error() != "sql: transaction has already been committed or rolled back" {
+			log.Printf("Error rolling back stream bus transaction: %v\n", rbErr)
+		}
+	}()
+
+	for i := 0; i < total; i++ {
+		start := i * maxChunkSize
+		end := start + maxChunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		bytes, err := json.Marshal(envelope{
+			Id:    id,
+			Index: i,
+			Total: total,
+			Data:  payload[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("error marshalling stream bus envelope: %v", err)
+		}
+
+		_, err = tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, string(bytes))
+		if err != nil {
+			return fmt.Errorf("error sending stream bus notification: %v", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing stream bus transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (b *PostgresBus) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	err := b.listen(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	sub := &postgresSubscriber{
+		ch: make(chan []byte, subscriberBufferSize),
+	}
+
+	b.mu.Lock()
+	subs, ok := b.subscribers[channel]
+	if !ok {
+		subs = map[string]*postgresSubscriber{}
+		b.subscribers[channel] = subs
+	}
+	subs[id] = sub
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		subs := b.subscribers[channel]
+		delete(subs, id)
+		if len(subs) == 0 {
+			delete(b.subscribers, channel)
+		}
+		b.closeSubscriber(sub)
+		b.mu.Unlock()
+
+		b.unlisten(channel)
+	}()
+
+	return sub.ch, nil
+}
+
+// listen starts listening on a channel for its first subscriber
+func (b *PostgresBus) listen(channel string) error {
+	b.listenMu.Lock()
+	defer b.listenMu.Unlock()
+
+	if b.listenRefs[channel] == 0 {
+		err := b.listener.Listen(channel)
+		if err != nil && err != pq.ErrChannelAlreadyOpen {
+			return fmt.Errorf("error listening on stream bus channel %s: %v", channel, err)
+		}
+	}
+	b.listenRefs[channel]++
+
+	return nil
+}
+
+// unlisten stops listening on a channel once its last subscriber is gone
+func (b *PostgresBus) unlisten(channel string) {
+	b.listenMu.Lock()
+	defer b.listenMu.Unlock()
+
+	b.listenRefs[channel]--
+	if b.listenRefs[channel] > 0 {
+		return
+	}
+	delete(b.listenRefs, channel)
+
+	err := b.listener.Unlisten(channel)
+	if err != nil && err != pq.ErrChannelNotOpen {
+		log.Printf("Error unlistening from stream bus channel %s: %v\n", channel, err)
+	}
+}
+
+// closeSubscriber closes a subscriber's channel once -- b.mu must be held
+func (b *PostgresBus) closeSubscriber(sub *postgresSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+}
+
+func (b *PostgresBus) Close() error {
+	b.cancelFn()
+	return b.listener.Close()
+}
+
+func (b *PostgresBus) dispatch(ctx context.Context) {
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			go func() {
+				err := b.listener.Ping()
+				if err != nil {
+					log.Printf("Error pinging stream bus listener: %v\n", err)
+				}
+			}()
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			// nil notification is sent after a reconnect
+			if n == nil {
+				continue
+			}
+			b.handleNotification(n)
+		}
+	}
+}
+
+func (b *PostgresBus) handleNotification(n *pq.Notification) {
+	var env envelope
+	err := json.Unmarshal([]byte(n.Extra), &env)
+	if err != nil {
+		log.Printf("Error unmarshalling stream bus envelope: %v\n", err)
+		return
+	}
+
+	payload := b.assemble(&env)
+	if payload == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, sub := range b.subscribers[n.Channel] {
+		b.send(n.Channel, id, sub, payload)
+	}
+}
+
+// send never blocks, so one slow subscriber can't hold up the listener -- b.mu must be held
+func (b *PostgresBus) send(channel, id string, sub *postgresSubscriber, payload []byte) {
+	if sub.closed {
+		return
+	}
+
+	select {
+	case sub.ch <- payload:
+	default:
+		log.Printf("Stream bus subscriber on %s is not keeping up -- disconnecting it\n", channel)
+		delete(b.subscribers[channel], id)
+		b.closeSubscriber(sub)
+	}
+}
+
+// returns the full payload once all chunks of a message have been received, otherwise nil
+func (b *PostgresBus) assemble(env *envelope) []byte {
+	if env.Total <= 1 {
+		if env.Data == nil {
+			return []byte{}
+		}
+		return env.Data
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for id, p := range b.pending {
+		if now.Sub(p.createdAt) > pendingChunkTimeout {
+			log.Printf("Stream bus message %s timed out waiting for chunks\n", id)
+			delete(b.pending, id)
+		}
+	}
+
+	p, ok := b.pending[env.Id]
+	if !ok {
+		p = &pendingMessage{
+			parts:     make([][]byte, env.Total),
+			createdAt: now,
+		}
+		b.pending[env.Id] = p
+	}
+
+	if env.Index < 0 || env.Index >= len(p.parts) || p.parts[env.Index] != nil {
+		return nil
+	}
+
+	p.parts[env.Index] = env.Data
+	p.received++
+
+	if p.received < len(p.parts) {
+		return nil
+	}
+
+	delete(b.pending, env.Id)
+
+	var payload []byte
+	for _, part := range p.parts {
+		payload = append(payload, part...)
+	}
+	return payload
+}
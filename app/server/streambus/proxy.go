@@ -0,0 +1,324 @@
+package streambus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// how long to wait for the instance that owns an active plan to start responding before giving up
+const ownerResponseTimeout = 10 * time.Second
+
+const (
+	frameTypeHeader = "header"
+	frameTypeBody   = "body"
+	frameTypeEnd    = "end"
+)
+
+type busRequest struct {
+	Id     string      `json:"id"`
+	Cancel bool        `json:"cancel,omitempty"`
+	Method string      `json:"method,omitempty"`
+	Path   string      `json:"path,omitempty"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+type busResponseFrame struct {
+	Type   string      `json:"type"`
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+var handler http.Handler
+
+// SetHandler sets the router that requests forwarded over the bus are dispatched to on the instance that owns the active plan
+func SetHandler(h http.Handler) {
+	handler = h
+}
+
+// Forward sends a request for an active plan to whichever instance owns it and streams the response back to w. It returns false if no instance responded, meaning the plan is no longer active anywhere.
+func Forward(w http.ResponseWriter, r *http.Request, planId, branch string, body []byte) (bool, error) {
+	if Active == nil {
+		return false, fmt.Errorf("stream bus not initialized")
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	req := busRequest{
+		Id:     uuid.New().String(),
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header,
+		Body:   body,
+	}
+
+	resCh, err := Active.Subscribe(ctx, responseChannel(req.Id))
+	if err != nil {
+		return false, fmt.Errorf("error subscribing to response channel: %v", err)
+	}
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return false, fmt.Errorf("error marshalling forwarded request: %v", err)
+	}
+
+	err = Active.Publish(ctx, requestChannel(planId, branch), reqBytes)
+	if err != nil {
+		return false, fmt.Errorf("error publishing forwarded request: %v", err)
+	}
+
+	timer := time.NewTimer(ownerResponseTimeout)
+	defer timer.Stop()
+
+	wroteHeader := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stream bus: client disconnected from forwarded request %s\n", req.Id)
+			sendCancel(planId, branch, req.Id)
+			return true, nil
+
+		case <-timer.C:
+			if !wroteHeader {
+				log.Printf("Stream bus: no response from owner of plan %s after %s\n", planId, ownerResponseTimeout)
+				sendCancel(planId, branch, req.Id)
+				return false, nil
+			}
+
+		case msg, ok := <-resCh:
+			if !ok {
+				return wroteHeader, nil
+			}
+
+			var frame busResponseFrame
+			err := json.Unmarshal(msg, &frame)
+			if err != nil {
+				return wroteHeader, fmt.Errorf("error unmarshalling response frame: %v", err)
+			}
+
+			switch frame.Type {
+			case frameTypeHeader:
+				for name, headers := range frame.Header {
+					for _, h := range headers {
+						w.Header().Add(name, h)
+					}
+				}
+				w.WriteHeader(frame.Status)
+				wroteHeader = true
+
+			case frameTypeBody:
+				_, err := w.Write(frame.Body)
+				if err != nil {
+					sendCancel(planId, branch, req.Id)
+					return true, fmt.Errorf("error writing forwarded response: %v", err)
+				}
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+
+			case frameTypeEnd:
+				return true, nil
+			}
+		}
+	}
+}
+
+func sendCancel(planId, branch, requestId string) {
+	bytes, err := json.Marshal(busRequest{Id: requestId, Cancel: true})
+	if err != nil {
+		log.Printf("Stream bus: error marshalling cancel request: %v\n", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = Active.Publish(ctx, requestChannel(planId, branch), bytes)
+	if err != nil {
+		log.Printf("Stream bus: error publishing cancel request: %v\n", err)
+	}
+}
+
+// ServePlan listens for requests forwarded from other instances for an active plan owned by this instance until ctx is done
+func ServePlan(ctx context.Context, planId, branch string) {
+	if Active == nil {
+		return
+	}
+
+	if handler == nil {
+		log.Println("Stream bus: no handler set -- can't serve forwarded requests")
+		return
+	}
+
+	log.Printf("Stream bus: serving forwarded requests for plan %s on branch %s\n", planId, branch)
+
+	var mu sync.Mutex
+	cancelFns := map[string]context.CancelFunc{}
+
+	// the bus disconnects subscribers that fall behind, so subscribe again until the plan is done
+	for ctx.Err() == nil {
+		reqCh, err := Active.Subscribe(ctx, requestChannel(planId, branch))
+		if err != nil {
+			log.Printf("Stream bus: error subscribing to requests for plan %s: %v\n", planId, err)
+			return
+		}
+
+		serveRequests(ctx, reqCh, &mu, cancelFns)
+	}
+
+	log.Printf("Stream bus: stopped serving forwarded requests for plan %s on branch %s\n", planId, branch)
+}
+
+func serveRequests(ctx context.Context, reqCh <-chan []byte, mu *sync.Mutex, cancelFns map[string]context.CancelFunc) {
+	for msg := range reqCh {
+		var req busRequest
+		err := json.Unmarshal(msg, &req)
+		if err != nil {
+			log.Printf("Stream bus: error unmarshalling forwarded request: %v\n", err)
+			continue
+		}
+
+		if req.Cancel {
+			mu.Lock()
+			if cancelFn, ok := cancelFns[req.Id]; ok {
+				cancelFn()
+			}
+			mu.Unlock()
+			continue
+		}
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		mu.Lock()
+		cancelFns[req.Id] = cancel
+		mu.Unlock()
+
+		go func(req busRequest) {
+			defer func() {
+				mu.Lock()
+				delete(cancelFns, req.Id)
+				mu.Unlock()
+				cancel()
+			}()
+			serveRequest(reqCtx, req)
+		}(req)
+	}
+}
+
+func serveRequest(ctx context.Context, req busRequest) {
+	log.Printf("Stream bus: serving forwarded request %s %s\n", req.Method, req.Path)
+
+	url := req.Path + "?proxy=true"
+	if req.Query != "" {
+		url += "&" + req.Query
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, bytes.NewReader(req.Body))
+	if err != nil {
+		log.Printf("Stream bus: error creating forwarded request: %v\n", err)
+		return
+	}
+	httpReq.Header = req.Header
+
+	w := newBusResponseWriter(ctx, responseChannel(req.Id))
+	handler.ServeHTTP(w, httpReq)
+	w.end()
+}
+
+// busResponseWriter publishes whatever a handler writes to the response channel for a forwarded request
+type busResponseWriter struct {
+	ctx         context.Context
+	channel     string
+	header      http.Header
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	err         error
+}
+
+func newBusResponseWriter(ctx context.Context, channel string) *busResponseWriter {
+	return &busResponseWriter{
+		ctx:     ctx,
+		channel: channel,
+		header:  http.Header{},
+		status:  http.StatusOK,
+	}
+}
+
+func (bw *busResponseWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *busResponseWriter) WriteHeader(status int) {
+	if bw.wroteHeader {
+		return
+	}
+	bw.status = status
+	bw.publishHeader()
+}
+
+func (bw *busResponseWriter) Write(b []byte) (int, error) {
+	if bw.err != nil {
+		return 0, bw.err
+	}
+	bw.publishHeader()
+	return bw.buf.Write(b)
+}
+
+func (bw *busResponseWriter) Flush() {
+	bw.publishHeader()
+
+	if bw.buf.Len() == 0 || bw.err != nil {
+		return
+	}
+
+	body := make([]byte, bw.buf.Len())
+	copy(body, bw.buf.Bytes())
+	bw.buf.Reset()
+
+	bw.publish(busResponseFrame{Type: frameTypeBody, Body: body})
+}
+
+func (bw *busResponseWriter) end() {
+	bw.Flush()
+	bw.publish(busResponseFrame{Type: frameTypeEnd})
+}
+
+func (bw *busResponseWriter) publishHeader() {
+	if bw.wroteHeader {
+		return
+	}
+	bw.wroteHeader = true
+	bw.publish(busResponseFrame{Type: frameTypeHeader, Status: bw.status, Header: bw.header})
+}
+
+func (bw *busResponseWriter) publish(frame busResponseFrame) {
+	if bw.err != nil {
+		return
+	}
+
+	bytes, err := json.Marshal(frame)
+	if err != nil {
+		bw.err = fmt.Errorf("error marshalling response frame: %v", err)
+		log.Printf("Stream bus: %v\n", bw.err)
+		return
+	}
+
+	err = Active.Publish(bw.ctx, bw.channel, bytes)
+	if err != nil {
+		bw.err = fmt.Errorf("error publishing response frame: %v", err)
+		log.Printf("Stream bus: %v\n", bw.err)
+	}
+}
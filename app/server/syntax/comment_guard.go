@@ -0,0 +1,278 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// a change description that asks for comments or a header to be removed explains their removal
+var commentRemovalDescRegex = regexp.MustCompile(`\b(remove|removes|removed|removing|delete|deletes|deleted|deleting|strip|strips|stripped|drop|drops|dropped)\b[^\n.]*\b(comments?|docstrings?|license|copyright|header)\b`)
+
+type GuardCommentsParams struct {
+	Original string
+	Updated  string
+	Desc     string
+	Parser   *tree_sitter.Parser
+}
+
+type GuardCommentsResult struct {
+	NewFile            string
+	NeedsVerifyReasons []NeedsVerifyReason
+	Restored           []string
+}
+
+// a run of whole-line comments on consecutive lines, like a doc comment or a license header
+type commentBlock struct {
+	startLine int
+	endLine   int
+	isHeader  bool
+}
+
+// GuardComments compares the comments in the original and updated files. A comment block that was dropped while the code it sits above was kept is restored above that code, and a dropped file header is restored at the top of the file. Each restoration is flagged, unless the change description asked for comments to be removed, in which case the updated file is returned unchanged.
+//
+// Blocks that were partly kept, that were replaced by a new comment, or whose code was also removed are treated as intentional edits.
+func GuardComments(ctx context.Context, params GuardCommentsParams) (*GuardCommentsResult, error) {
+	original := params.Original
+	updated := params.Updated
+
+	res := &GuardCommentsResult{NewFile: updated}
+
+	if params.Parser == nil || original == updated {
+		return res, nil
+	}
+
+	if commentRemovalDescRegex.MatchString(strings.ToLower(params.Desc)) {
+		return res, nil
+	}
+
+	originalComments, err := FindComments(ctx, params.Parser, original)
+	if err != nil {
+		return nil, fmt.Errorf("error finding comments in original file: %v", err)
+	}
+	if len(originalComments) == 0 {
+		return res, nil
+	}
+
+	updatedComments, err := FindComments(ctx, params.Parser, updated)
+	if err != nil {
+		return nil, fmt.Errorf("error finding comments in updated file: %v", err)
+	}
+
+	originalLines := strings.Split(original, "\n")
+	updatedLines := strings.Split(updated, "\n")
+
+	originalCommentLines := commentLineSet(originalComments)
+	updatedCommentLines := commentLineSet(updatedComments)
+
+	originalTrimmed := map[string]bool{}
+	for _, line := range originalLines {
+		originalTrimmed[strings.TrimSpace(line)] = true
+	}
+
+	updatedTrimmed := map[string]bool{}
+	for _, line := range updatedLines {
+		updatedTrimmed[strings.TrimSpace(line)] = true
+	}
+
+	var commentsRemoved, headerRemoved bool
+	headerInsertIdx := 0
+	if len(updatedLines) > 0 && strings.HasPrefix(updatedLines[0], "#!") {
+		headerInsertIdx = 1
+	}
+
+	for _, block := range findCommentBlocks(originalLines, originalComments, originalCommentLines) {
+		blockLines := originalLines[block.startLine : block.endLine+1]
+		if !blockRemoved(blockLines, updatedTrimmed) {
+			continue
+		}
+
+		if block.isHeader {
+			toInsert := append([]string{}, blockLines...)
+			if block.endLine+1 < len(originalLines) && strings.TrimSpace(originalLines[block.endLine+1]) == "" {
+				toInsert = append(toInsert, "")
+			}
+			updatedLines = insertLines(updatedLines, headerInsertIdx, toInsert)
+			headerInsertIdx += len(toInsert)
+
+			headerRemoved = true
+			res.Restored = append(res.Restored, strings.Join(blockLines, "\n"))
+			continue
+		}
+
+		// the code the block documents is the next non-blank line that isn't a comment
+		anchorIdx := -1
+		for i := block.endLine + 1; i < len(originalLines); i++ {
+			if strings.TrimSpace(originalLines[i]) != "" && !originalCommentLines[i] {
+				anchorIdx = i
+				break
+			}
+		}
+		if anchorIdx == -1 {
+			continue
+		}
+
+		anchor := strings.TrimSpace(originalLines[anchorIdx])
+		if !updatedTrimmed[anchor] {
+			// the code went with its comment
+			continue
+		}
+
+		var matches []int
+		for i, line := range updatedLines {
+			if strings.TrimSpace(line) == anchor {
+				matches = append(matches, i)
+			}
+		}
+
+		if len(matches) != 1 {
+			// the code is still there, but there's no single place to put the comment back -- flag it for verification without restoring it
+			log.Printf("GuardComments - comment above %q was removed, but its position in the updated file is ambiguous\n", anchor)
+			commentsRemoved = true
+			continue
+		}
+		updatedIdx := matches[0]
+
+		// a new comment right above the code means the comment was rewritten rather than dropped
+		if updatedIdx > 0 {
+			prev := updatedIdx - 1
+			prevTrimmed := strings.TrimSpace(updatedLines[prev])
+			if updatedCommentLines[prev] && !originalTrimmed[prevTrimmed] {
+				continue
+			}
+		}
+
+		// keep any blank lines between the comment and its code, and follow the code's indentation if it changed
+		toInsert := append([]string{}, originalLines[block.startLine:anchorIdx]...)
+		originalIndent := leadingWhitespace(originalLines[anchorIdx])
+		updatedIndent := leadingWhitespace(updatedLines[updatedIdx])
+		if originalIndent != updatedIndent {
+			for i, line := range toInsert {
+				if strings.HasPrefix(line, originalIndent) {
+					toInsert[i] = updatedIndent + strings.TrimPrefix(line, originalIndent)
+				}
+			}
+		}
+
+		updatedLines = insertLines(updatedLines, updatedIdx, toInsert)
+		if updatedIdx < headerInsertIdx {
+			headerInsertIdx += len(toInsert)
+		}
+		// line numbers in the updated file have shifted
+		updatedCommentLines = shiftLineSet(updatedCommentLines, updatedIdx, len(toInsert))
+		for i := range blockLines {
+			updatedCommentLines[updatedIdx+i] = true
+		}
+
+		commentsRemoved = true
+		res.Restored = append(res.Restored, strings.Join(blockLines, "\n"))
+	}
+
+	if commentsRemoved {
+		res.NeedsVerifyReasons = append(res.NeedsVerifyReasons, NeedsVerifyReasonCommentsRemoved)
+	}
+	if headerRemoved {
+		res.NeedsVerifyReasons = append(res.NeedsVerifyReasons, NeedsVerifyReasonHeaderRemoved)
+	}
+
+	if len(res.Restored) > 0 {
+		log.Printf("GuardComments - restored %d removed comment blocks\n", len(res.Restored))
+		res.NewFile = strings.Join(updatedLines, "\n")
+	}
+
+	return res, nil
+}
+
+func commentLineSet(comments []Comment) map[int]bool {
+	lines := map[int]bool{}
+	for _, comment := range comments {
+		for i := comment.StartLine; i <= comment.EndLine; i++ {
+			lines[i] = true
+		}
+	}
+	return lines
+}
+
+// findCommentBlocks groups comments that start their own line into blocks. Trailing comments after code and reference comments like '... existing code ...' are skipped. Blocks before the first line of code are the file's header.
+func findCommentBlocks(lines []string, comments []Comment, commentLines map[int]bool) []commentBlock {
+	firstCodeLine := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || commentLines[i] || (i == 0 && strings.HasPrefix(trimmed, "#!")) {
+			continue
+		}
+		firstCodeLine = i
+		break
+	}
+
+	var blocks []commentBlock
+	for _, comment := range comments {
+		if comment.IsRef || isRemoval(comment.Txt) {
+			continue
+		}
+		if comment.StartLine >= len(lines) || strings.TrimSpace(lines[comment.StartLine][:comment.StartCol]) != "" {
+			continue
+		}
+
+		if len(blocks) > 0 && blocks[len(blocks)-1].endLine+1 == comment.StartLine {
+			blocks[len(blocks)-1].endLine = comment.EndLine
+			continue
+		}
+
+		blocks = append(blocks, commentBlock{
+			startLine: comment.StartLine,
+			endLine:   comment.EndLine,
+		})
+	}
+
+	for i := range blocks {
+		blocks[i].isHeader = blocks[i].endLine < firstCodeLine
+	}
+
+	return blocks
+}
+
+// blockRemoved is true when none of a block's lines with any text in them are left in the updated file
+func blockRemoved(blockLines []string, updatedTrimmed map[string]bool) bool {
+	hasText := false
+	for _, line := range blockLines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.ContainsFunc(trimmed, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		hasText = true
+		if updatedTrimmed[trimmed] {
+			return false
+		}
+	}
+	return hasText
+}
+
+func insertLines(lines []string, idx int, toInsert []string) []string {
+	res := make([]string, 0, len(lines)+len(toInsert))
+	res = append(res, lines[:idx]...)
+	res = append(res, toInsert...)
+	res = append(res, lines[idx:]...)
+	return res
+}
+
+func shiftLineSet(lines map[int]bool, from, by int) map[int]bool {
+	res := make(map[int]bool, len(lines))
+	for line := range lines {
+		if line >= from {
+			res[line+by] = true
+		} else {
+			res[line] = true
+		}
+	}
+	return res
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
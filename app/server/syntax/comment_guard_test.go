@@ -0,0 +1,183 @@
+package syntax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardComments(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		original    string
+		updated     string
+		desc        string
+		want        string
+		wantReasons []NeedsVerifyReason
+	}{
+		{
+			name: "go doc comment dropped",
+			path: "users.go",
+			original: `package db
+
+// getUser loads a user by name
+// and returns nil if there isn't one
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+			updated: `package db
+
+func getUser(name string) *User {
+	return lookupUser(name)
+}
+`,
+			desc: "Type: replace\nSummary: Replace the lookup call in `getUser`",
+			want: `package db
+
+// getUser loads a user by name
+// and returns nil if there isn't one
+func getUser(name string) *User {
+	return lookupUser(name)
+}
+`,
+			wantReasons: []NeedsVerifyReason{NeedsVerifyReasonCommentsRemoved},
+		},
+		{
+			name: "license header dropped",
+			path: "index.ts",
+			original: `/*
+ * Copyright (c) Example Inc.
+ * Licensed under the MIT license.
+ */
+
+import { a } from "./a";
+
+export const b = a + 1;
+`,
+			updated: `import { a } from "./a";
+
+export const b = a + 2;
+`,
+			desc: "Type: overwrite\nSummary: Update `b`",
+			want: `/*
+ * Copyright (c) Example Inc.
+ * Licensed under the MIT license.
+ */
+
+import { a } from "./a";
+
+export const b = a + 2;
+`,
+			wantReasons: []NeedsVerifyReason{NeedsVerifyReasonHeaderRemoved},
+		},
+		{
+			name: "indented comment follows its code",
+			path: "app.py",
+			original: `def run():
+    # retry once on failure
+    call()
+`,
+			updated: `def run():
+    if ready:
+        call()
+`,
+			desc: "Type: replace\nSummary: Only call when ready",
+			want: `def run():
+    if ready:
+        # retry once on failure
+        call()
+`,
+			wantReasons: []NeedsVerifyReason{NeedsVerifyReasonCommentsRemoved},
+		},
+		{
+			name: "removal asked for in description",
+			path: "users.go",
+			original: `package db
+
+// TODO: remove this
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+			updated: `package db
+
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+			desc: "Type: remove\nSummary: Remove the stale TODO comment above `getUser`",
+			want: `package db
+
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+		},
+		{
+			name: "comment removed with its code",
+			path: "users.go",
+			original: `package db
+
+// getUser loads a user
+func getUser(name string) *User {
+	return lookup(name)
+}
+
+func other() {}
+`,
+			updated: `package db
+
+func other() {}
+`,
+			desc: "Type: remove\nSummary: Remove `getUser`",
+			want: `package db
+
+func other() {}
+`,
+		},
+		{
+			name: "comment rewritten",
+			path: "users.go",
+			original: `package db
+
+// getUser loads a user
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+			updated: `package db
+
+// getUser loads a user, using the cache when possible
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+			desc: "Type: replace\nSummary: Update the doc comment",
+			want: `package db
+
+// getUser loads a user, using the cache when possible
+func getUser(name string) *User {
+	return lookup(name)
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, _, _, _ := GetParserForPath(tt.path)
+			res, err := GuardComments(context.Background(), GuardCommentsParams{
+				Original: tt.original,
+				Updated:  tt.updated,
+				Desc:     tt.desc,
+				Parser:   parser,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, res.NewFile)
+			assert.Equal(t, tt.wantReasons, res.NeedsVerifyReasons)
+		})
+	}
+}
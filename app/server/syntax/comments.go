@@ -1,28 +1,46 @@
 package syntax
 
-import shared "plandex-shared"
+import (
+	"context"
+	"strings"
 
-// // FindComments parses the given source code for the language implied by path
-// // and returns a slice of all comment strings plus an IsRef field indicating
-// // whether the comment is referencing original code (heuristic).
-// func FindComments(ctx context.Context, parser *tree_sitter.Parser, source string) ([]Comment, error) {
-// 	nodes, err := findCommentNodesForPath(ctx, parser, source)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	var comments []Comment
-// 	for _, node := range nodes {
-// 		start := node.StartByte()
-// 		end := node.EndByte()
-// 		raw := source[start:end]
+	shared "plandex-shared"
 
-// 		comments = append(comments, Comment{
-// 			Txt:   raw,
-// 			IsRef: isRef(raw), // your existing logic
-// 		})
-// 	}
-// 	return comments, nil
-// }
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindComments parses the given source code and returns every comment, with its line range and an IsRef field indicating whether the comment is referencing original code (heuristic).
+func FindComments(ctx context.Context, parser *tree_sitter.Parser, source string) ([]Comment, error) {
+	if parser == nil {
+		// If no parser is available for this file type, return empty.
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, parserTimeout)
+	defer cancel()
+
+	tree, err := parser.ParseCtx(ctx, nil, []byte(source))
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	var comments []Comment
+	for _, node := range findCommentNodes(tree.RootNode()) {
+		start := node.StartByte()
+		end := node.EndByte()
+		raw := source[start:end]
+
+		comments = append(comments, Comment{
+			Txt:       raw,
+			IsRef:     isRef(raw),
+			StartLine: int(node.StartPoint().Row),
+			EndLine:   int(node.EndPoint().Row),
+			StartCol:  int(node.StartPoint().Column),
+		})
+	}
+	return comments, nil
+}
 
 // // StripComments removes all comments from the given source code using the appropriate parser
 // func StripComments(ctx context.Context, parser *tree_sitter.Parser, source string) (string, error) {
@@ -54,39 +72,19 @@ import shared "plandex-shared"
 // 	return string(result), nil
 // }
 
-// func findCommentNodesForPath(ctx context.Context, parser *tree_sitter.Parser, source string) ([]*tree_sitter.Node, error) {
-// 	if parser == nil {
-// 		// If no parser is available for this file type, return empty.
-// 		return nil, nil
-// 	}
-
-// 	// Use a context with timeout (from your existing parserTimeout).
-// 	ctx, cancel := context.WithTimeout(ctx, parserTimeout)
-// 	defer cancel()
-
-// 	tree, err := parser.ParseCtx(ctx, nil, []byte(source))
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	defer tree.Close()
-
-// 	// Gather all comment nodes.
-// 	root := tree.RootNode()
-// 	commentNodes := findCommentNodes(root)
-// 	return commentNodes, nil
-// }
-
-// func findCommentNodes(node *tree_sitter.Node) []*tree_sitter.Node {
-// 	var commentNodes []*tree_sitter.Node
+// findCommentNodes collects comment nodes without descending into them. Grammars name these "comment", "line_comment", "block_comment", and so on.
+func findCommentNodes(node *tree_sitter.Node) []*tree_sitter.Node {
+	if strings.HasSuffix(node.Type(), "comment") {
+		return []*tree_sitter.Node{node}
+	}
 
-// 	visitNodes(node, func(n *tree_sitter.Node) {
-// 		if n.Type() == "comment" {
-// 			commentNodes = append(commentNodes, n)
-// 		}
-// 	})
+	var commentNodes []*tree_sitter.Node
+	for i := 0; i < int(node.ChildCount()); i++ {
+		commentNodes = append(commentNodes, findCommentNodes(node.Child(i))...)
+	}
 
-// 	return commentNodes
-// }
+	return commentNodes
+}
 
 func GetCommentSymbols(lang shared.Language) (string, string) {
 	switch lang {
@@ -94,7 +92,7 @@ func GetCommentSymbols(lang shared.Language) (string, string) {
 		return "//", ""
 	case shared.LanguageBash, shared.LanguageDockerfile, shared.LanguageElixir, shared.LanguageHcl, shared.LanguagePython, shared.LanguageRuby, shared.LanguageToml, shared.LanguageYaml:
 		return "#", ""
-	case shared.LanguageLua, shared.LanguageElm:
+	case shared.LanguageLua, shared.LanguageElm, shared.LanguageSql:
 		return "--", ""
 	case shared.LanguageCss:
 		return "/*", "*/"
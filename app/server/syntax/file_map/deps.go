@@ -0,0 +1,430 @@
+package file_map
+
+import (
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+const (
+	maxDepsPerFile = 1000
+	minDepsNameLen = 3
+)
+
+// calls that load another file, like require("x") -- the callee is found in the given field
+type importCallConfig struct {
+	calleeField string
+	names       map[string]bool
+	isPathSpec  bool // the argument is a module name rather than a string
+}
+
+var importCallConfigs = map[shared.Language]importCallConfig{
+	shared.LanguageJavascript: {calleeField: "function", names: map[string]bool{"require": true, "import": true}},
+	shared.LanguageTypescript: {calleeField: "function", names: map[string]bool{"require": true, "import": true}},
+	shared.LanguageJsx:        {calleeField: "function", names: map[string]bool{"require": true, "import": true}},
+	shared.LanguageTsx:        {calleeField: "function", names: map[string]bool{"require": true, "import": true}},
+	shared.LanguageRuby:       {calleeField: "method", names: map[string]bool{"require": true, "require_relative": true, "load": true, "autoload": true}},
+	shared.LanguageLua:        {calleeField: "name", names: map[string]bool{"require": true, "dofile": true}},
+	shared.LanguageElixir:     {calleeField: "target", names: map[string]bool{"alias": true, "import": true, "use": true, "require": true}, isPathSpec: true},
+	shared.LanguageBash:       {calleeField: "name", names: map[string]bool{"source": true, ".": true}, isPathSpec: true},
+}
+
+var importCallNodeTypes = map[string]bool{
+	"call_expression": true,
+	"call":            true,
+	"function_call":   true,
+	"command":         true,
+}
+
+// keywords and modifiers that can come before the module in a path-style import
+var importKeywords = map[string]bool{
+	"import": true,
+	"from":   true,
+	"use":    true,
+	"using":  true,
+	"static": true,
+	"global": true,
+	"pub":    true,
+	"mod":    true,
+}
+
+var stringContentNodeTypes = map[string]bool{
+	"string_fragment":                    true,
+	"string_content":                     true,
+	"interpreted_string_literal_content": true,
+	"system_lib_string":                  true,
+}
+
+var stringNodeTypes = map[string]bool{
+	"interpreted_string_literal": true,
+	"raw_string_literal":         true,
+	"string_literal":             true,
+	"string":                     true,
+	"encapsed_string":            true,
+	"string_value":               true,
+	"word":                       true,
+}
+
+// mapDeps extracts what a file imports, the names it defines, and the identifiers it references for the project dependency graph
+func mapDeps(root *tree_sitter.Node, content []byte, lang shared.Language) *shared.FileMapDeps {
+	deps := &shared.FileMapDeps{}
+
+	importSet := map[string]bool{}
+	var walkImports func(tsNode *tree_sitter.Node)
+	walkImports = func(tsNode *tree_sitter.Node) {
+		if len(deps.Imports) >= maxDepsPerFile {
+			return
+		}
+
+		node := Node{
+			Type:   tsNode.Type(),
+			Lang:   lang,
+			TsNode: tsNode,
+			Bytes:  content,
+		}
+
+		if spec, ok := importSpec(node); ok {
+			if spec != "" && !importSet[spec] {
+				importSet[spec] = true
+				deps.Imports = append(deps.Imports, spec)
+			}
+			return
+		}
+
+		for i := 0; i < int(tsNode.NamedChildCount()); i++ {
+			walkImports(tsNode.NamedChild(i))
+		}
+	}
+	walkImports(root)
+
+	defineSet := map[string]bool{}
+	collectDefines(Node{
+		Lang:   lang,
+		TsNode: root,
+		Bytes:  content,
+	}, nil, func(name string) {
+		if len(name) >= minDepsNameLen && !defineSet[name] && len(deps.Defines) < maxDepsPerFile {
+			defineSet[name] = true
+			deps.Defines = append(deps.Defines, name)
+		}
+	})
+
+	refSet := map[string]bool{}
+	cursor := tree_sitter.NewTreeCursor(root)
+	defer cursor.Close()
+
+	var walkRefs func()
+	walkRefs = func() {
+		tsNode := cursor.CurrentNode()
+		if tsNode.ChildCount() == 0 {
+			node := Node{
+				Type:   tsNode.Type(),
+				Lang:   lang,
+				TsNode: tsNode,
+				Bytes:  content,
+			}
+			if isIdentifierNode(node) {
+				name := tsNode.Content(content)
+				if len(name) >= minDepsNameLen && !defineSet[name] && !refSet[name] && len(deps.Refs) < maxDepsPerFile {
+					refSet[name] = true
+					deps.Refs = append(deps.Refs, name)
+				}
+			}
+			return
+		}
+
+		if cursor.GoToFirstChild() {
+			for {
+				walkRefs()
+				if !cursor.GoToNextSibling() {
+					break
+				}
+			}
+			cursor.GoToParent()
+		}
+	}
+	walkRefs()
+
+	return deps
+}
+
+// importSpec returns the imported module or path if the node is an import. The bool is false for nodes that aren't imports.
+func importSpec(node Node) (string, bool) {
+	tsNode := node.TsNode
+
+	if importNodeMap.getConfig(node.Type, node.Lang) != nil {
+		specNode := tsNode.ChildByFieldName("source")
+		if specNode == nil {
+			specNode = tsNode.ChildByFieldName("path")
+		}
+		if specNode == nil {
+			// an export without 'from' is a definition, not an import
+			if node.Type == "export_statement" {
+				return "", false
+			}
+			specNode = tsNode
+		}
+		return stringSpec(specNode, node.Bytes), true
+	}
+
+	if importPathNodeMap.getConfig(node.Type, node.Lang) != nil {
+		// an inline rust module rather than a reference to another file
+		if node.Type == "mod_item" && tsNode.ChildByFieldName("body") != nil {
+			return "", false
+		}
+		return pathSpec(tsNode.Content(node.Bytes)), true
+	}
+
+	if node.Lang == shared.LanguageHcl && node.Type == "block" {
+		return hclModuleSource(node)
+	}
+
+	if importCallNodeTypes[node.Type] {
+		config, ok := importCallConfigs[node.Lang]
+		if !ok {
+			return "", false
+		}
+		callee := tsNode.ChildByFieldName(config.calleeField)
+		if callee == nil || !config.names[callee.Content(node.Bytes)] {
+			return "", false
+		}
+		if config.isPathSpec {
+			// skip past the callee so it isn't taken for the module
+			return pathSpec(string(node.Bytes[callee.EndByte():tsNode.EndByte()])), true
+		}
+		args := tsNode.ChildByFieldName("arguments")
+		if args == nil {
+			args = tsNode
+		}
+		return stringSpec(args, node.Bytes), true
+	}
+
+	return "", false
+}
+
+// hclModuleSource returns the source of a terraform module block, like "./modules/vpc" in module "vpc" { source = "./modules/vpc" }. Other blocks aren't imports.
+func hclModuleSource(node Node) (string, bool) {
+	tsNode := node.TsNode
+	if tsNode.NamedChildCount() == 0 || tsNode.NamedChild(0).Content(node.Bytes) != "module" {
+		return "", false
+	}
+
+	for i := 0; i < int(tsNode.NamedChildCount()); i++ {
+		body := tsNode.NamedChild(i)
+		if body.Type() != "body" {
+			continue
+		}
+		for j := 0; j < int(body.NamedChildCount()); j++ {
+			attr := body.NamedChild(j)
+			if attr.Type() != "attribute" || attr.NamedChildCount() < 2 || attr.NamedChild(0).Content(node.Bytes) != "source" {
+				continue
+			}
+			return hclStringSpec(attr.NamedChild(1), node.Bytes), true
+		}
+	}
+
+	return "", true
+}
+
+// hclStringSpec is stringSpec for hcl, where a string's text is a template_literal inside a quoted template -- other languages use template_literal for template strings, which aren't plain paths
+func hclStringSpec(tsNode *tree_sitter.Node, content []byte) string {
+	var find func(n *tree_sitter.Node) *tree_sitter.Node
+	find = func(n *tree_sitter.Node) *tree_sitter.Node {
+		if n.Type() == "template_literal" {
+			return n
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if found := find(n.NamedChild(i)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	if found := find(tsNode); found != nil {
+		return found.Content(content)
+	}
+	return stringSpec(tsNode, content)
+}
+
+// stringSpec finds the first string literal in the node and returns its content without quotes
+func stringSpec(tsNode *tree_sitter.Node, content []byte) string {
+	var fallback *tree_sitter.Node
+
+	var find func(n *tree_sitter.Node) *tree_sitter.Node
+	find = func(n *tree_sitter.Node) *tree_sitter.Node {
+		if stringContentNodeTypes[n.Type()] {
+			return n
+		}
+		if fallback == nil && stringNodeTypes[n.Type()] {
+			fallback = n
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if found := find(n.NamedChild(i)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	found := find(tsNode)
+	if found == nil {
+		found = fallback
+	}
+	if found == nil {
+		return ""
+	}
+
+	return strings.Trim(found.Content(content), "\"'`<>")
+}
+
+// pathSpec pulls the module out of an import written as keywords followed by a path, like 'from a.b import c' or 'use a::b::{c, d};'
+func pathSpec(s string) string {
+	fields := strings.Fields(s)
+	for len(fields) > 0 && (importKeywords[fields[0]] || strings.HasPrefix(fields[0], "pub(")) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	spec := strings.Trim(strings.TrimRight(fields[0], ";,"), "\"'")
+	if i := strings.IndexAny(spec, "{*("); i != -1 {
+		spec = spec[:i]
+	}
+
+	// python's 'from . import x' and 'from .. import x'
+	if strings.Trim(spec, ".") == "" {
+		if len(fields) > 2 && fields[1] == "import" {
+			return spec + strings.TrimRight(fields[2], ",")
+		}
+		return spec
+	}
+
+	return strings.TrimRight(spec, ".:")
+}
+
+// collectDefines follows the same structure as mapTraditional, so names are only collected for definitions that would appear in the map
+func collectDefines(baseNode Node, parentNode *Node, onName func(string)) {
+	cursor := tree_sitter.NewTreeCursor(baseNode.TsNode)
+	defer cursor.Close()
+
+	if !cursor.GoToFirstChild() {
+		return
+	}
+
+	for {
+		tsNode := cursor.CurrentNode()
+		node := Node{
+			Type:   tsNode.Type(),
+			Lang:   baseNode.Lang,
+			TsNode: tsNode,
+			Bytes:  baseNode.Bytes,
+		}
+
+		if isTransparentNode(node) {
+			collectDefines(node, parentNode, onName)
+		} else if !isIncludeAndContinueNode(node) && isDefinitionNode(node, parentNode) {
+			for _, name := range definitionNames(node) {
+				onName(name)
+			}
+
+			if isPassThroughParentNode(node) {
+				collectDefines(node, nil, onName)
+			} else if isParentNode(node) {
+				if body := findImplementationBoundary(node); body != nil && body.TsNode != tsNode {
+					collectDefines(*body, &node, onName)
+				}
+			}
+		}
+
+		if !cursor.GoToNextSibling() {
+			break
+		}
+	}
+}
+
+// definitionNames finds the names a definition introduces -- more than one for grouped declarations like go's var ( ... )
+func definitionNames(node Node) []string {
+	tsNode := node.TsNode
+
+	// hcl blocks are referenced by their last label, like var.region or module.vpc -- blocks without labels like 'locals' don't introduce a name
+	if node.Lang == shared.LanguageHcl && node.Type == "block" {
+		var name string
+		for i := 0; i < int(tsNode.NamedChildCount()); i++ {
+			if label := tsNode.NamedChild(i); label.Type() == "string_lit" || (i > 0 && label.Type() == "identifier") {
+				name = stringSpec(label, node.Bytes)
+				if name == "" {
+					name = label.Content(node.Bytes)
+				}
+			}
+		}
+		if name == "" {
+			return nil
+		}
+		return []string{name}
+	}
+
+	if name := nameField(tsNode, node.Bytes); name != "" {
+		return []string{name}
+	}
+
+	var names []string
+	for i := 0; i < int(tsNode.NamedChildCount()); i++ {
+		child := tsNode.NamedChild(i)
+		if name := nameField(child, node.Bytes); name != "" {
+			names = append(names, name)
+		}
+		// go's grouped specs are one level further down
+		for j := 0; j < int(child.NamedChildCount()); j++ {
+			if name := nameField(child.NamedChild(j), node.Bytes); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		return names
+	}
+
+	for _, identifier := range findIdentifier(node) {
+		name := identifier.TsNode.Content(node.Bytes)
+		// elixir definitions are calls like 'def name(...)'
+		if node.Lang == shared.LanguageElixir && strings.HasPrefix(name, "def") {
+			continue
+		}
+		return []string{lastNameSegment(name)}
+	}
+
+	return nil
+}
+
+// nameField follows 'name' and 'declarator' fields down to an identifier
+func nameField(tsNode *tree_sitter.Node, content []byte) string {
+	for depth := 0; tsNode != nil && depth < 5; depth++ {
+		if name := tsNode.ChildByFieldName("name"); name != nil {
+			if name.NamedChildCount() == 0 {
+				return lastNameSegment(name.Content(content))
+			}
+			tsNode = name
+			continue
+		}
+		if declarator := tsNode.ChildByFieldName("declarator"); declarator != nil {
+			if declarator.NamedChildCount() == 0 {
+				return lastNameSegment(declarator.Content(content))
+			}
+			tsNode = declarator
+			continue
+		}
+		break
+	}
+	return ""
+}
+
+func lastNameSegment(name string) string {
+	if i := strings.LastIndexAny(name, ".:"); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,234 @@
+package file_map
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestMapFileImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     []string
+	}{
+		{
+			name:     "go import block",
+			filename: "main.go",
+			content: `package main
+
+import (
+	"fmt"
+	db "example.com/app/db"
+)
+
+func main() { fmt.Println(db.Get()) }
+`,
+			want: []string{"fmt", "example.com/app/db"},
+		},
+		{
+			name:     "typescript imports, re-exports, and require",
+			filename: "index.ts",
+			content: `import { a } from "./a";
+export { b } from './b';
+export const c = 1;
+const d = require("./d");
+`,
+			want: []string{"./a", "./b", "./d"},
+		},
+		{
+			name:     "python absolute and relative imports",
+			filename: "app.py",
+			content: `import os.path
+from .models import User
+from . import utils
+`,
+			want: []string{"os.path", ".models", ".utils"},
+		},
+		{
+			name:     "rust use and file modules",
+			filename: "lib.rs",
+			content: `mod db;
+mod inline { fn f() {} }
+use crate::db::{users, posts};
+`,
+			want: []string{"db", "crate::db"},
+		},
+		{
+			name:     "c includes",
+			filename: "main.c",
+			content: `#include <stdio.h>
+#include "util.h"
+`,
+			want: []string{"stdio.h", "util.h"},
+		},
+		{
+			name:     "ruby requires",
+			filename: "app.rb",
+			content: `require "json"
+require_relative "lib/user"
+`,
+			want: []string{"json", "lib/user"},
+		},
+		{
+			name:     "elixir aliases",
+			filename: "user.ex",
+			content: `defmodule MyApp.Web do
+  alias MyApp.Accounts.User
+  import Ecto.Query, only: [from: 2]
+end
+`,
+			want: []string{"MyApp.Accounts.User", "Ecto.Query"},
+		},
+		{
+			name:     "bash source",
+			filename: "run.sh",
+			content: `source ./env.sh
+. "./lib.sh"
+`,
+			want: []string{"./env.sh", "./lib.sh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileMap, err := MapFile(context.Background(), tt.filename, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("MapFile() error = %v", err)
+			}
+			if fileMap.Deps == nil {
+				t.Fatalf("MapFile() deps = nil")
+			}
+			if !reflect.DeepEqual(fileMap.Deps.Imports, tt.want) {
+				t.Errorf("imports = %v, want %v", fileMap.Deps.Imports, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapFileDefinesAndRefs(t *testing.T) {
+	content := `package db
+
+type User struct{ Name string }
+
+func GetUser(id string) *User { return lookupUser(id) }
+`
+	fileMap, err := MapFile(context.Background(), "db/users.go", []byte(content))
+	if err != nil {
+		t.Fatalf("MapFile() error = %v", err)
+	}
+
+	defines := map[string]bool{}
+	for _, name := range fileMap.Deps.Defines {
+		defines[name] = true
+	}
+	for _, name := range []string{"User", "GetUser"} {
+		if !defines[name] {
+			t.Errorf("defines = %v, missing %s", fileMap.Deps.Defines, name)
+		}
+	}
+
+	refs := map[string]bool{}
+	for _, name := range fileMap.Deps.Refs {
+		refs[name] = true
+	}
+	if !refs["lookupUser"] {
+		t.Errorf("refs = %v, missing lookupUser", fileMap.Deps.Refs)
+	}
+	if refs["GetUser"] {
+		t.Errorf("refs = %v, shouldn't include names the file defines", fileMap.Deps.Refs)
+	}
+}
+
+func TestBuildGraphReachable(t *testing.T) {
+	deps := shared.FileMapDepsByPath{
+		"handlers/users.go": {Imports: []string{"example.com/app/db"}, Refs: []string{"GetUser"}},
+		"db/users.go":       {Defines: []string{"GetUser"}},
+		"db/posts.go":       {Defines: []string{"GetPost"}},
+		"routes/routes.go":  {Refs: []string{"UsersHandler"}},
+		"web/index.ts":      {Imports: []string{"./api"}},
+		"web/api/index.ts":  {},
+	}
+	deps["handlers/users.go"].Defines = []string{"UsersHandler"}
+
+	graph := deps.BuildGraph()
+
+	mentioned := graph.PathsMentionedIn("Add pagination to the handler in handlers/users.go.")
+	if !reflect.DeepEqual(mentioned, []string{"handlers/users.go"}) {
+		t.Fatalf("mentioned = %v", mentioned)
+	}
+
+	var got []string
+	for _, r := range graph.Reachable(mentioned, 1, 10) {
+		got = append(got, r.Path)
+	}
+	// referenced and imported first, then imported only, then dependents
+	want := []string{"handlers/users.go", "db/users.go", "db/posts.go", "routes/routes.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reachable = %v, want %v", got, want)
+	}
+
+	if edge := graph.Out["web/index.ts"]["web/api/index.ts"]; edge == nil || !edge.Import {
+		t.Errorf("expected ./api to resolve to web/api/index.ts")
+	}
+}
+
+func TestBudgetedMap(t *testing.T) {
+	bodies := shared.FileMapBodies{}
+	tokens := map[string]int{}
+	deps := shared.FileMapDepsByPath{}
+
+	coreContent := "package core\n\nfunc GetUser(id string) string { return id }\n\nfunc ListUsers() []string { return nil }\n"
+	coreMap, err := MapFile(context.Background(), "core/users.go", []byte(coreContent))
+	if err != nil {
+		t.Fatalf("MapFile() error = %v", err)
+	}
+	bodies["core/users.go"] = coreMap.String()
+	deps["core/users.go"] = coreMap.Deps
+
+	for i := 0; i < 200; i++ {
+		p := fmt.Sprintf("handlers/handler%03d.go", i)
+		content := fmt.Sprintf("package handlers\n\nfunc Handler%03d() string { return GetUser(\"%d\") }\n\nfunc helper%03d() {}\n", i, i, i)
+		fileMap, err := MapFile(context.Background(), p, []byte(content))
+		if err != nil {
+			t.Fatalf("MapFile() error = %v", err)
+		}
+		bodies[p] = fileMap.String()
+		deps[p] = fileMap.Deps
+		tokens[p] = 20
+	}
+
+	full, fullTokens, budgeted := shared.BudgetedMap(shared.FileMapBudgetParams{Bodies: bodies, Tokens: tokens, Deps: deps})
+	if budgeted || full != bodies.CombinedMap(tokens) {
+		t.Fatalf("expected the full map without a budget")
+	}
+
+	maxTokens := fullTokens / 3
+	res, numTokens, budgeted := shared.BudgetedMap(shared.FileMapBudgetParams{
+		Bodies:    bodies,
+		Tokens:    tokens,
+		Deps:      deps,
+		Focus:     []string{"handlers/handler150.go"},
+		MaxTokens: maxTokens,
+	})
+	if !budgeted {
+		t.Fatalf("expected the map to be reduced")
+	}
+	if numTokens > maxTokens {
+		t.Errorf("reduced map has %d tokens, budget is %d", numTokens, maxTokens)
+	}
+	if !strings.Contains(res, "func GetUser(id string) string") {
+		t.Errorf("expected the most referenced file to be mapped in full:\n%s", res)
+	}
+	if !strings.Contains(res, "func Handler150() string") {
+		t.Errorf("expected the file in context to be mapped")
+	}
+	if !strings.Contains(res, "### Files not shown") {
+		t.Errorf("expected files that didn't fit to be summarized")
+	}
+}
@@ -0,0 +1,33 @@
+import 'dart:async';
+import 'package:http/http.dart' as http;
+
+/// A user of the app
+class User {
+  final String name;
+  final int age;
+
+  User(this.name, this.age);
+
+  String greet() {
+    return 'Hello, $name';
+  }
+}
+
+abstract class Repository<T> {
+  Future<T?> find(int id);
+}
+
+enum Status { active, inactive }
+
+typedef Handler = void Function(String message);
+
+const defaultTimeout = Duration(seconds: 5);
+
+Future<User> fetchUser(int id) async {
+  final response = await http.get(Uri.parse('https://example.com/users/$id'));
+  return User(response.body, 0);
+}
+
+void main() {
+  print(defaultTimeout);
+}
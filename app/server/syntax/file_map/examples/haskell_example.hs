@@ -0,0 +1,33 @@
+module Data.Shapes
+  ( Shape (..)
+  , area
+  ) where
+
+import qualified Data.Map as Map
+import Data.List (sortOn)
+
+{- Shapes and their
+   measurements -}
+
+-- | A geometric shape
+data Shape
+  = Circle Double
+  | Rect Double Double
+  deriving (Show, Eq)
+
+newtype Name = Name String
+
+class Describable a where
+  describe :: a -> String
+
+instance Describable Shape where
+  describe _ = "shape"
+
+-- | The area of a shape
+area :: Shape -> Double
+area (Circle r) = pi * r * r
+area (Rect w h) = w * h
+
+largest :: [Shape] -> Maybe Shape
+largest [] = Nothing
+largest xs = Just (last (sortOn area xs))
@@ -0,0 +1,31 @@
+module Geometry
+
+using LinearAlgebra
+import Base: show
+include("utils.jl")
+
+abstract type Shape end
+
+"A circle with a radius"
+struct Circle <: Shape
+    radius::Float64
+end
+
+mutable struct Canvas
+    shapes::Vector{Shape}
+end
+
+const DEFAULT_RADIUS = 1.0
+
+function area(c::Circle)
+    inner = c.radius^2
+    return pi * inner
+end
+
+perimeter(c::Circle) = 2 * pi * c.radius
+
+macro twice(ex)
+    return :($ex; $ex)
+end
+
+end
@@ -0,0 +1,20 @@
+{ pkgs ? import <nixpkgs> {} }:
+
+let
+  # shared build inputs
+  buildInputs = with pkgs; [ go gopls ];
+  helpers = import ./lib/helpers.nix { inherit pkgs; };
+  mkService = name: pkgs.writeShellScriptBin name ''
+    echo ${name}
+  '';
+in
+{
+  devShell = pkgs.mkShell {
+    inherit buildInputs;
+    shellHook = ''
+      echo "ready"
+    '';
+  };
+
+  server = pkgs.callPackage ./server.nix { };
+}
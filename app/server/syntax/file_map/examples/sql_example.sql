@@ -0,0 +1,30 @@
+-- Schema for the accounts service
+
+CREATE TABLE users (
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE orders (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id),
+    total NUMERIC(10, 2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending'
+);
+
+CREATE INDEX orders_user_id_idx ON orders (user_id);
+
+CREATE VIEW active_users AS
+SELECT u.id, u.email
+FROM users u
+WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id);
+
+CREATE FUNCTION order_total(uid BIGINT) RETURNS NUMERIC AS $$
+    SELECT sum(total) FROM orders WHERE user_id = uid;
+$$ LANGUAGE sql;
+
+ALTER TABLE users ADD COLUMN last_login TIMESTAMP;
+
+INSERT INTO users (email, name) VALUES ('admin@example.com', 'Admin');
@@ -0,0 +1,33 @@
+terraform {
+  required_version = ">= 1.5"
+}
+
+variable "region" {
+  type    = string
+  default = "us-west-2"
+}
+
+module "network" {
+  source = "./modules/network"
+
+  region = var.region
+}
+
+resource "aws_instance" "web" {
+  ami           = data.aws_ami.ubuntu.id
+  instance_type = "t3.micro"
+  subnet_id     = module.network.private_subnet_ids[0]
+
+  tags = {
+    Name = "web"
+  }
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+  owners      = ["099720109477"]
+}
+
+output "web_ip" {
+  value = aws_instance.web.private_ip
+}
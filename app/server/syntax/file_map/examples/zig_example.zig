@@ -0,0 +1,25 @@
+const std = @import("std");
+const config = @import("config.zig");
+
+/// A point in 2D space
+pub const Point = struct {
+    x: f32,
+    y: f32,
+
+    pub fn length(self: Point) f32 {
+        return @sqrt(self.x * self.x + self.y * self.y);
+    }
+};
+
+pub const Color = enum { red, green, blue };
+
+const max_points = 64;
+
+pub fn addPoints(a: Point, b: Point) Point {
+    return .{ .x = a.x + b.x, .y = a.y + b.y };
+}
+
+test "addPoints" {
+    const p = addPoints(.{ .x = 1, .y = 2 }, .{ .x = 3, .y = 4 });
+    try std.testing.expectEqual(@as(f32, 4), p.x);
+}
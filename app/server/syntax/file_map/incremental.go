@@ -0,0 +1,345 @@
+package file_map
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"plandex-server/syntax"
+	"sync"
+	"sync/atomic"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// parsed trees are kept in memory per instance -- they can't be serialized, so they're rebuilt after a restart
+const (
+	parseCacheMaxEntries = 5000
+	// the limit applies to entries' estimated memory, not their source size -- see estimatedEntrySize
+	parseCacheMaxBytes = 512 * 1024 * 1024 // 512MB
+
+	// a tree-sitter tree takes several times its source's size, since each node is a separate allocation, so entries are counted at this multiple of their source. 512MB holds roughly 50MB of source.
+	parseCacheSizePerSourceByte = 10
+)
+
+// estimatedEntrySize approximates an entry's memory from its source -- the source itself, its syntax tree, and the mapped definitions
+func estimatedEntrySize(content []byte) int {
+	return len(content) * parseCacheSizePerSourceByte
+}
+
+type parseCacheEntry struct {
+	mu sync.Mutex
+
+	key  string
+	elem *list.Element
+	// estimated bytes counted in the cache's total -- guarded by the cache's mu rather than the entry's
+	size int
+	// set by the cache when the entry is dropped, without taking mu -- whoever holds mu next resets the entry
+	evicted atomic.Bool
+
+	sha     string
+	lang    shared.Language
+	content []byte
+	tree    *tree_sitter.Tree
+	// one per top-level node, in order -- nil for nodes that aren't definitions
+	childDefs []*Definition
+	fileMap   *FileMap
+}
+
+type parseCache struct {
+	mu         sync.Mutex
+	entries    map[string]*parseCacheEntry
+	lru        *list.List // front is most recently used
+	totalBytes int
+}
+
+var treeCache = &parseCache{
+	entries: map[string]*parseCacheEntry{},
+	lru:     list.New(),
+}
+
+func (c *parseCache) get(key string) *parseCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entries[key]
+	if entry == nil {
+		entry = &parseCacheEntry{key: key}
+		entry.elem = c.lru.PushFront(entry)
+		c.entries[key] = entry
+	} else {
+		c.lru.MoveToFront(entry.elem)
+	}
+	return entry
+}
+
+// resize records an entry's new estimated size and evicts the least recently used entries until the cache is back under its limits. The caller holds entry's mu, so evicted entries are only marked here, never locked -- locking them could deadlock with another mapper evicting entry.
+func (c *parseCache) resize(entry *parseCacheEntry, size int) {
+	var evicted []*parseCacheEntry
+
+	c.mu.Lock()
+	if c.entries[entry.key] == entry {
+		c.totalBytes += size - entry.size
+		entry.size = size
+	}
+	for c.lru.Len() > 1 && (c.lru.Len() > parseCacheMaxEntries || c.totalBytes > parseCacheMaxBytes) {
+		oldest := c.lru.Back().Value.(*parseCacheEntry)
+		if oldest == entry {
+			break
+		}
+		c.drop(oldest)
+		evicted = append(evicted, oldest)
+	}
+	c.mu.Unlock()
+
+	for _, e := range evicted {
+		// if another mapper holds the entry, it resets it when it unlocks
+		if e.mu.TryLock() {
+			e.reset()
+			e.mu.Unlock()
+		}
+	}
+}
+
+// remove drops an entry that the caller holds the lock for
+func (c *parseCache) remove(entry *parseCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[entry.key] != entry {
+		return
+	}
+	c.drop(entry)
+}
+
+// drop removes an entry from the cache -- c.mu must be held
+func (c *parseCache) drop(entry *parseCacheEntry) {
+	c.lru.Remove(entry.elem)
+	delete(c.entries, entry.key)
+	c.totalBytes -= entry.size
+	entry.size = 0
+	entry.evicted.Store(true)
+}
+
+// unlock releases the entry, and resets it if it was evicted while locked. The check runs after unlocking so that an eviction racing with the unlock is always handled, either here or by the evicting mapper's TryLock.
+func (e *parseCacheEntry) unlock() {
+	e.mu.Unlock()
+	if e.evicted.Load() && e.mu.TryLock() {
+		e.reset()
+		e.mu.Unlock()
+	}
+}
+
+func (e *parseCacheEntry) reset() {
+	if e.tree != nil {
+		e.tree.Close()
+	}
+	e.tree = nil
+	e.sha = ""
+	e.lang = ""
+	e.content = nil
+	e.childDefs = nil
+	e.fileMap = nil
+}
+
+// MapFileIncremental maps a file like MapFile, but keeps the parsed tree in an in-memory cache under cacheKey (org and project) and the file's path. When the same path is mapped again with new content, the cached tree is edited to match the change and re-parsed incrementally, and only top-level definitions touched by the change are mapped again. Content that hasn't changed since the last call is returned from the cache without parsing. Files that don't map through a tree-sitter syntax tree, and calls with an empty cacheKey, fall back to MapFile.
+func MapFileIncremental(ctx context.Context, cacheKey, filename string, content []byte) (*FileMap, error) {
+	if cacheKey == "" || !shared.HasFileMapSupport(filename) {
+		return MapFile(ctx, filename, content)
+	}
+
+	lang := syntax.GetLanguageForPath(filename)
+	if lang == "" || !shared.IsTreeSitterLanguage(lang) || lang == shared.LanguageHtml || lang == shared.LanguageSvelte {
+		return MapFile(ctx, filename, content)
+	}
+
+	hash := sha256.Sum256(content)
+	sha := hex.EncodeToString(hash[:])
+
+	entry := treeCache.get(cacheKey + "|" + filename)
+	entry.mu.Lock()
+	defer entry.unlock()
+
+	if entry.evicted.Load() {
+		// evicted between lookup and lock -- not worth retrying
+		entry.reset()
+		return MapFile(ctx, filename, content)
+	}
+
+	if entry.fileMap != nil && entry.sha == sha && entry.lang == lang {
+		return entry.fileMap, nil
+	}
+
+	parser, _, _, _ := syntax.GetParserForPath(filename)
+	if parser == nil {
+		treeCache.remove(entry)
+		entry.reset()
+		return MapFile(ctx, filename, content)
+	}
+	defer parser.Close()
+
+	var oldTree *tree_sitter.Tree
+	reusable := map[parseChildKey]*Definition{}
+
+	if entry.tree != nil && entry.lang == lang {
+		if edit, ok := contentEdit(entry.content, content); ok {
+			entry.tree.Edit(edit)
+			oldTree = entry.tree
+
+			// after the edit, nodes the change didn't touch report no changes and have their positions shifted to match the new content
+			oldNodes := topLevelNodes(oldTree.RootNode(), lang, content)
+			for i, node := range oldNodes {
+				if i >= len(entry.childDefs) {
+					break
+				}
+				if !node.HasChanges() {
+					reusable[parseChildKeyFor(node)] = entry.childDefs[i]
+				}
+			}
+		}
+	}
+
+	tree, err := parser.ParseCtx(ctx, oldTree, content)
+	if err != nil || tree.RootNode().Type() == "error" {
+		if tree != nil {
+			tree.Close()
+		}
+		// let MapFile handle the fallback parser
+		treeCache.remove(entry)
+		entry.reset()
+		return MapFile(ctx, filename, content)
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	nodes := topLevelNodes(root, lang, content)
+	var defs []Definition
+	childDefs := make([]*Definition, 0, len(nodes))
+	numReused := 0
+
+	for _, child := range nodes {
+		var def *Definition
+		if cached, ok := reusable[parseChildKeyFor(child)]; ok {
+			numReused++
+			if cached != nil {
+				shifted := shiftDefinitionLines(*cached, int(child.StartPoint().Row)+1-cached.Line)
+				def = &shifted
+			}
+		} else {
+			def = mapTraditionalChild(Node{
+				Type:   child.Type(),
+				Lang:   lang,
+				TsNode: child,
+				Bytes:  content,
+			}, nil)
+		}
+
+		childDefs = append(childDefs, def)
+		if def != nil {
+			defs = append(defs, *def)
+		}
+	}
+
+	if verboseLogging {
+		log.Printf("MapFileIncremental - %s - incremental: %t, reused %d/%d top-level nodes", filename, oldTree != nil, numReused, len(nodes))
+	}
+
+	fileMap := &FileMap{
+		Definitions: defs,
+		Deps:        mapNodeDeps(root, content, lang),
+	}
+
+	if entry.tree != nil {
+		entry.tree.Close()
+	}
+	// a fresh copy drops the node wrappers cached while mapping, which would otherwise stay in memory with the tree
+	entry.tree = tree.Copy()
+	entry.sha = sha
+	entry.lang = lang
+	entry.content = content
+	entry.childDefs = childDefs
+	entry.fileMap = fileMap
+
+	treeCache.resize(entry, estimatedEntrySize(content))
+
+	return fileMap, nil
+}
+
+// topLevelNodes lists the root's children in the order mapTraditional visits them, with transparent nodes replaced by their own children
+func topLevelNodes(root *tree_sitter.Node, lang shared.Language, content []byte) []*tree_sitter.Node {
+	var nodes []*tree_sitter.Node
+	for i := 0; i < int(root.ChildCount()); i++ {
+		child := root.Child(i)
+		if isTransparentNode(Node{Type: child.Type(), Lang: lang, TsNode: child, Bytes: content}) {
+			nodes = append(nodes, topLevelNodes(child, lang, content)...)
+		} else {
+			nodes = append(nodes, child)
+		}
+	}
+	return nodes
+}
+
+type parseChildKey struct {
+	start, end uint32
+	nodeType   string
+}
+
+func parseChildKeyFor(node *tree_sitter.Node) parseChildKey {
+	return parseChildKey{start: node.StartByte(), end: node.EndByte(), nodeType: node.Type()}
+}
+
+// contentEdit describes the change from prev to next as a single edit spanning everything between their common prefix and common suffix
+func contentEdit(prev, next []byte) (tree_sitter.EditInput, bool) {
+	if bytes.Equal(prev, next) {
+		return tree_sitter.EditInput{}, false
+	}
+
+	start := 0
+	for start < len(prev) && start < len(next) && prev[start] == next[start] {
+		start++
+	}
+
+	oldEnd, newEnd := len(prev), len(next)
+	for oldEnd > start && newEnd > start && prev[oldEnd-1] == next[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+
+	return tree_sitter.EditInput{
+		StartIndex:  uint32(start),
+		OldEndIndex: uint32(oldEnd),
+		NewEndIndex: uint32(newEnd),
+		StartPoint:  pointAt(prev, start),
+		OldEndPoint: pointAt(prev, oldEnd),
+		NewEndPoint: pointAt(next, newEnd),
+	}, true
+}
+
+// pointAt returns the row and byte column of an offset, as tree-sitter expects
+func pointAt(content []byte, offset int) tree_sitter.Point {
+	row := bytes.Count(content[:offset], []byte("\n"))
+	col := offset
+	if i := bytes.LastIndexByte(content[:offset], '\n'); i != -1 {
+		col = offset - i - 1
+	}
+	return tree_sitter.Point{Row: uint32(row), Column: uint32(col)}
+}
+
+// shiftDefinitionLines copies a definition with its line numbers, and those of its children, moved by delta
+func shiftDefinitionLines(def Definition, delta int) Definition {
+	if delta == 0 {
+		return def
+	}
+	def.Line += delta
+	if len(def.Children) > 0 {
+		children := make([]Definition, len(def.Children))
+		for i, child := range def.Children {
+			children[i] = shiftDefinitionLines(child, delta)
+		}
+		def.Children = children
+	}
+	return def
+}
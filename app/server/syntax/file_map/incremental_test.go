@@ -0,0 +1,245 @@
+package file_map
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMapFileIncremental(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		versions []string
+	}{
+		{
+			name:     "go edits that shift and change definitions",
+			filename: "users.go",
+			versions: []string{
+				`package db
+
+// User is a user
+type User struct{ Name string }
+
+func GetUser(id string) *User { return lookupUser(id) }
+
+func ListUsers() []*User { return nil }
+`,
+				`package db
+
+import "strings"
+
+// User is a user
+type User struct {
+	Name  string
+	Email string
+}
+
+func GetUser(id string) *User { return lookupUser(strings.TrimSpace(id)) }
+
+func ListUsers() []*User { return nil }
+`,
+				`package db
+
+import "strings"
+
+// User is a user
+type User struct {
+	Name  string
+	Email string
+}
+
+func ListUsers() []*User { return nil }
+
+func DeleteUser(id string) error { return nil }
+`,
+			},
+		},
+		{
+			name:     "typescript edit inside a class",
+			filename: "api.ts",
+			versions: []string{
+				`import { get } from "./http";
+
+export class Api {
+  users() { return get("/users"); }
+}
+
+export function createApi(): Api { return new Api(); }
+`,
+				`import { get, post } from "./http";
+
+export class Api {
+  users() { return get("/users"); }
+  createUser(name: string) { return post("/users", { name }); }
+}
+
+export function createApi(): Api { return new Api(); }
+`,
+			},
+		},
+		{
+			name:     "terraform blocks inside the top-level body",
+			filename: "main.tf",
+			versions: []string{
+				`variable "region" {
+  default = "us-west-2"
+}
+
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+`,
+				`variable "region" {
+  default = "us-east-1"
+}
+
+module "network" {
+  source = "./modules/network"
+}
+
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheKey := "org/" + tt.name
+			for i, content := range tt.versions {
+				got, err := MapFileIncremental(context.Background(), cacheKey, tt.filename, []byte(content))
+				if err != nil {
+					t.Fatalf("version %d: MapFileIncremental() error = %v", i, err)
+				}
+				want, err := MapFile(context.Background(), tt.filename, []byte(content))
+				if err != nil {
+					t.Fatalf("version %d: MapFile() error = %v", i, err)
+				}
+
+				if got.String() != want.String() {
+					t.Errorf("version %d: incremental map =\n%s\nwant\n%s", i, got.String(), want.String())
+				}
+				if !reflect.DeepEqual(got.Definitions, want.Definitions) {
+					t.Errorf("version %d: incremental definitions differ from a full map", i)
+				}
+				if !reflect.DeepEqual(got.Deps, want.Deps) {
+					t.Errorf("version %d: deps = %+v, want %+v", i, got.Deps, want.Deps)
+				}
+			}
+
+			last := []byte(tt.versions[len(tt.versions)-1])
+			first, _ := MapFileIncremental(context.Background(), cacheKey, tt.filename, last)
+			again, _ := MapFileIncremental(context.Background(), cacheKey, tt.filename, last)
+			if first != again {
+				t.Errorf("expected unchanged content to be served from the cache")
+			}
+		})
+	}
+}
+
+func TestContentEdit(t *testing.T) {
+	prev := []byte("a\nbc\nd\n")
+	next := []byte("a\nbXYc\nd\n")
+
+	edit, ok := contentEdit(prev, next)
+	if !ok {
+		t.Fatalf("expected an edit")
+	}
+	if edit.StartIndex != 3 || edit.OldEndIndex != 3 || edit.NewEndIndex != 5 {
+		t.Errorf("edit indexes = %d, %d, %d", edit.StartIndex, edit.OldEndIndex, edit.NewEndIndex)
+	}
+	if edit.StartPoint.Row != 1 || edit.StartPoint.Column != 1 || edit.NewEndPoint.Column != 3 {
+		t.Errorf("edit points = %+v, %+v", edit.StartPoint, edit.NewEndPoint)
+	}
+
+	if _, ok := contentEdit(prev, prev); ok {
+		t.Errorf("expected no edit for identical content")
+	}
+
+	if !strings.Contains(string(next[edit.StartIndex:edit.NewEndIndex]), "XY") {
+		t.Errorf("edit range doesn't cover the inserted text")
+	}
+}
+
+func TestParseCacheEvictsLockedEntries(t *testing.T) {
+	cache := &parseCache{
+		entries: map[string]*parseCacheEntry{},
+		lru:     list.New(),
+	}
+
+	// another mapper is working on the oldest entry when it's evicted
+	held := cache.get("held")
+	held.mu.Lock()
+	held.content = []byte("held")
+	cache.resize(held, estimatedEntrySize(held.content))
+
+	for i := 0; i < parseCacheMaxEntries-1; i++ {
+		cache.get(fmt.Sprintf("file-%d", i))
+	}
+
+	entry := cache.get("new")
+	entry.mu.Lock()
+	entry.content = []byte("new content")
+
+	done := make(chan struct{})
+	go func() {
+		cache.resize(entry, estimatedEntrySize(entry.content))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resize blocked on an entry locked by another mapper")
+	}
+	entry.unlock()
+
+	if !held.evicted.Load() {
+		t.Fatal("expected the oldest entry to be evicted")
+	}
+	if want := estimatedEntrySize([]byte("new content")); cache.totalBytes != want {
+		t.Errorf("expected %d cached bytes, got %d", want, cache.totalBytes)
+	}
+
+	// the evicted entry is reset once its mapper is done with it
+	held.unlock()
+	if held.content != nil {
+		t.Error("expected the evicted entry to be reset after it was unlocked")
+	}
+}
+
+func TestParseCacheLimitsEstimatedSize(t *testing.T) {
+	cache := &parseCache{
+		entries: map[string]*parseCacheEntry{},
+		lru:     list.New(),
+	}
+
+	// each file's source is well under the limit, but two of their trees aren't
+	content := make([]byte, parseCacheMaxBytes/parseCacheSizePerSourceByte/2+1)
+
+	first := cache.get("first")
+	first.mu.Lock()
+	first.content = content
+	cache.resize(first, estimatedEntrySize(content))
+	first.unlock()
+
+	second := cache.get("second")
+	second.mu.Lock()
+	second.content = content
+	cache.resize(second, estimatedEntrySize(content))
+	second.unlock()
+
+	if !first.evicted.Load() {
+		t.Fatal("expected the first entry to be evicted")
+	}
+	if cache.totalBytes != estimatedEntrySize(content) {
+		t.Errorf("expected %d cached bytes, got %d", estimatedEntrySize(content), cache.totalBytes)
+	}
+}
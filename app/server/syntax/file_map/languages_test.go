@@ -0,0 +1,202 @@
+package file_map
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"plandex-server/syntax"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapLanguageExamples(t *testing.T) {
+	tests := []struct {
+		example  string
+		wantMap  []string
+		defines  []string
+		imports  []string
+		notInMap []string
+		// the language is only mapped, so builds don't validate the file
+		mapOnly bool
+	}{
+		{
+			example: "sql_example.sql",
+			mapOnly: true,
+			wantMap: []string{
+				"CREATE TABLE users",
+				"email TEXT NOT NULL UNIQUE",
+				"user_id BIGINT NOT NULL REFERENCES users (id)",
+				"CREATE INDEX orders_user_id_idx ON orders (user_id)",
+				"CREATE VIEW active_users AS",
+				"CREATE FUNCTION order_total(uid BIGINT) RETURNS NUMERIC",
+			},
+			defines:  []string{"users", "orders", "active_users", "order_total"},
+			notInMap: []string{"INSERT INTO", "ALTER TABLE", "SELECT"},
+		},
+		{
+			example: "terraform_example.tf",
+			wantMap: []string{
+				"terraform",
+				`variable "region"`,
+				`module "network"`,
+				`resource "aws_instance" "web"`,
+				`data "aws_ami" "ubuntu"`,
+				`output "web_ip"`,
+			},
+			defines:  []string{"region", "network", "web", "ubuntu", "web_ip"},
+			imports:  []string{"./modules/network"},
+			notInMap: []string{"instance_type", "{"},
+		},
+		{
+			example: "hcl_example.hcl",
+			wantMap: []string{
+				`variable "environment"`,
+				"locals",
+				`resource "aws_security_group" "example"`,
+				`module "vpc"`,
+			},
+			defines: []string{"environment", "example", "vpc"},
+		},
+		{
+			example: "zig_example.zig",
+			mapOnly: true,
+			wantMap: []string{
+				"pub const Point = struct",
+				"pub const Color = enum",
+				"pub fn addPoints(a: Point, b: Point) Point",
+				`test "addPoints"`,
+			},
+			defines:  []string{"Point", "Color", "max_points", "addPoints"},
+			imports:  []string{"std", "config.zig"},
+			notInMap: []string{"fn length", "@import", "return"},
+		},
+		{
+			example: "dart_example.dart",
+			mapOnly: true,
+			wantMap: []string{
+				"class User",
+				"abstract class Repository<T>",
+				"enum Status",
+				"typedef Handler",
+				"Future<User> fetchUser(int id) async",
+				"void main()",
+			},
+			defines:  []string{"User", "Repository", "Status", "Handler", "fetchUser"},
+			imports:  []string{"dart:async", "package:http/http.dart"},
+			notInMap: []string{"String greet()", "final String name", "await"},
+		},
+		{
+			example: "r_example.R",
+			mapOnly: true,
+			wantMap: []string{
+				"summarize_scores <- function(df, group_col)",
+				"normalize = function(x)",
+				`setClass("Person"`,
+				`setGeneric("describe"`,
+			},
+			defines:  []string{"summarize_scores", "normalize", "Person", "describe"},
+			imports:  []string{"dplyr", "helpers.R"},
+			notInMap: []string{"inner_helper", "group_by"},
+		},
+		{
+			example: "julia_example.jl",
+			mapOnly: true,
+			wantMap: []string{
+				"module Geometry",
+				"abstract type Shape end",
+				"struct Circle <: Shape",
+				"mutable struct Canvas",
+				"function area(c::Circle)",
+				"perimeter(c::Circle) =",
+				"macro twice(ex)",
+			},
+			defines:  []string{"Geometry", "Shape", "Circle", "Canvas", "DEFAULT_RADIUS", "area", "perimeter", "twice"},
+			imports:  []string{"LinearAlgebra", "Base", "utils.jl"},
+			notInMap: []string{"inner =", "radius::Float64"},
+		},
+		{
+			example: "nix_example.nix",
+			mapOnly: true,
+			wantMap: []string{
+				"buildInputs = with pkgs;",
+				"mkService = name:",
+				"devShell = pkgs.mkShell",
+			},
+			defines:  []string{"buildInputs", "mkService", "devShell"},
+			imports:  []string{"./lib/helpers.nix", "./server.nix"},
+			notInMap: []string{"shellHook", "inherit"},
+		},
+		{
+			example: "haskell_example.hs",
+			mapOnly: true,
+			wantMap: []string{
+				"module Data.Shapes",
+				"data Shape",
+				"newtype Name",
+				"class Describable a where",
+				"instance Describable Shape where",
+				"area :: Shape -> Double",
+				"largest :: [Shape] -> Maybe Shape",
+			},
+			defines:  []string{"Shape", "Name", "Describable", "area", "largest"},
+			imports:  []string{"Data.Map", "Data.List"},
+			notInMap: []string{"measurements", "area (Circle r)", "describe ::"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.example, func(t *testing.T) {
+			path := filepath.Join("examples", tt.example)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read example: %v", err)
+			}
+
+			validation, err := syntax.ValidateFile(context.Background(), path, string(content))
+			if err != nil {
+				t.Fatalf("ValidateFile() error = %v", err)
+			}
+			if tt.mapOnly {
+				if validation.Parser != nil {
+					t.Errorf("expected no build parser for a map-only language")
+				}
+			} else if validation.Parser == nil || !validation.Valid {
+				t.Errorf("expected the example to parse without errors: %v", validation.Errors)
+			}
+
+			fileMap, err := MapFile(context.Background(), path, content)
+			if err != nil {
+				t.Fatalf("MapFile() error = %v", err)
+			}
+			res := fileMap.String()
+
+			for _, want := range tt.wantMap {
+				if !strings.Contains(res, want) {
+					t.Errorf("map is missing %q:\n%s", want, res)
+				}
+			}
+			for _, unwanted := range tt.notInMap {
+				if strings.Contains(res, unwanted) {
+					t.Errorf("map shouldn't include %q:\n%s", unwanted, res)
+				}
+			}
+
+			if fileMap.Deps == nil {
+				t.Fatalf("MapFile() deps = nil")
+			}
+			defines := map[string]bool{}
+			for _, name := range fileMap.Deps.Defines {
+				defines[name] = true
+			}
+			for _, name := range tt.defines {
+				if !defines[name] {
+					t.Errorf("defines = %v, missing %s", fileMap.Deps.Defines, name)
+				}
+			}
+			if tt.imports != nil && !reflect.DeepEqual(fileMap.Deps.Imports, tt.imports) {
+				t.Errorf("imports = %v, want %v", fileMap.Deps.Imports, tt.imports)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package file_map
+
+import (
+	"regexp"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// line-based mapping for languages without a tree-sitter grammar. Only top-level lines are matched, since without a syntax tree there's no reliable way to tell nested definitions from code.
+
+type lineRule struct {
+	defType string
+	re      *regexp.Regexp
+	// the submatch holding the defined name, or 0 if the rule doesn't define a name
+	nameGroup int
+}
+
+type lineLanguage struct {
+	rules   []lineRule
+	imports []*regexp.Regexp // the first submatch is the import specifier
+	comment []string         // line comment prefixes
+	// how far a top-level definition can be indented, e.g. 2 for the attributes of a nix file's top-level set
+	maxIndent int
+	// where the signature ends, e.g. at the opening brace of a body
+	bodyStart string
+}
+
+var lineLanguages = map[shared.Language]lineLanguage{
+	shared.LanguageZig: {
+		rules: []lineRule{
+			{"struct", regexp.MustCompile(`^(?:pub\s+)?const\s+(\w+)\s*=\s*(?:extern\s+|packed\s+)?(?:struct|enum|union|opaque|error)\b`), 1},
+			{"function", regexp.MustCompile(`^(?:pub\s+)?(?:export\s+|extern\s+|inline\s+)*fn\s+(\w+)`), 1},
+			{"test", regexp.MustCompile(`^test\s+"[^"]*"`), 0},
+			{"variable", regexp.MustCompile(`^(?:pub\s+)?(?:const|var)\s+(\w+)`), 1},
+		},
+		imports:   []*regexp.Regexp{regexp.MustCompile(`@import\(\s*"([^"]+)"\s*\)`)},
+		comment:   []string{"///", "//!", "//"},
+		bodyStart: "{",
+	},
+	shared.LanguageDart: {
+		rules: []lineRule{
+			{"class", regexp.MustCompile(`^(?:(?:abstract|sealed|base|final|interface|mixin)\s+)*(?:class|mixin|enum|extension)\s+(\w+)`), 1},
+			{"typedef", regexp.MustCompile(`^typedef\s+(\w+)`), 1},
+			{"function", regexp.MustCompile(`^(?:[\w<>?,\[\]]+(?:<[^>]*>)?\??\s+)?(?:get\s+|set\s+)?([a-zA-Z_]\w*)\s*(?:<[^>]*>)?\s*\(`), 1},
+			{"variable", regexp.MustCompile(`^(?:const|final|var|late)\s+(?:[\w<>?,]+\s+)?(\w+)\s*=`), 1},
+		},
+		imports:   []*regexp.Regexp{regexp.MustCompile(`^(?:import|export|part)\s+['"]([^'"]+)['"]`)},
+		comment:   []string{"///", "//"},
+		bodyStart: "{",
+	},
+	shared.LanguageR: {
+		rules: []lineRule{
+			{"function", regexp.MustCompile(`^([\w.]+)\s*(?:<-|<<-|=)\s*function\s*\(`), 1},
+			{"class", regexp.MustCompile(`^(?:([\w.]+)\s*(?:<-|=)\s*)?(?:setClass|setRefClass|R6Class|R6::R6Class)\(\s*["']?([\w.]*)`), 2},
+			{"generic", regexp.MustCompile(`^set(?:Generic|Method)\(\s*["']([\w.]+)["']`), 1},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*(?:library|require|requireNamespace)\(\s*["']?([\w.]+)`),
+			regexp.MustCompile(`^\s*source\(\s*["']([^"']+)["']`),
+		},
+		comment:   []string{"#'", "#"},
+		bodyStart: "{",
+	},
+	shared.LanguageJulia: {
+		rules: []lineRule{
+			{"module", regexp.MustCompile(`^(?:bare)?module\s+(\w+)`), 1},
+			{"struct", regexp.MustCompile(`^(?:mutable\s+)?struct\s+(\w+)`), 1},
+			{"type", regexp.MustCompile(`^(?:abstract|primitive)\s+type\s+(\w+)`), 1},
+			{"function", regexp.MustCompile(`^(?:function|macro)\s+(?:[\w.]+\.)?(\w+!?)`), 1},
+			{"function", regexp.MustCompile(`^(\w+!?)(?:\{[^}]*\})?\([^)]*\)\s*(?:::\s*\S+\s*)?=[^=]`), 1},
+			{"const", regexp.MustCompile(`^const\s+(\w+)`), 1},
+		},
+		imports: []*regexp.Regexp{
+			regexp.MustCompile(`^\s*(?:using|import)\s+([\w.]+)`),
+			regexp.MustCompile(`^\s*include\(\s*"([^"]+)"`),
+		},
+		comment: []string{"#"},
+	},
+	shared.LanguageNix: {
+		rules: []lineRule{
+			{"function", regexp.MustCompile(`^\s*([\w.-]+)\s*=\s*(?:\{[^}]*\}|\w+)\s*:`), 1},
+			{"key", regexp.MustCompile(`^\s*([\w.-]+)\s*=`), 1},
+		},
+		imports:   []*regexp.Regexp{regexp.MustCompile(`\b(?:import|callPackage)\s+(\.{0,2}/[\w./-]+)`)},
+		comment:   []string{"#"},
+		maxIndent: 2,
+	},
+	shared.LanguageHaskell: {
+		rules: []lineRule{
+			{"module", regexp.MustCompile(`^module\s+([\w.]+)`), 0},
+			{"type", regexp.MustCompile(`^(?:data|newtype|type(?:\s+family)?)\s+(\w+)`), 1},
+			{"class", regexp.MustCompile(`^class\s+(?:\(.*\)\s*=>\s*|\S+\s*=>\s*)?(\w+)`), 1},
+			{"instance", regexp.MustCompile(`^instance\s+`), 0},
+			{"function", regexp.MustCompile(`^([a-z_][\w']*)\s*::`), 1},
+		},
+		imports: []*regexp.Regexp{regexp.MustCompile(`^import\s+(?:qualified\s+)?([\w.]+)`)},
+		comment: []string{"--"},
+	},
+}
+
+// mapLines maps a file in a language from lineLanguages, returning its top-level definitions and deps
+func mapLines(content []byte, lang shared.Language) ([]Definition, *shared.FileMapDeps) {
+	config := lineLanguages[lang]
+	deps := &shared.FileMapDeps{}
+	var defs []Definition
+
+	importSet := map[string]bool{}
+	defineSet := map[string]bool{}
+
+	var comments []string
+	inBlockComment := false
+
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		isImport := false
+		for _, re := range config.imports {
+			for _, match := range re.FindAllStringSubmatch(line, -1) {
+				isImport = true
+				if len(deps.Imports) < maxDepsPerFile && !importSet[match[1]] {
+					importSet[match[1]] = true
+					deps.Imports = append(deps.Imports, match[1])
+				}
+			}
+		}
+
+		// haskell's {- -} and dart's /* */ comments aren't mapped
+		if inBlockComment {
+			if strings.Contains(trimmed, "-}") || strings.Contains(trimmed, "*/") {
+				inBlockComment = false
+			}
+			continue
+		}
+		if (strings.HasPrefix(trimmed, "{-") && !strings.Contains(trimmed, "-}")) || (strings.HasPrefix(trimmed, "/*") && !strings.Contains(trimmed, "*/")) {
+			inBlockComment = true
+			continue
+		}
+
+		if trimmed == "" {
+			comments = nil
+			continue
+		}
+
+		if isLineComment(trimmed, config.comment) {
+			comments = append(comments, trimmed)
+			continue
+		}
+
+		// imports bound to a name, like zig's `const std = @import("std");`, are deps rather than definitions
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if isImport || indent > config.maxIndent {
+			comments = nil
+			continue
+		}
+
+		for _, rule := range config.rules {
+			match := rule.re.FindStringSubmatch(trimmed)
+			if match == nil {
+				continue
+			}
+
+			def := Definition{
+				Type:      rule.defType,
+				Signature: lineSignature(trimmed, config.bodyStart),
+				Comments:  comments,
+				Line:      i + 1,
+			}
+			defs = append(defs, def)
+
+			if rule.nameGroup > 0 && rule.nameGroup < len(match) {
+				name := match[rule.nameGroup]
+				if len(name) >= minDepsNameLen && !defineSet[name] && len(deps.Defines) < maxDepsPerFile {
+					defineSet[name] = true
+					deps.Defines = append(deps.Defines, name)
+				}
+			}
+			break
+		}
+
+		comments = nil
+	}
+
+	return defs, deps
+}
+
+func isLineComment(line string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineSignature trims a definition's line to its header
+func lineSignature(line, bodyStart string) string {
+	if bodyStart != "" {
+		if i := strings.Index(line, bodyStart); i > 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+	}
+	line = strings.TrimSuffix(line, "{")
+	if len(line) > 200 {
+		line = line[:200] + "..."
+	}
+	return strings.TrimSpace(line)
+}
@@ -17,6 +17,7 @@ var verboseLogging = os.Getenv("VERBOSE_LOGGING") == "true"
 // FileMap represents a file's important definitions
 type FileMap struct {
 	Definitions []Definition
+	Deps        *shared.FileMapDeps // nil for languages without imports, like markup
 }
 
 type Definition struct {
@@ -64,6 +65,12 @@ func MapFile(ctx context.Context, filename string, content []byte) (*FileMap, er
 			return &FileMap{
 				Definitions: mapMarkdownSimple(content),
 			}, nil
+		case shared.LanguageZig, shared.LanguageDart, shared.LanguageR, shared.LanguageJulia, shared.LanguageNix, shared.LanguageHaskell:
+			defs, deps := mapLines(content, lang)
+			return &FileMap{
+				Definitions: defs,
+				Deps:        deps,
+			}, nil
 		default:
 			// return nil, fmt.Errorf("unsupported file type: %s", ext)
 			return &FileMap{
@@ -122,12 +129,14 @@ func MapFile(ctx context.Context, filename string, content []byte) (*FileMap, er
 		if fallbackTree.RootNode().Type() != "error" {
 			return &FileMap{
 				Definitions: mapNode(fallbackTree.RootNode(), content, fallbackLang),
+				Deps:        mapNodeDeps(fallbackTree.RootNode(), content, fallbackLang),
 			}, nil
 		}
 	}
 
 	return &FileMap{
 		Definitions: mapNode(tree.RootNode(), content, lang),
+		Deps:        mapNodeDeps(tree.RootNode(), content, lang),
 	}, nil
 
 }
@@ -147,6 +156,15 @@ func mapNode(node *tree_sitter.Node, content []byte, lang shared.Language) []Def
 	}
 }
 
+func mapNodeDeps(node *tree_sitter.Node, content []byte, lang shared.Language) *shared.FileMapDeps {
+	switch lang {
+	case shared.LanguageHtml, shared.LanguageSvelte, shared.LanguageDockerfile:
+		return nil
+	default:
+		return mapDeps(node, content, lang)
+	}
+}
+
 // For traditional programming languages
 func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 	var defs []Definition
@@ -163,170 +181,177 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 				Bytes:  baseNode.Bytes,
 			}
 
-			if isIncludeAndContinueNode(node) {
-				if verboseLogging {
-					fmt.Println("include and continue node", cursor.CurrentNode().Type())
-				}
-				if !cursor.GoToNextSibling() {
-					break
-				}
-				continue
+			if isTransparentNode(node) {
+				defs = append(defs, mapTraditional(node, parentNode)...)
+			} else if def := mapTraditionalChild(node, parentNode); def != nil {
+				defs = append(defs, *def)
 			}
 
-			if verboseLogging {
-				fmt.Println()
-				fmt.Println("node", node.Type)
-				// fmt.Println("content", string(node.Content(content)))
-				fmt.Println()
+			if !cursor.GoToNextSibling() {
+				break
 			}
+		}
+	}
 
-			// Check if this is a definition node
-			if isDefinitionNode(node, parentNode) {
-				if verboseLogging {
-					fmt.Println("definition node", node.Type)
-				}
+	return defs
+}
 
-				def := Definition{
-					Type: node.Type,
-					Line: int(tsNode.StartPoint().Row) + 1,
-				}
+// mapTraditionalChild maps a single child of a traditional language node, returning nil if it isn't a definition
+func mapTraditionalChild(node Node, parentNode *Node) *Definition {
+	if isIncludeAndContinueNode(node) {
+		if verboseLogging {
+			fmt.Println("include and continue node", node.Type)
+		}
+		return nil
+	}
 
-				if isAssignmentNode(node) {
-					if verboseLogging {
-						fmt.Println("assignment node", node.Type)
-					}
-					// Try different field names for identifiers
-					// fmt.Printf("assignment node: %s\n", node.Type)
-					sig := ""
+	if verboseLogging {
+		fmt.Println()
+		fmt.Println("node", node.Type)
+		// fmt.Println("content", string(node.Content(content)))
+		fmt.Println()
+	}
 
-					assignmentBoundary := findAssignmentBoundary(node)
+	// Check if this is a definition node
+	if isDefinitionNode(node, parentNode) {
+		if verboseLogging {
+			fmt.Println("definition node", node.Type)
+		}
 
-					if assignmentBoundary != nil {
-						start := tsNode.StartByte()
-						end := assignmentBoundary.TsNode.StartByte()
-						sig = string(node.Bytes[start:end])
-						sig = strings.TrimSuffix(strings.TrimSpace(sig), "=")
-					} else {
-						identifiers := findIdentifier(node)
-						if len(identifiers) > 0 {
-							if verboseLogging {
-								fmt.Println("found identifiers", len(identifiers))
-							}
-
-							start := tsNode.StartByte()
-							end := identifiers[len(identifiers)-1].TsNode.EndByte()
-							sig = string(node.Bytes[start:end])
-						} else {
-							if verboseLogging {
-								fmt.Println("no identifier found", node.Type)
-							}
-							sig = string(node.TsNode.Content(node.Bytes)) + " "
-						}
+		def := Definition{
+			Type: node.Type,
+			Line: int(node.TsNode.StartPoint().Row) + 1,
+		}
+
+		if isAssignmentNode(node) {
+			if verboseLogging {
+				fmt.Println("assignment node", node.Type)
+			}
+			// Try different field names for identifiers
+			// fmt.Printf("assignment node: %s\n", node.Type)
+			sig := ""
+
+			assignmentBoundary := findAssignmentBoundary(node)
+
+			if assignmentBoundary != nil {
+				start := node.TsNode.StartByte()
+				end := assignmentBoundary.TsNode.StartByte()
+				sig = string(node.Bytes[start:end])
+				sig = strings.TrimSuffix(strings.TrimSpace(sig), "=")
+			} else {
+				identifiers := findIdentifier(node)
+				if len(identifiers) > 0 {
+					if verboseLogging {
+						fmt.Println("found identifiers", len(identifiers))
 					}
 
-					def.Signature = sig
-				} else if isPassThroughParentNode(node) {
+					start := node.TsNode.StartByte()
+					end := identifiers[len(identifiers)-1].TsNode.EndByte()
+					sig = string(node.Bytes[start:end])
+				} else {
 					if verboseLogging {
-						fmt.Println("pass through parent node", node.Type)
+						fmt.Println("no identifier found", node.Type)
 					}
+					sig = string(node.TsNode.Content(node.Bytes)) + " "
+				}
+			}
 
-					start := tsNode.StartByte()
+			def.Signature = sig
+		} else if isPassThroughParentNode(node) {
+			if verboseLogging {
+				fmt.Println("pass through parent node", node.Type)
+			}
 
-					firstChild := firstDefinitionChild(node)
-					if firstChild != nil {
-						if verboseLogging {
-							fmt.Println("firstChild", firstChild.Type)
-						}
-						end := firstChild.TsNode.StartByte()
-						def.Signature = string(node.Bytes[start:end])
+			start := node.TsNode.StartByte()
 
-						if verboseLogging {
-							fmt.Println("got pass through parent signature", def.Signature)
-							fmt.Println("recursing into first child", firstChild.Type)
-						}
+			firstChild := firstDefinitionChild(node)
+			if firstChild != nil {
+				if verboseLogging {
+					fmt.Println("firstChild", firstChild.Type)
+				}
+				end := firstChild.TsNode.StartByte()
+				def.Signature = string(node.Bytes[start:end])
 
-						def.Children = mapTraditional(node, nil)
-					} else {
-						if verboseLogging {
-							fmt.Println("no first child found", node.Type)
-						}
-					}
+				if verboseLogging {
+					fmt.Println("got pass through parent signature", def.Signature)
+					fmt.Println("recursing into first child", firstChild.Type)
+				}
 
-				} else {
+				def.Children = mapTraditional(node, nil)
+			} else {
+				if verboseLogging {
+					fmt.Println("no first child found", node.Type)
+				}
+			}
+
+		} else {
+			if verboseLogging {
+				fmt.Println("not assignment node", node.Type)
+				fmt.Println("looking for implementation boundary")
+			}
+			// Get signature (up to body)
+			if body := findImplementationBoundary(node); body != nil {
+				if verboseLogging {
+					fmt.Println("found implementation boundary", body.Type)
+				}
+
+				start := node.TsNode.StartByte()
+				var end uint32
+				if node.TsNode == body.TsNode {
 					if verboseLogging {
-						fmt.Println("not assignment node", node.Type)
-						fmt.Println("looking for implementation boundary")
+						fmt.Println("node == body")
 					}
-					// Get signature (up to body)
-					if body := findImplementationBoundary(node); body != nil {
-						if verboseLogging {
-							fmt.Println("found implementation boundary", body.Type)
-						}
-
-						start := tsNode.StartByte()
-						var end uint32
-						if tsNode == body.TsNode {
-							if verboseLogging {
-								fmt.Println("node == body")
-							}
-							firstChild := firstDefinitionChild(*body)
-							if firstChild != nil {
-								if verboseLogging {
-									fmt.Println("firstChild != nil")
-									fmt.Println("firstChild", firstChild.Type)
-								}
-								end = firstChild.TsNode.StartByte()
-							} else {
-								if verboseLogging {
-									fmt.Println("firstChild == nil")
-								}
-								end = body.TsNode.EndByte()
-							}
-						} else {
-							end = body.TsNode.StartByte()
-						}
-						if verboseLogging {
-							fmt.Println("start", start)
-							fmt.Println("end", end)
-						}
-						def.Signature = string(node.Bytes[start:end])
+					firstChild := firstDefinitionChild(*body)
+					if firstChild != nil {
 						if verboseLogging {
-							fmt.Println("got signature", def.Signature)
-						}
-
-						// If this is a parent type node, recurse into the body
-						if isParentNode(node) {
-							if verboseLogging {
-								fmt.Println("isParentNode, recursing into body", node.Type)
-							}
-							def.Children = mapTraditional(*body, &node)
+							fmt.Println("firstChild != nil")
+							fmt.Println("firstChild", firstChild.Type)
 						}
+						end = firstChild.TsNode.StartByte()
 					} else {
 						if verboseLogging {
-							fmt.Println("no implementation boundary found", node.Type)
+							fmt.Println("firstChild == nil")
 						}
-						def.Signature = string(node.TsNode.Content(node.Bytes))
+						end = body.TsNode.EndByte()
 					}
+				} else {
+					end = body.TsNode.StartByte()
+				}
+				if verboseLogging {
+					fmt.Println("start", start)
+					fmt.Println("end", end)
+				}
+				def.Signature = string(node.Bytes[start:end])
+				if verboseLogging {
+					fmt.Println("got signature", def.Signature)
 				}
 
-				// Get preceding comments
-				// no comments for now to minimize tokens
-				// def.Comments = getPrecedingComments(node)
-
-				defs = append(defs, def)
+				// If this is a parent type node, recurse into the body
+				if isParentNode(node) {
+					if verboseLogging {
+						fmt.Println("isParentNode, recursing into body", node.Type)
+					}
+					def.Children = mapTraditional(*body, &node)
+				}
 			} else {
 				if verboseLogging {
-					fmt.Println("not definition node", node.Type)
+					fmt.Println("no implementation boundary found", node.Type)
 				}
-			}
-
-			if !cursor.GoToNextSibling() {
-				break
+				def.Signature = string(node.TsNode.Content(node.Bytes))
 			}
 		}
+
+		// Get preceding comments
+		// no comments for now to minimize tokens
+		// def.Comments = getPrecedingComments(node)
+
+		return &def
 	}
 
-	return defs
+	if verboseLogging {
+		fmt.Println("not definition node", node.Type)
+	}
+	return nil
 }
 
 // // Get preceding comments
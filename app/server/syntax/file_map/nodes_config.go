@@ -249,6 +249,13 @@ var assignmentNodeMap = nodeMap{
 			shared.LanguageSwift: true,
 		},
 	},
+
+	"attribute": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
 }
 
 var definitionNodeMap = nodeMap{
@@ -388,6 +395,18 @@ var definitionNodeMap = nodeMap{
 			shared.LanguageDockerfile: true,
 		},
 	},
+	"block": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
+	"create_": {
+		nodeMatch: matchTypePrefix,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
 
 	// Ignored patterns
 	"import_": {
@@ -581,6 +600,13 @@ var parentNodeMap = nodeMap{
 			"function_statement": true,
 		},
 	},
+
+	"create_table": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
 }
 
 var implBoundaryNodeMap = nodeMap{
@@ -591,6 +617,7 @@ var implBoundaryNodeMap = nodeMap{
 		except: langSet{
 			shared.LanguageRuby:   true,
 			shared.LanguageElixir: true,
+			shared.LanguageHcl:    true,
 		},
 	},
 	"body": {
@@ -701,6 +728,26 @@ var implBoundaryNodeMap = nodeMap{
 			shared.LanguageScala: true,
 		},
 	},
+
+	"block_start": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
+
+	"column_definitions": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
+	"create_query": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
 }
 
 var assignmentBoundaryNodeMap = nodeMap{
@@ -809,6 +856,22 @@ var passThroughParentNodeMap = nodeMap{
 	},
 }
 
+// nodes that only group other nodes -- their children are mapped in their place
+var transparentNodeMap = nodeMap{
+	"statement": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
+	"body": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageHcl: true,
+		},
+	},
+}
+
 var includeAndContinueNodeMap = nodeMap{
 	"template_parameter_list": {
 		nodeMatch: matchTypeEqual,
@@ -817,3 +880,123 @@ var includeAndContinueNodeMap = nodeMap{
 		},
 	},
 }
+
+// imports where the imported path is a string literal
+var importNodeMap = nodeMap{
+	"import_spec": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageGo: true,
+		},
+	},
+	"import_statement": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJavascript: true,
+			shared.LanguageTypescript: true,
+			shared.LanguageJsx:        true,
+			shared.LanguageTsx:        true,
+			shared.LanguageCss:        true,
+		},
+	},
+	"export_statement": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJavascript: true,
+			shared.LanguageTypescript: true,
+			shared.LanguageJsx:        true,
+			shared.LanguageTsx:        true,
+		},
+	},
+	"preproc_include": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageC:   true,
+			shared.LanguageCpp: true,
+		},
+	},
+	"require_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+	"require_once_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+	"include_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+	"include_once_expression": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+}
+
+// imports where the imported module is written as a path or qualified name, like a.b.c or a::b
+var importPathNodeMap = nodeMap{
+	"import_statement": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePython: true,
+		},
+	},
+	"import_from_statement": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePython: true,
+		},
+	},
+	"import_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageJava:  true,
+			shared.LanguageSwift: true,
+			shared.LanguageScala: true,
+		},
+	},
+	"import_header": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageKotlin: true,
+		},
+	},
+	"using_directive": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageCsharp: true,
+		},
+	},
+	"use_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageRust: true,
+		},
+	},
+	"mod_item": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageRust: true,
+		},
+	},
+	"import_clause": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageElm: true,
+		},
+	},
+	"namespace_use_declaration": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguagePhp: true,
+		},
+	},
+}
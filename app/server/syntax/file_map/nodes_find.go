@@ -90,6 +90,15 @@ func isPassThroughParentNode(node Node) bool {
 	return !config.ignore
 }
 
+func isTransparentNode(node Node) bool {
+	setNodeType(&node)
+	config := transparentNodeMap.getConfig(node.Type, node.Lang)
+	if config == nil {
+		return false
+	}
+	return !config.ignore
+}
+
 func isIncludeAndContinueNode(node Node) bool {
 	setNodeType(&node)
 	config := includeAndContinueNodeMap.getConfig(node.Type, node.Lang)
@@ -29,6 +29,7 @@ import (
 	"github.com/smacker/go-tree-sitter/ruby"
 	"github.com/smacker/go-tree-sitter/rust"
 	"github.com/smacker/go-tree-sitter/scala"
+	"github.com/smacker/go-tree-sitter/sql"
 	"github.com/smacker/go-tree-sitter/svelte"
 	"github.com/smacker/go-tree-sitter/swift"
 	"github.com/smacker/go-tree-sitter/toml"
@@ -89,6 +90,15 @@ func GetParserForPath(path string) (*tree_sitter.Parser, shared.Language, *tree_
 	return parser, lang, fallbackParser, fallback
 }
 
+// GetBuildParserForPath is GetParserForPath for validating and editing built files. It returns no parsers for languages whose grammars are only used for file maps.
+func GetBuildParserForPath(path string) (*tree_sitter.Parser, shared.Language, *tree_sitter.Parser, shared.Language) {
+	lang := GetLanguageForPath(path)
+	if shared.MapOnlyTreeSitter[lang] {
+		return nil, lang, nil, ""
+	}
+	return GetParserForPath(path)
+}
+
 func GetParserForLanguage(lang shared.Language) *tree_sitter.Parser {
 	parser := tree_sitter.NewParser()
 	switch lang {
@@ -140,6 +150,8 @@ func GetParserForLanguage(lang shared.Language) *tree_sitter.Parser {
 		parser.SetLanguage(rust.GetLanguage())
 	case shared.LanguageScala:
 		parser.SetLanguage(scala.GetLanguage())
+	case shared.LanguageSql:
+		parser.SetLanguage(sql.GetLanguage())
 	case shared.LanguageSvelte:
 		parser.SetLanguage(svelte.GetLanguage())
 	case shared.LanguageSwift:
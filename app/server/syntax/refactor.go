@@ -0,0 +1,277 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// how deep below a top-level node to look for the node that names a definition, e.g. export_statement > class_declaration, or type_declaration > type_spec
+const maxDefinitionNameDepth = 3
+
+// RenameSymbol renames oldName to newName within the scope of its definition and returns the updated content along with the number of identifiers renamed. A top-level definition, or one in another file, is renamed across the whole file, except where a nested definition shadows it. A nested definition is only renamed within the block it's defined in. Only identifier nodes are renamed, so strings, comments, and longer names that contain oldName are left alone, and so are members accessed through another receiver, like pkg.Get or obj.get, since without types there's no telling whether they refer to the renamed symbol.
+func RenameSymbol(ctx context.Context, parser *tree_sitter.Parser, content, oldName, newName string) (string, int, error) {
+	if oldName == "" || newName == "" {
+		return content, 0, fmt.Errorf("rename needs both an old and a new name")
+	}
+
+	// cheap check before parsing, since most files in a rename's scope won't mention the name
+	if !strings.Contains(content, oldName) {
+		return content, 0, nil
+	}
+
+	tree, err := parseForRefactor(ctx, parser, content)
+	if err != nil {
+		return content, 0, err
+	}
+	defer tree.Close()
+
+	bytes := []byte(content)
+	root := tree.RootNode()
+
+	var defScopes []*tree_sitter.Node
+	visitNodes(root, func(node *tree_sitter.Node) {
+		if !isDefinitionNode(node) {
+			return
+		}
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil || !isIdentifierNode(nameNode) || nameNode.Content(bytes) != oldName {
+			return
+		}
+		defScopes = append(defScopes, enclosingScope(node, root))
+	})
+
+	// with no definition in the file, the symbol is defined elsewhere and used at the top level
+	scopes := []*tree_sitter.Node{root}
+	var shadowed []*tree_sitter.Node
+	if len(defScopes) > 0 {
+		isTopLevel := false
+		for _, scope := range defScopes {
+			if scope.Equal(root) {
+				isTopLevel = true
+				break
+			}
+		}
+		if isTopLevel {
+			for _, scope := range defScopes {
+				if !scope.Equal(root) {
+					shadowed = append(shadowed, scope)
+				}
+			}
+		} else {
+			scopes = defScopes
+		}
+	}
+
+	var sb strings.Builder
+	last := 0
+	num := 0
+
+	visitNodes(root, func(node *tree_sitter.Node) {
+		if node.ChildCount() > 0 || !isIdentifierNode(node) {
+			return
+		}
+		if node.Content(bytes) != oldName {
+			return
+		}
+		if !containsNode(scopes, node) || containsNode(shadowed, node) {
+			return
+		}
+		if receiver := memberReceiver(node); receiver != nil {
+			if r := receiver.Content(bytes); r != "this" && r != "self" {
+				return
+			}
+		}
+
+		start := int(node.StartByte())
+		sb.WriteString(content[last:start])
+		sb.WriteString(newName)
+		last = int(node.EndByte())
+		num++
+	})
+
+	if num == 0 {
+		return content, 0, nil
+	}
+
+	sb.WriteString(content[last:])
+	return sb.String(), num, nil
+}
+
+// member access nodes by their receiver and member fields, e.g. go's selector_expression or js's member_expression
+var memberFields = []struct{ receiver, member string }{
+	{"operand", "field"},    // go selector_expression
+	{"package", "name"},     // go qualified_type
+	{"object", "property"},  // js/ts member_expression
+	{"object", "attribute"}, // python attribute
+	{"object", "field"},     // java field_access
+	{"object", "name"},      // java method_invocation
+	{"value", "field"},      // rust field_expression
+	{"path", "name"},        // rust scoped_identifier
+	{"argument", "field"},   // c/c++ field_expression
+	{"scope", "name"},       // c++ qualified_identifier
+	{"receiver", "method"},  // ruby call
+	{"expression", "name"},  // c# member_access_expression
+}
+
+// memberReceiver returns the receiver when node is the member of a member access, like Get in pkg.Get, or nil otherwise
+func memberReceiver(node *tree_sitter.Node) *tree_sitter.Node {
+	parent := node.Parent()
+	if parent == nil {
+		return nil
+	}
+	for _, fields := range memberFields {
+		member := parent.ChildByFieldName(fields.member)
+		if member == nil || !member.Equal(node) {
+			continue
+		}
+		if receiver := parent.ChildByFieldName(fields.receiver); receiver != nil {
+			return receiver
+		}
+	}
+	return nil
+}
+
+// isDefinitionNode matches the declaration node types used across tree-sitter grammars, e.g. function_declaration, class_definition, variable_declarator, type_spec, rust's function_item, and ruby's method -- other nodes with a name field, like python's keyword_argument, don't define anything
+func isDefinitionNode(node *tree_sitter.Node) bool {
+	t := node.Type()
+	for _, suffix := range []string{"declaration", "definition", "declarator", "_spec", "_item"} {
+		if strings.HasSuffix(t, suffix) {
+			return true
+		}
+	}
+	return t == "method" || t == "singleton_method" || t == "class" || t == "module"
+}
+
+// enclosingScope returns the nearest block or body around a definition, or root if it's defined at the top level
+func enclosingScope(def, root *tree_sitter.Node) *tree_sitter.Node {
+	for node := def.Parent(); node != nil; node = node.Parent() {
+		t := node.Type()
+		if strings.HasSuffix(t, "block") || strings.HasSuffix(t, "body") || strings.HasSuffix(t, "declaration_list") || t == "compound_statement" {
+			return node
+		}
+	}
+	return root
+}
+
+func containsNode(scopes []*tree_sitter.Node, node *tree_sitter.Node) bool {
+	for _, scope := range scopes {
+		if node.StartByte() >= scope.StartByte() && node.EndByte() <= scope.EndByte() {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractDefinition removes the top-level definition of name from content, along with the comments directly above it, and returns the remaining content and the removed definition
+func ExtractDefinition(ctx context.Context, parser *tree_sitter.Parser, content, name string) (string, string, error) {
+	tree, err := parseForRefactor(ctx, parser, content)
+	if err != nil {
+		return content, "", err
+	}
+	defer tree.Close()
+
+	bytes := []byte(content)
+	root := tree.RootNode()
+
+	var matches []*tree_sitter.Node
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+
+		named := findNamedNodes(child, bytes, 0)
+		for _, node := range named {
+			if node.ChildByFieldName("name").Content(bytes) != name {
+				continue
+			}
+			if len(named) > 1 {
+				return content, "", fmt.Errorf("%s is declared together with other definitions", name)
+			}
+			matches = append(matches, child)
+		}
+	}
+
+	if len(matches) == 0 {
+		return content, "", fmt.Errorf("no top-level definition of %s found", name)
+	}
+	if len(matches) > 1 {
+		return content, "", fmt.Errorf("found %d top-level definitions of %s", len(matches), name)
+	}
+
+	first := matches[0]
+	// include doc comments that end on the line directly above
+	for {
+		prev := first.PrevNamedSibling()
+		if prev == nil || !strings.Contains(prev.Type(), "comment") || prev.EndPoint().Row+1 != first.StartPoint().Row {
+			break
+		}
+		first = prev
+	}
+
+	start := int(first.StartByte())
+	for start > 0 && content[start-1] != '\n' {
+		start--
+	}
+	end := int(matches[0].EndByte())
+	for end < len(content) && content[end] != '\n' {
+		end++
+	}
+	if end < len(content) {
+		end++
+	}
+
+	definition := strings.TrimRight(content[start:end], "\n") + "\n"
+
+	before := strings.TrimRight(content[:start], "\n")
+	after := strings.TrimLeft(content[end:], "\n")
+
+	var remaining string
+	switch {
+	case before == "":
+		remaining = after
+	case after == "":
+		remaining = before + "\n"
+	default:
+		remaining = before + "\n\n" + after
+	}
+
+	return remaining, definition, nil
+}
+
+// findNamedNodes returns the nodes at or below node that have a 'name' field, without looking inside them
+func findNamedNodes(node *tree_sitter.Node, bytes []byte, depth int) []*tree_sitter.Node {
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil && isIdentifierNode(nameNode) {
+		return []*tree_sitter.Node{node}
+	}
+	if depth >= maxDefinitionNameDepth {
+		return nil
+	}
+
+	var res []*tree_sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		res = append(res, findNamedNodes(node.NamedChild(i), bytes, depth+1)...)
+	}
+	return res
+}
+
+// isIdentifierNode covers the identifier node types used across tree-sitter grammars, e.g. identifier, type_identifier, field_identifier, property_identifier, simple_identifier, and php's name or ruby's constant
+func isIdentifierNode(node *tree_sitter.Node) bool {
+	t := node.Type()
+	return strings.HasSuffix(t, "identifier") || t == "name" || t == "constant"
+}
+
+func parseForRefactor(ctx context.Context, parser *tree_sitter.Parser, content string) (*tree_sitter.Tree, error) {
+	if parser == nil {
+		return nil, fmt.Errorf("no parser for this file type")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, parserTimeout)
+	defer cancel()
+
+	tree, err := parser.ParseCtx(ctx, nil, []byte(content))
+	if err != nil || tree == nil {
+		return nil, fmt.Errorf("failed to parse the content: %v", err)
+	}
+	return tree, nil
+}
@@ -0,0 +1,280 @@
+package syntax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameSymbol(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		oldName string
+		newName string
+		want    string
+		wantNum int
+	}{
+		{
+			name: "go function and call sites",
+			path: "users.go",
+			content: `package db
+
+// getUser loads a user -- see getUserById for the id version
+func getUser(name string) *User {
+	return lookup("getUser", name)
+}
+
+func load() { getUser("a"); getUserById(1) }
+`,
+			oldName: "getUser",
+			newName: "loadUser",
+			want: `package db
+
+// getUser loads a user -- see getUserById for the id version
+func loadUser(name string) *User {
+	return lookup("getUser", name)
+}
+
+func load() { loadUser("a"); getUserById(1) }
+`,
+			wantNum: 2,
+		},
+		{
+			name: "typescript type and property access",
+			path: "api.ts",
+			content: `export class Api {}
+
+export function createApi(): Api {
+  const api: Api = new Api();
+  return api;
+}
+`,
+			oldName: "Api",
+			newName: "Client",
+			want: `export class Client {}
+
+export function createApi(): Client {
+  const api: Client = new Client();
+  return api;
+}
+`,
+			wantNum: 4,
+		},
+		{
+			name: "go member access through another package",
+			path: "handlers.go",
+			content: `package handlers
+
+func Get(id string) *User {
+	return db.Get(id)
+}
+
+func handle() {
+	u := Get("a")
+	cache.Get(u.Id)
+}
+`,
+			oldName: "Get",
+			newName: "GetUser",
+			want: `package handlers
+
+func GetUser(id string) *User {
+	return db.Get(id)
+}
+
+func handle() {
+	u := GetUser("a")
+	cache.Get(u.Id)
+}
+`,
+			wantNum: 2,
+		},
+		{
+			name: "typescript nested function",
+			path: "report.ts",
+			content: `function build() {
+  function format(s: string) {
+    return s.trim();
+  }
+  return format(" a ");
+}
+
+function print() {
+  return format("b");
+}
+`,
+			oldName: "format",
+			newName: "clean",
+			want: `function build() {
+  function clean(s: string) {
+    return s.trim();
+  }
+  return clean(" a ");
+}
+
+function print() {
+  return format("b");
+}
+`,
+			wantNum: 2,
+		},
+		{
+			name: "python top-level function shadowed in a nested scope",
+			path: "jobs.py",
+			content: `def run(job):
+    return job()
+
+
+def schedule(jobs):
+    def run(job):
+        return job
+    return [run(j) for j in jobs]
+
+
+run(schedule)
+`,
+			oldName: "run",
+			newName: "execute",
+			want: `def execute(job):
+    return job()
+
+
+def schedule(jobs):
+    def run(job):
+        return job
+    return [run(j) for j in jobs]
+
+
+execute(schedule)
+`,
+			wantNum: 2,
+		},
+		{
+			name:    "name not in file",
+			path:    "main.py",
+			content: "def main():\n    pass\n",
+			oldName: "run",
+			newName: "start",
+			want:    "def main():\n    pass\n",
+			wantNum: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, _, _, _ := GetParserForPath(tt.path)
+			got, num, err := RenameSymbol(context.Background(), parser, tt.content, tt.oldName, tt.newName)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantNum, num)
+		})
+	}
+}
+
+func TestExtractDefinition(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		content        string
+		symbol         string
+		wantRemaining  string
+		wantDefinition string
+		wantErr        bool
+	}{
+		{
+			name: "go function with doc comment",
+			path: "util.go",
+			content: `package util
+
+func a() {}
+
+// b does b
+// across two lines
+func b() int {
+	return 1
+}
+
+func c() {}
+`,
+			symbol: "b",
+			wantRemaining: `package util
+
+func a() {}
+
+func c() {}
+`,
+			wantDefinition: `// b does b
+// across two lines
+func b() int {
+	return 1
+}
+`,
+		},
+		{
+			name: "exported typescript function at the end of the file",
+			path: "util.ts",
+			content: `export const x = 1;
+
+export function format(s: string): string {
+  return s.trim();
+}
+`,
+			symbol:        "format",
+			wantRemaining: "export const x = 1;\n",
+			wantDefinition: `export function format(s: string): string {
+  return s.trim();
+}
+`,
+		},
+		{
+			name: "decorated python function",
+			path: "views.py",
+			content: `@route("/")
+def index():
+    return "hi"
+
+
+def other():
+    pass
+`,
+			symbol:        "index",
+			wantRemaining: "def other():\n    pass\n",
+			wantDefinition: `@route("/")
+def index():
+    return "hi"
+`,
+		},
+		{
+			name:    "declared together with another name",
+			path:    "vars.ts",
+			content: "export const a = 1, b = 2;\n",
+			symbol:  "a",
+			wantErr: true,
+		},
+		{
+			name:    "missing definition",
+			path:    "util.go",
+			content: "package util\n\nfunc a() {}\n",
+			symbol:  "b",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, _, _, _ := GetParserForPath(tt.path)
+			remaining, definition, err := ExtractDefinition(context.Background(), parser, tt.content, tt.symbol)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.content, remaining)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRemaining, remaining)
+			assert.Equal(t, tt.wantDefinition, definition)
+		})
+	}
+}
@@ -27,6 +27,11 @@ const (
 	NeedsVerifyReasonCodeRemoved       NeedsVerifyReason = "code_removed"
 	NeedsVerifyReasonCodeDuplicated    NeedsVerifyReason = "code_duplicated"
 	NeedsVerifyReasonAmbiguousLocation NeedsVerifyReason = "ambiguous_location"
+	// set by GuardComments -- comments or a file header were dropped without the change description asking for it, and were restored
+	NeedsVerifyReasonCommentsRemoved NeedsVerifyReason = "comments_removed"
+	NeedsVerifyReasonHeaderRemoved   NeedsVerifyReason = "header_removed"
+	// set by the build's semantic check rather than ApplyChanges -- a local type checker found new errors in the built file
+	NeedsVerifyReasonSemanticErrors NeedsVerifyReason = "semantic_errors"
 )
 
 type ApplyChangesResult struct {
@@ -51,6 +56,10 @@ type ReferenceBlock struct {
 type Comment struct {
 	Txt   string
 	IsRef bool
+	// 0-based rows, inclusive
+	StartLine int
+	EndLine   int
+	StartCol  int
 }
 
 type RemovalRange struct {
@@ -355,6 +364,22 @@ func ApplyChanges(
 		}
 	}
 
+	// put back comments and headers that were dropped without being asked for -- before the removal checks below so that restored lines don't count as removed
+	if parser != nil {
+		guardRes, err := GuardComments(ctx, GuardCommentsParams{
+			Original: original,
+			Updated:  res.NewFile,
+			Desc:     params.Desc,
+			Parser:   parser,
+		})
+		if err != nil {
+			log.Printf("ApplyChanges - error guarding comments: %v", err)
+		} else {
+			res.NewFile = guardRes.NewFile
+			res.NeedsVerifyReasons = append(res.NeedsVerifyReasons, guardRes.NeedsVerifyReasons...)
+		}
+	}
+
 	// we want to verify if any code was removed/replaced based on length comparison or description
 	// check if code was removed based on length comparison
 	if len(res.NewFile) < len(original) {
@@ -456,14 +481,6 @@ func ApplyChanges(
 	// 	}
 	// }
 
-	// if parser != nil {
-	// 	comments, err := FindComments(ctx, parser, proposed)
-	// 	if err != nil {
-	// 		log.Printf("ApplyChanges - error finding comments: %v", err)
-	// 	}
-	// 	res.Comments = comments
-	// }
-
 	if verboseLogging && len(res.BlocksRemoved) > 0 {
 		log.Printf("ApplyChanges - detected %d removed code blocks", len(res.BlocksRemoved))
 		for i, block := range res.BlocksRemoved {
@@ -23,7 +23,7 @@ type ValidationRes = struct {
 }
 
 func ValidateFile(ctx context.Context, path string, file string) (*ValidationRes, error) {
-	parser, lang, fallbackParser, fallbackLang := GetParserForPath(path)
+	parser, lang, fallbackParser, fallbackLang := GetBuildParserForPath(path)
 
 	if parser == nil {
 		return &ValidationRes{Lang: lang, Parser: nil}, nil
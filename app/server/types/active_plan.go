@@ -19,6 +19,9 @@ import (
 const MaxStreamRate = 70 * time.Millisecond
 const ActivePlanTimeout = 2 * time.Hour
 
+// max number of sent stream messages kept so that clients can resume after a disconnect
+const MaxReplayBufferSize = 2000
+
 type ActiveBuild struct {
 	ReplyId           string
 	FileDescription   string
@@ -32,6 +35,12 @@ type ActiveBuild struct {
 	MoveDestination   string
 	IsRemoveOp        bool
 	IsResetOp         bool
+	IsRenameOp        bool
+	IsMoveSymbolOp    bool
+	Symbol            string
+	NewSymbol         string
+	// the second half of a move symbol op -- FileContent is the moved definition, which is appended to the file
+	IsInsertSymbolOp bool
 }
 
 type subscription struct {
@@ -41,6 +50,13 @@ type subscription struct {
 	mu           sync.Mutex // Protects the messageQueue
 	messageQueue []string
 	cond         *sync.Cond // Used to wait for and signal new messages
+	// the stream of the cli that requested semantic checks -- other followers, like sse clients, can't run them
+	semanticChecker bool
+}
+
+type replayEntry struct {
+	seq int64
+	msg string
 }
 
 type ActivePlan struct {
@@ -81,6 +97,14 @@ type ActivePlan struct {
 	DidEditFiles          bool
 	SessionId             string
 
+	// started without a client connected to the stream (--bg)
+	IsBackground bool
+
+	// the client type-checks built files with local tools before each build finishes
+	SemanticCheck            bool
+	semanticCheckResponseChs map[string]chan shared.RespondSemanticCheckRequest
+	semanticCheckMu          sync.Mutex
+
 	subscriptions  map[string]*subscription
 	subscriptionMu sync.Mutex
 
@@ -88,6 +112,10 @@ type ActivePlan struct {
 	streamMu              sync.Mutex
 	lastStreamMessageSent time.Time
 	streamMessageBuffer   []shared.StreamMessage
+	streamSeq             int64
+
+	replayBuffer []replayEntry
+	replayMu     sync.Mutex
 }
 
 func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, autoContext bool, sessionId string) *ActivePlan {
@@ -99,34 +127,35 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 	summaryCtx, cancelSummary := context.WithCancel(shutdown.ShutdownCtx)
 
 	active := ActivePlan{
-		Id:                    planId,
-		OrgId:                 orgId,
-		UserId:                userId,
-		BuildOnly:             buildOnly,
-		Branch:                branch,
-		Prompt:                prompt,
-		Ctx:                   ctx,
-		CancelFn:              cancel,
-		ModelStreamCtx:        modelStreamCtx,
-		CancelModelStreamFn:   cancelModelStream,
-		SummaryCtx:            summaryCtx,
-		SummaryCancelFn:       cancelSummary,
-		BuildQueuesByPath:     map[string][]*ActiveBuild{},
-		Contexts:              []*db.Context{},
-		ContextsByPath:        map[string]*db.Context{},
-		Operations:            []*shared.Operation{},
-		BuiltFiles:            map[string]bool{},
-		IsBuildingByPath:      map[string]bool{},
-		StreamDoneCh:          make(chan *shared.ApiError),
-		MissingFileResponseCh: make(chan shared.RespondMissingFileChoice),
-		AutoContext:           autoContext,
-		AutoLoadContextCh:     make(chan struct{}),
-		AllowOverwritePaths:   map[string]bool{},
-		SkippedPaths:          map[string]bool{},
-		SessionId:             sessionId,
-		streamCh:              make(chan string),
-		subscriptions:         map[string]*subscription{},
-		subscriptionMu:        sync.Mutex{},
+		Id:                       planId,
+		OrgId:                    orgId,
+		UserId:                   userId,
+		BuildOnly:                buildOnly,
+		Branch:                   branch,
+		Prompt:                   prompt,
+		Ctx:                      ctx,
+		CancelFn:                 cancel,
+		ModelStreamCtx:           modelStreamCtx,
+		CancelModelStreamFn:      cancelModelStream,
+		SummaryCtx:               summaryCtx,
+		SummaryCancelFn:          cancelSummary,
+		BuildQueuesByPath:        map[string][]*ActiveBuild{},
+		Contexts:                 []*db.Context{},
+		ContextsByPath:           map[string]*db.Context{},
+		Operations:               []*shared.Operation{},
+		BuiltFiles:               map[string]bool{},
+		IsBuildingByPath:         map[string]bool{},
+		StreamDoneCh:             make(chan *shared.ApiError),
+		MissingFileResponseCh:    make(chan shared.RespondMissingFileChoice),
+		AutoContext:              autoContext,
+		AutoLoadContextCh:        make(chan struct{}),
+		AllowOverwritePaths:      map[string]bool{},
+		SkippedPaths:             map[string]bool{},
+		SessionId:                sessionId,
+		semanticCheckResponseChs: map[string]chan shared.RespondSemanticCheckRequest{},
+		streamCh:                 make(chan string),
+		subscriptions:            map[string]*subscription{},
+		subscriptionMu:           sync.Mutex{},
 	}
 
 	go func() {
@@ -190,7 +219,7 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 	ap.streamMu.Lock()
 
 	skipBuffer := false
-	if msg.Type == shared.StreamMessagePromptMissingFile || msg.Type == shared.StreamMessageLoadContext || msg.Type == shared.StreamMessageFinished || msg.Type == shared.StreamMessageError {
+	if msg.Type == shared.StreamMessagePromptMissingFile || msg.Type == shared.StreamMessageLoadContext || msg.Type == shared.StreamMessageSemanticCheck || msg.Type == shared.StreamMessageFinished || msg.Type == shared.StreamMessageError {
 		skipBuffer = true
 
 		log.Println("ActivePlan.Stream: skipping buffer for special message")
@@ -237,17 +266,6 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 	}
 
 	// Direct send path
-	msgJson, err := json.Marshal(msg)
-	if err != nil {
-		ap.streamMu.Unlock()
-		ap.StreamDoneCh <- &shared.ApiError{
-			Type:   shared.ApiErrorTypeOther,
-			Status: http.StatusInternalServerError,
-			Msg:    "Error marshalling stream message: " + err.Error(),
-		}
-		return
-	}
-
 	if skipBuffer && len(ap.streamMessageBuffer) > 0 {
 		// Handle any remaining buffered messages before sending the message
 		// log.Println("ActivePlan.Stream: message is a skip buffer type and there are buffered messages")
@@ -285,11 +303,27 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 		}
 	}
 
+	ap.streamSeq++
+	msg.Seq = ap.streamSeq
+
+	msgJson, err := json.Marshal(msg)
+	if err != nil {
+		ap.streamMu.Unlock()
+		ap.StreamDoneCh <- &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    "Error marshalling stream message: " + err.Error(),
+		}
+		return
+	}
+
 	if verboseStreamLogging {
 		log.Println("ActivePlan.Stream: sending direct message")
 		log.Println(string(msgJson))
 	}
 
+	ap.addToReplayBuffer(msg.Seq, string(msgJson))
+
 	ap.streamCh <- string(msgJson)
 
 	now := time.Now()
@@ -306,6 +340,37 @@ func (ap *ActivePlan) Stream(msg shared.StreamMessage) {
 	}
 }
 
+func (ap *ActivePlan) addToReplayBuffer(seq int64, msg string) {
+	ap.replayMu.Lock()
+	defer ap.replayMu.Unlock()
+
+	ap.replayBuffer = append(ap.replayBuffer, replayEntry{seq: seq, msg: msg})
+	if len(ap.replayBuffer) > MaxReplayBufferSize {
+		ap.replayBuffer = ap.replayBuffer[len(ap.replayBuffer)-MaxReplayBufferSize:]
+	}
+}
+
+// StreamMessagesSince returns the sent stream messages with a seq greater than the given seq, along with the seq of the last one. ok is false if the replay buffer no longer reaches back far enough to resume from seq.
+func (ap *ActivePlan) StreamMessagesSince(seq int64) (msgs []string, lastSeq int64, ok bool) {
+	ap.replayMu.Lock()
+	defer ap.replayMu.Unlock()
+
+	lastSeq = seq
+
+	if len(ap.replayBuffer) > 0 && ap.replayBuffer[0].seq > seq+1 {
+		return nil, lastSeq, false
+	}
+
+	for _, entry := range ap.replayBuffer {
+		if entry.seq > seq {
+			msgs = append(msgs, entry.msg)
+			lastSeq = entry.seq
+		}
+	}
+
+	return msgs, lastSeq, true
+}
+
 func (ap *ActivePlan) ResetModelCtx() {
 	ap.ModelStreamCtx, ap.CancelModelStreamFn = context.WithCancel(ap.Ctx)
 }
@@ -332,7 +397,7 @@ func (ap *ActivePlan) PathQueueEmpty(path string) bool {
 	return true
 }
 
-func (ap *ActivePlan) Subscribe(reqCtx context.Context) (string, chan string) {
+func (ap *ActivePlan) Subscribe(reqCtx context.Context, semanticChecker bool) (string, chan string) {
 	ap.subscriptionMu.Lock()
 	defer ap.subscriptionMu.Unlock()
 	id := uuid.New().String()
@@ -355,6 +420,7 @@ func (ap *ActivePlan) Subscribe(reqCtx context.Context) (string, chan string) {
 	}()
 
 	sub := newSubscription(subCtx)
+	sub.semanticChecker = semanticChecker
 
 	ap.subscriptions[id] = sub
 	return id, sub.ch
@@ -371,6 +437,21 @@ func (ap *ActivePlan) Unsubscribe(id string) {
 		sub.cond.Signal()
 		delete(ap.subscriptions, id)
 	}
+
+	if !ap.hasSemanticChecker() {
+		// no client is left to run pending semantic checks, so stop the builds waiting on them
+		ap.cancelSemanticChecks("client disconnected")
+	}
+}
+
+// hasSemanticChecker must be called with subscriptionMu held
+func (ap *ActivePlan) hasSemanticChecker() bool {
+	for _, sub := range ap.subscriptions {
+		if sub.semanticChecker {
+			return true
+		}
+	}
+	return false
 }
 
 func (ap *ActivePlan) NumSubscribers() int {
@@ -437,6 +518,57 @@ func (ap *ActivePlan) Finish() {
 	})
 }
 
+// WaitForSemanticCheck registers a pending semantic check and returns the channel its response will be sent on, along with a function that must be called once the caller stops waiting. It returns a nil channel if the client that requested semantic checks isn't subscribed to run the check. If that client unsubscribes while the check is pending, an error response is sent on the channel.
+func (ap *ActivePlan) WaitForSemanticCheck(id string) (chan shared.RespondSemanticCheckRequest, func()) {
+	// lock order matches Unsubscribe, so a check can't be registered after the checking client has gone
+	ap.subscriptionMu.Lock()
+	defer ap.subscriptionMu.Unlock()
+
+	if !ap.hasSemanticChecker() {
+		return nil, func() {}
+	}
+
+	ch := make(chan shared.RespondSemanticCheckRequest, 1)
+
+	ap.semanticCheckMu.Lock()
+	ap.semanticCheckResponseChs[id] = ch
+	ap.semanticCheckMu.Unlock()
+
+	return ch, func() {
+		ap.semanticCheckMu.Lock()
+		delete(ap.semanticCheckResponseChs, id)
+		ap.semanticCheckMu.Unlock()
+	}
+}
+
+func (ap *ActivePlan) cancelSemanticChecks(reason string) {
+	ap.semanticCheckMu.Lock()
+	defer ap.semanticCheckMu.Unlock()
+
+	for id, ch := range ap.semanticCheckResponseChs {
+		delete(ap.semanticCheckResponseChs, id)
+		ch <- shared.RespondSemanticCheckRequest{Id: id, Error: reason}
+	}
+}
+
+// RespondSemanticCheck passes a client's semantic check result to the build waiting on it. It returns false if no build is waiting, e.g. because the check timed out.
+func (ap *ActivePlan) RespondSemanticCheck(res shared.RespondSemanticCheckRequest) bool {
+	ap.semanticCheckMu.Lock()
+	defer ap.semanticCheckMu.Unlock()
+
+	ch, ok := ap.semanticCheckResponseChs[res.Id]
+	if !ok {
+		return false
+	}
+	delete(ap.semanticCheckResponseChs, res.Id)
+	ch <- res
+	return true
+}
+
 func (ab *ActiveBuild) IsFileOperation() bool {
 	return ab.IsMoveOp || ab.IsRemoveOp || ab.IsResetOp
 }
+
+func (ab *ActiveBuild) IsRefactorOp() bool {
+	return ab.IsRenameOp || ab.IsMoveSymbolOp || ab.IsInsertSymbolOp
+}
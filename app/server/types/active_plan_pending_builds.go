@@ -74,6 +74,11 @@ func (ap *ActivePlan) PendingBuildsByPath(orgId, userId string, convoMessagesArg
 					FileContentTokens: numTokens,
 					Path:              op.Path,
 					FileDescription:   op.Description,
+					MoveDestination:   op.Destination,
+					IsRenameOp:        op.Type == shared.OperationTypeRename,
+					IsMoveSymbolOp:    op.Type == shared.OperationTypeMoveSymbol,
+					Symbol:            op.Symbol,
+					NewSymbol:         op.NewSymbol,
 				})
 				numAdded++
 			}
@@ -7,7 +7,8 @@ import (
 )
 
 type ServerAuth struct {
-	AuthToken   *db.AuthToken
+	AuthToken   *db.AuthToken // nil when authenticated with an api token
+	ApiToken    *db.ApiToken
 	User        *db.User
 	OrgId       string
 	Permissions shared.Permissions
@@ -13,13 +13,14 @@ import (
 const verboseLogging = false
 
 type ReplyParserRes struct {
-	MaybeFilePath   string
-	CurrentFilePath string
-	IsInMoveBlock   bool
-	IsInRemoveBlock bool
-	IsInResetBlock  bool
-	Operations      []*shared.Operation
-	TotalTokens     int
+	MaybeFilePath     string
+	CurrentFilePath   string
+	IsInMoveBlock     bool
+	IsInRemoveBlock   bool
+	IsInResetBlock    bool
+	IsInRefactorBlock bool
+	Operations        []*shared.Operation
+	TotalTokens       int
 }
 
 type ReplyParser struct {
@@ -38,6 +39,7 @@ type ReplyParser struct {
 	isInMoveBlock             bool
 	isInRemoveBlock           bool
 	isInResetBlock            bool
+	isInRefactorBlock         bool
 }
 
 func NewReplyParser() *ReplyParser {
@@ -190,7 +192,7 @@ func (r *ReplyParser) AddChunk(chunk string, addToTotal bool) {
 
 	}
 
-	if r.maybeFilePath != "" && !r.isInMoveBlock && !r.isInRemoveBlock && !r.isInResetBlock {
+	if r.maybeFilePath != "" && !r.isInMoveBlock && !r.isInRemoveBlock && !r.isInResetBlock && !r.isInRefactorBlock {
 		if verboseLogging {
 			log.Println("Maybe file path is:", r.maybeFilePath) // Logging the maybeFilePath
 		}
@@ -212,7 +214,7 @@ func (r *ReplyParser) AddChunk(chunk string, addToTotal bool) {
 		}
 	}
 
-	if r.currentFilePath == "" && !r.isInMoveBlock && !r.isInRemoveBlock && !r.isInResetBlock {
+	if r.currentFilePath == "" && !r.isInMoveBlock && !r.isInRemoveBlock && !r.isInResetBlock && !r.isInRefactorBlock {
 		if verboseLogging {
 			log.Println("Current file path is empty--checking for possible file path...")
 		}
@@ -245,6 +247,11 @@ func (r *ReplyParser) AddChunk(chunk string, addToTotal bool) {
 				log.Println("Found reset block")
 			}
 			r.isInResetBlock = true
+		} else if prevFullLineTrimmed == "### Refactor" {
+			if verboseLogging {
+				log.Println("Found refactor block")
+			}
+			r.isInRefactorBlock = true
 		}
 
 		if gotPath != "" {
@@ -274,9 +281,9 @@ func (r *ReplyParser) AddChunk(chunk string, addToTotal bool) {
 			r.currentFileLines = append(r.currentFileLines, prevFullLine)
 
 		}
-	} else if r.isInMoveBlock || r.isInRemoveBlock || r.isInResetBlock {
+	} else if r.isInMoveBlock || r.isInRemoveBlock || r.isInResetBlock || r.isInRefactorBlock {
 		if verboseLogging {
-			log.Println("In move, remove, or reset block")
+			log.Println("In move, remove, reset, or refactor block")
 		}
 		if prevFullLineTrimmed == "<EndPlandexFileOps/>" {
 			if verboseLogging {
@@ -285,6 +292,7 @@ func (r *ReplyParser) AddChunk(chunk string, addToTotal bool) {
 			r.isInMoveBlock = false
 			r.isInRemoveBlock = false
 			r.isInResetBlock = false
+			r.isInRefactorBlock = false
 			r.operations = append(r.operations, r.pendingOperations...)
 			r.pendingOperations = []*shared.Operation{}
 			r.pendingPaths = map[string]bool{}
@@ -315,19 +323,32 @@ func (r *ReplyParser) AddChunk(chunk string, addToTotal bool) {
 				r.pendingOperations = append(r.pendingOperations, op)
 				r.pendingPaths[op.Path] = true
 			}
+		} else if r.isInRefactorBlock {
+			// a file can have several refactors, so these are deduplicated by the whole operation rather than the path
+			for _, op := range extractRefactorOps(prevFullLineTrimmed) {
+				if r.pendingPaths[op.Name()] {
+					continue
+				}
+				if verboseLogging {
+					log.Println("Found refactor operation")
+				}
+				r.pendingOperations = append(r.pendingOperations, op)
+				r.pendingPaths[op.Name()] = true
+			}
 		}
 	}
 }
 
 func (r *ReplyParser) Read() ReplyParserRes {
 	return ReplyParserRes{
-		MaybeFilePath:   r.maybeFilePath,
-		CurrentFilePath: r.currentFilePath,
-		Operations:      r.operations,
-		IsInMoveBlock:   r.isInMoveBlock,
-		IsInRemoveBlock: r.isInRemoveBlock,
-		IsInResetBlock:  r.isInResetBlock,
-		TotalTokens:     r.numTokens,
+		MaybeFilePath:     r.maybeFilePath,
+		CurrentFilePath:   r.currentFilePath,
+		Operations:        r.operations,
+		IsInMoveBlock:     r.isInMoveBlock,
+		IsInRemoveBlock:   r.isInRemoveBlock,
+		IsInResetBlock:    r.isInResetBlock,
+		IsInRefactorBlock: r.isInRefactorBlock,
+		TotalTokens:       r.numTokens,
 	}
 }
 
@@ -383,7 +404,7 @@ func (r *ReplyParser) GetReplyForMissingFile() string {
 }
 
 func (r *ReplyParserRes) FileOperationBlockOpen() bool {
-	return r.IsInMoveBlock || r.IsInRemoveBlock || r.IsInResetBlock
+	return r.IsInMoveBlock || r.IsInRemoveBlock || r.IsInResetBlock || r.IsInRefactorBlock
 }
 
 func LineHasXmlPath(line string) bool {
@@ -501,3 +522,55 @@ func extractRemoveOrResetFile(opType shared.OperationType, line string) *shared.
 		Path: path,
 	}
 }
+
+var backtickRegex = regexp.MustCompile("`([^`]+)`")
+
+// extractRefactorOps parses a line from a '### Refactor' section. A rename applies to each file it lists, so it's split into one operation per file, since builds run per path:
+//
+//   - rename `oldName` → `newName` in `a.go`, `b.go`
+//   - move `funcName` from `a.go` → `b.go`
+func extractRefactorOps(line string) []*shared.Operation {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "-") {
+		return nil
+	}
+
+	// Remove the leading dash and trim
+	line = strings.TrimPrefix(line, "-")
+	line = strings.TrimSpace(line)
+
+	if !strings.Contains(line, "→") {
+		return nil
+	}
+
+	var args []string
+	for _, match := range backtickRegex.FindAllStringSubmatch(line, -1) {
+		args = append(args, strings.TrimSpace(match[1]))
+	}
+
+	lower := strings.ToLower(line)
+
+	if strings.HasPrefix(lower, "rename ") && len(args) >= 3 {
+		var ops []*shared.Operation
+		for _, path := range args[2:] {
+			ops = append(ops, &shared.Operation{
+				Type:      shared.OperationTypeRename,
+				Path:      path,
+				Symbol:    args[0],
+				NewSymbol: args[1],
+			})
+		}
+		return ops
+	}
+
+	if strings.HasPrefix(lower, "move ") && len(args) == 3 {
+		return []*shared.Operation{{
+			Type:        shared.OperationTypeMoveSymbol,
+			Path:        args[1],
+			Symbol:      args[0],
+			Destination: args[2],
+		}}
+	}
+
+	return nil
+}
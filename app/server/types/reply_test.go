@@ -209,3 +209,44 @@ func TestReplyParser(t *testing.T) {
 		})
 	}
 }
+
+func TestReplyParserRefactor(t *testing.T) {
+	content := "Let's rename the helper and move it next to its callers.\n\n" +
+		"### Refactor\n" +
+		"- rename `getUser` → `loadUser` in `db/users.go`, `api/users.go`\n" +
+		"- move `loadUser` from `db/users.go` → `db/queries.go`\n" +
+		"- rename `getUser` → `loadUser` in `db/users.go`\n" +
+		"- not a refactor\n" +
+		"<EndPlandexFileOps/>\n\n" +
+		"Done.\n"
+
+	want := []shared.Operation{
+		{Type: shared.OperationTypeRename, Path: "db/users.go", Symbol: "getUser", NewSymbol: "loadUser"},
+		{Type: shared.OperationTypeRename, Path: "api/users.go", Symbol: "getUser", NewSymbol: "loadUser"},
+		{Type: shared.OperationTypeMoveSymbol, Path: "db/users.go", Symbol: "loadUser", Destination: "db/queries.go"},
+	}
+
+	parser := NewReplyParser()
+	for i := 0; i < len(content); i += 5 {
+		end := i + 5
+		if end > len(content) {
+			end = len(content)
+		}
+		parser.AddChunk(content[i:end], true)
+	}
+	res := parser.FinishAndRead()
+
+	if res.FileOperationBlockOpen() {
+		t.Errorf("Expected refactor block to be closed")
+	}
+
+	if len(res.Operations) != len(want) {
+		t.Fatalf("Expected %d operations, got %d", len(want), len(res.Operations))
+	}
+
+	for i, op := range res.Operations {
+		if op.Name() != want[i].Name() {
+			t.Errorf("Expected operation %s, got %s", want[i].Name(), op.Name())
+		}
+	}
+}
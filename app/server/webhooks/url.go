@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+)
+
+// carrier-grade NAT addresses aren't covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// allowLocalUrls is only true in local mode, where the server runs on the user's own machine and webhooks to local services are expected
+func allowLocalUrls() bool {
+	return os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1"
+}
+
+// ValidateUrl checks a webhook url before it's stored. Urls must use https, and a literal ip must be public. Hostnames are checked again when each delivery connects, since they can resolve to a different address later.
+func ValidateUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook url: %s", rawUrl)
+	}
+
+	if allowLocalUrls() {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("webhook url must use http or https: %s", rawUrl)
+		}
+		return nil
+	}
+
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use https: %s", rawUrl)
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIp(ip) {
+		return fmt.Errorf("webhook url can't point to a private or local address: %s", rawUrl)
+	}
+
+	return nil
+}
+
+func isPublicIp(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip) ||
+		(ip.To4() != nil && ip.To4()[0] == 0))
+}
+
+// dialer refuses connections to non-public addresses. The check runs on the address actually being connected to, after dns resolution, so a hostname can't pass validation and then resolve to an internal address.
+var dialer = &net.Dialer{
+	Timeout: requestTimeout,
+	Control: func(network, address string, c syscall.RawConn) error {
+		if allowLocalUrls() {
+			return nil
+		}
+
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+
+		ip := net.ParseIP(host)
+		if ip == nil || !isPublicIp(ip) {
+			return fmt.Errorf("webhook deliveries to %s aren't allowed", host)
+		}
+
+		return nil
+	},
+}
+
+func dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return dialer.DialContext(ctx, network, address)
+}
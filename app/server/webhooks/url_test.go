@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateUrl(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://hooks.example.com/plandex", true},
+		{"https://93.184.216.34/hook", true},
+		{"http://hooks.example.com/plandex", false},
+		{"ftp://hooks.example.com", false},
+		{"https://", false},
+		{"https://127.0.0.1/hook", false},
+		{"https://10.0.0.5/hook", false},
+		{"https://192.168.1.1/hook", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"https://100.64.0.1/hook", false},
+		{"https://0.0.0.0/hook", false},
+		{"https://[::1]/hook", false},
+		{"https://[fd00::1]/hook", false},
+		{"https://[::ffff:127.0.0.1]/hook", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateUrl(tt.url)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateUrl(%s): expected valid, got %v", tt.url, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("ValidateUrl(%s): expected an error", tt.url)
+		}
+	}
+}
+
+func TestClientRefusesLocalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	// the hostname passes validation, so only the dialer stops it
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://localhost:"+port, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Do(req)
+	if err == nil {
+		res.Body.Close()
+		t.Fatal("expected the request to a local address to be refused")
+	}
+}
@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"plandex-server/db"
+	"strconv"
+	"time"
+
+	shared "plandex-shared"
+)
+
+type PlanEventParams struct {
+	OrgId  string
+	UserId string
+	PlanId string
+	Branch string
+
+	Background bool
+	Error      string
+	FilePath   string
+	CommitMsg  string
+}
+
+// DispatchPlanEvent queues deliveries of a plan event to every webhook subscribed to it. It runs in the background so that plan streams and requests aren't held up.
+func DispatchPlanEvent(event shared.WebhookEvent, params PlanEventParams) {
+	go func() {
+		err := dispatchPlanEvent(event, params)
+		if err != nil {
+			log.Printf("Error dispatching webhook event %s for plan %s: %v\n", event, params.PlanId, err)
+		}
+	}()
+}
+
+func dispatchPlanEvent(event shared.WebhookEvent, params PlanEventParams) error {
+	plan, err := db.GetPlan(params.PlanId)
+	if err != nil {
+		return fmt.Errorf("error getting plan: %v", err)
+	}
+
+	if plan == nil {
+		return fmt.Errorf("plan not found")
+	}
+
+	webhooks, err := db.ListWebhooksForEvent(params.OrgId, plan.ProjectId, event)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload := shared.WebhookPayload{
+		Event: event,
+		OrgId: params.OrgId,
+		Plan: &shared.WebhookPlan{
+			Id:        plan.Id,
+			Name:      plan.Name,
+			Branch:    params.Branch,
+			ProjectId: plan.ProjectId,
+		},
+		UserId:     params.UserId,
+		Background: params.Background,
+		Error:      params.Error,
+		FilePath:   params.FilePath,
+		CommitMsg:  params.CommitMsg,
+		CreatedAt:  time.Now(),
+	}
+
+	if params.UserId != "" {
+		user, err := db.GetUser(params.UserId)
+		if err != nil {
+			return fmt.Errorf("error getting user: %v", err)
+		}
+		if user != nil {
+			payload.UserEmail = user.Email
+		}
+	}
+
+	payload.Text = summarize(&payload)
+
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	for _, webhook := range webhooks {
+		delivery := &db.WebhookDelivery{
+			WebhookId: webhook.Id,
+			OrgId:     params.OrgId,
+			Event:     event,
+			PlanId:    &plan.Id,
+			Payload:   string(bytes),
+		}
+
+		err = db.CreateWebhookDelivery(delivery)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Queued %d webhook deliveries for %s on plan %s\n", len(webhooks), event, plan.Id)
+
+	wake()
+
+	return nil
+}
+
+// Ping sends a test delivery to a webhook right away and returns the result. Failed pings aren't retried.
+func Ping(webhook *db.Webhook, userId, userEmail string) (*db.WebhookDelivery, error) {
+	payload := shared.WebhookPayload{
+		Event:     shared.WebhookEventPing,
+		OrgId:     webhook.OrgId,
+		UserId:    userId,
+		UserEmail: userEmail,
+		CreatedAt: time.Now(),
+	}
+	payload.Text = summarize(&payload)
+
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	delivery := &db.DueWebhookDelivery{
+		WebhookDelivery: db.WebhookDelivery{
+			WebhookId: webhook.Id,
+			OrgId:     webhook.OrgId,
+			Event:     shared.WebhookEventPing,
+			Payload:   string(bytes),
+		},
+		Url:    webhook.Url,
+		Secret: webhook.Secret,
+	}
+
+	err = db.CreateWebhookDelivery(&delivery.WebhookDelivery)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt(delivery, 1)
+
+	return &delivery.WebhookDelivery, nil
+}
+
+// Sign computes the signature header value for a delivery
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func summarize(payload *shared.WebhookPayload) string {
+	if payload.Plan == nil {
+		return fmt.Sprintf("Plandex webhook test from %s", payload.UserEmail)
+	}
+
+	plan := fmt.Sprintf("Plan '%s' (%s)", payload.Plan.Name, payload.Plan.Branch)
+
+	switch payload.Event {
+	case shared.WebhookEventPlanFinished:
+		return plan + " finished"
+	case shared.WebhookEventPlanStopped:
+		return plan + " was stopped"
+	case shared.WebhookEventExecFailed:
+		return fmt.Sprintf("%s failed: %s", plan, payload.Error)
+	case shared.WebhookEventBuildFailed:
+		return fmt.Sprintf("%s failed to build %s: %s", plan, payload.FilePath, payload.Error)
+	case shared.WebhookEventChangesApplied:
+		if payload.CommitMsg != "" {
+			return fmt.Sprintf("%s changes applied: %s", plan, payload.CommitMsg)
+		}
+		return plan + " changes applied"
+	}
+
+	return fmt.Sprintf("%s: %s", plan, payload.Event)
+}
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/shutdown"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const (
+	maxAttempts = 8
+
+	// retries back off exponentially from the base delay, up to the max
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = time.Hour
+
+	requestTimeout = 10 * time.Second
+	pollInterval   = 5 * time.Second
+	claimBatchSize = 20
+
+	// long enough for a claimed batch to be sent before another host can claim it again
+	claimLease = 5 * time.Minute
+
+	cleanupInterval = time.Hour
+
+	// how much of a failed response's body is kept in the delivery log
+	maxErrorBodyLen = 500
+)
+
+var client = &http.Client{
+	Timeout: requestTimeout,
+	// no proxy, so that the dialer's address check applies to the webhook's host itself
+	Transport: &http.Transport{
+		DialContext:         dialContext,
+		TLSHandshakeTimeout: requestTimeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	// don't follow redirects -- a redirect is reported as a failure so the url can be fixed
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+var wakeCh = make(chan struct{}, 1)
+
+// wake runs the worker right away rather than waiting for the next poll
+func wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// StartWorker sends due deliveries until the server shuts down. Every host runs a worker -- deliveries are claimed so that each is only sent by one of them.
+func StartWorker() {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		lastCleanup := time.Time{}
+
+		for {
+			select {
+			case <-shutdown.ShutdownCtx.Done():
+				log.Println("Webhook worker stopped")
+				return
+			case <-ticker.C:
+			case <-wakeCh:
+			}
+
+			sendDue()
+
+			if time.Since(lastCleanup) > cleanupInterval {
+				err := db.DeleteExpiredWebhookDeliveries()
+				if err != nil {
+					log.Printf("Error deleting expired webhook deliveries: %v\n", err)
+				}
+				lastCleanup = time.Now()
+			}
+		}
+	}()
+}
+
+func sendDue() {
+	for {
+		deliveries, err := db.ClaimDueWebhookDeliveries(claimBatchSize, claimLease)
+		if err != nil {
+			log.Printf("Error claiming webhook deliveries: %v\n", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			attempt(delivery, maxAttempts)
+		}
+
+		if len(deliveries) < claimBatchSize || shutdown.ShutdownCtx.Err() != nil {
+			return
+		}
+	}
+}
+
+// attempt sends a delivery and records the result, scheduling a retry if it failed and attempts remain
+func attempt(delivery *db.DueWebhookDelivery, attempts int) {
+	delivery.Attempts++
+
+	statusCode, retryable, err := send(delivery)
+
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	} else {
+		delivery.LastStatusCode = nil
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = shared.WebhookDeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	} else {
+		log.Printf("Webhook delivery %s attempt %d failed: %v\n", delivery.Id, delivery.Attempts, err)
+
+		delivery.LastError = err.Error()
+
+		if retryable && delivery.Attempts < attempts {
+			next := time.Now().Add(retryDelay(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		} else {
+			delivery.Status = shared.WebhookDeliveryStatusFailed
+			delivery.NextAttemptAt = nil
+		}
+	}
+
+	err = db.RecordWebhookDeliveryAttempt(&delivery.WebhookDelivery)
+	if err != nil {
+		log.Printf("Error recording webhook delivery %s: %v\n", delivery.Id, err)
+	}
+}
+
+// send posts a delivery and returns the response status, and whether a failure is worth retrying
+func send(delivery *db.DueWebhookDelivery) (int, bool, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now()
+
+	req, err := http.NewRequestWithContext(shutdown.ShutdownCtx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Plandex-Webhooks")
+	req.Header.Set(shared.WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(shared.WebhookDeliveryHeader, delivery.Id)
+	req.Header.Set(shared.WebhookTimestampHeader, fmt.Sprintf("%d", timestamp.Unix()))
+	req.Header.Set(shared.WebhookSignatureHeader, Sign(delivery.Secret, timestamp, body))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, true, fmt.Errorf("error sending request: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, false, nil
+	}
+
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyLen))
+	err = fmt.Errorf("received status %d: %s", res.StatusCode, strings.TrimSpace(string(resBody)))
+
+	// other client errors won't go away on their own
+	retryable := res.StatusCode >= 500 || res.StatusCode == http.StatusRequestTimeout || res.StatusCode == http.StatusTooManyRequests
+
+	return res.StatusCode, retryable, err
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,60 @@
+package shared
+
+type AuditAction string
+
+const (
+	AuditActionPlanCreate      AuditAction = "plan.create"
+	AuditActionPlanDelete      AuditAction = "plan.delete"
+	AuditActionPlanDeleteAll   AuditAction = "plan.delete_all"
+	AuditActionPlanRename      AuditAction = "plan.rename"
+	AuditActionPlanArchive     AuditAction = "plan.archive"
+	AuditActionPlanUnarchive   AuditAction = "plan.unarchive"
+	AuditActionPlanApply       AuditAction = "plan.apply"
+	AuditActionPlanRejectAll   AuditAction = "plan.reject_all"
+	AuditActionPlanRejectFiles AuditAction = "plan.reject_files"
+	AuditActionPlanRewind      AuditAction = "plan.rewind"
+	AuditActionPlanConfig      AuditAction = "plan.update_config"
+	AuditActionPlanSettings    AuditAction = "plan.update_settings"
+	AuditActionPlanShare       AuditAction = "plan.share"
+	AuditActionPlanUnshare     AuditAction = "plan.unshare"
+
+	AuditActionDefaultConfig AuditAction = "user.update_default_config"
+
+	AuditActionOrgCreate          AuditAction = "org.create"
+	AuditActionOrgRemoveUser      AuditAction = "org.remove_user"
+	AuditActionOrgDefaultSettings AuditAction = "org.update_default_settings"
+
+	AuditActionInviteCreate AuditAction = "invite.create"
+	AuditActionInviteDelete AuditAction = "invite.delete"
+
+	AuditActionOrgRoleCreate AuditAction = "org_role.create"
+	AuditActionOrgRoleUpdate AuditAction = "org_role.update"
+	AuditActionOrgRoleDelete AuditAction = "org_role.delete"
+
+	AuditActionApiTokenCreate       AuditAction = "api_token.create"
+	AuditActionApiTokenRevoke       AuditAction = "api_token.revoke"
+	AuditActionServiceAccountDelete AuditAction = "service_account.delete"
+
+	AuditActionWebhookCreate AuditAction = "webhook.create"
+	AuditActionWebhookUpdate AuditAction = "webhook.update"
+	AuditActionWebhookDelete AuditAction = "webhook.delete"
+
+	AuditActionPlanTemplateCreate AuditAction = "plan_template.create"
+	AuditActionPlanTemplateUpdate AuditAction = "plan_template.update"
+	AuditActionPlanTemplateDelete AuditAction = "plan_template.delete"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetPlan           AuditTargetType = "plan"
+	AuditTargetProject        AuditTargetType = "project"
+	AuditTargetOrg            AuditTargetType = "org"
+	AuditTargetUser           AuditTargetType = "user"
+	AuditTargetInvite         AuditTargetType = "invite"
+	AuditTargetOrgRole        AuditTargetType = "org_role"
+	AuditTargetApiToken       AuditTargetType = "api_token"
+	AuditTargetServiceAccount AuditTargetType = "service_account"
+	AuditTargetWebhook        AuditTargetType = "webhook"
+	AuditTargetPlanTemplate   AuditTargetType = "plan_template"
+)
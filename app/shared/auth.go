@@ -7,6 +7,12 @@ type AuthHeader struct {
 	OrgId string `json:"orgId"`
 }
 
+// api tokens start with this prefix so they can be told apart from session tokens -- they can be sent either in an AuthHeader or directly as the bearer token
+const ApiTokenPrefix = "pdx_"
+
+// env var the cli reads an api token from for non-interactive use (e.g. CI)
+const ApiTokenEnvVar = "PLANDEX_API_TOKEN"
+
 type ApiErrorType string
 
 const (
@@ -0,0 +1,70 @@
+package shared
+
+import "time"
+
+// the strategies a build can use to apply a file's proposed changes, in the order they're normally tried
+type BuilderStrategy string
+
+const (
+	// deterministic structured edits, with no model call
+	BuilderStrategyAutoApply BuilderStrategy = "auto_apply"
+	// the builder model validates the structured edits and fixes them with targeted replacements
+	BuilderStrategyValidate BuilderStrategy = "validate"
+	// the fast apply hook merges the proposed changes
+	BuilderStrategyFastApply BuilderStrategy = "fast_apply"
+	// the whole-file builder model rewrites the file
+	BuilderStrategyWholeFile BuilderStrategy = "whole_file"
+)
+
+var BuilderStrategies = []BuilderStrategy{
+	BuilderStrategyAutoApply,
+	BuilderStrategyValidate,
+	BuilderStrategyFastApply,
+	BuilderStrategyWholeFile,
+}
+
+// file sizes are bucketed by their token count before the build so stats for similar files are grouped together
+var BuilderSizeBuckets = []struct {
+	Name      string
+	MaxTokens int
+}{
+	{"xs", 1000},
+	{"s", 4000},
+	{"m", 16000},
+	{"l", 0},
+}
+
+func GetBuilderSizeBucket(numTokens int) string {
+	for _, bucket := range BuilderSizeBuckets {
+		if bucket.MaxTokens == 0 || numTokens <= bucket.MaxTokens {
+			return bucket.Name
+		}
+	}
+	return BuilderSizeBuckets[len(BuilderSizeBuckets)-1].Name
+}
+
+type BuilderStrategyStats struct {
+	Strategy BuilderStrategy `json:"strategy"`
+	Lang     string          `json:"lang"`
+	// empty for strategies that don't call a model from the model pack
+	Model          string    `json:"model"`
+	SizeBucket     string    `json:"sizeBucket"`
+	Attempts       int       `json:"attempts"`
+	Successes      int       `json:"successes"`
+	TotalLatencyMs int64     `json:"totalLatencyMs"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (s *BuilderStrategyStats) SuccessRate() float64 {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return float64(s.Successes) / float64(s.Attempts)
+}
+
+func (s *BuilderStrategyStats) AvgLatency() time.Duration {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return time.Duration(s.TotalLatencyMs/int64(s.Attempts)) * time.Millisecond
+}
@@ -0,0 +1,47 @@
+package shared
+
+// Output schemas for the CLI's --json flag. Commands whose output maps directly onto an existing type (ps, log, usage, etc.) output that type as is -- these cover the ones that combine or annotate data.
+
+type CliJsonPlan struct {
+	*Plan
+	IsCurrent     bool    `json:"isCurrent"`
+	CurrentBranch *Branch `json:"currentBranch,omitempty"`
+
+	// set for plans in parent or child directories of the current project
+	ProjectPath string `json:"projectPath,omitempty"`
+}
+
+type CliJsonCurrentPlan struct {
+	Plan   *Plan   `json:"plan"`
+	Branch *Branch `json:"branch"`
+}
+
+type CliJsonBranch struct {
+	*Branch
+	IsCurrent bool `json:"isCurrent"`
+}
+
+// CliJsonContext is the context list for 'plandex ls'. Context bodies are left out.
+type CliJsonContext struct {
+	Contexts      []*Context `json:"contexts"`
+	TotalTokens   int        `json:"totalTokens"`
+	MapTokens     int        `json:"mapTokens"`
+	ContextTokens int        `json:"contextTokens"`
+}
+
+type CliJsonFileDiff struct {
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+	// changes formatters will make on apply, listed after the plan's own changes
+	Formatting bool `json:"formatting,omitempty"`
+}
+
+type CliJsonAvailableModels struct {
+	BuiltIn []*AvailableModel `json:"builtIn"`
+	Custom  []*AvailableModel `json:"custom"`
+}
+
+type CliJsonModelPacks struct {
+	BuiltIn []*ModelPack `json:"builtIn"`
+	Custom  []*ModelPack `json:"custom"`
+}
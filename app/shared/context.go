@@ -12,7 +12,7 @@ import (
 const (
 	MaxContextBodySize           = 25 * 1024 * 1024 // 25MB
 	MaxContextCount              = 1000
-	MaxContextMapPaths           = 3000
+	MaxContextMapPaths           = 10000
 	MaxContextMapSingleInputSize = 500 * 1024             // 500KB
 	MaxContextMapTotalInputSize  = 250 * 1024 * 1024      // 250MB
 	MaxTotalContextSize          = 1 * 1024 * 1024 * 1024 // 1GB
@@ -46,6 +46,86 @@ type Invite struct {
 	CreatedAt  time.Time  `json:"createdAt"`
 }
 
+type ServiceAccount struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	OrgRoleId string    `json:"orgRoleId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ApiToken struct {
+	Id                 string       `json:"id"`
+	OrgId              string       `json:"orgId"`
+	UserId             string       `json:"userId"`
+	CreatorId          string       `json:"creatorId"`
+	Name               string       `json:"name"`
+	TokenPrefix        string       `json:"tokenPrefix"`
+	Permissions        []Permission `json:"permissions"`
+	ServiceAccountName string       `json:"serviceAccountName,omitempty"`
+	ExpiresAt          *time.Time   `json:"expiresAt"`
+	LastUsedAt         *time.Time   `json:"lastUsedAt"`
+	CreatedAt          time.Time    `json:"createdAt"`
+}
+
+type AuditLogEntry struct {
+	Id         string          `json:"id"`
+	Seq        int64           `json:"seq"`
+	OrgId      string          `json:"orgId"`
+	ActorId    string          `json:"actorId"`
+	ActorEmail string          `json:"actorEmail"`
+	ApiTokenId *string         `json:"apiTokenId,omitempty"`
+	Action     AuditAction     `json:"action"`
+	TargetType AuditTargetType `json:"targetType"`
+	TargetId   string          `json:"targetId"`
+	PlanId     *string         `json:"planId,omitempty"`
+	Before     string          `json:"before,omitempty"`
+	After      string          `json:"after,omitempty"`
+	ClientIp   string          `json:"clientIp"`
+	UserAgent  string          `json:"userAgent"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type Webhook struct {
+	Id          string         `json:"id"`
+	OrgId       string         `json:"orgId"`
+	ProjectId   *string        `json:"projectId,omitempty"`
+	CreatorId   string         `json:"creatorId"`
+	Url         string         `json:"url"`
+	Description string         `json:"description"`
+	Events      []WebhookEvent `json:"events"`
+	Disabled    bool           `json:"disabled"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+type WebhookDelivery struct {
+	Id             string                `json:"id"`
+	WebhookId      string                `json:"webhookId"`
+	Event          WebhookEvent          `json:"event"`
+	PlanId         *string               `json:"planId,omitempty"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode *int                  `json:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+type PlanTemplate struct {
+	Id          string              `json:"id"`
+	OrgId       string              `json:"orgId"`
+	CreatorId   string              `json:"creatorId"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Prompt      string              `json:"prompt"`
+	Context     PlanTemplateContext `json:"context"`
+	AutoMode    AutoModeType        `json:"autoMode,omitempty"`
+	ModelPack   string              `json:"modelPack,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
+}
+
 type Project struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
@@ -65,6 +145,21 @@ type Plan struct {
 	UpdatedAt       time.Time   `json:"updatedAt"`
 }
 
+type PlanShare struct {
+	Id     string `json:"id"`
+	PlanId string `json:"planId"`
+
+	// either UserId or ProjectId is set -- a project share applies to everyone working in the plan's project
+	UserId    *string `json:"userId,omitempty"`
+	UserEmail string  `json:"userEmail,omitempty"`
+	UserName  string  `json:"userName,omitempty"`
+	ProjectId *string `json:"projectId,omitempty"`
+
+	Level     PlanShareLevel `json:"level"`
+	CreatedBy string         `json:"createdBy"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
 type Branch struct {
 	Id              string     `json:"id"`
 	PlanId          string     `json:"planId"`
@@ -94,6 +189,15 @@ const (
 
 type FileMapBodies map[string]string
 
+// FileMapDeps holds the cross-file relationships extracted from a single file while mapping it
+type FileMapDeps struct {
+	Imports []string `json:"imports,omitempty"` // import/include specifiers as written in the source
+	Defines []string `json:"defines,omitempty"` // names of definitions in the file
+	Refs    []string `json:"refs,omitempty"`    // identifiers used in the file that it doesn't define itself
+}
+
+type FileMapDepsByPath map[string]*FileMapDeps
+
 type Context struct {
 	Id              string                `json:"id"`
 	OwnerId         string                `json:"ownerId"`
@@ -112,6 +216,7 @@ type Context struct {
 	MapTokens       map[string]int        `json:"mapTokens,omitempty"`
 	MapSizes        map[string]int64      `json:"mapSizes,omitempty"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
+	GeneratedReason GeneratedFileReason   `json:"generatedReason,omitempty"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	UpdatedAt       time.Time             `json:"updatedAt"`
 }
@@ -191,12 +296,18 @@ const (
 	OperationTypeMove   OperationType = "move"
 	OperationTypeRemove OperationType = "remove"
 	OperationTypeReset  OperationType = "reset"
+
+	// deterministic refactors, executed with tree-sitter rather than by the builder model
+	OperationTypeRename     OperationType = "rename"
+	OperationTypeMoveSymbol OperationType = "move_symbol"
 )
 
 type Operation struct {
 	Type        OperationType
 	Path        string
 	Destination string
+	Symbol      string
+	NewSymbol   string
 	Content     string
 	Description string
 	ReplyBefore string
@@ -205,6 +316,12 @@ type Operation struct {
 
 func (o *Operation) Name() string {
 	res := string(o.Type) + " | " + o.Path
+	if o.Symbol != "" {
+		res += " | " + o.Symbol
+	}
+	if o.NewSymbol != "" {
+		res += " → " + o.NewSymbol
+	}
 	if o.Destination != "" {
 		res += " → " + o.Destination
 	}
@@ -299,10 +416,11 @@ type CurrentPlanState struct {
 }
 
 type OrgRole struct {
-	Id          string `json:"id"`
-	IsDefault   bool   `json:"isDefault"`
-	Label       string `json:"label"`
-	Description string `json:"description"`
+	Id          string       `json:"id"`
+	IsDefault   bool         `json:"isDefault"`
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty"`
 }
 
 type CloudBillingFields struct {
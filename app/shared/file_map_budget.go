@@ -0,0 +1,286 @@
+package shared
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// share of the budget that file headings can use before the remaining files are only counted by directory
+	fileMapBudgetHeadingsShare = 0.5
+	// share of the budget held back for the directory summary of files that aren't listed
+	fileMapBudgetSummaryShare = 0.1
+	// top-level definitions kept when a file's map is collapsed
+	fileMapBudgetCollapsedLines = 8
+)
+
+const fileMapBudgetNote = "[This map was reduced to fit its token budget. Files are ranked by how much of the codebase depends on them, how recently they changed, and how close they are to files in context. Higher ranked files are mapped in full. Lower ranked files show only their most referenced definitions, or just their path. Files that didn't fit are counted by directory at the end.]\n"
+
+type FileMapBudgetParams struct {
+	Bodies    FileMapBodies
+	Tokens    map[string]int // token counts of the mapped files themselves, shown in headings
+	Deps      FileMapDepsByPath
+	ChangedAt map[string]int64
+	Focus     []string
+	MaxTokens int
+}
+
+type fileMapBudgetTier int
+
+const (
+	fileMapTierOmitted fileMapBudgetTier = iota
+	fileMapTierHeading
+	fileMapTierCollapsed
+	fileMapTierFull
+)
+
+// BudgetedMap combines the map like CombinedMap when it fits in MaxTokens (or MaxTokens is 0). Otherwise files are ranked with FileMapGraph.Rank and rendered in rank order at the most detail that still fits: the full map, then only the top-level definitions other files reference most, then just the heading. Files left over are counted by directory. Returns the map, its token count, and whether it had to be reduced.
+func BudgetedMap(params FileMapBudgetParams) (string, int, bool) {
+	full := params.Bodies.CombinedMap(params.Tokens)
+	numTokens := GetNumTokensEstimate(full)
+	if params.MaxTokens <= 0 || numTokens <= params.MaxTokens {
+		return full, numTokens, false
+	}
+
+	paths := make([]string, 0, len(params.Bodies))
+	for p := range params.Bodies {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	graph := params.Deps.BuildGraph()
+	ranks := graph.Rank(FileMapRankParams{
+		Paths:     paths,
+		Focus:     params.Focus,
+		ChangedAt: params.ChangedAt,
+	})
+	refCounts := params.Deps.SymbolRefCounts()
+
+	byRank := append([]string{}, paths...)
+	sort.SliceStable(byRank, func(i, j int) bool {
+		return ranks[byRank[i]] > ranks[byRank[j]]
+	})
+
+	budget := params.MaxTokens - GetNumTokensEstimate(fileMapBudgetNote) - int(float64(params.MaxTokens)*fileMapBudgetSummaryShare)
+	headingsBudget := int(float64(budget) * fileMapBudgetHeadingsShare)
+	used := 0
+
+	tiers := map[string]fileMapBudgetTier{}
+	collapsed := map[string]string{}
+
+	for _, p := range byRank {
+		// counts the placeholder for a heading-only file too, so the listing stays in budget whichever tier each file ends up at
+		cost := GetNumTokensEstimate(MapFileHeading(p, params.Tokens[p]) + "[MAP OMITTED]\n")
+		if used+cost > headingsBudget {
+			break
+		}
+		used += cost
+		tiers[p] = fileMapTierHeading
+	}
+
+	// collapse every listed file first so the budget covers as many files as possible, then upgrade to full maps in rank order with what's left
+	collapsedCosts := map[string]int{}
+	for _, p := range byRank {
+		if tiers[p] != fileMapTierHeading {
+			break
+		}
+
+		var defines []string
+		if fileDeps := params.Deps[p]; fileDeps != nil {
+			defines = fileDeps.Defines
+		}
+		c := collapseMapBody(strings.TrimSpace(params.Bodies[p]), defines, refCounts)
+		if c == "" {
+			continue
+		}
+		if cost := GetNumTokensEstimate(c); used+cost <= budget {
+			used += cost
+			tiers[p] = fileMapTierCollapsed
+			collapsed[p] = c
+			collapsedCosts[p] = cost
+		}
+	}
+
+	for _, p := range byRank {
+		if tiers[p] == fileMapTierOmitted {
+			break
+		}
+		body := strings.TrimSpace(params.Bodies[p])
+		if body == "" {
+			continue
+		}
+		if cost := GetNumTokensEstimate(body) - collapsedCosts[p]; used+cost <= budget {
+			used += cost
+			tiers[p] = fileMapTierFull
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fileMapBudgetNote)
+
+	var omitted []string
+	for _, p := range paths {
+		tier := tiers[p]
+		if tier == fileMapTierOmitted {
+			omitted = append(omitted, p)
+			continue
+		}
+
+		b.WriteString(MapFileHeading(p, params.Tokens[p]))
+		switch tier {
+		case fileMapTierFull:
+			b.WriteString(strings.TrimSpace(params.Bodies[p]))
+		case fileMapTierCollapsed:
+			b.WriteString(collapsed[p])
+		default:
+			if strings.TrimSpace(params.Bodies[p]) == "" {
+				b.WriteString("[NO MAP]")
+			} else {
+				b.WriteString("[MAP OMITTED]")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(omitted) > 0 {
+		summaryBudget := params.MaxTokens - GetNumTokensEstimate(b.String())
+		b.WriteString(omittedMapFilesSummary(omitted, summaryBudget))
+	}
+
+	res := b.String()
+	return res, GetNumTokensEstimate(res), true
+}
+
+// collapseMapBody keeps the top-level lines of a file's map that declare the symbols other files reference most, in their original order. Files without deps keep their first top-level lines instead. Returns an empty string if nothing is worth keeping.
+func collapseMapBody(body string, defines []string, refCounts map[string]int) string {
+	lines := strings.Split(body, "\n")
+
+	defined := map[string]bool{}
+	for _, name := range defines {
+		defined[name] = true
+	}
+
+	type scored struct {
+		idx   int
+		score int
+	}
+	var topLevel []scored
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		score := 0
+		for _, name := range mapLineIdentifiers(line) {
+			if defined[name] && refCounts[name] > score {
+				score = refCounts[name]
+			}
+		}
+		topLevel = append(topLevel, scored{idx: i, score: score})
+	}
+
+	var kept []scored
+	if len(defines) == 0 {
+		kept = topLevel
+	} else {
+		for _, s := range topLevel {
+			if s.score > 0 {
+				kept = append(kept, s)
+			}
+		}
+		sort.SliceStable(kept, func(i, j int) bool {
+			return kept[i].score > kept[j].score
+		})
+	}
+	if len(kept) > fileMapBudgetCollapsedLines {
+		kept = kept[:fileMapBudgetCollapsedLines]
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].idx < kept[j].idx
+	})
+
+	res := make([]string, 0, len(kept)+1)
+	for _, s := range kept {
+		res = append(res, strings.TrimRight(lines[s.idx], " \t"))
+	}
+
+	numOmitted := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			numOmitted++
+		}
+	}
+	numOmitted -= len(kept)
+	if numOmitted > 0 {
+		res = append(res, fmt.Sprintf("[+%d more lines]", numOmitted))
+	}
+
+	return strings.Join(res, "\n")
+}
+
+func mapLineIdentifiers(line string) []string {
+	return strings.FieldsFunc(line, func(r rune) bool {
+		return !(r == '_' || r == '$' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+	})
+}
+
+// omittedMapFilesSummary counts files by directory, merging directories into their parents until the summary fits in maxTokens
+func omittedMapFilesSummary(paths []string, maxTokens int) string {
+	maxDepth := 0
+	for _, p := range paths {
+		if d := strings.Count(path.Dir(p), "/") + 1; d > maxDepth {
+			maxDepth = d
+		}
+	}
+
+	var summary string
+	for depth := maxDepth; depth >= 0; depth-- {
+		counts := map[string]int{}
+		merged := map[string]bool{}
+		for _, p := range paths {
+			dir := path.Dir(p)
+			if parts := strings.Split(dir, "/"); len(parts) > depth {
+				dir = strings.Join(parts[:depth], "/")
+				if dir == "" {
+					dir = "."
+				}
+				merged[dir] = true
+			}
+			counts[dir]++
+		}
+
+		dirs := make([]string, 0, len(counts))
+		for dir := range counts {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "\n### Files not shown (%d)\n\n", len(paths))
+		for _, dir := range dirs {
+			label := dir + "/"
+			if dir == "." {
+				label = "./"
+			}
+			if merged[dir] {
+				label += "**"
+			}
+			if counts[dir] == 1 {
+				fmt.Fprintf(&b, "- %s (1 file)\n", label)
+			} else {
+				fmt.Fprintf(&b, "- %s (%d files)\n", label, counts[dir])
+			}
+		}
+
+		summary = b.String()
+		if GetNumTokensEstimate(summary) <= maxTokens {
+			break
+		}
+	}
+
+	return summary
+}
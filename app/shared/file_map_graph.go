@@ -0,0 +1,430 @@
+package shared
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// symbols defined in more files than this are too generic to link files by
+	fileMapGraphMaxSymbolDefs = 3
+	// shorter identifiers are mostly loop variables, receivers, and the like
+	fileMapGraphMinSymbolLen = 3
+	// an import that matches more files than this is ambiguous and is skipped
+	fileMapGraphMaxImportMatches = 20
+	// symbols kept per edge for display -- NumRefs has the full count
+	fileMapGraphMaxEdgeSymbols = 5
+)
+
+type FileMapEdge struct {
+	Import  bool
+	Symbols []string
+	NumRefs int
+}
+
+// weight favors symbol references over imports, since a package import links every file in the package while a reference points at the specific file that's used
+func (e *FileMapEdge) weight() int {
+	w := e.NumRefs * 2
+	if e.Import {
+		w++
+	}
+	return w
+}
+
+// FileMapGraph links mapped files by the imports and symbol references extracted from them. Out holds each file's dependencies and In holds its dependents -- both point to the same edges.
+type FileMapGraph struct {
+	Paths []string
+	Out   map[string]map[string]*FileMapEdge
+	In    map[string]map[string]*FileMapEdge
+}
+
+type FileMapReachable struct {
+	Path        string
+	Depth       int
+	From        string // the file this one was reached from -- empty for starting files
+	IsDependent bool   // true if this file depends on From, false if From depends on this file
+	Edge        *FileMapEdge
+}
+
+// BuildGraph resolves each file's imports against the other mapped paths and links references to the files that define them. Resolution is by path only, so it's a best-effort approximation of what each language's module system would do.
+func (deps FileMapDepsByPath) BuildGraph() *FileMapGraph {
+	g := &FileMapGraph{
+		Paths: make([]string, 0, len(deps)),
+		Out:   map[string]map[string]*FileMapEdge{},
+		In:    map[string]map[string]*FileMapEdge{},
+	}
+
+	for p := range deps {
+		g.Paths = append(g.Paths, p)
+	}
+	sort.Strings(g.Paths)
+
+	idx := newFileMapPathIndex(g.Paths)
+
+	definedIn := map[string][]string{}
+	for _, p := range g.Paths {
+		fileDeps := deps[p]
+		if fileDeps == nil {
+			continue
+		}
+		for _, name := range fileDeps.Defines {
+			if len(name) < fileMapGraphMinSymbolLen {
+				continue
+			}
+			definedIn[name] = append(definedIn[name], p)
+		}
+	}
+
+	for _, p := range g.Paths {
+		fileDeps := deps[p]
+		if fileDeps == nil {
+			continue
+		}
+
+		for _, spec := range fileDeps.Imports {
+			for _, target := range idx.resolve(p, spec) {
+				if target != p {
+					g.edge(p, target).Import = true
+				}
+			}
+		}
+
+		for _, ref := range fileDeps.Refs {
+			targets := definedIn[ref]
+			if len(targets) == 0 || len(targets) > fileMapGraphMaxSymbolDefs {
+				continue
+			}
+			for _, target := range targets {
+				if target == p {
+					continue
+				}
+				e := g.edge(p, target)
+				e.NumRefs++
+				if len(e.Symbols) < fileMapGraphMaxEdgeSymbols {
+					e.Symbols = append(e.Symbols, ref)
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *FileMapGraph) edge(from, to string) *FileMapEdge {
+	if g.Out[from] == nil {
+		g.Out[from] = map[string]*FileMapEdge{}
+	}
+	e := g.Out[from][to]
+	if e == nil {
+		e = &FileMapEdge{}
+		g.Out[from][to] = e
+		if g.In[to] == nil {
+			g.In[to] = map[string]*FileMapEdge{}
+		}
+		g.In[to][from] = e
+	}
+	return e
+}
+
+// Reachable walks out from the given files through both dependencies and dependents, breadth first, up to maxDepth steps and maxFiles results including the starting files. At each step dependencies come before dependents and heavier edges come first, so the most closely related files are listed earliest.
+func (g *FileMapGraph) Reachable(from []string, maxDepth, maxFiles int) []FileMapReachable {
+	res := []FileMapReachable{}
+	seen := map[string]bool{}
+
+	var frontier []string
+	for _, p := range from {
+		if seen[p] || len(res) >= maxFiles {
+			continue
+		}
+		seen[p] = true
+		res = append(res, FileMapReachable{Path: p})
+		frontier = append(frontier, p)
+	}
+
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, p := range frontier {
+			for _, step := range []struct {
+				edges       map[string]*FileMapEdge
+				isDependent bool
+			}{
+				{g.Out[p], false},
+				{g.In[p], true},
+			} {
+				for _, target := range sortedFileMapEdgeTargets(step.edges) {
+					if seen[target] {
+						continue
+					}
+					if len(res) >= maxFiles {
+						return res
+					}
+					seen[target] = true
+					res = append(res, FileMapReachable{
+						Path:        target,
+						Depth:       depth,
+						From:        p,
+						IsDependent: step.isDependent,
+						Edge:        step.edges[target],
+					})
+					next = append(next, target)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return res
+}
+
+// PathsMentionedIn returns graph paths that appear in the text, in order of first appearance. A bare file name counts as a mention when only one mapped file has that name.
+func (g *FileMapGraph) PathsMentionedIn(text string) []string {
+	type mention struct {
+		path string
+		pos  int
+	}
+	var mentions []mention
+
+	byBase := map[string][]string{}
+	for _, p := range g.Paths {
+		base := path.Base(p)
+		byBase[base] = append(byBase[base], p)
+	}
+
+	for _, p := range g.Paths {
+		pos := indexOfPathMention(text, p)
+		if pos == -1 {
+			base := path.Base(p)
+			if base != p && len(byBase[base]) == 1 && path.Ext(base) != "" {
+				pos = indexOfPathMention(text, base)
+			}
+		}
+		if pos != -1 {
+			mentions = append(mentions, mention{path: p, pos: pos})
+		}
+	}
+
+	sort.SliceStable(mentions, func(i, j int) bool {
+		return mentions[i].pos < mentions[j].pos
+	})
+
+	res := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		res = append(res, m.path)
+	}
+	return res
+}
+
+var pathMentionBoundary = regexp.MustCompile(`[A-Za-z0-9_\-/.]`)
+
+// indexOfPathMention finds p in text where it isn't part of a longer path or name
+func indexOfPathMention(text, p string) int {
+	offset := 0
+	for {
+		i := strings.Index(text[offset:], p)
+		if i == -1 {
+			return -1
+		}
+		start := offset + i
+		end := start + len(p)
+
+		before := start == 0 || !pathMentionBoundary.MatchString(text[start-1:start])
+		// allow a trailing period at the end of a sentence
+		after := end == len(text) || !pathMentionBoundary.MatchString(text[end:end+1]) ||
+			(text[end] == '.' && (end+1 == len(text) || !pathMentionBoundary.MatchString(text[end+1:end+2])))
+
+		if before && after {
+			return start
+		}
+		offset = start + 1
+	}
+}
+
+func sortedFileMapEdgeTargets(edges map[string]*FileMapEdge) []string {
+	targets := make([]string, 0, len(edges))
+	for target := range edges {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		a, b := edges[targets[i]].weight(), edges[targets[j]].weight()
+		if a != b {
+			return a > b
+		}
+		return targets[i] < targets[j]
+	})
+	return targets
+}
+
+type fileMapPathIndex struct {
+	paths        map[string]bool
+	byStem       map[string][]string // path without extension -> paths
+	byStemSuffix map[string][]string // each trailing run of a stem's segments -> paths
+	byDirSuffix  map[string][]string // each trailing run of a directory's segments -> paths in that directory
+}
+
+func newFileMapPathIndex(paths []string) *fileMapPathIndex {
+	idx := &fileMapPathIndex{
+		paths:        map[string]bool{},
+		byStem:       map[string][]string{},
+		byStemSuffix: map[string][]string{},
+		byDirSuffix:  map[string][]string{},
+	}
+
+	for _, p := range paths {
+		idx.paths[p] = true
+
+		stem := strings.TrimSuffix(p, path.Ext(p))
+		idx.byStem[stem] = append(idx.byStem[stem], p)
+
+		segs := strings.Split(stem, "/")
+		for i := range segs {
+			suffix := strings.Join(segs[i:], "/")
+			idx.byStemSuffix[suffix] = append(idx.byStemSuffix[suffix], p)
+		}
+
+		dir := path.Dir(p)
+		if dir != "." {
+			dirSegs := strings.Split(dir, "/")
+			for i := range dirSegs {
+				suffix := strings.Join(dirSegs[i:], "/")
+				idx.byDirSuffix[suffix] = append(idx.byDirSuffix[suffix], p)
+			}
+		}
+	}
+
+	return idx
+}
+
+var fileMapIndexStems = []string{"", "/index", "/__init__", "/mod", "/main"}
+
+// resolveExact matches a cleaned path to a file, allowing the extension or an index file to be left off
+func (idx *fileMapPathIndex) resolveExact(p string) []string {
+	p = path.Clean(p)
+	if idx.paths[p] {
+		return []string{p}
+	}
+	for _, suffix := range fileMapIndexStems {
+		if res := idx.byStem[p+suffix]; len(res) > 0 {
+			return res
+		}
+	}
+	return nil
+}
+
+func (idx *fileMapPathIndex) resolve(from, spec string) []string {
+	spec = strings.Trim(strings.TrimSpace(spec), "\"'`<>")
+	if spec == "" {
+		return nil
+	}
+
+	fromDir := path.Dir(from)
+
+	// relative paths like ./x or ../x
+	if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") {
+		return idx.resolveExact(path.Join(fromDir, spec))
+	}
+
+	// python relative imports like .x or ..x.y
+	if strings.HasPrefix(spec, ".") {
+		trimmed := strings.TrimLeft(spec, ".")
+		dir := fromDir
+		for i := 1; i < len(spec)-len(trimmed); i++ {
+			dir = path.Dir(dir)
+		}
+		return idx.resolveExact(path.Join(dir, strings.ReplaceAll(trimmed, ".", "/")))
+	}
+
+	// includes are usually relative to the including file
+	if res := idx.resolveExact(path.Join(fromDir, spec)); len(res) > 0 {
+		return res
+	}
+
+	normalized := strings.ReplaceAll(spec, "::", "/")
+	normalized = strings.ReplaceAll(normalized, "\\", "/")
+	for _, prefix := range []string{"crate/", "self/", "super/", "@/", "~/"} {
+		normalized = strings.TrimPrefix(normalized, prefix)
+	}
+
+	candidates := []string{normalized}
+	if strings.Contains(normalized, ".") && !strings.Contains(normalized, "/") {
+		// dotted module names -- python, java, kotlin, c# and so on
+		candidates = append(candidates, strings.ReplaceAll(normalized, ".", "/"))
+	}
+	for _, candidate := range candidates {
+		// module names like MyApp.Accounts that map to snake case files
+		if snake := camelToSnakePath(candidate); snake != candidate {
+			candidates = append(candidates, snake)
+		}
+	}
+	if !strings.Contains(spec, "/") {
+		for _, candidate := range candidates {
+			// imports of a symbol within a module, like 'use a::b::C' or 'import a.b.C'
+			if i := strings.LastIndex(candidate, "/"); i > 0 {
+				candidates = append(candidates, candidate[:i])
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		segs := strings.Split(strings.Trim(candidate, "/"), "/")
+
+		// leading segments can be dropped to get past a module path prefix, but only down to a single segment when the prefix looks like a module name (github.com/... or my-app/...) since short names like 'json' or 'path' are usually from the standard library
+		minSegs := 2
+		if len(segs) == 1 || strings.ContainsAny(segs[0], ".-") {
+			minSegs = 1
+		}
+
+		for i := 0; len(segs)-i >= minSegs; i++ {
+			suffix := strings.Join(segs[i:], "/")
+			if res := idx.lookupSuffix(suffix, path.Ext(from)); len(res) > 0 {
+				return res
+			}
+		}
+	}
+
+	return nil
+}
+
+func (idx *fileMapPathIndex) lookupSuffix(suffix, ext string) []string {
+	if idx.paths[suffix] {
+		return []string{suffix}
+	}
+
+	for _, indexStem := range fileMapIndexStems {
+		res := idx.byStemSuffix[suffix+indexStem]
+		if len(res) > 0 {
+			if len(res) > fileMapGraphMaxImportMatches {
+				return nil
+			}
+			return res
+		}
+	}
+
+	// a whole package or directory, like a go import -- only link files in the importing file's language
+	var res []string
+	for _, p := range idx.byDirSuffix[suffix] {
+		if ext == "" || path.Ext(p) == ext {
+			res = append(res, p)
+		}
+	}
+	if len(res) > fileMapGraphMaxImportMatches {
+		return nil
+	}
+	return res
+}
+
+func camelToSnakePath(p string) string {
+	var b strings.Builder
+	for i, r := range p {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && p[i-1] != '/' && !(p[i-1] >= 'A' && p[i-1] <= 'Z') {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r + ('a' - 'A'))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,171 @@
+package shared
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	fileMapRankDamping    = 0.85
+	fileMapRankIterations = 30
+	// a file last changed this long before the repo's latest change gets half the recency weight of the latest change
+	fileMapRankRecencyHalfLife = 14 * 24 * 60 * 60
+
+	// shares of the teleport vector -- a share falls back to uniform when there's nothing to weight it by
+	fileMapRankRecencyShare = 0.3
+	fileMapRankFocusShare   = 0.3
+)
+
+type FileMapRankParams struct {
+	Paths     []string         // every path to rank, including files without deps
+	Focus     []string         // files already in context
+	ChangedAt map[string]int64 // unix time of each path's last change in git
+}
+
+// Rank scores files with a personalized PageRank over the graph. Rank flows from each file to the files it imports and references, weighted by edge weight, so files the rest of the codebase depends on score highest. Random jumps favor files changed recently in git and files in context along with their direct neighbors, which pulls rank toward the parts of the codebase currently being worked on. Scores sum to 1.
+func (g *FileMapGraph) Rank(params FileMapRankParams) map[string]float64 {
+	pathSet := map[string]bool{}
+	var paths []string
+	for _, p := range append(append([]string{}, params.Paths...), g.Paths...) {
+		if !pathSet[p] {
+			pathSet[p] = true
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	n := len(paths)
+	if n == 0 {
+		return map[string]float64{}
+	}
+
+	teleport := make(map[string]float64, n)
+	uniformShare := 1.0
+
+	var newest int64
+	for _, p := range paths {
+		if t := params.ChangedAt[p]; t > newest {
+			newest = t
+		}
+	}
+	if newest > 0 {
+		recency := map[string]float64{}
+		var total float64
+		for _, p := range paths {
+			t, ok := params.ChangedAt[p]
+			if !ok || t <= 0 {
+				continue
+			}
+			w := math.Pow(0.5, float64(newest-t)/fileMapRankRecencyHalfLife)
+			recency[p] = w
+			total += w
+		}
+		for p, w := range recency {
+			teleport[p] += fileMapRankRecencyShare * w / total
+		}
+		uniformShare -= fileMapRankRecencyShare
+	}
+
+	focus := map[string]float64{}
+	for _, p := range params.Focus {
+		if !pathSet[p] {
+			continue
+		}
+		focus[p] = 1
+		for _, neighbors := range []map[string]*FileMapEdge{g.Out[p], g.In[p]} {
+			for q := range neighbors {
+				if focus[q] < 0.5 {
+					focus[q] = 0.5
+				}
+			}
+		}
+	}
+	if len(focus) > 0 {
+		var total float64
+		for _, w := range focus {
+			total += w
+		}
+		for p, w := range focus {
+			teleport[p] += fileMapRankFocusShare * w / total
+		}
+		uniformShare -= fileMapRankFocusShare
+	}
+
+	for _, p := range paths {
+		teleport[p] += uniformShare / float64(n)
+	}
+
+	outWeights := map[string]float64{}
+	for from, edges := range g.Out {
+		for _, e := range edges {
+			outWeights[from] += float64(e.weight())
+		}
+	}
+
+	rank := make(map[string]float64, n)
+	for p, w := range teleport {
+		rank[p] = w
+	}
+
+	for i := 0; i < fileMapRankIterations; i++ {
+		var dangling float64
+		for _, p := range paths {
+			if outWeights[p] == 0 {
+				dangling += rank[p]
+			}
+		}
+
+		next := make(map[string]float64, n)
+		for _, p := range paths {
+			next[p] = (1 - fileMapRankDamping + fileMapRankDamping*dangling) * teleport[p]
+		}
+		for from, edges := range g.Out {
+			total := outWeights[from]
+			if total == 0 {
+				continue
+			}
+			for to, e := range edges {
+				next[to] += fileMapRankDamping * rank[from] * float64(e.weight()) / total
+			}
+		}
+		rank = next
+	}
+
+	return rank
+}
+
+// SymbolRefCounts counts how many files reference each symbol that's defined in a mapped file, not counting the files that define it. Like BuildGraph, it skips short names and names defined in too many files to be meaningful, like String or New.
+func (deps FileMapDepsByPath) SymbolRefCounts() map[string]int {
+	numDefs := map[string]int{}
+	for _, fileDeps := range deps {
+		if fileDeps == nil {
+			continue
+		}
+		for _, name := range fileDeps.Defines {
+			if len(name) >= fileMapGraphMinSymbolLen {
+				numDefs[name]++
+			}
+		}
+	}
+	defined := map[string]bool{}
+	for name, n := range numDefs {
+		if n <= fileMapGraphMaxSymbolDefs {
+			defined[name] = true
+		}
+	}
+
+	counts := map[string]int{}
+	for _, fileDeps := range deps {
+		if fileDeps == nil {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, name := range fileDeps.Refs {
+			if defined[name] && !seen[name] {
+				seen[name] = true
+				counts[name]++
+			}
+		}
+	}
+	return counts
+}
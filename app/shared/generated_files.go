@@ -0,0 +1,230 @@
+package shared
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// why a file was detected as generated -- generated files are loaded as maps by default, and edits to them are skipped unless the plan's allow-generated-edits setting is on
+type GeneratedFileReason string
+
+const (
+	GeneratedReasonHeader     GeneratedFileReason = "header"        // a "Code generated ... DO NOT EDIT" style comment
+	GeneratedReasonPath       GeneratedFileReason = "path"          // a generator's naming convention, like *.pb.go or *_gen.go
+	GeneratedReasonLockfile   GeneratedFileReason = "lockfile"      // a package manager lockfile
+	GeneratedReasonMinified   GeneratedFileReason = "minified"      // minified or bundled code
+	GeneratedReasonVendored   GeneratedFileReason = "vendored"      // third-party code checked into the project
+	GeneratedReasonEncoded    GeneratedFileReason = "encoded"       // long lines of high-entropy data, like embedded base64
+	GeneratedReasonAttributes GeneratedFileReason = "gitattributes" // marked linguist-generated or linguist-vendored in .gitattributes
+)
+
+var GeneratedReasonDescriptions = map[GeneratedFileReason]string{
+	GeneratedReasonHeader:     "has a generated code header",
+	GeneratedReasonPath:       "is named like generated code",
+	GeneratedReasonLockfile:   "is a lockfile",
+	GeneratedReasonMinified:   "is minified",
+	GeneratedReasonVendored:   "is vendored",
+	GeneratedReasonEncoded:    "is encoded data",
+	GeneratedReasonAttributes: "is marked as generated in .gitattributes",
+}
+
+var lockfileNames = map[string]bool{
+	"package-lock.json":     true,
+	"npm-shrinkwrap.json":   true,
+	"yarn.lock":             true,
+	"pnpm-lock.yaml":        true,
+	"bun.lockb":             true,
+	"go.sum":                true,
+	"Cargo.lock":            true,
+	"Gemfile.lock":          true,
+	"poetry.lock":           true,
+	"Pipfile.lock":          true,
+	"uv.lock":               true,
+	"composer.lock":         true,
+	"mix.lock":              true,
+	"Podfile.lock":          true,
+	"Package.resolved":      true,
+	"packages.lock.json":    true,
+	"flake.lock":            true,
+	"pubspec.lock":          true,
+	"gradle.lockfile":       true,
+	".terraform.lock.hcl":   true,
+	"deno.lock":             true,
+	"paket.lock":            true,
+	"Manifest.toml":         true,
+	"conan.lock":            true,
+	"shard.lock":            true,
+	"project.assets.json":   true,
+	"Berksfile.lock":        true,
+	"Chart.lock":            true,
+	"requirements.lock":     true,
+	"requirements-dev.lock": true,
+	"pdm.lock":              true,
+	"rebar.lock":            true,
+}
+
+var generatedNameSuffixes = []string{
+	".pb.go",
+	".pb.gw.go",
+	"_gen.go",
+	".gen.go",
+	"_generated.go",
+	"_pb2.py",
+	"_pb2.pyi",
+	"_pb2_grpc.py",
+	".pb.h",
+	".pb.cc",
+	"_pb.js",
+	"_pb.d.ts",
+	"_grpc_pb.js",
+	".g.dart",
+	".freezed.dart",
+	".designer.cs",
+	".g.cs",
+	".generated.cs",
+	".generated.ts",
+	".generated.js",
+}
+
+var minifiedNameSuffixes = []string{
+	".min.js",
+	".min.mjs",
+	".min.css",
+	".bundle.js",
+	".js.map",
+	".css.map",
+}
+
+var vendoredDirs = map[string]bool{
+	"vendor":           true,
+	"vendors":          true,
+	"third_party":      true,
+	"third-party":      true,
+	"thirdparty":       true,
+	"node_modules":     true,
+	"bower_components": true,
+	"jspm_packages":    true,
+	"Pods":             true,
+	"Carthage":         true,
+}
+
+// only the first lines are checked for a generated code header, like Go's "Code generated ... DO NOT EDIT." convention
+const generatedHeaderLines = 10
+
+var generatedHeaderRegex = regexp.MustCompile(`(?i)(code generated\b.*\bdo not edit|@generated\b|do not (edit|modify)\b.*\bgenerated\b|\bgenerated\b.*\bdo not (edit|modify)|\b(file|code) (is|was) (auto-?generated|automatically generated|generated automatically)\b|\b(auto-?generated|automatically generated) by\b)`)
+
+const (
+	// minified and encoded checks only apply to files at least this large, so that short files with a long line aren't flagged
+	minGeneratedHeuristicSize = 2000
+
+	// average characters per line above which a file is treated as minified
+	minifiedAvgLineLength = 300
+
+	// bits per byte above which long lines are treated as encoded data -- source code is usually 4-5.5, base64 is 6
+	encodedEntropy = 5.8
+	// only this many bytes are sampled for entropy
+	entropySampleSize = 64 * 1024
+)
+
+// GetGeneratedPathReason checks a path against generators' naming conventions, lockfile names, and vendored directories
+func GetGeneratedPathReason(path string) GeneratedFileReason {
+	path = filepath.ToSlash(path)
+	base := filepath.Base(path)
+	lowerBase := strings.ToLower(base)
+
+	if lockfileNames[base] {
+		return GeneratedReasonLockfile
+	}
+
+	for _, suffix := range minifiedNameSuffixes {
+		if strings.HasSuffix(lowerBase, suffix) {
+			return GeneratedReasonMinified
+		}
+	}
+
+	for _, suffix := range generatedNameSuffixes {
+		if strings.HasSuffix(lowerBase, suffix) {
+			return GeneratedReasonPath
+		}
+	}
+
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if vendoredDirs[dir] {
+			return GeneratedReasonVendored
+		}
+	}
+
+	return ""
+}
+
+// GetGeneratedContentReason checks a file's first lines for a generated code header, then its line lengths and entropy for minified code and encoded data
+func GetGeneratedContentReason(content string) GeneratedFileReason {
+	lines := strings.SplitN(content, "\n", generatedHeaderLines+1)
+	if len(lines) > generatedHeaderLines {
+		lines = lines[:generatedHeaderLines]
+	}
+	for _, line := range lines {
+		if generatedHeaderRegex.MatchString(line) {
+			return GeneratedReasonHeader
+		}
+	}
+
+	if len(content) < minGeneratedHeuristicSize {
+		return ""
+	}
+
+	numLines := strings.Count(strings.TrimRight(content, "\n"), "\n") + 1
+	if len(content)/numLines < minifiedAvgLineLength {
+		return ""
+	}
+
+	if byteEntropy(content) > encodedEntropy {
+		return GeneratedReasonEncoded
+	}
+
+	return GeneratedReasonMinified
+}
+
+// GetGeneratedReason combines the path and content checks. Paths are checked first since they don't need the content.
+func GetGeneratedReason(path, content string) GeneratedFileReason {
+	if reason := GetGeneratedPathReason(path); reason != "" {
+		return reason
+	}
+	return GetGeneratedContentReason(content)
+}
+
+// IsBinaryContent uses git's heuristic: content with a NUL byte in its first 8000 bytes is binary
+func IsBinaryContent(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) != -1
+}
+
+func byteEntropy(content string) float64 {
+	sample := content
+	if len(sample) > entropySampleSize {
+		sample = sample[:entropySampleSize]
+	}
+
+	var counts [256]int
+	for i := 0; i < len(sample); i++ {
+		counts[sample[i]]++
+	}
+
+	var entropy float64
+	total := float64(len(sample))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
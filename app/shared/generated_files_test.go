@@ -0,0 +1,144 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetGeneratedPathReason(t *testing.T) {
+	tests := []struct {
+		path string
+		want GeneratedFileReason
+	}{
+		{"package-lock.json", GeneratedReasonLockfile},
+		{"web/yarn.lock", GeneratedReasonLockfile},
+		{"go.sum", GeneratedReasonLockfile},
+		{"infra/.terraform.lock.hcl", GeneratedReasonLockfile},
+		{"api/user.pb.go", GeneratedReasonPath},
+		{"api/user.pb.gw.go", GeneratedReasonPath},
+		{"models/user_gen.go", GeneratedReasonPath},
+		{"proto/user_pb2.py", GeneratedReasonPath},
+		{"lib/user.g.dart", GeneratedReasonPath},
+		{"Forms/Main.Designer.cs", GeneratedReasonPath},
+		{"static/app.min.js", GeneratedReasonMinified},
+		{"static/APP.MIN.CSS", GeneratedReasonMinified},
+		{"dist/main.bundle.js", GeneratedReasonMinified},
+		{"static/app.js.map", GeneratedReasonMinified},
+		{"vendor/github.com/pkg/errors/errors.go", GeneratedReasonVendored},
+		{"web/node_modules/react/index.js", GeneratedReasonVendored},
+		{"src/third_party/zlib/zlib.h", GeneratedReasonVendored},
+		{"ios/Pods/Alamofire/Source/AF.swift", GeneratedReasonVendored},
+
+		{"main.go", ""},
+		{"cmd/generate.go", ""},
+		{"package.json", ""},
+		{"lockfile.go", ""},
+		{"src/vendors.ts", ""},
+		{"docs/minimal.js", ""},
+		// lockfile names are case-sensitive
+		{"CARGO.LOCK", ""},
+	}
+
+	for _, tt := range tests {
+		if got := GetGeneratedPathReason(tt.path); got != tt.want {
+			t.Errorf("GetGeneratedPathReason(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGetGeneratedContentReason(t *testing.T) {
+	source := strings.Repeat("func add(a, b int) int {\n\treturn a + b\n}\n\n", 100)
+
+	tests := []struct {
+		name    string
+		content string
+		want    GeneratedFileReason
+	}{
+		{"go header", "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage api\n", GeneratedReasonHeader},
+		{"@generated", "/**\n * @generated\n */\nexport const a = 1;\n", GeneratedReasonHeader},
+		{"do not edit generated", "# DO NOT EDIT: this file is generated by make schema\n", GeneratedReasonHeader},
+		{"auto-generated by", "-- Auto-generated by sqlc\nSELECT 1;\n", GeneratedReasonHeader},
+		{"file was automatically generated", "/* This file was automatically generated */\n", GeneratedReasonHeader},
+		{"header on the last checked line", strings.Repeat("\n", generatedHeaderLines-1) + "// Code generated by mockgen. DO NOT EDIT.\n", GeneratedReasonHeader},
+		{"header past the checked lines", strings.Repeat("\n", generatedHeaderLines) + "// Code generated by mockgen. DO NOT EDIT.\n", ""},
+		{"mentions generation", "// generate builds the report\nfunc generate() {}\n", ""},
+		{"do not edit without generated", "// do not edit this without asking\n", ""},
+
+		{"source", source, ""},
+		{"long line under the size threshold", strings.Repeat("a", minGeneratedHeuristicSize-1), ""},
+		{"minified", strings.Repeat("var a=function(b){return b+1};", 100), GeneratedReasonMinified},
+		{"minified at the line length threshold", strings.Repeat(strings.Repeat("x", minifiedAvgLineLength-1)+"\n", 10), GeneratedReasonMinified},
+		{"under the line length threshold", strings.Repeat(strings.Repeat("x", minifiedAvgLineLength-2)+"\n", 10), ""},
+		{"encoded", "data = \"" + base64Like(4000) + "\"\n", GeneratedReasonEncoded},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetGeneratedContentReason(tt.content); got != tt.want {
+				t.Errorf("GetGeneratedContentReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGeneratedReason(t *testing.T) {
+	header := "// Code generated by stringer. DO NOT EDIT.\n"
+
+	// the path is checked first
+	if got := GetGeneratedReason("go.sum", header); got != GeneratedReasonLockfile {
+		t.Errorf("GetGeneratedReason(go.sum) = %q, want %q", got, GeneratedReasonLockfile)
+	}
+	if got := GetGeneratedReason("kind_string.go", header); got != GeneratedReasonHeader {
+		t.Errorf("GetGeneratedReason(kind_string.go) = %q, want %q", got, GeneratedReasonHeader)
+	}
+	if got := GetGeneratedReason("main.go", "package main\n"); got != "" {
+		t.Errorf("GetGeneratedReason(main.go) = %q, want none", got)
+	}
+}
+
+func TestByteEntropy(t *testing.T) {
+	if got := byteEntropy(strings.Repeat("a", 1000)); got != 0 {
+		t.Errorf("byteEntropy(single byte) = %f, want 0", got)
+	}
+	if got := byteEntropy(strings.Repeat("ab", 500)); got != 1 {
+		t.Errorf("byteEntropy(two bytes) = %f, want 1", got)
+	}
+	if got := byteEntropy(base64Like(4000)); got <= encodedEntropy {
+		t.Errorf("byteEntropy(base64) = %f, want > %f", got, encodedEntropy)
+	}
+	if got := byteEntropy(strings.Repeat("func add(a, b int) int { return a + b }\n", 100)); got >= encodedEntropy {
+		t.Errorf("byteEntropy(source) = %f, want < %f", got, encodedEntropy)
+	}
+}
+
+func TestIsBinaryContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"text", []byte("hello\nworld\n"), false},
+		{"empty", nil, false},
+		{"utf-8", []byte("héllo wörld ✓"), false},
+		{"nul", []byte("PNG\x00\x01\x02"), true},
+		{"nul at the last checked byte", append([]byte(strings.Repeat("a", 7999)), 0), true},
+		{"nul past the checked bytes", append([]byte(strings.Repeat("a", 8000)), 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBinaryContent(tt.content); got != tt.want {
+			t.Errorf("IsBinaryContent(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// base64Like returns n characters cycling through the base64 alphabet, so every character is equally common
+func base64Like(n int) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(alphabet[(i*7)%len(alphabet)])
+	}
+	return b.String()
+}
@@ -16,6 +16,11 @@ const (
 
 const defaultEditor = EditorTypeVim
 
+const (
+	SemanticCheckOff  string = "off"
+	SemanticCheckAuto string = "auto"
+)
+
 type AutoModeType string
 
 const (
@@ -62,6 +67,15 @@ type PlanConfig struct {
 	AutoLoadContext   bool `json:"autoContext"`
 	SmartContext      bool `json:"smartContext"`
 
+	// 0 means auto -- see MapTokenBudgetFor
+	MapTokenBudget int `json:"mapTokenBudget"`
+
+	// "off" or "auto" -- empty means off. Custom checker commands are only read from the project's .plandex-check.yml, since plan config can be changed by anyone the plan is shared with.
+	SemanticCheck string `json:"semanticCheck"`
+
+	// generated files (see GetGeneratedReason) are loaded as maps and skipped by builds unless this is on
+	AllowGeneratedEdits bool `json:"allowGeneratedEdits"`
+
 	// AutoApproveContext bool `json:"autoApproveContext"`
 	// QuietContext       bool `json:"quietContext"`
 
@@ -323,6 +337,49 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%t", p.SmartContext)
 		},
 	},
+	"maptokenbudget": {
+		Name: "map-token-budget",
+		Desc: "Max tokens for each project map (0 for auto)",
+		IntSetter: func(p *PlanConfig, value int) {
+			if value < 0 {
+				value = 0
+			}
+			p.MapTokenBudget = value
+		},
+		Getter: func(p *PlanConfig) string {
+			if p.MapTokenBudget == 0 {
+				return "auto"
+			}
+			return fmt.Sprintf("%d", p.MapTokenBudget)
+		},
+	},
+	"semanticcheck": {
+		Name: "semantic-check",
+		Desc: "Type-check built files with local tools (checkers can be added in .plandex-check.yml)",
+		StringSetter: func(p *PlanConfig, value string) {
+			if value == SemanticCheckOff {
+				value = ""
+			}
+			p.SemanticCheck = value
+		},
+		Getter: func(p *PlanConfig) string {
+			if p.SemanticCheck == "" {
+				return SemanticCheckOff
+			}
+			return p.SemanticCheck
+		},
+		Choices: &[]string{SemanticCheckOff, SemanticCheckAuto},
+	},
+	"allowgeneratededits": {
+		Name: "allow-generated-edits",
+		Desc: "Allow edits to generated files like *.pb.go, lockfiles, and minified code",
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.AllowGeneratedEdits = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.AllowGeneratedEdits)
+		},
+	},
 	"autocommit": {
 		Name: "auto-commit",
 		Desc: "Automatically commit changes to git after apply",
@@ -467,6 +524,24 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 	},
 }
 
+// MapTokenBudgetFor returns the token budget for each project map given the context loader's effective max tokens. Maps that are larger are ranked and reduced to fit. Auto uses half the context loader's limit, leaving room for the prompt, conversation, and any other context.
+func (p *PlanConfig) MapTokenBudgetFor(contextLoaderMaxTokens int) int {
+	if p.MapTokenBudget > 0 {
+		return p.MapTokenBudget
+	}
+	return contextLoaderMaxTokens / 2
+}
+
+// Validate returns an error for settings that aren't safe to store, since a plan's config is shared with everyone who can contribute to it
+func (p *PlanConfig) Validate() error {
+	switch p.SemanticCheck {
+	case "", SemanticCheckOff, SemanticCheckAuto:
+	default:
+		return fmt.Errorf("semantic-check must be '%s' or '%s' -- custom checkers go in .plandex-check.yml", SemanticCheckOff, SemanticCheckAuto)
+	}
+	return nil
+}
+
 func init() {
 	DefaultPlanConfig.SetAutoMode(AutoModeSemi)
 
@@ -0,0 +1,163 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// variables are written as {{name}} in a template's prompt and context paths
+var planTemplateVarRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_-]*)\s*\}\}`)
+
+var planTemplateNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+const MaxPlanTemplateNameLength = 64
+
+// auto modes a template can start a plan with -- 'custom' is left out since it depends on individual settings
+var PlanTemplateAutoModes = []AutoModeType{
+	AutoModeFull,
+	AutoModeSemi,
+	AutoModePlus,
+	AutoModeBasic,
+	AutoModeNone,
+}
+
+// PlanTemplateContext is the context a template loads into a new plan. Files accept globs, including '**'. Maps and trees are directories.
+type PlanTemplateContext struct {
+	Files []string `json:"files,omitempty"`
+	Maps  []string `json:"maps,omitempty"`
+	Trees []string `json:"trees,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+func (c *PlanTemplateContext) IsEmpty() bool {
+	return len(c.Files) == 0 && len(c.Maps) == 0 && len(c.Trees) == 0 && len(c.Notes) == 0
+}
+
+func (c *PlanTemplateContext) Scan(src interface{}) error {
+	if src == nil {
+		*c = PlanTemplateContext{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		if len(s) == 0 {
+			*c = PlanTemplateContext{}
+			return nil
+		}
+		return json.Unmarshal(s, c)
+	case string:
+		if s == "" {
+			*c = PlanTemplateContext{}
+			return nil
+		}
+		return json.Unmarshal([]byte(s), c)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (c PlanTemplateContext) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func IsValidPlanTemplateName(name string) bool {
+	return len(name) <= MaxPlanTemplateNameLength && planTemplateNameRegex.MatchString(name)
+}
+
+func IsValidPlanTemplateAutoMode(mode AutoModeType) bool {
+	if mode == "" {
+		return true
+	}
+	for _, m := range PlanTemplateAutoModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// templates can start a plan with any built-in model pack -- 'daily' is accepted for daily-driver, as with 'plandex set-model'
+func IsValidPlanTemplateModelPack(name string) bool {
+	if name == "" || name == "daily" {
+		return true
+	}
+	for _, mp := range BuiltInModelPacks {
+		if strings.EqualFold(mp.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Variables returns the names of the template's variables in the order they first appear
+func (t *PlanTemplate) Variables() []string {
+	var res []string
+	seen := map[string]bool{}
+
+	for _, s := range t.templatedStrings() {
+		for _, match := range planTemplateVarRegex.FindAllStringSubmatch(s, -1) {
+			name := match[1]
+			if !seen[name] {
+				seen[name] = true
+				res = append(res, name)
+			}
+		}
+	}
+
+	return res
+}
+
+// Render returns a copy of the template with its variables replaced. Every variable must have a value.
+func (t *PlanTemplate) Render(vars map[string]string) (*PlanTemplate, error) {
+	var missing []string
+	for _, name := range t.Variables() {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing template variables: %s", strings.Join(missing, ", "))
+	}
+
+	render := func(s string) string {
+		return planTemplateVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := planTemplateVarRegex.FindStringSubmatch(match)[1]
+			return vars[name]
+		})
+	}
+
+	renderAll := func(list []string) []string {
+		if list == nil {
+			return nil
+		}
+		res := make([]string, len(list))
+		for i, s := range list {
+			res[i] = render(s)
+		}
+		return res
+	}
+
+	rendered := *t
+	rendered.Prompt = render(t.Prompt)
+	rendered.Context = PlanTemplateContext{
+		Files: renderAll(t.Context.Files),
+		Maps:  renderAll(t.Context.Maps),
+		Trees: renderAll(t.Context.Trees),
+		Notes: renderAll(t.Context.Notes),
+	}
+
+	return &rendered, nil
+}
+
+func (t *PlanTemplate) templatedStrings() []string {
+	res := []string{t.Prompt}
+	res = append(res, t.Context.Files...)
+	res = append(res, t.Context.Maps...)
+	res = append(res, t.Context.Trees...)
+	res = append(res, t.Context.Notes...)
+	return res
+}
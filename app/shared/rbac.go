@@ -23,11 +23,57 @@ const (
 	PermissionDeleteAnyPlan         Permission = "delete_any_plan"
 	PermissionUpdateAnyPlan         Permission = "update_any_plan"
 	PermissionArchiveAnyPlan        Permission = "archive_any_plan"
+	PermissionManageServiceAccounts Permission = "manage_service_accounts"
+	PermissionManageOrgRoles        Permission = "manage_org_roles"
+	PermissionTellPlan              Permission = "tell_plan"
+	PermissionApplyPlan             Permission = "apply_plan"
+	PermissionManageCustomModels    Permission = "manage_custom_models"
+	PermissionViewAuditLog          Permission = "view_audit_log"
+	PermissionManageWebhooks        Permission = "manage_webhooks"
+	PermissionManagePlanTemplates   Permission = "manage_plan_templates"
 )
 
+// permissions that can be given to a custom org role -- the per-role invite_user, remove_user, and set_user_role permissions are granted to owners and admins automatically when a custom role is created
+var CustomRolePermissions = []Permission{
+	PermissionManageEmailDomainAuth,
+	PermissionManageBilling,
+	PermissionListOrgRoles,
+	PermissionCreateProject,
+	PermissionRenameAnyProject,
+	PermissionDeleteAnyProject,
+	PermissionCreatePlan,
+	PermissionManageAnyPlanShares,
+	PermissionRenameAnyPlan,
+	PermissionDeleteAnyPlan,
+	PermissionUpdateAnyPlan,
+	PermissionArchiveAnyPlan,
+	PermissionManageServiceAccounts,
+	PermissionManageOrgRoles,
+	PermissionTellPlan,
+	PermissionApplyPlan,
+	PermissionManageCustomModels,
+	PermissionViewAuditLog,
+	PermissionManageWebhooks,
+	PermissionManagePlanTemplates,
+}
+
+func IsCustomRolePermission(permission Permission) bool {
+	for _, p := range CustomRolePermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 type Permissions map[string]bool
 
 func (perms Permissions) HasPermission(permission Permission) bool {
+	// resource-scoped checks pass the full name|resourceId string
+	if perms[string(permission)] {
+		return true
+	}
+
 	for p := range perms {
 		split := strings.Split(p, "|")
 		perm := Permission(split[0])
@@ -42,6 +88,9 @@ func (perms Permissions) HasPermission(permission Permission) bool {
 func (perms Permissions) HasPermissionForResource(permission Permission, resourceId string) bool {
 	for p := range perms {
 		split := strings.Split(p, "|")
+		if len(split) < 2 {
+			continue
+		}
 		perm := Permission(split[0])
 		resId := split[1]
 
@@ -51,3 +100,30 @@ func (perms Permissions) HasPermissionForResource(permission Permission, resourc
 	}
 	return false
 }
+
+// PlanShareLevel is the access a plan share grants. Each level includes the ones before it.
+type PlanShareLevel string
+
+const (
+	// view the plan, its context, convo, diffs and logs, and connect to its stream
+	PlanShareLevelReadOnly PlanShareLevel = "read_only"
+	// also send chat messages to the plan
+	PlanShareLevelComment PlanShareLevel = "comment"
+	// also tell, build, apply, reject, rewind and update context/config -- enough to take over a plan
+	PlanShareLevelContributor PlanShareLevel = "contributor"
+)
+
+var planShareLevelRanks = map[PlanShareLevel]int{
+	PlanShareLevelReadOnly:    1,
+	PlanShareLevelComment:     2,
+	PlanShareLevelContributor: 3,
+}
+
+func (level PlanShareLevel) IsValid() bool {
+	_, ok := planShareLevelRanks[level]
+	return ok
+}
+
+func (level PlanShareLevel) Includes(other PlanShareLevel) bool {
+	return planShareLevelRanks[level] >= planShareLevelRanks[other]
+}
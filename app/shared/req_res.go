@@ -50,6 +50,42 @@ type SessionResponse struct {
 	IsLocalMode bool   `json:"isLocalMode"`
 }
 
+type SsoProvider struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ListSsoProvidersResponse struct {
+	Providers  []*SsoProvider `json:"providers"`
+	RequireSso bool           `json:"requireSso"`
+}
+
+type StartSsoDeviceResponse struct {
+	PollToken               string    `json:"pollToken"`
+	UserCode                string    `json:"userCode"`
+	VerificationUri         string    `json:"verificationUri"`
+	VerificationUriComplete string    `json:"verificationUriComplete,omitempty"`
+	Interval                int       `json:"interval"`
+	ExpiresAt               time.Time `json:"expiresAt"`
+}
+
+type PollSsoDeviceRequest struct {
+	PollToken string `json:"pollToken"`
+}
+
+type SsoDeviceStatus string
+
+const (
+	SsoDeviceStatusPending  SsoDeviceStatus = "pending"
+	SsoDeviceStatusSlowDown SsoDeviceStatus = "slow_down"
+	SsoDeviceStatusComplete SsoDeviceStatus = "complete"
+)
+
+type PollSsoDeviceResponse struct {
+	Status  SsoDeviceStatus  `json:"status"`
+	Session *SessionResponse `json:"session,omitempty"`
+}
+
 type CreateOrgRequest struct {
 	Name               string `json:"name"`
 	AutoAddDomainUsers bool   `json:"autoAddDomainUsers"`
@@ -73,6 +109,93 @@ type InviteRequest struct {
 	OrgRoleId string `json:"orgRoleId"`
 }
 
+type CreateOrgRoleRequest struct {
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// UpdateOrgRoleRequest replaces a custom role's label, description, and permissions
+type UpdateOrgRoleRequest struct {
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type CreateApiTokenRequest struct {
+	Name string `json:"name"`
+
+	// leave empty for a token that has all of the owner's permissions
+	Permissions []Permission `json:"permissions"`
+
+	// 0 for a token that doesn't expire
+	ExpiresInDays int `json:"expiresInDays"`
+
+	// when set, the token belongs to this org service account rather than the current user -- the account is created with OrgRoleId if it doesn't exist yet
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	OrgRoleId          string `json:"orgRoleId,omitempty"`
+}
+
+type CreateApiTokenResponse struct {
+	Token    string    `json:"token"`
+	ApiToken *ApiToken `json:"apiToken"`
+}
+
+type CreateWebhookRequest struct {
+	Url         string         `json:"url"`
+	Description string         `json:"description"`
+	Events      []WebhookEvent `json:"events"`
+
+	// limits the webhook to plans in this project -- leave empty for all plans in the org
+	ProjectId string `json:"projectId,omitempty"`
+}
+
+type CreateWebhookResponse struct {
+	// used to sign deliveries -- it's only returned here and can't be retrieved later
+	Secret  string   `json:"secret"`
+	Webhook *Webhook `json:"webhook"`
+}
+
+// UpdateWebhookRequest only changes the fields that are set
+type UpdateWebhookRequest struct {
+	Url         *string        `json:"url,omitempty"`
+	Description *string        `json:"description,omitempty"`
+	Events      []WebhookEvent `json:"events,omitempty"`
+	Disabled    *bool          `json:"disabled,omitempty"`
+}
+
+type CreatePlanTemplateRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Prompt      string              `json:"prompt"`
+	Context     PlanTemplateContext `json:"context"`
+	AutoMode    AutoModeType        `json:"autoMode,omitempty"`
+	ModelPack   string              `json:"modelPack,omitempty"`
+}
+
+// UpdatePlanTemplateRequest only changes the fields that are set
+type UpdatePlanTemplateRequest struct {
+	Name        *string              `json:"name,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Prompt      *string              `json:"prompt,omitempty"`
+	Context     *PlanTemplateContext `json:"context,omitempty"`
+	AutoMode    *AutoModeType        `json:"autoMode,omitempty"`
+	ModelPack   *string              `json:"modelPack,omitempty"`
+}
+
+type SharePlanRequest struct {
+	// share with an org member by email, or with everyone working in the plan's project
+	Email        string         `json:"email,omitempty"`
+	WholeProject bool           `json:"wholeProject,omitempty"`
+	Level        PlanShareLevel `json:"level"`
+}
+
+type ListSharedPlansResponse struct {
+	Plans             []*Plan                   `json:"plans"`
+	LevelByPlanId     map[string]PlanShareLevel `json:"levelByPlanId"`
+	OwnerNameByPlanId map[string]string         `json:"ownerNameByPlanId"`
+}
+
 type CreateProjectRequest struct {
 	Name string `json:"name"`
 }
@@ -91,6 +214,9 @@ type RenameProjectRequest struct {
 
 type CreatePlanRequest struct {
 	Name string `json:"name"`
+
+	// the plan template the plan is started from, if any -- the client applies the template's config and context
+	TemplateId string `json:"templateId,omitempty"`
 }
 
 type CreatePlanResponse struct {
@@ -139,6 +265,56 @@ type TellPlanRequest struct {
 	IsImplementationOfChat bool              `json:"isImplementationOfChat"`
 	IsGitRepo              bool              `json:"isGitRepo"`
 	SessionId              string            `json:"sessionId"`
+	SemanticCheck          bool              `json:"semanticCheck,omitempty"`
+}
+
+type TellPlanPreviewContextStatus string
+
+const (
+	TellPlanPreviewContextIncluded TellPlanPreviewContextStatus = "included"
+	TellPlanPreviewContextDropped  TellPlanPreviewContextStatus = "dropped" // didn't fit in the remaining token budget
+	TellPlanPreviewContextSkipped  TellPlanPreviewContextStatus = "skipped" // not used at this stage (smart context, or not activated by auto context)
+)
+
+type TellPlanPreviewContext struct {
+	ContextId   string                       `json:"contextId,omitempty"`
+	Name        string                       `json:"name"`
+	FilePath    string                       `json:"filePath,omitempty"`
+	ContextType ContextType                  `json:"contextType"`
+	NumTokens   int                          `json:"numTokens"`
+	IsPending   bool                         `json:"isPending"`
+	Status      TellPlanPreviewContextStatus `json:"status"`
+}
+
+type TellPlanPreviewResponse struct {
+	Stage CurrentStage `json:"stage"`
+
+	SystemPromptTokens int                       `json:"systemPromptTokens"`
+	ContextTokens      int                       `json:"contextTokens"`
+	Contexts           []*TellPlanPreviewContext `json:"contexts"`
+	PromptTokens       int                       `json:"promptTokens"`
+
+	ConvoMessages int `json:"convoMessages"`
+	ConvoTokens   int `json:"convoTokens"`
+
+	// set when a stored summary will replace the earlier part of the conversation
+	WillSummarize           bool `json:"willSummarize"`
+	SummaryTokens           int  `json:"summaryTokens"`
+	ConvoTokensAfterSummary int  `json:"convoTokensAfterSummary"`
+
+	TotalTokens          int `json:"totalTokens"`
+	MaxTokens            int `json:"maxTokens"`
+	MaxConvoTokens       int `json:"maxConvoTokens"`
+	ReservedOutputTokens int `json:"reservedOutputTokens"`
+
+	ModelProvider  ModelProvider `json:"modelProvider"`
+	ModelName      ModelName     `json:"modelName"`
+	ModelMaxTokens int           `json:"modelMaxTokens"`
+	BaseModelName  ModelName     `json:"baseModelName"`
+	IsFallback     bool          `json:"isFallback"`
+
+	// set when the request would fail before reaching the model
+	Error string `json:"error,omitempty"`
 }
 
 type BuildPlanRequest struct {
@@ -150,6 +326,7 @@ type BuildPlanRequest struct {
 	OpenAIOrgId   string            `json:"openAIOrgId"`
 	ProjectPaths  map[string]bool   `json:"projectPaths"`
 	SessionId     string            `json:"sessionId"`
+	SemanticCheck bool              `json:"semanticCheck,omitempty"`
 }
 
 const NoBuildsErr string = "No builds"
@@ -168,6 +345,15 @@ type RespondMissingFileRequest struct {
 	Body     string                   `json:"body"`
 }
 
+type RespondSemanticCheckRequest struct {
+	Id string `json:"id"`
+	// the command that ran, e.g. "go vet" -- empty if no checker is available for the file
+	Checker string `json:"checker"`
+	// only diagnostics the built file introduced -- those already present in the project are left out
+	Diagnostics []string `json:"diagnostics"`
+	Error       string   `json:"error,omitempty"`
+}
+
 type FileMapInputs map[string]string
 
 func (f FileMapInputs) NumFiles() int {
@@ -191,11 +377,15 @@ type LoadContextParams struct {
 	ForceSkipIgnore bool                  `json:"forceSkipIgnore"`
 	ImageDetail     openai.ImageURLDetail `json:"imageDetail"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
+	GeneratedReason GeneratedFileReason   `json:"generatedReason,omitempty"` // set when a generated file is loaded in full
 
-	InputShas   map[string]string `json:"inputShas"`
-	InputTokens map[string]int    `json:"inputTokens"`
-	InputSizes  map[string]int64  `json:"inputSizes"`
-	MapBodies   FileMapBodies     `json:"mapBodies"`
+	InputShas      map[string]string              `json:"inputShas"`
+	InputTokens    map[string]int                 `json:"inputTokens"`
+	InputSizes     map[string]int64               `json:"inputSizes"`
+	InputChangedAt map[string]int64               `json:"inputChangedAt,omitempty"` // unix time of each path's last commit in git
+	InputGenerated map[string]GeneratedFileReason `json:"inputGenerated,omitempty"`
+	MapBodies      FileMapBodies                  `json:"mapBodies"`
+	MapDeps        FileMapDepsByPath              `json:"mapDeps"`
 
 	// For naming piped data
 	ApiKeys     map[string]string `json:"apiKeys"`
@@ -215,20 +405,25 @@ type LoadContextResponse struct {
 }
 
 type UpdateContextParams struct {
-	Body            string            `json:"body"`
-	InputShas       map[string]string `json:"inputShas"`
-	InputTokens     map[string]int    `json:"inputTokens"`
-	InputSizes      map[string]int64  `json:"inputSizes"`
-	MapBodies       FileMapBodies     `json:"mapBodies"`
-	RemovedMapPaths []string          `json:"removedMapPaths"`
+	Body            string                         `json:"body"`
+	InputShas       map[string]string              `json:"inputShas"`
+	InputTokens     map[string]int                 `json:"inputTokens"`
+	InputSizes      map[string]int64               `json:"inputSizes"`
+	InputChangedAt  map[string]int64               `json:"inputChangedAt,omitempty"`
+	InputGenerated  map[string]GeneratedFileReason `json:"inputGenerated,omitempty"`
+	MapBodies       FileMapBodies                  `json:"mapBodies"`
+	MapDeps         FileMapDepsByPath              `json:"mapDeps"`
+	RemovedMapPaths []string                       `json:"removedMapPaths"`
 }
 
 type GetFileMapRequest struct {
 	MapInputs FileMapInputs `json:"mapInputs"`
+	ProjectId string        `json:"projectId,omitempty"` // lets the server reuse parsed trees from earlier maps of the same project
 }
 
 type GetFileMapResponse struct {
-	MapBodies FileMapBodies `json:"mapBodies"`
+	MapBodies FileMapBodies     `json:"mapBodies"`
+	MapDeps   FileMapDepsByPath `json:"mapDeps"`
 }
 
 type LoadCachedFileMapRequest struct {
@@ -322,6 +517,9 @@ type ApplyPlanRequest struct {
 	OpenAIBase  string            `json:"openAIBase"`
 	OpenAIOrgId string            `json:"openAIOrgId"`
 	SessionId   string            `json:"sessionId"`
+
+	// bodies of applied files that the client's formatters changed, by path -- stored as the files' context instead of the built bodies so context matches what was written to disk
+	FormattedFiles map[string]string `json:"formattedFiles,omitempty"`
 }
 
 type RenamePlanRequest struct {
@@ -365,3 +563,28 @@ type CreditsSummaryResponse struct {
 
 	CacheSavings decimal.Decimal `json:"cacheSavings"`
 }
+
+// ListAuditLogRequest is sent as query params
+type ListAuditLogRequest struct {
+	Actions    []string   `json:"action"`
+	Actor      string     `json:"actor"`
+	PlanId     string     `json:"planId"`
+	TargetType string     `json:"targetType"`
+	TargetId   string     `json:"targetId"`
+	Since      *time.Time `json:"since"`
+	Until      *time.Time `json:"until"`
+	BeforeSeq  int64      `json:"beforeSeq"`
+	AfterSeq   int64      `json:"afterSeq"`
+	Limit      int        `json:"limit"`
+}
+
+type ListAuditLogResponse struct {
+	Entries []*AuditLogEntry `json:"entries"`
+
+	// set when more entries matched than the limit -- pass the last entry's seq as beforeSeq to get the next page
+	HasMore bool `json:"hasMore"`
+}
+
+type GetBuilderStatsResponse struct {
+	Stats []*BuilderStrategyStats `json:"stats"`
+}
@@ -2,11 +2,15 @@ package shared
 
 const STREAM_MESSAGE_SEPARATOR = "@@PX@@"
 
+// clients send the seq of the last stream message they received in this header to resume a stream
+const STREAM_RESUME_HEADER = "Last-Event-ID"
+
 type BuildInfo struct {
 	Path      string `json:"path"`
 	NumTokens int    `json:"numTokens"`
 	Finished  bool   `json:"finished"`
 	Removed   bool   `json:"removed,omitempty"`
+	Skipped   bool   `json:"skipped,omitempty"` // edits to a generated file that weren't allowed
 }
 
 type StreamMessageType string
@@ -21,6 +25,7 @@ const (
 	StreamMessageBuildInfo         StreamMessageType = "buildInfo"
 	StreamMessagePromptMissingFile StreamMessageType = "promptMissingFile"
 	StreamMessageLoadContext       StreamMessageType = "loadContext"
+	StreamMessageSemanticCheck     StreamMessageType = "semanticCheck"
 	StreamMessageAborted           StreamMessageType = "aborted"
 	StreamMessageFinished          StreamMessageType = "finished"
 	StreamMessageError             StreamMessageType = "error"
@@ -28,9 +33,19 @@ const (
 	StreamMessageMulti StreamMessageType = "multi"
 )
 
+// SemanticCheckRequest asks the client to type-check a built file against the local project before the build is finished
+type SemanticCheckRequest struct {
+	Id      string `json:"id"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
 type StreamMessage struct {
 	Type StreamMessageType `json:"type"`
 
+	// Seq is a monotonic sequence number assigned by the server to each message sent on a plan's stream -- clients pass the last one they received to resume after a disconnect
+	Seq int64 `json:"seq,omitempty"`
+
 	ReplyChunk string `json:"replyChunk,omitempty"`
 
 	BuildInfo              *BuildInfo               `json:"buildInfo,omitempty"`
@@ -43,6 +58,7 @@ type StreamMessage struct {
 	InitPrompt             string                   `json:"initPrompt,omitempty"`
 	InitReplies            []string                 `json:"initReplies,omitempty"`
 	InitBuildOnly          bool                     `json:"initBuildOnly,omitempty"`
+	SemanticCheck          *SemanticCheckRequest    `json:"semanticCheck,omitempty"`
 
 	StreamMessages []StreamMessage `json:"streamMessages,omitempty"`
 }
@@ -33,6 +33,7 @@ const (
 	LanguageRuby       Language = "ruby"
 	LanguageRust       Language = "rust"
 	LanguageScala      Language = "scala"
+	LanguageSql        Language = "sql"
 	LanguageSvelte     Language = "svelte"
 	LanguageSwift      Language = "swift"
 	LanguageToml       Language = "toml"
@@ -41,6 +42,12 @@ const (
 	LanguageTsx        Language = "tsx"
 	LanguageYaml       Language = "yaml"
 	LanguageMarkdown   Language = "markdown"
+	LanguageZig        Language = "zig"
+	LanguageDart       Language = "dart"
+	LanguageR          Language = "r"
+	LanguageJulia      Language = "julia"
+	LanguageNix        Language = "nix"
+	LanguageHaskell    Language = "haskell"
 )
 
 var Languages = []Language{
@@ -70,6 +77,7 @@ var Languages = []Language{
 	LanguageRuby,
 	LanguageRust,
 	LanguageScala,
+	LanguageSql,
 	LanguageSvelte,
 	LanguageSwift,
 	LanguageToml,
@@ -77,11 +85,17 @@ var Languages = []Language{
 	LanguageJsx,
 	LanguageTsx,
 	LanguageYaml,
+	LanguageZig,
+	LanguageDart,
+	LanguageR,
+	LanguageJulia,
+	LanguageNix,
+	LanguageHaskell,
 }
 
 var lacksFileMapSupport = []Language{
 	// config languages aren't mapped, model decides whether to load them based on file name
-	LanguageHcl,
+	// (hcl is mapped since terraform modules define resources, variables, and outputs)
 	LanguageYaml,
 	LanguageToml,
 	LanguageCue,
@@ -95,6 +109,19 @@ var lacksFileMapSupport = []Language{
 
 var SkipTreeSitter = map[Language]bool{
 	LanguageMarkdown: true,
+
+	// the pinned go-tree-sitter release has no grammars for these, so they're mapped line by line and built files aren't syntax-checked
+	LanguageZig:     true,
+	LanguageDart:    true,
+	LanguageR:       true,
+	LanguageJulia:   true,
+	LanguageNix:     true,
+	LanguageHaskell: true,
+}
+
+// grammars that are only used for file maps. tree-sitter-sql reports errors on valid postgres (dollar-quoted function bodies, triggers), so sql files aren't syntax-checked or edited through their syntax tree during builds.
+var MapOnlyTreeSitter = map[Language]bool{
+	LanguageSql: true,
 }
 
 var LanguageSet = map[Language]bool{}
@@ -146,6 +173,8 @@ var LanguageByExtension = map[string]Language{
 	".go":     LanguageGo,
 	".groovy": LanguageGroovy,
 	".hcl":    LanguageHcl,
+	".tf":     LanguageHcl,
+	".tfvars": LanguageHcl,
 	".html":   LanguageHtml,
 	".java":   LanguageJava,
 	".js":     LanguageJavascript,
@@ -160,6 +189,7 @@ var LanguageByExtension = map[string]Language{
 	".rb":     LanguageRuby,
 	".rs":     LanguageRust,
 	".scala":  LanguageScala,
+	".sql":    LanguageSql,
 	".svelte": LanguageSvelte,
 	".swift":  LanguageSwift,
 	".toml":   LanguageToml,
@@ -168,6 +198,13 @@ var LanguageByExtension = map[string]Language{
 	".yaml":   LanguageYaml,
 	".yml":    LanguageYaml,
 	".md":     LanguageMarkdown,
+	".zig":    LanguageZig,
+	".dart":   LanguageDart,
+	".r":      LanguageR,
+	".R":      LanguageR,
+	".jl":     LanguageJulia,
+	".nix":    LanguageNix,
+	".hs":     LanguageHaskell,
 }
 
 var LanguageFallbackByExtension = map[string]Language{
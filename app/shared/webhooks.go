@@ -0,0 +1,78 @@
+package shared
+
+import "time"
+
+type WebhookEvent string
+
+const (
+	WebhookEventPlanFinished   WebhookEvent = "plan.finished"
+	WebhookEventPlanStopped    WebhookEvent = "plan.stopped"
+	WebhookEventExecFailed     WebhookEvent = "plan.exec_failed"
+	WebhookEventBuildFailed    WebhookEvent = "plan.build_failed"
+	WebhookEventChangesApplied WebhookEvent = "plan.changes_applied"
+
+	// only sent by 'plandex webhooks test' -- webhooks don't subscribe to it
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookEventPlanFinished,
+	WebhookEventPlanStopped,
+	WebhookEventExecFailed,
+	WebhookEventBuildFailed,
+	WebhookEventChangesApplied,
+}
+
+func IsValidWebhookEvent(event WebhookEvent) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// headers sent with each delivery -- the signature is a hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook's secret, prefixed with "sha256="
+const (
+	WebhookEventHeader     = "X-Plandex-Event"
+	WebhookDeliveryHeader  = "X-Plandex-Delivery"
+	WebhookTimestampHeader = "X-Plandex-Timestamp"
+	WebhookSignatureHeader = "X-Plandex-Signature"
+)
+
+type WebhookPlan struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Branch    string `json:"branch"`
+	ProjectId string `json:"projectId"`
+}
+
+type WebhookPayload struct {
+	Event     WebhookEvent `json:"event"`
+	OrgId     string       `json:"orgId"`
+	Plan      *WebhookPlan `json:"plan,omitempty"`
+	UserId    string       `json:"userId,omitempty"`
+	UserEmail string       `json:"userEmail,omitempty"`
+
+	// true when the plan was started with --bg, without a client connected to the stream
+	Background bool `json:"background,omitempty"`
+
+	Error    string `json:"error,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+
+	// applied changes
+	CommitMsg string `json:"commitMsg,omitempty"`
+
+	// a one line summary -- lets chat incoming webhooks (like Slack's) post the payload as-is
+	Text string `json:"text"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}